@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
 	"go-backend-v2/internal/initialize"
 	"go-backend-v2/internal/middlewares"
+	"go-backend-v2/internal/repo"
 	"go-backend-v2/internal/router"
+	"go-backend-v2/internal/services"
 	"log"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -24,7 +29,7 @@ func main() {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     "http://localhost:5173, http://127.0.0.1:5173",
 		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Requested-With, X-Organization-ID,X-User-ID",
-		AllowMethods:     "GET, POST, PUT, DELETE, OPTIONS",
+		AllowMethods:     "GET, POST, PUT, PATCH, DELETE, OPTIONS",
 		AllowCredentials: true, // Chỉ bật khi cần thiết
 		ExposeHeaders:    "Set-Cookie, Authorization",
 		MaxAge:           86400,
@@ -33,6 +38,26 @@ func main() {
 
 	router.SetupRoutes(app)
 
+	// Bring stored workspace settings to the current schema version before serving requests
+	migrated, err := services.MigrateWorkspaceSettings(repo.NewWorkspaceRepository())
+	if err != nil {
+		log.Fatalf("Failed to migrate workspace settings: %v", err)
+	}
+	if migrated > 0 {
+		fmt.Printf("Workspace settings migrated to version %d: %d workspaces\n", common.WorkspaceSettingsVersion, migrated)
+	}
+
+	// Delete data export archives once their retention is over
+	dataExportService := services.NewDataExportService(repo.NewUserRepository(), repo.NewWorkspaceRepository(), repo.NewUserDeviceRepository(), "")
+	go services.RunDataExportSweeper(context.Background(), dataExportService, time.Hour)
+
+	// Purge accounts whose deletion grace period is over
+	userRepo := repo.NewUserRepository()
+	authCache := services.NewAuthCache(userRepo)
+	authService := services.NewAuthService(userRepo, services.NewDeviceService(repo.NewUserDeviceRepository()), authCache)
+	accountDeletionService := services.NewAccountDeletionService(userRepo, repo.NewWorkspaceRepository(), authService, authCache)
+	go services.RunAccountPurger(context.Background(), accountDeletionService, time.Hour)
+
 	port := fmt.Sprintf(":%d", global.Config.Server.Port)
 	if err := app.Listen(port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
@@ -1,7 +1,10 @@
 package global
 
 import (
+	"context"
 	"go-backend-v2/pkg/setting"
+	"io"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/redis/go-redis/v9"
@@ -13,10 +16,26 @@ type EventPublisher interface {
 	Close() error
 }
 
+// Notifier delivers user facing notifications (email, push, ...) rendered from a template
+type Notifier interface {
+	Send(recipient, subject, template string, data map[string]interface{}) error
+}
+
+// FileStorage keeps generated files such as data exports
+type FileStorage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a time limited direct download URL, empty when the backend has none
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
 var (
 	Config              *setting.Config
 	RedisClient         *redis.Client    // Redis connection
 	DB                  *gorm.DB         // MySQL database connection
 	RabbitMQConn        *amqp.Connection // RabbitMQ connection
 	EventTopicPublisher EventPublisher   // Event publisher service
+	UserNotifier        Notifier         // User notification service
+	Storage             FileStorage      // File storage backend
 )
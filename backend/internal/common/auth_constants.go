@@ -3,6 +3,8 @@ package common
 const (
 	JWTCookieName            = "access_token"
 	EncryptedTokenCookieName = "encrypted_token"
+	DPoPHeader               = "DPoP"
+	WorkspaceHeader          = "X-Organization-ID" // selects the workspace of routes without a workspace param
 )
 
 const (
@@ -14,10 +16,16 @@ const (
 	AuthProviderMicrosoft = "microsoft"
 	AuthProviderLinkedin  = "linkedin"
 	AuthProviderTwitter   = "twitter"
+	AuthProviderSAML      = "saml"
+	AuthProviderSCIM      = "scim"
+	AuthProviderLDAP      = "ldap"
 )
 
 const (
-	ContextUserID = "user_id"
+	ContextUserID      = "user_id"
+	ContextWorkspaceID = "workspace_id"
+	ContextSCIMTokenID = "scim_token_id"
+	ContextSession     = "session"
 )
 
 const (
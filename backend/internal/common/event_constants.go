@@ -1,12 +1,60 @@
 package common
 
 const (
-	WorkspaceCreatedLog = "workspace.created.log"
+	WorkspaceCreatedLog  = "workspace.created.log"
+	WorkspaceUpdatedLog  = "workspace.updated.log"
+	WorkspaceArchivedLog = "workspace.archived.log"
+
+	WorkspaceOwnershipTransferredLog = "workspace.ownership_transferred.log"
 
 	UserCreatedLog = "user.created.log"
 	UserLoginLog   = "user.login.log"
+	UserUpdatedLog = "user.updated.log"
+
+	UserDataExportedLog = "user.data_exported.log"
+
+	UserDeletionScheduledLog = "user.deletion_scheduled.log"
+	UserRestoredLog          = "user.restored.log"
+	UserPurgedLog            = "user.purged.log"
+
+	UserNewDeviceLoginLog = "user.new_device_login.log"
+	UserProvisionedLog    = "user.provisioned.log"
+
+	MembershipInvitedLog       = "membership.invited.log"
+	MembershipRequestedLog     = "membership.requested.log"
+	MembershipJoinedLog        = "membership.joined.log"
+	MembershipStatusChangedLog = "membership.status_changed.log"
+	MembershipRoleChangedLog   = "membership.role_changed.log"
+	MembershipRemovedLog       = "membership.removed.log"
+
+	RoleCreatedLog = "role.created.log"
+	RoleUpdatedLog = "role.updated.log"
+	RoleDeletedLog = "role.deleted.log"
+
+	GroupCreatedLog       = "group.created.log"
+	GroupUpdatedLog       = "group.updated.log"
+	GroupDeletedLog       = "group.deleted.log"
+	GroupMemberAddedLog   = "group.member_added.log"
+	GroupMemberRemovedLog = "group.member_removed.log"
 )
 
 const (
 	SourceServiceIAM = "iam_service"
+
+	MembershipSourceInvitation  = "invitation"
+	MembershipSourceMemberAPI   = "member_api"   // changes made by workspace members through the API
+	MembershipSourceDomain      = "domain"       // joined with an email on a verified domain of the workspace
+	MembershipSourceJoinRequest = "join_request" // joined once a member approved the request
+)
+
+const (
+	NotificationTemplateNewDeviceLogin     = "security.new_device_login"
+	NotificationTemplateEmailChangeConfirm = "account.email_change_confirm"
+	NotificationTemplateEmailChangeNotice  = "account.email_change_requested"
+	NotificationTemplateEmailChanged       = "account.email_changed"
+	NotificationTemplateDataExportReady    = "account.data_export_ready"
+	NotificationTemplateDeletionScheduled  = "account.deletion_scheduled"
+	NotificationTemplateWorkspaceInvite    = "workspace.invitation"
+	NotificationTemplateOwnershipTransfer  = "workspace.ownership_transfer"
+	NotificationTemplateJoinApproved       = "workspace.join_request_approved"
 )
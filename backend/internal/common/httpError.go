@@ -15,6 +15,11 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("%s: %s", e.Code, e.Message)
 }
 
+// NewForbiddenError is ErrForbidden with the reason access was denied
+func NewForbiddenError(reason string) *APIError {
+	return &APIError{Status: ErrForbidden.Status, Code: ErrForbidden.Code, Message: reason}
+}
+
 var (
 	// General errors
 	ErrInvalidInput        = &APIError{Status: http.StatusBadRequest, Code: "INVALID_INPUT", Message: "Invalid input"}
@@ -37,6 +42,9 @@ var (
 	ErrInvalidTokenFormat   = &APIError{Status: http.StatusUnauthorized, Code: "INVALID_TOKEN_FORMAT", Message: "Invalid authorization header format. Expected: Bearer <token>"}
 	ErrInvalidTokenType     = &APIError{Status: http.StatusUnauthorized, Code: "INVALID_TOKEN_TYPE", Message: "Invalid token type. Access token required"}
 	ErrTokenRequired        = &APIError{Status: http.StatusUnauthorized, Code: "TOKEN_REQUIRED", Message: "Token is required"}
+	ErrDPoPProofRequired    = &APIError{Status: http.StatusUnauthorized, Code: "DPOP_PROOF_REQUIRED", Message: "A DPoP proof is required for this token"}
+	ErrDPoPProofInvalid     = &APIError{Status: http.StatusUnauthorized, Code: "DPOP_PROOF_INVALID", Message: "DPoP proof is invalid"}
+	ErrSessionExpired       = &APIError{Status: http.StatusUnauthorized, Code: "SESSION_EXPIRED", Message: "Session has expired"}
 	ErrTokenRefreshFailed   = &APIError{Status: http.StatusUnauthorized, Code: "TOKEN_REFRESH_FAILED", Message: "Failed to refresh token"}
 	ErrRegistrationFailed   = &APIError{Status: http.StatusInternalServerError, Code: "REGISTRATION_FAILED", Message: "Failed to register user"}
 	ErrAuthenticationFailed = &APIError{Status: http.StatusInternalServerError, Code: "AUTHENTICATION_FAILED", Message: "Failed to authenticate user"}
@@ -54,6 +62,32 @@ var (
 	ErrUserCreationFailed = &APIError{Status: http.StatusInternalServerError, Code: "USER_CREATION_FAILED", Message: "Failed to create user"}
 	ErrUserUpdateFailed   = &APIError{Status: http.StatusInternalServerError, Code: "USER_UPDATE_FAILED", Message: "Failed to update user"}
 	ErrUserDeleteFailed   = &APIError{Status: http.StatusInternalServerError, Code: "USER_DELETE_FAILED", Message: "Failed to delete user"}
+	ErrInvalidDateOfBirth = &APIError{Status: http.StatusBadRequest, Code: "INVALID_DATE_OF_BIRTH", Message: "Date of birth must be in the past"}
+	ErrCountryNotAllowed  = &APIError{Status: http.StatusBadRequest, Code: "COUNTRY_NOT_ALLOWED", Message: "Country is not allowed"}
+	ErrIncorrectPassword  = &APIError{Status: http.StatusForbidden, Code: "INCORRECT_PASSWORD", Message: "Current password is incorrect"}
+
+	// Data export errors
+	ErrDataExportNotFound    = &APIError{Status: http.StatusNotFound, Code: "DATA_EXPORT_NOT_FOUND", Message: "Data export not found"}
+	ErrDataExportLinkInvalid = &APIError{Status: http.StatusNotFound, Code: "DATA_EXPORT_LINK_INVALID", Message: "Download link is invalid or has expired"}
+	ErrStorageUnavailable    = &APIError{Status: http.StatusServiceUnavailable, Code: "STORAGE_UNAVAILABLE", Message: "File storage is not available"}
+
+	// Avatar errors
+	ErrAvatarFileRequired    = &APIError{Status: http.StatusBadRequest, Code: "AVATAR_FILE_REQUIRED", Message: "An image must be uploaded in the file field"}
+	ErrAvatarUnsupportedType = &APIError{Status: http.StatusUnsupportedMediaType, Code: "AVATAR_UNSUPPORTED_TYPE", Message: "Avatar must be a PNG, JPEG, GIF or WebP image"}
+	ErrAvatarInvalidImage    = &APIError{Status: http.StatusBadRequest, Code: "AVATAR_INVALID_IMAGE", Message: "Avatar image could not be read"}
+	ErrAvatarTooLarge        = &APIError{Status: http.StatusRequestEntityTooLarge, Code: "AVATAR_TOO_LARGE", Message: "Avatar image is too large"}
+	ErrAvatarNotFound        = &APIError{Status: http.StatusNotFound, Code: "AVATAR_NOT_FOUND", Message: "Avatar not found"}
+
+	// Account deletion errors
+	ErrAccountRestoreTokenInvalid = &APIError{Status: http.StatusBadRequest, Code: "ACCOUNT_RESTORE_TOKEN_INVALID", Message: "Restore link is invalid or has expired"}
+
+	// Email change errors
+	ErrEmailUnchanged          = &APIError{Status: http.StatusBadRequest, Code: "EMAIL_UNCHANGED", Message: "New email is the same as the current one"}
+	ErrEmailChangeTokenInvalid = &APIError{Status: http.StatusBadRequest, Code: "EMAIL_CHANGE_TOKEN_INVALID", Message: "Email change link is invalid or has expired"}
+	ErrRecentSignInRequired    = &APIError{Status: http.StatusForbidden, Code: "RECENT_SIGN_IN_REQUIRED", Message: "Sign in again before changing your email"}
+
+	// Device errors
+	ErrDeviceNotFound = &APIError{Status: http.StatusNotFound, Code: "DEVICE_NOT_FOUND", Message: "Device not found"}
 
 	// Database transaction errors
 	ErrTransactionFailed = &APIError{Status: http.StatusInternalServerError, Code: "TRANSACTION_FAILED", Message: "Database transaction failed"}
@@ -62,7 +96,17 @@ var (
 	ErrWorkspaceCreateForbidden = &APIError{
 		Status:  http.StatusForbidden,
 		Code:    "WORKSPACE_CREATE_FORBIDDEN",
-		Message: "Only super admin users can create workspaces",
+		Message: "Workspace creation is restricted to super admin users",
+	}
+	ErrWorkspaceQuotaExceeded = &APIError{
+		Status:  http.StatusForbidden,
+		Code:    "WORKSPACE_QUOTA_EXCEEDED",
+		Message: "You already own the maximum number of workspaces",
+	}
+	ErrWorkspacePlanInvalid = &APIError{
+		Status:  http.StatusBadRequest,
+		Code:    "WORKSPACE_PLAN_INVALID",
+		Message: "Plan is not one of the configured workspace plans",
 	}
 	ErrWorkspaceNameRequired = &APIError{
 		Status:  http.StatusBadRequest,
@@ -79,4 +123,80 @@ var (
 		Code:    "WORKSPACE_SLUG_GENERATION_FAILED",
 		Message: "Failed to generate unique workspace slug",
 	}
+	ErrWorkspaceSlugTaken    = &APIError{Status: http.StatusConflict, Code: "WORKSPACE_SLUG_TAKEN", Message: "Slug is used by another workspace"}
+	ErrWorkspaceSlugReserved = &APIError{Status: http.StatusBadRequest, Code: "WORKSPACE_SLUG_RESERVED", Message: "Slug is reserved"}
+	ErrWorkspaceNotFound     = &APIError{Status: http.StatusNotFound, Code: "WORKSPACE_NOT_FOUND", Message: "Workspace not found"}
+	ErrWorkspaceRequired     = &APIError{Status: http.StatusBadRequest, Code: "WORKSPACE_REQUIRED", Message: "Workspace is required, pass it in the path or the X-Organization-ID header"}
+	ErrWorkspaceInactive     = &APIError{Status: http.StatusForbidden, Code: "WORKSPACE_INACTIVE", Message: "Workspace is not active"}
+	ErrMembershipInactive    = &APIError{Status: http.StatusForbidden, Code: "MEMBERSHIP_INACTIVE", Message: "Workspace membership is not active"}
+	ErrWorkspaceRoleNotFound = &APIError{Status: http.StatusNotFound, Code: "WORKSPACE_ROLE_NOT_FOUND", Message: "Workspace role not found"}
+	ErrWorkspaceRoleExists   = &APIError{Status: http.StatusConflict, Code: "WORKSPACE_ROLE_EXISTS", Message: "A role with this name already exists in the workspace"}
+	ErrWorkspaceRoleBuiltIn  = &APIError{Status: http.StatusConflict, Code: "WORKSPACE_ROLE_BUILT_IN", Message: "Built-in roles cannot be renamed or deleted, and the admin role cannot be changed"}
+	ErrWorkspaceRoleInUse    = &APIError{Status: http.StatusConflict, Code: "WORKSPACE_ROLE_IN_USE", Message: "Role is still assigned to members or groups or inherited by other roles, move them to another role first"}
+	ErrInvalidPermission     = &APIError{Status: http.StatusBadRequest, Code: "INVALID_PERMISSION", Message: "Permission is not a known resource:action"}
+	ErrRoleInheritanceCycle  = &APIError{Status: http.StatusConflict, Code: "ROLE_INHERITANCE_CYCLE", Message: "Role would inherit from itself through the roles it inherits from"}
+	ErrWorkspaceArchived     = &APIError{Status: http.StatusConflict, Code: "WORKSPACE_ARCHIVED", Message: "Workspace is archived and read-only"}
+	ErrWorkspaceNotArchived  = &APIError{Status: http.StatusConflict, Code: "WORKSPACE_NOT_ARCHIVED", Message: "Workspace is not archived"}
+	ErrInvalidSettings       = &APIError{Status: http.StatusBadRequest, Code: "INVALID_WORKSPACE_SETTINGS", Message: "Workspace settings do not match the settings schema"}
+
+	ErrWorkspacePermissionDenied = &APIError{Status: http.StatusForbidden, Code: "WORKSPACE_PERMISSION_DENIED", Message: "You do not have permission to perform this action in the workspace"}
+
+	// Workspace invitation errors
+	ErrInvitationNotFound      = &APIError{Status: http.StatusNotFound, Code: "INVITATION_NOT_FOUND", Message: "Invitation not found"}
+	ErrInvitationInvalid       = &APIError{Status: http.StatusBadRequest, Code: "INVITATION_INVALID", Message: "Invitation is invalid, no longer pending or has expired"}
+	ErrInvitationPending       = &APIError{Status: http.StatusConflict, Code: "INVITATION_PENDING", Message: "An invitation for this email is already pending, resend it instead"}
+	ErrInvitationEmailMismatch = &APIError{Status: http.StatusForbidden, Code: "INVITATION_EMAIL_MISMATCH", Message: "Invitation was sent to a different email address"}
+	ErrInvitationResendTooSoon = &APIError{Status: http.StatusTooManyRequests, Code: "INVITATION_RESEND_TOO_SOON", Message: "Invitation was sent moments ago, try again later"}
+	ErrAlreadyWorkspaceMember  = &APIError{Status: http.StatusConflict, Code: "ALREADY_WORKSPACE_MEMBER", Message: "User is already a member of this workspace"}
+
+	// Workspace domain and join request errors
+	ErrDomainNotFound           = &APIError{Status: http.StatusNotFound, Code: "DOMAIN_NOT_FOUND", Message: "Workspace domain not found"}
+	ErrDomainInvalid            = &APIError{Status: http.StatusBadRequest, Code: "DOMAIN_INVALID", Message: "Domain must be a host name such as example.com"}
+	ErrDomainExists             = &APIError{Status: http.StatusConflict, Code: "DOMAIN_EXISTS", Message: "Workspace already has this domain"}
+	ErrDomainVerificationFailed = &APIError{Status: http.StatusUnprocessableEntity, Code: "DOMAIN_VERIFICATION_FAILED", Message: "The verification TXT record of the domain was not found"}
+	ErrDomainLookupFailed       = &APIError{Status: http.StatusServiceUnavailable, Code: "DOMAIN_LOOKUP_FAILED", Message: "DNS lookup of the domain failed, try again later"}
+	ErrEmailNotVerified         = &APIError{Status: http.StatusForbidden, Code: "EMAIL_NOT_VERIFIED", Message: "Verify your email address first"}
+	ErrJoinRequestNotFound      = &APIError{Status: http.StatusNotFound, Code: "JOIN_REQUEST_NOT_FOUND", Message: "No pending join request"}
+	ErrJoinRequestPending       = &APIError{Status: http.StatusConflict, Code: "JOIN_REQUEST_PENDING", Message: "Your request to join this workspace is awaiting approval"}
+	ErrJoinRequestRejected      = &APIError{Status: http.StatusForbidden, Code: "JOIN_REQUEST_REJECTED", Message: "Your request to join this workspace was rejected"}
+
+	// Workspace ownership transfer errors
+	ErrOwnershipTransferForbidden = &APIError{Status: http.StatusForbidden, Code: "OWNERSHIP_TRANSFER_FORBIDDEN", Message: "Only the workspace owner or a super admin can transfer ownership"}
+	ErrOwnershipTransferNotFound  = &APIError{Status: http.StatusNotFound, Code: "OWNERSHIP_TRANSFER_NOT_FOUND", Message: "No pending ownership transfer"}
+	ErrOwnershipTransferTarget    = &APIError{Status: http.StatusBadRequest, Code: "OWNERSHIP_TRANSFER_TARGET_INVALID", Message: "Ownership can only be transferred to another active member of the workspace"}
+	ErrOwnershipTransferInvalid   = &APIError{Status: http.StatusConflict, Code: "OWNERSHIP_TRANSFER_INVALID", Message: "Ownership transfer is no longer valid"}
+
+	// Resource catalog errors
+	ErrResourceNotFound = &APIError{Status: http.StatusNotFound, Code: "RESOURCE_NOT_FOUND", Message: "Resource not found"}
+	ErrResourceExists   = &APIError{Status: http.StatusConflict, Code: "RESOURCE_EXISTS", Message: "A resource with this name already exists"}
+
+	// Workspace member management errors
+	ErrMemberNotFound       = &APIError{Status: http.StatusNotFound, Code: "MEMBER_NOT_FOUND", Message: "Workspace member not found"}
+	ErrOwnerMembershipFixed = &APIError{Status: http.StatusConflict, Code: "OWNER_MEMBERSHIP_FIXED", Message: "The workspace owner cannot be suspended, removed or given another role"}
+	ErrMemberLimitReached   = &APIError{Status: http.StatusForbidden, Code: "MEMBER_LIMIT_REACHED", Message: "Workspace has reached the member limit of its plan"}
+	ErrInvalidCursor        = &APIError{Status: http.StatusBadRequest, Code: "INVALID_CURSOR", Message: "Pagination cursor is invalid"}
+
+	// Workspace group errors
+	ErrGroupNotFound       = &APIError{Status: http.StatusNotFound, Code: "GROUP_NOT_FOUND", Message: "Workspace group not found"}
+	ErrGroupExists         = &APIError{Status: http.StatusConflict, Code: "GROUP_EXISTS", Message: "A group with this name already exists in the workspace"}
+	ErrGroupMemberNotFound = &APIError{Status: http.StatusNotFound, Code: "GROUP_MEMBER_NOT_FOUND", Message: "User is not a member of the group"}
+
+	// SAML single sign-on errors
+	ErrSAMLNotConfigured        = &APIError{Status: http.StatusNotFound, Code: "SAML_NOT_CONFIGURED", Message: "SAML single sign-on is not configured for this workspace"}
+	ErrSAMLConfigInvalid        = &APIError{Status: http.StatusBadRequest, Code: "SAML_CONFIG_INVALID", Message: "SAML configuration is invalid"}
+	ErrSAMLInvalidResponse      = &APIError{Status: http.StatusUnauthorized, Code: "SAML_INVALID_RESPONSE", Message: "SAML response could not be verified"}
+	ErrSAMLMissingEmail         = &APIError{Status: http.StatusUnprocessableEntity, Code: "SAML_MISSING_EMAIL", Message: "SAML assertion does not contain an email address"}
+	ErrSAMLAccountConflict      = &APIError{Status: http.StatusConflict, Code: "SAML_ACCOUNT_CONFLICT", Message: "An account with this email exists outside of this workspace"}
+	ErrSAMLProvisioningDisabled = &APIError{Status: http.StatusForbidden, Code: "SAML_PROVISIONING_DISABLED", Message: "Just-in-time provisioning is disabled for this workspace"}
+	ErrSAMLDomainNotVerified    = &APIError{Status: http.StatusForbidden, Code: "SAML_DOMAIN_NOT_VERIFIED", Message: "Accounts can only be provisioned for email domains verified by this workspace"}
+
+	// LDAP authentication errors
+	ErrLDAPDisabled        = &APIError{Status: http.StatusNotFound, Code: "LDAP_DISABLED", Message: "LDAP authentication is not enabled"}
+	ErrLDAPUnavailable     = &APIError{Status: http.StatusServiceUnavailable, Code: "LDAP_UNAVAILABLE", Message: "LDAP directory is unavailable"}
+	ErrLDAPMissingEmail    = &APIError{Status: http.StatusUnprocessableEntity, Code: "LDAP_MISSING_EMAIL", Message: "LDAP entry does not contain an email address"}
+	ErrLDAPAccountConflict = &APIError{Status: http.StatusConflict, Code: "LDAP_ACCOUNT_CONFLICT", Message: "An account with this email already exists"}
+
+	// SCIM provisioning errors
+	ErrSCIMTokenNotFound = &APIError{Status: http.StatusNotFound, Code: "SCIM_TOKEN_NOT_FOUND", Message: "SCIM token not found"}
+	ErrSCIMTokenInvalid  = &APIError{Status: http.StatusUnauthorized, Code: "SCIM_TOKEN_INVALID", Message: "SCIM token is invalid, expired or revoked"}
 )
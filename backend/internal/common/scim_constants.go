@@ -0,0 +1,58 @@
+package common
+
+import "net/http"
+
+const (
+	SCIMContentType = "application/scim+json"
+
+	SCIMSchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIMSchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SCIMSchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SCIMSchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SCIMSchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SCIMSchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+
+	SCIMBearerAuthScheme  = "Bearer"
+	SCIMTokenPrefix       = "scim_"
+	SCIMDefaultPageSize   = 100
+	SCIMMaxPageSize       = 200
+	SCIMMaxPatchOperation = 100
+)
+
+// SCIM error types (RFC 7644 section 3.12)
+const (
+	SCIMTypeInvalidFilter = "invalidFilter"
+	SCIMTypeUniqueness    = "uniqueness"
+	SCIMTypeMutability    = "mutability"
+	SCIMTypeInvalidSyntax = "invalidSyntax"
+	SCIMTypeInvalidPath   = "invalidPath"
+	SCIMTypeInvalidValue  = "invalidValue"
+	SCIMTypeNoTarget      = "noTarget"
+)
+
+// SCIMError is returned by the SCIM endpoints and rendered in the SCIM error format
+type SCIMError struct {
+	Status   int
+	ScimType string
+	Detail   string
+}
+
+func (e *SCIMError) Error() string {
+	if e.ScimType != "" {
+		return e.ScimType + ": " + e.Detail
+	}
+	return e.Detail
+}
+
+func NewSCIMError(status int, scimType, detail string) *SCIMError {
+	return &SCIMError{Status: status, ScimType: scimType, Detail: detail}
+}
+
+var (
+	ErrSCIMUserNotFound   = &SCIMError{Status: http.StatusNotFound, Detail: "User not found"}
+	ErrSCIMGroupNotFound  = &SCIMError{Status: http.StatusNotFound, Detail: "Group not found"}
+	ErrSCIMUserExists     = &SCIMError{Status: http.StatusConflict, ScimType: SCIMTypeUniqueness, Detail: "User is already a member of this workspace"}
+	ErrSCIMAccountExists  = &SCIMError{Status: http.StatusConflict, ScimType: SCIMTypeUniqueness, Detail: "An account with this userName exists outside of this workspace"}
+	ErrSCIMGroupExists    = &SCIMError{Status: http.StatusConflict, ScimType: SCIMTypeUniqueness, Detail: "A group with this displayName already exists"}
+	ErrSCIMOwnerImmutable = &SCIMError{Status: http.StatusBadRequest, ScimType: SCIMTypeMutability, Detail: "The workspace owner cannot be modified through SCIM"}
+)
@@ -6,6 +6,9 @@ const (
 	UserStatusSuspended = "suspended"
 	UserStatusPending   = "pending"
 	UserStatusDeleted   = "deleted"
+
+	// UserStatusPendingDeletion is an account the user deleted, restorable until its grace period ends
+	UserStatusPendingDeletion = "pending_deletion"
 )
 
 const (
@@ -13,3 +16,13 @@ const (
 	GlobalRoleSuperMember = "super_member"
 	GlobalRoleCustomer    = "customer"
 )
+
+const (
+	DataExportStatusPending    = "pending"
+	DataExportStatusProcessing = "processing"
+	DataExportStatusReady      = "ready"
+	DataExportStatusFailed     = "failed"
+
+	DataExportFormatJSON = "json"
+	DataExportFormatZIP  = "zip"
+)
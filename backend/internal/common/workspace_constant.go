@@ -1,5 +1,47 @@
 package common
 
 const (
-	WorkspaceRoleAdmin = "admin"
+	WorkspaceRoleAdmin  = "admin"
+	WorkspaceRoleMember = "member"
+)
+
+// Workspace creation modes, set by workspace.creation_mode
+const (
+	WorkspaceCreationAdminOnly = "admin_only" // only super admins create workspaces
+	WorkspaceCreationSelfServe = "self_serve" // any active user, within the owned workspace quota
+)
+
+// WorkspaceDefaultPlan is used when no default plan is configured
+const WorkspaceDefaultPlan = "free"
+
+// WorkspaceSettingsVersion is the version of the workspace settings schema. Bumping it requires
+// a migration from the previous version and an updated schema document.
+const WorkspaceSettingsVersion = 2
+
+// Workspace permissions granted through roles. PermissionAll grants every permission.
+const (
+	PermissionAll              = "all"
+	PermissionWorkspaceRead    = "workspace:read"
+	PermissionWorkspaceUpdate  = "workspace:update"
+	PermissionWorkspaceArchive = "workspace:archive"
+	PermissionMemberRead       = "member:read"
+	PermissionMemberInvite     = "member:invite"
+	PermissionMemberUpdate     = "member:update" // change the role or suspend and reactivate
+	PermissionMemberRemove     = "member:remove"
+	PermissionRoleRead         = "role:read"
+	PermissionRoleCreate       = "role:create"
+	PermissionRoleUpdate       = "role:update"
+	PermissionRoleDelete       = "role:delete"
+	PermissionGroupRead        = "group:read"
+	PermissionGroupCreate      = "group:create"
+	PermissionGroupUpdate      = "group:update" // rename, change members and assign roles
+	PermissionGroupDelete      = "group:delete"
+)
+
+const (
+	DefaultWorkspacePageSize = 20
+	MaxWorkspacePageSize     = 100
+
+	DefaultMemberPageSize = 50
+	MaxMemberPageSize     = 200
 )
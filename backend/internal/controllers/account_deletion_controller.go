@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/services"
+	"go-backend-v2/pkg/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AccountDeletionController struct {
+	accountDeletionService services.AccountDeletionServiceInterface
+	validator              *validator.Validate
+}
+
+func NewAccountDeletionController(accountDeletionService services.AccountDeletionServiceInterface) *AccountDeletionController {
+	v := validator.New()
+	utils.SetupCustomValidators(v)
+
+	return &AccountDeletionController{
+		accountDeletionService: accountDeletionService,
+		validator:              v,
+	}
+}
+
+// DeleteAccount schedules the current user for deletion and signs them out everywhere
+func (c *AccountDeletionController) DeleteAccount(ctx *fiber.Ctx) error {
+	userID := ctx.Locals(common.ContextUserID)
+	if userID == nil {
+		return common.ErrUnauthorized
+	}
+
+	userIDStr, ok := userID.(string)
+	if !ok {
+		return common.ErrUnauthorized
+	}
+
+	purgeAt, err := c.accountDeletionService.ScheduleDeletion(userIDStr)
+	if err != nil {
+		return err
+	}
+
+	clearJWTCookie(ctx)
+	clearEncryptedTokenCookie(ctx)
+
+	return ctx.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message":               "Account scheduled for deletion",
+		"deletion_scheduled_at": purgeAt,
+	})
+}
+
+// RestoreAccount is called with the token from the deletion notice
+func (c *AccountDeletionController) RestoreAccount(ctx *fiber.Ctx) error {
+	var req dto.RestoreAccountRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	if err := c.accountDeletionService.RestoreAccount(req.Token); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.MessageResponse{
+		Message: "Account restored successfully, please sign in again",
+	})
+}
@@ -13,15 +13,19 @@ import (
 
 type AdminController struct {
 	workspaceService services.WorkspaceServiceInterface
+	samlService      services.SAMLServiceInterface
+	scimService      services.SCIMServiceInterface
 	validator        *validator.Validate
 }
 
-func NewAdminController(workspaceService services.WorkspaceServiceInterface) *AdminController {
+func NewAdminController(workspaceService services.WorkspaceServiceInterface, samlService services.SAMLServiceInterface, scimService services.SCIMServiceInterface) *AdminController {
 	v := validator.New()
 	utils.SetupCustomValidators(v)
 
 	return &AdminController{
 		workspaceService: workspaceService,
+		samlService:      samlService,
+		scimService:      scimService,
 		validator:        v,
 	}
 }
@@ -60,3 +64,145 @@ func (c *AdminController) CreateWorkspace(ctx *fiber.Ctx) error {
 		},
 	})
 }
+
+func (c *AdminController) ListWorkspaces(ctx *fiber.Ctx) error {
+	query := dto.ListWorkspacesQuery{
+		Status: ctx.Query("status"),
+		Search: ctx.Query("q"),
+		Offset: ctx.QueryInt("offset", 0),
+		Limit:  ctx.QueryInt("limit", common.DefaultWorkspacePageSize),
+	}
+
+	if err := c.validator.Struct(&query); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	workspaces, err := c.workspaceService.ListWorkspaces(&query)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Workspaces retrieved successfully",
+		"data":    workspaces,
+	})
+}
+
+func (c *AdminController) GetWorkspace(ctx *fiber.Ctx) error {
+	workspace, err := c.workspaceService.GetWorkspaceByID(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Workspace retrieved successfully",
+		"data":    workspace,
+	})
+}
+
+func (c *AdminController) UpdateWorkspacePlan(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	var req dto.UpdateWorkspacePlanRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	workspace, err := c.workspaceService.UpdatePlan(userID, ctx.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Workspace plan updated successfully",
+		"data":    workspace,
+	})
+}
+
+func (c *AdminController) GetSAMLConfig(ctx *fiber.Ctx) error {
+	config, err := c.samlService.GetConfig(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "SAML configuration retrieved successfully",
+		"data":    config,
+	})
+}
+
+func (c *AdminController) UpsertSAMLConfig(ctx *fiber.Ctx) error {
+	var req dto.UpsertSAMLConfigRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	config, err := c.samlService.UpsertConfig(ctx.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "SAML configuration saved successfully",
+		"data":    config,
+	})
+}
+
+func (c *AdminController) CreateSCIMToken(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	var req dto.CreateSCIMTokenRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	token, err := c.scimService.CreateToken(ctx.Params("id"), userID, &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "SCIM token created successfully. Store it now, it will not be shown again",
+		"data":    token,
+	})
+}
+
+func (c *AdminController) ListSCIMTokens(ctx *fiber.Ctx) error {
+	tokens, err := c.scimService.ListTokens(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "SCIM tokens retrieved successfully",
+		"data":    tokens,
+	})
+}
+
+func (c *AdminController) RevokeSCIMToken(ctx *fiber.Ctx) error {
+	if err := c.scimService.RevokeToken(ctx.Params("id"), ctx.Params("tokenId")); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.MessageResponse{
+		Message: "SCIM token revoked successfully",
+	})
+}
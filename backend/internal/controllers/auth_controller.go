@@ -13,17 +13,19 @@ import (
 )
 
 type AuthController struct {
-	authService services.AuthServiceInterface
-	validator   *validator.Validate
+	authService       services.AuthServiceInterface
+	invitationService services.InvitationServiceInterface
+	validator         *validator.Validate
 }
 
-func NewAuthController(authService services.AuthServiceInterface) *AuthController {
+func NewAuthController(authService services.AuthServiceInterface, invitationService services.InvitationServiceInterface) *AuthController {
 	v := validator.New()
 	utils.SetupCustomValidators(v)
 
 	return &AuthController{
-		authService: authService,
-		validator:   v,
+		authService:       authService,
+		invitationService: invitationService,
+		validator:         v,
 	}
 }
 
@@ -38,10 +40,31 @@ func (c *AuthController) Signup(ctx *fiber.Ctx) error {
 		return common.ErrValidationFailed
 	}
 
-	if err := c.authService.Signup(&req); err != nil {
+	// Check the invitation first, so that a bad link does not leave an account behind
+	if req.InvitationToken != "" {
+		if err := c.invitationService.CheckSignupInvitation(req.InvitationToken, req.Email); err != nil {
+			return err
+		}
+	}
+
+	user, err := c.authService.Signup(&req)
+	if err != nil {
 		return err
 	}
 
+	if req.InvitationToken != "" {
+		workspace, err := c.invitationService.AcceptInvitation(user.ID, req.InvitationToken)
+		if err != nil {
+			// The account exists at this point, the invitation can still be accepted after login
+			fmt.Printf("Warning: failed to accept invitation on signup: %v\n", err)
+		} else {
+			return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+				"message":   "Signup successful",
+				"workspace": workspace,
+			})
+		}
+	}
+
 	return ctx.Status(fiber.StatusCreated).JSON(dto.MessageResponse{
 		Message: "Signup successful",
 	})
@@ -58,17 +81,30 @@ func (c *AuthController) Login(ctx *fiber.Ctx) error {
 		return common.ErrValidationFailed
 	}
 
+	req.UserAgent = ctx.Get(fiber.HeaderUserAgent)
+	req.IPAddress = ctx.IP()
+	req.DPoPProof = ctx.Get(common.DPoPHeader)
+	req.RequestURL = utils.ExternalRequestURL(global.Config.Server.PublicURL, ctx.BaseURL(), ctx.Path())
+
 	loginResponse, err := c.authService.Login(&req)
 	if err != nil {
 		return err
 	}
 
-	c.setJWTCookie(ctx, loginResponse.AccessToken)
-	c.setEncryptedTokenCookie(ctx, loginResponse.EncryptedToken)
+	setSessionCookies(ctx, loginResponse)
 
 	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "Login successful",
 		"user":    loginResponse.User,
+		"session": loginResponse.Session,
+	})
+}
+
+// GetSession returns the lifetime of the current session, as extended by this very request
+func (c *AuthController) GetSession(ctx *fiber.Ctx) error {
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Session retrieved successfully",
+		"data":    ctx.Locals(common.ContextSession),
 	})
 }
 
@@ -89,71 +125,10 @@ func (c *AuthController) Logout(ctx *fiber.Ctx) error {
 	}
 
 	// Clear both cookies
-	c.clearJWTCookie(ctx)
-	c.clearEncryptedTokenCookie(ctx)
+	clearJWTCookie(ctx)
+	clearEncryptedTokenCookie(ctx)
 
 	return ctx.Status(fiber.StatusOK).JSON(dto.MessageResponse{
 		Message: "Logout successful",
 	})
 }
-
-func (c *AuthController) setJWTCookie(ctx *fiber.Ctx, token string) {
-	ctx.Cookie(&fiber.Cookie{
-		Name:     common.JWTCookieName,
-		Value:    token,
-		MaxAge:   int(global.Config.JWT.ExpirationTime.Seconds()),
-		HTTPOnly: global.Config.Cookie.HttpOnly,
-		Secure:   global.Config.Cookie.Secure,
-		SameSite: c.getSameSiteValue(global.Config.Cookie.SameSite),
-		Domain:   global.Config.Cookie.Domain,
-	})
-}
-
-func (c *AuthController) clearJWTCookie(ctx *fiber.Ctx) {
-	ctx.Cookie(&fiber.Cookie{
-		Name:     common.JWTCookieName,
-		Value:    "",
-		MaxAge:   -1,
-		HTTPOnly: global.Config.Cookie.HttpOnly,
-		Secure:   global.Config.Cookie.Secure,
-		SameSite: c.getSameSiteValue(global.Config.Cookie.SameSite),
-		Domain:   global.Config.Cookie.Domain,
-	})
-}
-
-func (c *AuthController) setEncryptedTokenCookie(ctx *fiber.Ctx, token string) {
-	ctx.Cookie(&fiber.Cookie{
-		Name:     common.EncryptedTokenCookieName,
-		Value:    token,
-		MaxAge:   int(global.Config.JWT.ExpirationTime.Seconds()),
-		HTTPOnly: global.Config.Cookie.HttpOnly,
-		Secure:   global.Config.Cookie.Secure,
-		SameSite: c.getSameSiteValue(global.Config.Cookie.SameSite),
-		Domain:   global.Config.Cookie.Domain,
-	})
-}
-
-func (c *AuthController) clearEncryptedTokenCookie(ctx *fiber.Ctx) {
-	ctx.Cookie(&fiber.Cookie{
-		Name:     common.EncryptedTokenCookieName,
-		Value:    "",
-		MaxAge:   -1,
-		HTTPOnly: global.Config.Cookie.HttpOnly,
-		Secure:   global.Config.Cookie.Secure,
-		SameSite: c.getSameSiteValue(global.Config.Cookie.SameSite),
-		Domain:   global.Config.Cookie.Domain,
-	})
-}
-
-func (c *AuthController) getSameSiteValue(sameSite string) string {
-	switch sameSite {
-	case common.CookieSameSiteStrict:
-		return "Strict"
-	case common.CookieSameSiteLax:
-		return "Lax"
-	case common.CookieSameSiteNone:
-		return "None"
-	default:
-		return "Strict"
-	}
-}
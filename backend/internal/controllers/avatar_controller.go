@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/services"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// avatarFormField is the multipart field carrying the uploaded image
+const avatarFormField = "file"
+
+type AvatarController struct {
+	avatarService services.AvatarServiceInterface
+}
+
+func NewAvatarController(avatarService services.AvatarServiceInterface) *AvatarController {
+	return &AvatarController{
+		avatarService: avatarService,
+	}
+}
+
+func (c *AvatarController) UploadUserAvatar(ctx *fiber.Ctx) error {
+	userID := ctx.Locals(common.ContextUserID)
+	if userID == nil {
+		return common.ErrUnauthorized
+	}
+
+	userIDStr, ok := userID.(string)
+	if !ok {
+		return common.ErrUnauthorized
+	}
+
+	data, err := readAvatarUpload(ctx)
+	if err != nil {
+		return err
+	}
+
+	avatar, err := c.avatarService.UploadUserAvatar(userIDStr, data)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Avatar updated successfully",
+		"avatar":  avatar,
+	})
+}
+
+func (c *AvatarController) DeleteUserAvatar(ctx *fiber.Ctx) error {
+	userID := ctx.Locals(common.ContextUserID)
+	if userID == nil {
+		return common.ErrUnauthorized
+	}
+
+	userIDStr, ok := userID.(string)
+	if !ok {
+		return common.ErrUnauthorized
+	}
+
+	if err := c.avatarService.DeleteUserAvatar(userIDStr); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Avatar removed successfully",
+	})
+}
+
+func (c *AvatarController) UploadWorkspaceAvatar(ctx *fiber.Ctx) error {
+	userID := ctx.Locals(common.ContextUserID)
+	if userID == nil {
+		return common.ErrUnauthorized
+	}
+
+	userIDStr, ok := userID.(string)
+	if !ok {
+		return common.ErrUnauthorized
+	}
+
+	data, err := readAvatarUpload(ctx)
+	if err != nil {
+		return err
+	}
+
+	avatar, err := c.avatarService.UploadWorkspaceAvatar(userIDStr, ctx.Params("id"), data)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Workspace avatar updated successfully",
+		"avatar":  avatar,
+	})
+}
+
+func (c *AvatarController) DeleteWorkspaceAvatar(ctx *fiber.Ctx) error {
+	userID := ctx.Locals(common.ContextUserID)
+	if userID == nil {
+		return common.ErrUnauthorized
+	}
+
+	userIDStr, ok := userID.(string)
+	if !ok {
+		return common.ErrUnauthorized
+	}
+
+	if err := c.avatarService.DeleteWorkspaceAvatar(userIDStr, ctx.Params("id")); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Workspace avatar removed successfully",
+	})
+}
+
+// Serve streams a stored avatar. Every upload gets a new name, so the files can be cached for good.
+func (c *AvatarController) Serve(ctx *fiber.Ctx) error {
+	body, contentType, err := c.avatarService.OpenAvatar(ctx.Params("*"))
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentType, contentType)
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=31536000, immutable")
+	ctx.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	// Pictures are embedded by the web client, which may live on another origin
+	ctx.Set(fiber.HeaderCrossOriginResourcePolicy, "cross-origin")
+
+	return ctx.SendStream(body)
+}
+
+// readAvatarUpload reads the uploaded image and refuses files above the configured size. The
+// request body is already buffered by then, only the app wide body limit bounds its size.
+func readAvatarUpload(ctx *fiber.Ctx) ([]byte, error) {
+	header, err := ctx.FormFile(avatarFormField)
+	if err != nil {
+		return nil, common.ErrAvatarFileRequired
+	}
+
+	maxSize := global.Config.Avatar.MaxSize
+	if maxSize > 0 && header.Size > maxSize {
+		return nil, common.ErrAvatarTooLarge
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, common.ErrAvatarFileRequired
+	}
+	defer file.Close()
+
+	reader := io.Reader(file)
+	if maxSize > 0 {
+		reader = io.LimitReader(file, maxSize+1)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, common.ErrAvatarFileRequired
+	}
+	if maxSize > 0 && int64(len(data)) > maxSize {
+		return nil, common.ErrAvatarTooLarge
+	}
+
+	return data, nil
+}
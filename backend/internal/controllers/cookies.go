@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// setSessionCookies stores both session tokens. Remember me sessions outlive the browser, the
+// others use session cookies that are dropped when the browser closes.
+func setSessionCookies(ctx *fiber.Ctx, loginResponse *dto.LoginResponse) {
+	maxAge := 0
+	if loginResponse.Session != nil && loginResponse.Session.RememberMe {
+		maxAge = int(time.Until(loginResponse.Session.ExpiresAt).Seconds())
+	}
+
+	setJWTCookie(ctx, loginResponse.AccessToken, maxAge)
+	setEncryptedTokenCookie(ctx, loginResponse.EncryptedToken, maxAge)
+}
+
+func setJWTCookie(ctx *fiber.Ctx, token string, maxAge int) {
+	ctx.Cookie(&fiber.Cookie{
+		Name:     common.JWTCookieName,
+		Value:    token,
+		MaxAge:   maxAge,
+		HTTPOnly: global.Config.Cookie.HttpOnly,
+		Secure:   global.Config.Cookie.Secure,
+		SameSite: getSameSiteValue(global.Config.Cookie.SameSite),
+		Domain:   global.Config.Cookie.Domain,
+	})
+}
+
+func clearJWTCookie(ctx *fiber.Ctx) {
+	ctx.Cookie(&fiber.Cookie{
+		Name:     common.JWTCookieName,
+		Value:    "",
+		MaxAge:   -1,
+		HTTPOnly: global.Config.Cookie.HttpOnly,
+		Secure:   global.Config.Cookie.Secure,
+		SameSite: getSameSiteValue(global.Config.Cookie.SameSite),
+		Domain:   global.Config.Cookie.Domain,
+	})
+}
+
+func setEncryptedTokenCookie(ctx *fiber.Ctx, token string, maxAge int) {
+	ctx.Cookie(&fiber.Cookie{
+		Name:     common.EncryptedTokenCookieName,
+		Value:    token,
+		MaxAge:   maxAge,
+		HTTPOnly: global.Config.Cookie.HttpOnly,
+		Secure:   global.Config.Cookie.Secure,
+		SameSite: getSameSiteValue(global.Config.Cookie.SameSite),
+		Domain:   global.Config.Cookie.Domain,
+	})
+}
+
+func clearEncryptedTokenCookie(ctx *fiber.Ctx) {
+	ctx.Cookie(&fiber.Cookie{
+		Name:     common.EncryptedTokenCookieName,
+		Value:    "",
+		MaxAge:   -1,
+		HTTPOnly: global.Config.Cookie.HttpOnly,
+		Secure:   global.Config.Cookie.Secure,
+		SameSite: getSameSiteValue(global.Config.Cookie.SameSite),
+		Domain:   global.Config.Cookie.Domain,
+	})
+}
+
+func getSameSiteValue(sameSite string) string {
+	switch sameSite {
+	case common.CookieSameSiteStrict:
+		return "Strict"
+	case common.CookieSameSiteLax:
+		return "Lax"
+	case common.CookieSameSiteNone:
+		return "None"
+	default:
+		return "Strict"
+	}
+}
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"fmt"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/services"
+	"go-backend-v2/pkg/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type DataExportController struct {
+	dataExportService services.DataExportServiceInterface
+	validator         *validator.Validate
+}
+
+func NewDataExportController(dataExportService services.DataExportServiceInterface) *DataExportController {
+	v := validator.New()
+	utils.SetupCustomValidators(v)
+
+	return &DataExportController{
+		dataExportService: dataExportService,
+		validator:         v,
+	}
+}
+
+func (c *DataExportController) RequestExport(ctx *fiber.Ctx) error {
+	userID := ctx.Locals(common.ContextUserID)
+	if userID == nil {
+		return common.ErrUnauthorized
+	}
+
+	userIDStr, ok := userID.(string)
+	if !ok {
+		return common.ErrUnauthorized
+	}
+
+	var req dto.CreateDataExportRequest
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&req); err != nil {
+			return common.ErrInvalidRequestBody
+		}
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	export, err := c.dataExportService.RequestExport(userIDStr, req.Format)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "Data export started",
+		"export":  export,
+	})
+}
+
+func (c *DataExportController) GetExport(ctx *fiber.Ctx) error {
+	userID := ctx.Locals(common.ContextUserID)
+	if userID == nil {
+		return common.ErrUnauthorized
+	}
+
+	userIDStr, ok := userID.(string)
+	if !ok {
+		return common.ErrUnauthorized
+	}
+
+	export, err := c.dataExportService.GetExport(userIDStr, ctx.Params("exportId"))
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Data export retrieved successfully",
+		"export":  export,
+	})
+}
+
+// Download streams an archive for a link handed out by GetExport. The link itself is the
+// credential, so that it also works from a plain browser download.
+func (c *DataExportController) Download(ctx *fiber.Ctx) error {
+	body, export, err := c.dataExportService.OpenDownload(ctx.Params("token"))
+	if err != nil {
+		return err
+	}
+
+	contentType := fiber.MIMEApplicationJSON
+	if export.Format == common.DataExportFormatZIP {
+		contentType = "application/zip"
+	}
+
+	ctx.Set(fiber.HeaderContentType, contentType)
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="data-export-%s.%s"`, export.CreatedAt.Format("2006-01-02"), export.Format))
+
+	return ctx.SendStream(body)
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/services"
+	"go-backend-v2/pkg/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type DomainController struct {
+	domainService services.DomainServiceInterface
+	validator     *validator.Validate
+}
+
+func NewDomainController(domainService services.DomainServiceInterface) *DomainController {
+	v := validator.New()
+	utils.SetupCustomValidators(v)
+
+	return &DomainController{
+		domainService: domainService,
+		validator:     v,
+	}
+}
+
+func (c *DomainController) ListDomains(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	domains, err := c.domainService.ListDomains(userID, ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Domains retrieved successfully",
+		"data":    domains,
+	})
+}
+
+func (c *DomainController) AddDomain(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	var req dto.AddDomainRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	domain, err := c.domainService.AddDomain(userID, ctx.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Domain added, publish the TXT record and verify it",
+		"data":    domain,
+	})
+}
+
+func (c *DomainController) VerifyDomain(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	domain, err := c.domainService.VerifyDomain(userID, ctx.Params("id"), ctx.Params("domainId"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Domain verified successfully",
+		"data":    domain,
+	})
+}
+
+func (c *DomainController) RemoveDomain(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	if err := c.domainService.RemoveDomain(userID, ctx.Params("id"), ctx.Params("domainId")); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.MessageResponse{
+		Message: "Domain removed successfully",
+	})
+}
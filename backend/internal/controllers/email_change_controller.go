@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/services"
+	"go-backend-v2/pkg/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type EmailChangeController struct {
+	emailChangeService services.EmailChangeServiceInterface
+	validator          *validator.Validate
+}
+
+func NewEmailChangeController(emailChangeService services.EmailChangeServiceInterface) *EmailChangeController {
+	v := validator.New()
+	utils.SetupCustomValidators(v)
+
+	return &EmailChangeController{
+		emailChangeService: emailChangeService,
+		validator:          v,
+	}
+}
+
+func (c *EmailChangeController) RequestChange(ctx *fiber.Ctx) error {
+	userID := ctx.Locals(common.ContextUserID)
+	if userID == nil {
+		return common.ErrUnauthorized
+	}
+
+	userIDStr, ok := userID.(string)
+	if !ok {
+		return common.ErrUnauthorized
+	}
+
+	var req dto.ChangeEmailRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	session, _ := ctx.Locals(common.ContextSession).(*dto.SessionMetadata)
+
+	if err := c.emailChangeService.RequestChange(userIDStr, session, &req); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(dto.MessageResponse{
+		Message: "Confirmation link sent to the new email address",
+	})
+}
+
+// ConfirmChange is called with the token from the link sent to the new address
+func (c *EmailChangeController) ConfirmChange(ctx *fiber.Ctx) error {
+	var req dto.EmailChangeTokenRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	if err := c.emailChangeService.ConfirmChange(req.Token); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.MessageResponse{
+		Message: "Email address changed successfully",
+	})
+}
+
+// RevertChange is called with the token from the link sent to the old address
+func (c *EmailChangeController) RevertChange(ctx *fiber.Ctx) error {
+	var req dto.EmailChangeTokenRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	if err := c.emailChangeService.RevertChange(req.Token); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.MessageResponse{
+		Message: "Email address change reverted, please sign in again",
+	})
+}
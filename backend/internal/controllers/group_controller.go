@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/services"
+	"go-backend-v2/pkg/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type GroupController struct {
+	groupService services.GroupServiceInterface
+	validator    *validator.Validate
+}
+
+func NewGroupController(groupService services.GroupServiceInterface) *GroupController {
+	v := validator.New()
+	utils.SetupCustomValidators(v)
+
+	return &GroupController{
+		groupService: groupService,
+		validator:    v,
+	}
+}
+
+func (c *GroupController) ListGroups(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	groups, err := c.groupService.ListGroups(userID, ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Groups retrieved successfully",
+		"data":    groups,
+	})
+}
+
+func (c *GroupController) GetGroup(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	group, err := c.groupService.GetGroup(userID, ctx.Params("id"), ctx.Params("groupId"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Group retrieved successfully",
+		"data":    group,
+	})
+}
+
+func (c *GroupController) CreateGroup(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	var req dto.CreateGroupRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	group, err := c.groupService.CreateGroup(userID, ctx.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Group created successfully",
+		"data":    group,
+	})
+}
+
+func (c *GroupController) UpdateGroup(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	var req dto.UpdateGroupRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	group, err := c.groupService.UpdateGroup(userID, ctx.Params("id"), ctx.Params("groupId"), &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Group updated successfully",
+		"data":    group,
+	})
+}
+
+func (c *GroupController) DeleteGroup(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	if err := c.groupService.DeleteGroup(userID, ctx.Params("id"), ctx.Params("groupId")); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.MessageResponse{
+		Message: "Group deleted successfully",
+	})
+}
+
+func (c *GroupController) ListGroupMembers(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	members, err := c.groupService.ListGroupMembers(userID, ctx.Params("id"), ctx.Params("groupId"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Group members retrieved successfully",
+		"data":    members,
+	})
+}
+
+func (c *GroupController) AddGroupMember(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	if err := c.groupService.AddGroupMember(userID, ctx.Params("id"), ctx.Params("groupId"), ctx.Params("userId")); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.MessageResponse{
+		Message: "Member added to the group",
+	})
+}
+
+func (c *GroupController) RemoveGroupMember(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	if err := c.groupService.RemoveGroupMember(userID, ctx.Params("id"), ctx.Params("groupId"), ctx.Params("userId")); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.MessageResponse{
+		Message: "Member removed from the group",
+	})
+}
+
+func (c *GroupController) AssignGroupRole(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	group, err := c.groupService.AssignGroupRole(userID, ctx.Params("id"), ctx.Params("groupId"), ctx.Params("roleId"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Role assigned to the group",
+		"data":    group,
+	})
+}
+
+func (c *GroupController) UnassignGroupRole(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	group, err := c.groupService.UnassignGroupRole(userID, ctx.Params("id"), ctx.Params("groupId"), ctx.Params("roleId"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Role removed from the group",
+		"data":    group,
+	})
+}
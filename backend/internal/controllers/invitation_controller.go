@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/services"
+	"go-backend-v2/pkg/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type InvitationController struct {
+	invitationService services.InvitationServiceInterface
+	validator         *validator.Validate
+}
+
+func NewInvitationController(invitationService services.InvitationServiceInterface) *InvitationController {
+	v := validator.New()
+	utils.SetupCustomValidators(v)
+
+	return &InvitationController{
+		invitationService: invitationService,
+		validator:         v,
+	}
+}
+
+func (c *InvitationController) CreateInvitation(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	var req dto.CreateInvitationRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	invitation, err := c.invitationService.CreateInvitation(userID, ctx.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Invitation sent successfully",
+		"data":    invitation,
+	})
+}
+
+func (c *InvitationController) ListInvitations(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	status := ctx.Query("status")
+	switch status {
+	case "", models.InvitationStatusPending, models.InvitationStatusAccepted, models.InvitationStatusDeclined,
+		models.InvitationStatusRevoked, models.InvitationStatusExpired:
+	default:
+		return common.ErrValidationFailed
+	}
+
+	invitations, err := c.invitationService.ListInvitations(userID, ctx.Params("id"), status)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Invitations retrieved successfully",
+		"data":    invitations,
+	})
+}
+
+func (c *InvitationController) ResendInvitation(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	invitation, err := c.invitationService.ResendInvitation(userID, ctx.Params("id"), ctx.Params("invitationId"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Invitation resent successfully",
+		"data":    invitation,
+	})
+}
+
+func (c *InvitationController) RevokeInvitation(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	if err := c.invitationService.RevokeInvitation(userID, ctx.Params("id"), ctx.Params("invitationId")); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.MessageResponse{
+		Message: "Invitation revoked successfully",
+	})
+}
+
+func (c *InvitationController) PreviewInvitation(ctx *fiber.Ctx) error {
+	var req dto.InvitationTokenRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	preview, err := c.invitationService.PreviewInvitation(req.Token)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Invitation retrieved successfully",
+		"data":    preview,
+	})
+}
+
+func (c *InvitationController) AcceptInvitation(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	var req dto.InvitationTokenRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	workspace, err := c.invitationService.AcceptInvitation(userID, req.Token)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Invitation accepted successfully",
+		"data":    workspace,
+	})
+}
+
+func (c *InvitationController) DeclineInvitation(ctx *fiber.Ctx) error {
+	var req dto.InvitationTokenRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	if err := c.invitationService.DeclineInvitation(req.Token); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.MessageResponse{
+		Message: "Invitation declined",
+	})
+}
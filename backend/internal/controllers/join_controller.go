@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/services"
+	"go-backend-v2/pkg/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type JoinController struct {
+	joinService services.JoinServiceInterface
+	validator   *validator.Validate
+}
+
+func NewJoinController(joinService services.JoinServiceInterface) *JoinController {
+	v := validator.New()
+	utils.SetupCustomValidators(v)
+
+	return &JoinController{
+		joinService: joinService,
+		validator:   v,
+	}
+}
+
+func (c *JoinController) ListJoinableWorkspaces(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	workspaces, err := c.joinService.ListJoinableWorkspaces(userID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Joinable workspaces retrieved successfully",
+		"data":    workspaces,
+	})
+}
+
+func (c *JoinController) JoinWorkspace(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	result, err := c.joinService.JoinWorkspace(userID, ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	if result.Status == models.MembershipStatusPending {
+		return ctx.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"message": "Join request sent, a workspace member has to approve it",
+			"data":    result,
+		})
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Joined workspace successfully",
+		"data":    result,
+	})
+}
+
+func (c *JoinController) ApproveJoinRequest(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	var req dto.ApproveJoinRequest
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&req); err != nil {
+			return common.ErrInvalidRequestBody
+		}
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	member, err := c.joinService.ApproveJoinRequest(userID, ctx.Params("id"), ctx.Params("userId"), &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Join request approved successfully",
+		"data":    member,
+	})
+}
+
+func (c *JoinController) RejectJoinRequest(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	if err := c.joinService.RejectJoinRequest(userID, ctx.Params("id"), ctx.Params("userId")); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.MessageResponse{
+		Message: "Join request rejected successfully",
+	})
+}
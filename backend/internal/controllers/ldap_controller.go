@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/services"
+	"go-backend-v2/pkg/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type LDAPController struct {
+	ldapService services.LDAPServiceInterface
+	validator   *validator.Validate
+}
+
+func NewLDAPController(ldapService services.LDAPServiceInterface) *LDAPController {
+	v := validator.New()
+	utils.SetupCustomValidators(v)
+
+	return &LDAPController{
+		ldapService: ldapService,
+		validator:   v,
+	}
+}
+
+func (c *LDAPController) Login(ctx *fiber.Ctx) error {
+	var req dto.LDAPLoginRequest
+
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	req.UserAgent = ctx.Get(fiber.HeaderUserAgent)
+	req.IPAddress = ctx.IP()
+	req.DPoPProof = ctx.Get(common.DPoPHeader)
+	req.RequestURL = utils.ExternalRequestURL(global.Config.Server.PublicURL, ctx.BaseURL(), ctx.Path())
+
+	loginResponse, err := c.ldapService.Login(&req)
+	if err != nil {
+		return err
+	}
+
+	setSessionCookies(ctx, loginResponse)
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Login successful",
+		"user":    loginResponse.User,
+		"session": loginResponse.Session,
+	})
+}
@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/services"
+	"go-backend-v2/pkg/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type MemberController struct {
+	memberService services.MemberServiceInterface
+	validator     *validator.Validate
+}
+
+func NewMemberController(memberService services.MemberServiceInterface) *MemberController {
+	v := validator.New()
+	utils.SetupCustomValidators(v)
+
+	return &MemberController{
+		memberService: memberService,
+		validator:     v,
+	}
+}
+
+func (c *MemberController) ListMembers(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	query := dto.ListMembersQuery{
+		Cursor: ctx.Query("cursor"),
+		Search: ctx.Query("q"),
+		RoleID: ctx.Query("role_id"),
+		Status: ctx.Query("status"),
+		Limit:  ctx.QueryInt("limit", common.DefaultMemberPageSize),
+	}
+
+	if err := c.validator.Struct(&query); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	members, err := c.memberService.ListMembers(userID, ctx.Params("id"), &query)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Members retrieved successfully",
+		"data":    members,
+	})
+}
+
+func (c *MemberController) UpdateMemberRole(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	var req dto.UpdateMemberRoleRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	member, err := c.memberService.UpdateMemberRole(userID, ctx.Params("id"), ctx.Params("userId"), &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Member role updated successfully",
+		"data":    member,
+	})
+}
+
+func (c *MemberController) SuspendMember(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	member, err := c.memberService.SuspendMember(userID, ctx.Params("id"), ctx.Params("userId"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Member suspended successfully",
+		"data":    member,
+	})
+}
+
+func (c *MemberController) ReactivateMember(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	member, err := c.memberService.ReactivateMember(userID, ctx.Params("id"), ctx.Params("userId"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Member reactivated successfully",
+		"data":    member,
+	})
+}
+
+func (c *MemberController) RemoveMember(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	if err := c.memberService.RemoveMember(userID, ctx.Params("id"), ctx.Params("userId")); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.MessageResponse{
+		Message: "Member removed successfully",
+	})
+}
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/services"
+	"go-backend-v2/pkg/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type OwnershipTransferController struct {
+	transferService services.OwnershipTransferServiceInterface
+	validator       *validator.Validate
+}
+
+func NewOwnershipTransferController(transferService services.OwnershipTransferServiceInterface) *OwnershipTransferController {
+	v := validator.New()
+	utils.SetupCustomValidators(v)
+
+	return &OwnershipTransferController{
+		transferService: transferService,
+		validator:       v,
+	}
+}
+
+func (c *OwnershipTransferController) InitiateTransfer(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	var req dto.CreateOwnershipTransferRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	transfer, err := c.transferService.InitiateTransfer(userID, ctx.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Ownership transfer requested, the new owner has to accept it",
+		"data":    transfer,
+	})
+}
+
+func (c *OwnershipTransferController) GetTransfer(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	transfer, err := c.transferService.GetTransfer(userID, ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Ownership transfer retrieved successfully",
+		"data":    transfer,
+	})
+}
+
+func (c *OwnershipTransferController) CancelTransfer(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	if err := c.transferService.CancelTransfer(userID, ctx.Params("id")); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.MessageResponse{
+		Message: "Ownership transfer cancelled",
+	})
+}
+
+func (c *OwnershipTransferController) AcceptTransfer(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	workspace, err := c.transferService.AcceptTransfer(userID, ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Ownership transferred successfully",
+		"data":    workspace,
+	})
+}
+
+func (c *OwnershipTransferController) DeclineTransfer(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	if err := c.transferService.DeclineTransfer(userID, ctx.Params("id")); err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.MessageResponse{
+		Message: "Ownership transfer declined",
+	})
+}
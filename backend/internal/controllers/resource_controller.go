@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/services"
+	"go-backend-v2/pkg/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ResourceController struct {
+	resourceService services.ResourceServiceInterface
+	validator       *validator.Validate
+}
+
+func NewResourceController(resourceService services.ResourceServiceInterface) *ResourceController {
+	v := validator.New()
+	utils.SetupCustomValidators(v)
+
+	return &ResourceController{
+		resourceService: resourceService,
+		validator:       v,
+	}
+}
+
+func (c *ResourceController) ListResources(ctx *fiber.Ctx) error {
+	query := dto.ListResourcesQuery{
+		Category: ctx.Query("category"),
+		Status:   ctx.Query("status"),
+	}
+
+	if err := c.validator.Struct(&query); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	resources, err := c.resourceService.ListResources(&query)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Resources retrieved successfully",
+		"data":    resources,
+	})
+}
+
+func (c *ResourceController) GetResource(ctx *fiber.Ctx) error {
+	resource, err := c.resourceService.GetResource(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Resource retrieved successfully",
+		"data":    resource,
+	})
+}
+
+func (c *ResourceController) CreateResource(ctx *fiber.Ctx) error {
+	var req dto.CreateResourceRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	resource, err := c.resourceService.CreateResource(&req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Resource created successfully",
+		"data":    resource,
+	})
+}
+
+func (c *ResourceController) UpdateResource(ctx *fiber.Ctx) error {
+	var req dto.UpdateResourceRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	resource, err := c.resourceService.UpdateResource(ctx.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Resource updated successfully",
+		"data":    resource,
+	})
+}
+
+func (c *ResourceController) DeleteResource(ctx *fiber.Ctx) error {
+	resource, err := c.resourceService.DeleteResource(ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	message := "Resource deleted successfully"
+	if resource.Status == models.ResourceStatusDeprecated {
+		message = "Resource is still granted by roles and was deprecated instead"
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": message,
+		"data":    resource,
+	})
+}
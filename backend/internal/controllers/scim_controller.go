@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/services"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SCIMController struct {
+	scimService services.SCIMServiceInterface
+}
+
+func NewSCIMController(scimService services.SCIMServiceInterface) *SCIMController {
+	return &SCIMController{
+		scimService: scimService,
+	}
+}
+
+func (c *SCIMController) ServiceProviderConfig(ctx *fiber.Ctx) error {
+	return c.respond(ctx, fiber.StatusOK, fiber.Map{
+		"schemas":        []string{common.SCIMSchemaServiceProviderConfig},
+		"patch":          fiber.Map{"supported": true},
+		"bulk":           fiber.Map{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         fiber.Map{"supported": true, "maxResults": common.SCIMMaxPageSize},
+		"changePassword": fiber.Map{"supported": false},
+		"sort":           fiber.Map{"supported": false},
+		"etag":           fiber.Map{"supported": false},
+		"authenticationSchemes": []fiber.Map{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer Token",
+			"description": "Workspace scoped SCIM token created by a workspace administrator",
+			"primary":     true,
+		}},
+	})
+}
+
+func (c *SCIMController) ListUsers(ctx *fiber.Ctx) error {
+	users, err := c.scimService.ListUsers(c.workspaceID(ctx), c.listQuery(ctx))
+	if err != nil {
+		return err
+	}
+
+	return c.respond(ctx, fiber.StatusOK, users)
+}
+
+func (c *SCIMController) GetUser(ctx *fiber.Ctx) error {
+	user, err := c.scimService.GetUser(c.workspaceID(ctx), ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.respond(ctx, fiber.StatusOK, user)
+}
+
+func (c *SCIMController) CreateUser(ctx *fiber.Ctx) error {
+	var req dto.SCIMUser
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidSyntax, "Invalid request body")
+	}
+
+	user, err := c.scimService.CreateUser(c.workspaceID(ctx), &req)
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderLocation, user.Meta.Location)
+	return c.respond(ctx, fiber.StatusCreated, user)
+}
+
+func (c *SCIMController) ReplaceUser(ctx *fiber.Ctx) error {
+	var req dto.SCIMUser
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidSyntax, "Invalid request body")
+	}
+
+	user, err := c.scimService.ReplaceUser(c.workspaceID(ctx), ctx.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.respond(ctx, fiber.StatusOK, user)
+}
+
+func (c *SCIMController) PatchUser(ctx *fiber.Ctx) error {
+	var req dto.SCIMPatchRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidSyntax, "Invalid request body")
+	}
+
+	user, err := c.scimService.PatchUser(c.workspaceID(ctx), ctx.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.respond(ctx, fiber.StatusOK, user)
+}
+
+func (c *SCIMController) DeleteUser(ctx *fiber.Ctx) error {
+	if err := c.scimService.DeleteUser(c.workspaceID(ctx), ctx.Params("id")); err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (c *SCIMController) ListGroups(ctx *fiber.Ctx) error {
+	groups, err := c.scimService.ListGroups(c.workspaceID(ctx), c.listQuery(ctx))
+	if err != nil {
+		return err
+	}
+
+	return c.respond(ctx, fiber.StatusOK, groups)
+}
+
+func (c *SCIMController) GetGroup(ctx *fiber.Ctx) error {
+	group, err := c.scimService.GetGroup(c.workspaceID(ctx), ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.respond(ctx, fiber.StatusOK, group)
+}
+
+func (c *SCIMController) CreateGroup(ctx *fiber.Ctx) error {
+	var req dto.SCIMGroup
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidSyntax, "Invalid request body")
+	}
+
+	group, err := c.scimService.CreateGroup(c.workspaceID(ctx), &req)
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderLocation, group.Meta.Location)
+	return c.respond(ctx, fiber.StatusCreated, group)
+}
+
+func (c *SCIMController) ReplaceGroup(ctx *fiber.Ctx) error {
+	var req dto.SCIMGroup
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidSyntax, "Invalid request body")
+	}
+
+	group, err := c.scimService.ReplaceGroup(c.workspaceID(ctx), ctx.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.respond(ctx, fiber.StatusOK, group)
+}
+
+func (c *SCIMController) PatchGroup(ctx *fiber.Ctx) error {
+	var req dto.SCIMPatchRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidSyntax, "Invalid request body")
+	}
+
+	group, err := c.scimService.PatchGroup(c.workspaceID(ctx), ctx.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.respond(ctx, fiber.StatusOK, group)
+}
+
+func (c *SCIMController) DeleteGroup(ctx *fiber.Ctx) error {
+	if err := c.scimService.DeleteGroup(c.workspaceID(ctx), ctx.Params("id")); err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// workspaceID returns the workspace the SCIM token was issued for
+func (c *SCIMController) workspaceID(ctx *fiber.Ctx) string {
+	workspaceID, _ := ctx.Locals(common.ContextWorkspaceID).(string)
+	return workspaceID
+}
+
+func (c *SCIMController) listQuery(ctx *fiber.Ctx) *dto.SCIMListQuery {
+	return &dto.SCIMListQuery{
+		Filter:     ctx.Query("filter"),
+		StartIndex: ctx.QueryInt("startIndex", 1),
+		Count:      ctx.QueryInt("count", common.SCIMDefaultPageSize),
+	}
+}
+
+func (c *SCIMController) respond(ctx *fiber.Ctx, status int, body interface{}) error {
+	if err := ctx.Status(status).JSON(body); err != nil {
+		return err
+	}
+	ctx.Set(fiber.HeaderContentType, common.SCIMContentType)
+	return nil
+}
@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/services"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SSOController struct {
+	samlService services.SAMLServiceInterface
+}
+
+func NewSSOController(samlService services.SAMLServiceInterface) *SSOController {
+	return &SSOController{
+		samlService: samlService,
+	}
+}
+
+func (c *SSOController) SAMLMetadata(ctx *fiber.Ctx) error {
+	metadata, err := c.samlService.GetMetadata(ctx.Params("workspace"))
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentType, "application/samlmetadata+xml")
+	return ctx.Status(fiber.StatusOK).Send(metadata)
+}
+
+func (c *SSOController) SAMLLogin(ctx *fiber.Ctx) error {
+	redirectURL, err := c.samlService.StartLogin(ctx.Params("workspace"), safeRelayState(ctx.Query("redirect")))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Redirect(redirectURL, fiber.StatusFound)
+}
+
+func (c *SSOController) SAMLACS(ctx *fiber.Ctx) error {
+	samlResponse := ctx.FormValue("SAMLResponse")
+	if samlResponse == "" {
+		return common.ErrInvalidRequestBody
+	}
+
+	loginResponse, err := c.samlService.ConsumeResponse(
+		ctx.Params("workspace"),
+		samlResponse,
+		ctx.Get(fiber.HeaderUserAgent),
+		ctx.IP(),
+	)
+	if err != nil {
+		return err
+	}
+
+	setSessionCookies(ctx, loginResponse)
+
+	return ctx.Redirect(strings.TrimRight(global.Config.Server.FrontendURL, "/")+safeRelayState(ctx.FormValue("RelayState")), fiber.StatusSeeOther)
+}
+
+// safeRelayState only keeps relative paths so the relay state cannot be used as an open redirect
+func safeRelayState(relayState string) string {
+	if relayState == "" || !strings.HasPrefix(relayState, "/") || strings.HasPrefix(relayState, "//") || strings.Contains(relayState, "\\") {
+		return "/"
+	}
+
+	parsed, err := url.Parse(relayState)
+	if err != nil || parsed.Scheme != "" || parsed.Host != "" {
+		return "/"
+	}
+
+	return relayState
+}
@@ -2,18 +2,28 @@ package controllers
 
 import (
 	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
 	"go-backend-v2/internal/services"
+	"go-backend-v2/pkg/utils"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 )
 
 type UserController struct {
-	userService services.UserServiceInterface
+	userService   services.UserServiceInterface
+	deviceService services.DeviceServiceInterface
+	validator     *validator.Validate
 }
 
-func NewUserController(userService services.UserServiceInterface) *UserController {
+func NewUserController(userService services.UserServiceInterface, deviceService services.DeviceServiceInterface) *UserController {
+	v := validator.New()
+	utils.SetupCustomValidators(v)
+
 	return &UserController{
-		userService: userService,
+		userService:   userService,
+		deviceService: deviceService,
+		validator:     v,
 	}
 }
 
@@ -39,7 +49,7 @@ func (c *UserController) GetCurrentUser(ctx *fiber.Ctx) error {
 	})
 }
 
-func (c *UserController) DeleteUser(ctx *fiber.Ctx) error {
+func (c *UserController) UpdateProfile(ctx *fiber.Ctx) error {
 	userID := ctx.Locals(common.ContextUserID)
 	if userID == nil {
 		return common.ErrUnauthorized
@@ -50,12 +60,64 @@ func (c *UserController) DeleteUser(ctx *fiber.Ctx) error {
 		return common.ErrUnauthorized
 	}
 
-	err := c.userService.DeleteUser(userIDStr)
+	var req dto.UpdateProfileRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	user, err := c.userService.UpdateProfile(userIDStr, &req)
 	if err != nil {
-		return common.ErrUserNotFound
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Profile updated successfully",
+		"user":    user,
+	})
+}
+
+func (c *UserController) ListDevices(ctx *fiber.Ctx) error {
+	userID := ctx.Locals(common.ContextUserID)
+	if userID == nil {
+		return common.ErrUnauthorized
+	}
+
+	userIDStr, ok := userID.(string)
+	if !ok {
+		return common.ErrUnauthorized
+	}
+
+	devices, err := c.deviceService.ListDevices(userIDStr)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Devices retrieved successfully",
+		"devices": devices,
+	})
+}
+
+func (c *UserController) ForgetDevice(ctx *fiber.Ctx) error {
+	userID := ctx.Locals(common.ContextUserID)
+	if userID == nil {
+		return common.ErrUnauthorized
+	}
+
+	userIDStr, ok := userID.(string)
+	if !ok {
+		return common.ErrUnauthorized
+	}
+
+	if err := c.deviceService.ForgetDevice(userIDStr, ctx.Params("deviceId")); err != nil {
+		return err
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "User deleted successfully",
+		"message": "Device removed successfully",
 	})
 }
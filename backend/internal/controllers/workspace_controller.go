@@ -0,0 +1,219 @@
+package controllers
+
+import (
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/services"
+	"go-backend-v2/pkg/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type WorkspaceController struct {
+	workspaceService services.WorkspaceServiceInterface
+	validator        *validator.Validate
+}
+
+func NewWorkspaceController(workspaceService services.WorkspaceServiceInterface) *WorkspaceController {
+	v := validator.New()
+	utils.SetupCustomValidators(v)
+
+	return &WorkspaceController{
+		workspaceService: workspaceService,
+		validator:        v,
+	}
+}
+
+// CreateWorkspace lets users create their own workspaces when self-serve creation is enabled
+func (c *WorkspaceController) CreateWorkspace(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	var req dto.CreateWorkspaceRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	workspace, err := c.workspaceService.CreateWorkspace(userID, &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Workspace created successfully",
+		"data":    workspace,
+	})
+}
+
+func (c *WorkspaceController) ListMyWorkspaces(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	workspaces, err := c.workspaceService.ListMyWorkspaces(userID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Workspaces retrieved successfully",
+		"data":    workspaces,
+	})
+}
+
+func (c *WorkspaceController) GetWorkspace(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	workspace, err := c.workspaceService.GetWorkspace(userID, ctx.Params("slug"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Workspace retrieved successfully",
+		"data":    workspace,
+	})
+}
+
+func (c *WorkspaceController) UpdateWorkspace(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	var req dto.UpdateWorkspaceRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	workspace, err := c.workspaceService.UpdateWorkspace(userID, ctx.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Workspace updated successfully",
+		"data":    workspace,
+	})
+}
+
+func (c *WorkspaceController) RenameSlug(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	var req dto.RenameWorkspaceSlugRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	if err := c.validator.Struct(&req); err != nil {
+		return common.ErrValidationFailed
+	}
+
+	workspace, err := c.workspaceService.RenameSlug(userID, ctx.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Workspace slug changed successfully",
+		"data":    workspace,
+	})
+}
+
+func (c *WorkspaceController) ArchiveWorkspace(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	workspace, err := c.workspaceService.ArchiveWorkspace(userID, ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Workspace archived successfully",
+		"data":    workspace,
+	})
+}
+
+func (c *WorkspaceController) UnarchiveWorkspace(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	workspace, err := c.workspaceService.UnarchiveWorkspace(userID, ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Workspace unarchived successfully",
+		"data":    workspace,
+	})
+}
+
+func (c *WorkspaceController) GetSettings(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	settings, err := c.workspaceService.GetSettings(userID, ctx.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Workspace settings retrieved successfully",
+		"data":    settings,
+	})
+}
+
+// UpdateSettings takes a JSON merge patch: objects are merged at every depth and null removes a key
+func (c *WorkspaceController) UpdateSettings(ctx *fiber.Ctx) error {
+	userID, ok := ctx.Locals(common.ContextUserID).(string)
+	if !ok || userID == "" {
+		return common.ErrUnauthorized
+	}
+
+	var patch map[string]interface{}
+	if err := ctx.BodyParser(&patch); err != nil || patch == nil {
+		return common.ErrInvalidRequestBody
+	}
+
+	settings, err := c.workspaceService.UpdateSettings(userID, ctx.Params("id"), patch)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Workspace settings updated successfully",
+		"data":    settings,
+	})
+}
+
+// GetSettingsSchema serves the JSON Schema that settings are validated against
+func (c *WorkspaceController) GetSettingsSchema(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderContentType, "application/schema+json")
+	return ctx.Status(fiber.StatusOK).Send(dto.WorkspaceSettingsSchema)
+}
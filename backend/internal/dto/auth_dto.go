@@ -7,17 +7,50 @@ type SignupRequest struct {
 	Password  string `json:"password" validate:"required,min=6,max=128"`
 	FirstName string `json:"first_name" validate:"required,min=1,max=100,alpha_space"`
 	LastName  string `json:"last_name" validate:"required,min=1,max=100,alpha_space"`
+
+	// InvitationToken joins the workspace of an invitation sent to Email once the account exists
+	InvitationToken string `json:"invitation_token,omitempty" validate:"omitempty,max=1024"`
 }
 
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Email      string `json:"email" validate:"required,email"`
+	Password   string `json:"password" validate:"required"`
+	RememberMe bool   `json:"remember_me"`
+
+	// Filled from the HTTP request by the controller, never from the body
+	UserAgent  string `json:"-"`
+	IPAddress  string `json:"-"`
+	DPoPProof  string `json:"-"`
+	RequestURL string `json:"-"`
+}
+
+// LDAPLoginRequest takes whatever the directory accepts as login name, usually uid or mail
+type LDAPLoginRequest struct {
+	Username   string `json:"username" validate:"required,max=255"`
+	Password   string `json:"password" validate:"required,max=255"`
+	RememberMe bool   `json:"remember_me"`
+
+	// Filled from the HTTP request by the controller, never from the body
+	UserAgent  string `json:"-"`
+	IPAddress  string `json:"-"`
+	DPoPProof  string `json:"-"`
+	RequestURL string `json:"-"`
+}
+
+// SessionOptions describes the login a session is issued for
+type SessionOptions struct {
+	Provider   string
+	UserAgent  string
+	IPAddress  string
+	RememberMe bool
+	DPoPJKT    string // thumbprint of the key the access token is bound to, empty for bearer tokens
 }
 
 type LoginResponse struct {
-	User           *models.User `json:"user"`
-	AccessToken    string       `json:"access_token"`
-	EncryptedToken string       `json:"encrypted_token"`
+	User           *models.User     `json:"user"`
+	AccessToken    string           `json:"access_token"`
+	EncryptedToken string           `json:"encrypted_token"`
+	Session        *SessionMetadata `json:"session"`
 }
 
 type MessageResponse struct {
@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+type AddDomainRequest struct {
+	Domain string `json:"domain" validate:"required,max=253"`
+}
+
+// DomainResponse tells workspace admins which TXT record proves they control the domain
+type DomainResponse struct {
+	ID          string     `json:"id"`
+	WorkspaceID string     `json:"workspace_id"`
+	Domain      string     `json:"domain"`
+	Status      string     `json:"status"`
+	RecordName  string     `json:"record_name"`  // name of the TXT record to publish
+	RecordValue string     `json:"record_value"` // content of the TXT record
+	VerifiedAt  *time.Time `json:"verified_at,omitempty"`
+	CreatedBy   string     `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// WorkspaceSummary is what users outside a workspace may see of it
+type WorkspaceSummary struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Description string `json:"description,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+}
+
+// JoinWorkspaceResponse is the outcome of a join: an active membership when the email domain of
+// the user is verified by the workspace, otherwise a pending join request
+type JoinWorkspaceResponse struct {
+	Status    string            `json:"status"`
+	Role      string            `json:"role,omitempty"` // set once the membership is active
+	Workspace *WorkspaceSummary `json:"workspace"`
+}
+
+// ApproveJoinRequest admits a user with a role, the workspace default role applies without one
+type ApproveJoinRequest struct {
+	RoleID string `json:"role_id,omitempty" validate:"omitempty,uuid"`
+}
@@ -20,5 +20,141 @@ type WorkspaceCreatedPayload struct {
 }
 
 type UserLoginPayload struct {
+	UserID    string `json:"userId"`
+	Provider  string `json:"provider"`
+	DeviceID  string `json:"deviceId,omitempty"`
+	IPAddress string `json:"ipAddress,omitempty"`
+	NewDevice bool   `json:"newDevice"`
+}
+
+type UserUpdatedPayload struct {
+	UserID        string   `json:"userId"`
+	ChangedFields []string `json:"changedFields"`
+}
+
+type UserDataExportedPayload struct {
+	UserID   string `json:"userId"`
+	ExportID string `json:"exportId"`
+	Format   string `json:"format"`
+}
+
+type UserDeletionScheduledPayload struct {
+	UserID  string    `json:"userId"`
+	PurgeAt time.Time `json:"purgeAt"`
+}
+
+type UserRestoredPayload struct {
 	UserID string `json:"userId"`
 }
+
+type UserPurgedPayload struct {
+	UserID                string   `json:"userId"`
+	TransferredWorkspaces []string `json:"transferredWorkspaces,omitempty"` // handed over to another admin
+	ClosedWorkspaces      []string `json:"closedWorkspaces,omitempty"`      // no admin was left to take over
+}
+
+type UserProvisionedPayload struct {
+	UserID      string `json:"userId"`
+	WorkspaceID string `json:"workspaceId,omitempty"` // empty for directory wide providers such as LDAP
+	Provider    string `json:"provider"`
+}
+
+type MembershipStatusChangedPayload struct {
+	UserID      string `json:"userId"`
+	WorkspaceID string `json:"workspaceId"`
+	OldStatus   string `json:"oldStatus"`
+	NewStatus   string `json:"newStatus"`
+	ChangedByID string `json:"changedById,omitempty"` // empty for changes pushed by a provider
+	Source      string `json:"source"`
+}
+
+type MembershipRemovedPayload struct {
+	UserID      string `json:"userId"`
+	WorkspaceID string `json:"workspaceId"`
+	RemovedByID string `json:"removedById,omitempty"` // empty for changes pushed by a provider
+	Source      string `json:"source"`
+}
+
+type MembershipRoleChangedPayload struct {
+	UserID      string `json:"userId"`
+	WorkspaceID string `json:"workspaceId"`
+	OldRoleID   string `json:"oldRoleId"`
+	NewRoleID   string `json:"newRoleId"`
+	ChangedByID string `json:"changedById"`
+}
+
+type RoleChangedPayload struct {
+	RoleID      string   `json:"roleId"`
+	WorkspaceID string   `json:"workspaceId"`
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+	Inherits    []string `json:"inherits,omitempty"`
+	Version     string   `json:"version"`
+	ChangedByID string   `json:"changedById"`
+}
+
+// GroupChangedPayload describes a group after a change; role changes are group updates
+type GroupChangedPayload struct {
+	GroupID     string   `json:"groupId"`
+	WorkspaceID string   `json:"workspaceId"`
+	Name        string   `json:"name"`
+	RoleIDs     []string `json:"roleIds"`
+	ChangedByID string   `json:"changedById"`
+}
+
+type GroupMemberChangedPayload struct {
+	GroupID     string `json:"groupId"`
+	WorkspaceID string `json:"workspaceId"`
+	UserID      string `json:"userId"`
+	ChangedByID string `json:"changedById"`
+}
+
+type UserNewDeviceLoginPayload struct {
+	UserID    string `json:"userId"`
+	DeviceID  string `json:"deviceId"`
+	Browser   string `json:"browser"`
+	OS        string `json:"os"`
+	IPAddress string `json:"ipAddress"`
+	IPSubnet  string `json:"ipSubnet"`
+}
+
+type WorkspaceUpdatedPayload struct {
+	WorkspaceID   string   `json:"workspaceId"`
+	UpdatedByID   string   `json:"updatedById"`
+	ChangedFields []string `json:"changedFields"`
+}
+
+type WorkspaceArchivedPayload struct {
+	WorkspaceID  string    `json:"workspaceId"`
+	ArchivedByID string    `json:"archivedById"`
+	ArchivedAt   time.Time `json:"archivedAt"`
+}
+
+type WorkspaceOwnershipTransferredPayload struct {
+	WorkspaceID     string `json:"workspaceId"`
+	TransferID      string `json:"transferId"`
+	PreviousOwnerID string `json:"previousOwnerId"`
+	NewOwnerID      string `json:"newOwnerId"`
+	InitiatedByID   string `json:"initiatedById"`
+}
+
+type MembershipInvitedPayload struct {
+	InvitationID string `json:"invitationId"`
+	WorkspaceID  string `json:"workspaceId"`
+	Email        string `json:"email"`
+	RoleID       string `json:"roleId"`
+	InvitedByID  string `json:"invitedById"`
+}
+
+type MembershipRequestedPayload struct {
+	UserID      string `json:"userId"`
+	WorkspaceID string `json:"workspaceId"`
+}
+
+type MembershipJoinedPayload struct {
+	UserID      string `json:"userId"`
+	WorkspaceID string `json:"workspaceId"`
+	RoleID      string `json:"roleId"`
+	InvitedByID string `json:"invitedById,omitempty"` // the inviter, or the member who approved a join request
+	Source      string `json:"source"`
+}
@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"go-backend-v2/internal/models"
+	"time"
+)
+
+type CreateDataExportRequest struct {
+	Format string `json:"format" validate:"omitempty,oneof=json zip"`
+}
+
+// DataExport tracks one export job, stored in Redis until the archive is deleted
+type DataExport struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	Format      string     `json:"format"`
+	Status      string     `json:"status"`
+	StorageKey  string     `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // when the archive is deleted
+
+	DownloadURL       string     `json:"download_url,omitempty"`
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty"`
+}
+
+// DataExportArchive is everything stored about a user, as handed out on a data subject access request
+type DataExportArchive struct {
+	ExportedAt           time.Time              `json:"exported_at"`
+	User                 *models.User           `json:"user"`
+	Profile              *models.UserProfile    `json:"profile"`
+	AuthProviders        []ExportedAuthProvider `json:"auth_providers"`
+	WorkspaceMemberships []ExportedMembership   `json:"workspace_memberships"`
+	Sessions             []SessionMetadata      `json:"sessions"`
+	LoginHistory         []models.UserDevice    `json:"login_history"`
+}
+
+// ExportedAuthProvider is a linked login without its credentials
+type ExportedAuthProvider struct {
+	Provider       string              `json:"provider"`
+	ProviderUserID string              `json:"provider_user_id"`
+	ProviderEmail  *string             `json:"provider_email,omitempty"`
+	ProviderData   models.ProviderData `json:"provider_data,omitempty"`
+	IsPrimary      bool                `json:"is_primary"`
+	Status         string              `json:"status"`
+	CreatedAt      time.Time           `json:"created_at"`
+}
+
+type ExportedMembership struct {
+	WorkspaceID   string     `json:"workspace_id"`
+	WorkspaceName string     `json:"workspace_name"`
+	WorkspaceSlug string     `json:"workspace_slug"`
+	Role          string     `json:"role"`
+	Status        string     `json:"status"`
+	InvitedBy     *string    `json:"invited_by,omitempty"`
+	JoinedAt      *time.Time `json:"joined_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+// CreateGroupRequest defines a group, optionally with the roles its members receive
+type CreateGroupRequest struct {
+	Name        string   `json:"name" validate:"required,min=1,max=100"`
+	Description string   `json:"description,omitempty" validate:"max=1000"`
+	RoleIDs     []string `json:"role_ids,omitempty" validate:"max=20,unique,dive,uuid"`
+}
+
+// UpdateGroupRequest changes only the fields that are present
+type UpdateGroupRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitnil,min=1,max=100"`
+	Description *string `json:"description,omitempty" validate:"omitnil,max=1000"`
+}
+
+type GroupRoleResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type GroupResponse struct {
+	ID          string              `json:"id"`
+	WorkspaceID string              `json:"workspace_id"`
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Roles       []GroupRoleResponse `json:"roles"` // active roles only
+	CreatedBy   string              `json:"created_by"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+type GroupMemberResponse struct {
+	UserID      string    `json:"user_id"`
+	Email       string    `json:"email"`
+	FirstName   string    `json:"first_name,omitempty"`
+	LastName    string    `json:"last_name,omitempty"`
+	DisplayName string    `json:"display_name,omitempty"`
+	AddedBy     string    `json:"added_by,omitempty"`
+	AddedAt     time.Time `json:"added_at"`
+}
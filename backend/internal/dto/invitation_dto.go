@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+// CreateInvitationRequest invites an email address, the workspace member role applies without a role
+type CreateInvitationRequest struct {
+	Email  string `json:"email" validate:"required,email,max=255"`
+	RoleID string `json:"role_id,omitempty" validate:"omitempty,uuid"`
+}
+
+// InvitationTokenRequest carries the token of an invitation link
+type InvitationTokenRequest struct {
+	Token string `json:"token" validate:"required,max=1024"`
+}
+
+type InvitationResponse struct {
+	ID          string     `json:"id"`
+	WorkspaceID string     `json:"workspace_id"`
+	Email       string     `json:"email"`
+	RoleID      string     `json:"role_id"`
+	RoleName    string     `json:"role_name,omitempty"`
+	InvitedBy   string     `json:"invited_by"`
+	Status      string     `json:"status"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	SentAt      time.Time  `json:"sent_at"`
+	SendCount   int        `json:"send_count"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// InvitationPreview is what an invitation link shows before it is accepted or declined
+type InvitationPreview struct {
+	WorkspaceID        string    `json:"workspace_id"`
+	WorkspaceName      string    `json:"workspace_name"`
+	WorkspaceSlug      string    `json:"workspace_slug"`
+	WorkspaceAvatarURL string    `json:"workspace_avatar_url,omitempty"`
+	Email              string    `json:"email"`
+	RoleName           string    `json:"role_name"`
+	InviterName        string    `json:"inviter_name,omitempty"`
+	ExpiresAt          time.Time `json:"expires_at"`
+	Registered         bool      `json:"registered"` // false leads the client to signup with the token
+}
@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+// ListMembersQuery filters the member list of a workspace. Without a status filter, active and
+// suspended members are listed.
+type ListMembersQuery struct {
+	Cursor string `validate:"max=512"`
+	Search string `validate:"max=100"` // matches email, first, last and display name
+	RoleID string `validate:"omitempty,uuid"`
+	Status string `validate:"omitempty,oneof=active suspended pending"`
+	Limit  int    `validate:"min=1,max=200"`
+}
+
+type MemberResponse struct {
+	UserID      string     `json:"user_id"`
+	Email       string     `json:"email"`
+	FirstName   string     `json:"first_name,omitempty"`
+	LastName    string     `json:"last_name,omitempty"`
+	DisplayName string     `json:"display_name,omitempty"`
+	AvatarURL   string     `json:"avatar_url,omitempty"`
+	RoleID      string     `json:"role_id"`
+	RoleName    string     `json:"role_name,omitempty"`
+	Status      string     `json:"status"`
+	IsOwner     bool       `json:"is_owner"`
+	InvitedBy   string     `json:"invited_by,omitempty"`
+	JoinedAt    *time.Time `json:"joined_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// MemberListResponse is a page of members; NextCursor is empty on the last page
+type MemberListResponse struct {
+	Members    []MemberResponse `json:"members"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+type UpdateMemberRoleRequest struct {
+	RoleID string `json:"role_id" validate:"required,uuid"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+type CreateOwnershipTransferRequest struct {
+	UserID string `json:"user_id" validate:"required,uuid"` // an active member of the workspace
+}
+
+type OwnershipTransferResponse struct {
+	ID          string     `json:"id"`
+	WorkspaceID string     `json:"workspace_id"`
+	FromUserID  string     `json:"from_user_id"`
+	ToUserID    string     `json:"to_user_id"`
+	InitiatedBy string     `json:"initiated_by"`
+	Status      string     `json:"status"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+// CreateResourceRequest adds a resource to the catalog. The name is the resource part of
+// resource:action permissions and cannot change later.
+type CreateResourceRequest struct {
+	Name        string `json:"name" validate:"required,max=100,resource_name"`
+	DisplayName string `json:"display_name" validate:"required,min=1,max=150"`
+	Description string `json:"description,omitempty" validate:"max=1000"`
+	Category    string `json:"category,omitempty" validate:"omitempty,oneof=core feature admin integration"`
+	Icon        string `json:"icon,omitempty" validate:"max=100"`
+}
+
+// UpdateResourceRequest changes only the fields that are present. Resources that are not active
+// cannot be granted in roles any more; existing grants keep working.
+type UpdateResourceRequest struct {
+	DisplayName *string `json:"display_name,omitempty" validate:"omitnil,min=1,max=150"`
+	Description *string `json:"description,omitempty" validate:"omitnil,max=1000"`
+	Category    *string `json:"category,omitempty" validate:"omitnil,omitempty,oneof=core feature admin integration"`
+	Icon        *string `json:"icon,omitempty" validate:"omitnil,max=100"`
+	Status      *string `json:"status,omitempty" validate:"omitnil,oneof=active inactive deprecated"`
+}
+
+// ListResourcesQuery filters the resource catalog, empty fields match all
+type ListResourcesQuery struct {
+	Category string `validate:"omitempty,oneof=core feature admin integration"`
+	Status   string `validate:"omitempty,oneof=active inactive deprecated"`
+}
+
+type ResourceResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"display_name"`
+	Description string    `json:"description,omitempty"`
+	Category    string    `json:"category,omitempty"`
+	Icon        string    `json:"icon,omitempty"`
+	Status      string    `json:"status"`
+	Actions     []string  `json:"actions"` // actions that can be combined with the name in permissions
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
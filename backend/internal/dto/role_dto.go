@@ -0,0 +1,37 @@
+package dto
+
+import "time"
+
+// CreateRoleRequest defines a custom role. Permissions are resource:action strings of the
+// resource catalog, where either part may be *, or all; a leading ! denies the permission.
+// Inherits lists roles of the workspace whose permissions the role grants as well.
+type CreateRoleRequest struct {
+	Name        string   `json:"name" validate:"required,min=1,max=100"`
+	Description string   `json:"description,omitempty" validate:"max=1000"`
+	Permissions []string `json:"permissions" validate:"max=100,dive,required,max=150"`
+	Inherits    []string `json:"inherits,omitempty" validate:"max=20,unique,dive,uuid"`
+}
+
+// UpdateRoleRequest changes only the fields that are present; permissions and inherits replace the
+// current ones
+type UpdateRoleRequest struct {
+	Name        *string   `json:"name,omitempty" validate:"omitnil,min=1,max=100"`
+	Description *string   `json:"description,omitempty" validate:"omitnil,max=1000"`
+	Permissions *[]string `json:"permissions,omitempty" validate:"omitnil,max=100,dive,required,max=150"`
+	Inherits    *[]string `json:"inherits,omitempty" validate:"omitnil,max=20,unique,dive,uuid"`
+}
+
+type RoleResponse struct {
+	ID          string    `json:"id"`
+	WorkspaceID string    `json:"workspace_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Permissions []string  `json:"permissions"`
+	Inherits    []string  `json:"inherits"`
+	BuiltIn     bool      `json:"built_in"`
+	Version     string    `json:"version"`
+	CreatedBy   string    `json:"created_by,omitempty"`
+	UpdatedBy   string    `json:"updated_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
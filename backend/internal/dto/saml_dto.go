@@ -0,0 +1,23 @@
+package dto
+
+import "go-backend-v2/internal/models"
+
+type UpsertSAMLConfigRequest struct {
+	Enabled             bool                        `json:"enabled"`
+	IdPMetadataXML      string                      `json:"idp_metadata_xml" validate:"required_without=IdPSSOURL"`
+	IdPEntityID         string                      `json:"idp_entity_id" validate:"required_with=IdPSSOURL,max=500"`
+	IdPSSOURL           string                      `json:"idp_sso_url" validate:"omitempty,url,max=1000"`
+	IdPCertificate      string                      `json:"idp_certificate" validate:"required_with=IdPSSOURL"`
+	AttributeMapping    models.SAMLAttributeMapping `json:"attribute_mapping"`
+	DefaultRoleID       string                      `json:"default_role_id" validate:"omitempty,uuid"`
+	AllowIDPInitiated   bool                        `json:"allow_idp_initiated"`
+	JITProvisioning     *bool                       `json:"jit_provisioning"`
+	RotateSPCertificate bool                        `json:"rotate_sp_certificate"`
+}
+
+type SAMLConfigResponse struct {
+	*models.WorkspaceSAMLConfig
+	SPEntityID    string `json:"sp_entity_id"`
+	SPMetadataURL string `json:"sp_metadata_url"`
+	SPACSURL      string `json:"sp_acs_url"`
+}
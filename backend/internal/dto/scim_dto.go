@@ -0,0 +1,95 @@
+package dto
+
+import (
+	"encoding/json"
+	"go-backend-v2/internal/models"
+	"time"
+)
+
+type SCIMMeta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location,omitempty"`
+}
+
+type SCIMName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+}
+
+// SCIMMultiValue is an entry of a multi-valued attribute such as emails, groups or members
+type SCIMMultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+type SCIMUser struct {
+	Schemas     []string         `json:"schemas"`
+	ID          string           `json:"id,omitempty"`
+	ExternalID  string           `json:"externalId,omitempty"`
+	UserName    string           `json:"userName"`
+	Name        *SCIMName        `json:"name,omitempty"`
+	DisplayName string           `json:"displayName,omitempty"`
+	Locale      string           `json:"locale,omitempty"`
+	Timezone    string           `json:"timezone,omitempty"`
+	Active      *bool            `json:"active,omitempty"`
+	Emails      []SCIMMultiValue `json:"emails,omitempty"`
+	Groups      []SCIMMultiValue `json:"groups,omitempty"` // read only, derived from the workspace role
+	Meta        *SCIMMeta        `json:"meta,omitempty"`
+}
+
+type SCIMGroup struct {
+	Schemas     []string         `json:"schemas"`
+	ID          string           `json:"id,omitempty"`
+	DisplayName string           `json:"displayName"`
+	Members     []SCIMMultiValue `json:"members,omitempty"`
+	Meta        *SCIMMeta        `json:"meta,omitempty"`
+}
+
+type SCIMListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int64       `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+type SCIMListQuery struct {
+	Filter     string
+	StartIndex int // 1-based
+	Count      int
+}
+
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations"`
+}
+
+type SCIMPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+type SCIMErrorResponse struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+type CreateSCIMTokenRequest struct {
+	Name          string `json:"name" validate:"required,min=1,max=100"`
+	ExpiresInDays int    `json:"expires_in_days" validate:"omitempty,min=1,max=730"`
+}
+
+// CreateSCIMTokenResponse carries the plaintext token, which is only ever shown once
+type CreateSCIMTokenResponse struct {
+	*models.WorkspaceSCIMToken
+	Token string `json:"token"`
+}
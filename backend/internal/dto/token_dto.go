@@ -1,13 +1,34 @@
 package dto
 
+import "time"
+
 type UserTokenData struct {
 	GlobalRole           string                         `json:"global_role"`
 	WorkspaceMemberships []WorkspaceMembershipTokenData `json:"workspace_memberships,omitempty"`
+	Session              *SessionMetadata               `json:"session,omitempty"`
+}
+
+// SessionMetadata describes the lifetime of one login session. The session ends at
+// IdleExpiresAt unless there is activity before, and at ExpiresAt no matter what.
+type SessionMetadata struct {
+	Provider       string    `json:"provider"`
+	RememberMe     bool      `json:"remember_me"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	IdleExpiresAt  time.Time `json:"idle_expires_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	DPoPJKT        string    `json:"dpop_jkt,omitempty"` // set when the access token is DPoP bound
 }
 
 type WorkspaceMembershipTokenData struct {
 	WorkspaceID string   `json:"workspace_id"`
-	RoleName    string   `json:"role_name"`
+	RoleName    string   `json:"role_name"` // role of the membership, groups may grant more
 	Permissions []string `json:"permissions"`
 	Status      string   `json:"status"`
 }
+
+// UserState is the part of a user the auth middleware needs on every request
+type UserState struct {
+	Status     string `json:"status"`
+	GlobalRole string `json:"global_role"`
+}
@@ -2,3 +2,49 @@ package dto
 
 // Simple request DTOs only - no response DTOs needed
 // Models can be returned directly with proper JSON tags
+
+// UpdateProfileRequest is a partial update: omitted fields are left alone and an empty string
+// clears an optional field
+type UpdateProfileRequest struct {
+	FirstName    *string `json:"first_name" validate:"omitnil,min=1,max=100,alpha_space"`
+	LastName     *string `json:"last_name" validate:"omitnil,min=1,max=100,alpha_space"`
+	DisplayName  *string `json:"display_name" validate:"omitnil,max=200"`
+	Bio          *string `json:"bio" validate:"omitnil,max=2000"`
+	DateOfBirth  *string `json:"date_of_birth" validate:"omitnil,eq=|datetime=2006-01-02"`
+	AddressLine1 *string `json:"address_line1" validate:"omitnil,max=255"`
+	AddressLine2 *string `json:"address_line2" validate:"omitnil,max=255"`
+	City         *string `json:"city" validate:"omitnil,max=100"`
+	State        *string `json:"state" validate:"omitnil,max=100"`
+	PostalCode   *string `json:"postal_code" validate:"omitnil,max=20"`
+	Country      *string `json:"country" validate:"omitnil,eq=|iso3166_1_alpha2"`
+	Timezone     *string `json:"timezone" validate:"omitnil,max=50,iana_timezone"`
+	Locale       *string `json:"locale" validate:"omitnil,max=10,bcp47_language_tag"`
+}
+
+// ChangeEmailRequest starts an email change. Accounts with a password must send it, accounts
+// without one, signed in through SSO or LDAP only, must have signed in within the last minutes.
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"omitempty,max=128"`
+}
+
+type RestoreAccountRequest struct {
+	Token string `json:"token" validate:"required,max=128"`
+}
+
+type EmailChangeTokenRequest struct {
+	Token string `json:"token" validate:"required,max=128"`
+}
+
+// EmailChange is the pending change stored in Redis behind a confirm or revert token
+type EmailChange struct {
+	UserID   string `json:"user_id"`
+	OldEmail string `json:"old_email"`
+	NewEmail string `json:"new_email"`
+}
+
+// AvatarResponse points at the stored avatar and its thumbnail
+type AvatarResponse struct {
+	AvatarURL    string `json:"avatar_url"`
+	ThumbnailURL string `json:"avatar_thumbnail_url"`
+}
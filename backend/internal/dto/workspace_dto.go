@@ -7,13 +7,52 @@ type CreateWorkspaceRequest struct {
 	Description string `json:"description,omitempty" validate:"max=1000"`
 }
 
+// UpdateWorkspaceRequest changes only the fields that are present. Settings are a JSON merge patch
+// of the current ones, like the body of PATCH /workspaces/:id/settings.
+type UpdateWorkspaceRequest struct {
+	Name        *string                `json:"name,omitempty" validate:"omitnil,min=1,max=255"`
+	Description *string                `json:"description,omitempty" validate:"omitnil,max=1000"`
+	Settings    map[string]interface{} `json:"settings,omitempty"`
+}
+
+// UpdateWorkspacePlanRequest moves a workspace to another of the configured plans
+type UpdateWorkspacePlanRequest struct {
+	Plan string `json:"plan" validate:"required,max=50"`
+}
+
+// RenameWorkspaceSlugRequest gives a workspace a new slug, the former one keeps resolving to it
+type RenameWorkspaceSlugRequest struct {
+	Slug string `json:"slug" validate:"required,min=3,slug"`
+}
+
 type WorkspaceResponse struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Slug        string    `json:"slug"`
-	Description string    `json:"description,omitempty"`
-	OwnerID     string    `json:"owner_id"`
-	Status      string    `json:"status"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                 string                 `json:"id"`
+	Name               string                 `json:"name"`
+	Slug               string                 `json:"slug"`
+	Description        string                 `json:"description,omitempty"`
+	AvatarURL          string                 `json:"avatar_url,omitempty"`
+	AvatarThumbnailURL string                 `json:"avatar_thumbnail_url,omitempty"`
+	OwnerID            string                 `json:"owner_id"`
+	Settings           map[string]interface{} `json:"settings,omitempty"`
+	Plan               string                 `json:"plan"`
+	Status             string                 `json:"status"`
+	Role               string                 `json:"role,omitempty"` // role of the requesting user, in their own workspace list
+	ArchivedAt         *time.Time             `json:"archived_at,omitempty"`
+	CreatedAt          time.Time              `json:"created_at"`
+	UpdatedAt          time.Time              `json:"updated_at"`
+}
+
+// ListWorkspacesQuery filters the workspace list of the admin API
+type ListWorkspacesQuery struct {
+	Status string `validate:"omitempty,oneof=active inactive suspended archived deleted"`
+	Search string `validate:"max=100"` // matches name or slug
+	Offset int    `validate:"min=0"`
+	Limit  int    `validate:"min=1,max=100"`
+}
+
+type WorkspaceListResponse struct {
+	Workspaces []WorkspaceResponse `json:"workspaces"`
+	Total      int64               `json:"total"`
+	Offset     int                 `json:"offset"`
+	Limit      int                 `json:"limit"`
 }
@@ -0,0 +1,23 @@
+package dto
+
+import _ "embed"
+
+// WorkspaceSettingsSchema is the JSON Schema of the current settings version
+//
+//go:embed schemas/workspace_settings.json
+var WorkspaceSettingsSchema []byte
+
+// WorkspaceSettings is the typed form of the settings document stored with a workspace. Documents
+// are validated against the JSON Schema served at GET /workspaces/settings/schema before they are
+// decoded into it, the schema holds every rule.
+type WorkspaceSettings struct {
+	Version             int                `json:"version"`
+	DefaultMemberRoleID *string            `json:"default_member_role_id,omitempty"` // role given to members that join without one
+	Branding            *WorkspaceBranding `json:"branding,omitempty"`
+}
+
+type WorkspaceBranding struct {
+	PrimaryColor *string `json:"primary_color,omitempty"`
+	AccentColor  *string `json:"accent_color,omitempty"`
+	LogoURL      *string `json:"logo_url,omitempty"`
+}
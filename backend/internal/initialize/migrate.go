@@ -14,9 +14,19 @@ func InitMigrations() {
 		&models.UserProfile{},
 		&models.UserAuthProvider{},
 		&models.Workspace{},
+		&models.WorkspaceSlug{},
 		&models.WorkspaceRole{},
 		&models.UserWorkspaceMembership{},
+		&models.WorkspaceGroup{},
+		&models.WorkspaceGroupMember{},
+		&models.WorkspaceGroupRole{},
 		&models.Resource{},
+		&models.UserDevice{},
+		&models.WorkspaceSAMLConfig{},
+		&models.WorkspaceSCIMToken{},
+		&models.WorkspaceInvitation{},
+		&models.WorkspaceOwnershipTransfer{},
+		&models.WorkspaceDomain{},
 	)
 
 	if err != nil {
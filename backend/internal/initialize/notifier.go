@@ -0,0 +1,26 @@
+package initialize
+
+import (
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/pkg/utils"
+)
+
+func InitNotifier() {
+	cfg := global.Config.Notifier
+
+	switch cfg.Driver {
+	case utils.NotifierDriverEvent:
+		notifier, err := utils.NewEventNotifier(global.EventTopicPublisher, cfg.Topic)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to create Notifier: %s", err))
+		}
+		global.UserNotifier = notifier
+	case utils.NotifierDriverLog, "":
+		global.UserNotifier = utils.NewLogNotifier()
+	default:
+		panic(fmt.Sprintf("Unknown notifier driver: %s", cfg.Driver))
+	}
+
+	fmt.Printf("Notifier initialized - Driver: %s\n", cfg.Driver)
+}
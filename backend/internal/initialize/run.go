@@ -18,12 +18,21 @@ func Run() {
 	// Run database migrations
 	InitMigrations()
 
+	// Seed the resource catalog (depends on the migrated schema)
+	InitResourceSeed()
+
 	// Initialize Redis connection
 	InitRedis()
 
 	// Initialize RabbitMQ connection
 	InitRabbitMQ()
 
+	// Initialize notifier (depends on the event publisher)
+	InitNotifier()
+
+	// Initialize file storage
+	InitStorage()
+
 	// Initialize logger (if implemented)
 	// InitLogger()
 
@@ -0,0 +1,74 @@
+package initialize
+
+import (
+	"fmt"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/pkg/utils"
+
+	"github.com/spf13/viper"
+)
+
+type seedResource struct {
+	Name        string `mapstructure:"name"`
+	DisplayName string `mapstructure:"display_name"`
+	Description string `mapstructure:"description"`
+	Category    string `mapstructure:"category"`
+	Icon        string `mapstructure:"icon"`
+}
+
+// InitResourceSeed creates the resources declared in configs/resources.yaml that the catalog
+// does not have yet, so that the permission strings used by the code can be granted
+func InitResourceSeed() {
+	v := viper.New()
+	v.AddConfigPath("configs")
+	v.SetConfigName("resources")
+	v.SetConfigType("yaml")
+
+	if err := v.ReadInConfig(); err != nil {
+		panic(fmt.Errorf("failed to read resource seed: %v", err))
+	}
+
+	var resources []seedResource
+	if err := v.UnmarshalKey("resources", &resources); err != nil {
+		panic(fmt.Errorf("failed to parse resource seed: %v", err))
+	}
+
+	resourceRepo := repo.NewResourceRepository()
+	created := 0
+	for _, seed := range resources {
+		if !utils.IsResourceName(seed.Name) || seed.DisplayName == "" {
+			panic(fmt.Errorf("invalid resource %q in seed", seed.Name))
+		}
+
+		exists, err := resourceRepo.ExistsByName(seed.Name)
+		if err != nil {
+			panic(fmt.Errorf("failed to seed resources: %v", err))
+		}
+		if exists {
+			continue
+		}
+
+		resource := &models.Resource{
+			Name:        seed.Name,
+			DisplayName: seed.DisplayName,
+			Description: seedString(seed.Description),
+			Category:    seedString(seed.Category),
+			Icon:        seedString(seed.Icon),
+			Status:      models.ResourceStatusActive,
+		}
+		if err := resourceRepo.CreateResource(resource); err != nil {
+			panic(fmt.Errorf("failed to seed resources: %v", err))
+		}
+		created++
+	}
+
+	fmt.Printf("Resource seed completed: %d of %d resources created\n", created, len(resources))
+}
+
+func seedString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
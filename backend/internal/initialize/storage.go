@@ -0,0 +1,39 @@
+package initialize
+
+import (
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/pkg/utils"
+)
+
+func InitStorage() {
+	cfg := global.Config.Storage
+
+	var (
+		storage global.FileStorage
+		err     error
+	)
+
+	switch cfg.Driver {
+	case utils.StorageDriverS3:
+		storage, err = utils.NewS3Storage(&utils.S3StorageOptions{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			UseSSL:    cfg.S3.UseSSL,
+		})
+	case utils.StorageDriverLocal, "":
+		storage, err = utils.NewLocalStorage(cfg.LocalPath)
+	default:
+		panic(fmt.Sprintf("Unknown storage driver: %s", cfg.Driver))
+	}
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create Storage: %s", err))
+	}
+
+	global.Storage = storage
+
+	fmt.Printf("Storage initialized - Driver: %s\n", cfg.Driver)
+}
@@ -1,62 +1,77 @@
 package middlewares
 
 import (
+	"go-backend-v2/global"
 	"go-backend-v2/internal/common"
-	"go-backend-v2/internal/repo"
 	"go-backend-v2/internal/services"
+	"go-backend-v2/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 func AuthMiddleware(authService services.AuthServiceInterface) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
-		token := ctx.Cookies(common.JWTCookieName)
-		if token == "" {
-			return common.ErrTokenRequired
+		if _, err := authenticate(ctx, authService); err != nil {
+			return err
 		}
 
-		userID, err := authService.ValidateToken(token)
-		if err != nil {
-			return common.ErrTokenInvalid
-		}
-
-		ctx.Locals(common.ContextUserID, userID)
-
 		return ctx.Next()
 	}
 }
 
 func RequireSuperAdmin(authService services.AuthServiceInterface) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
-		token := ctx.Cookies(common.JWTCookieName)
-		if token == "" {
-			return common.ErrTokenRequired
-		}
-
-		userID, err := authService.ValidateToken(token)
+		userID, err := authenticate(ctx, authService)
 		if err != nil {
-			return common.ErrTokenInvalid
+			return err
 		}
 
-		userRepo := repo.NewUserRepository()
-		user, err := userRepo.GetUserByID(userID)
+		// ValidateToken has just cached the user state, so this is a Redis hit
+		state, err := authService.GetUserState(userID)
 		if err != nil {
 			return common.ErrUserNotFound
 		}
-		if user == nil {
+		if state == nil {
 			return common.ErrUserNotFound
 		}
 
-		if user.Status != common.UserStatusActive {
+		if state.Status != common.UserStatusActive {
 			return common.ErrUserInactive
 		}
 
-		if user.GlobalRole != common.GlobalRoleSuperAdmin {
+		if state.GlobalRole != common.GlobalRoleSuperAdmin {
 			return common.ErrWorkspaceCreateForbidden
 		}
 
-		ctx.Locals(common.ContextUserID, userID)
-
 		return ctx.Next()
 	}
 }
+
+// authenticate checks the access token and its Redis session, extending the session on activity
+func authenticate(ctx *fiber.Ctx, authService services.AuthServiceInterface) (string, error) {
+	token := ctx.Cookies(common.JWTCookieName)
+	encryptedToken := ctx.Cookies(common.EncryptedTokenCookieName)
+	if token == "" || encryptedToken == "" {
+		return "", common.ErrTokenRequired
+	}
+
+	userID, err := authService.ValidateToken(token)
+	if err != nil {
+		return "", common.ErrTokenInvalid
+	}
+
+	requestURL := utils.ExternalRequestURL(global.Config.Server.PublicURL, ctx.BaseURL(), ctx.Path())
+	if err := authService.CheckDPoPBinding(token, ctx.Get(common.DPoPHeader), ctx.Method(), requestURL); err != nil {
+		return "", err
+	}
+
+	session, err := authService.TouchSession(userID, token, encryptedToken)
+	if err != nil {
+		return "", err
+	}
+
+	ctx.Locals(common.ContextUserID, userID)
+	ctx.Locals(common.ContextSession, session)
+
+	return userID, nil
+}
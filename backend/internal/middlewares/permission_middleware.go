@@ -0,0 +1,79 @@
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/services"
+	"go-backend-v2/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var errNotWorkspaceMember = common.NewForbiddenError("You are not an active member of this workspace")
+
+// RequirePermission lets a request through when the caller holds a permission in the workspace
+// it addresses. The workspace comes from the id or slug route param, or else the
+// X-Organization-ID header, and is stored in the context. Permissions are read from the RBAC data
+// cached with the session, which services refresh whenever a membership or role changes. Super
+// admins pass everywhere. Requests not yet authenticated by AuthMiddleware are authenticated here.
+func RequirePermission(authService services.AuthServiceInterface, workspaceService services.WorkspaceServiceInterface, permission string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		userID, ok := ctx.Locals(common.ContextUserID).(string)
+		if !ok || userID == "" {
+			var err error
+			if userID, err = authenticate(ctx, authService); err != nil {
+				return err
+			}
+		}
+
+		// The session has just been validated, so missing token data means it ended meanwhile
+		tokenData, err := authService.GetTokenData(userID, ctx.Cookies(common.EncryptedTokenCookieName))
+		if err != nil {
+			return common.ErrTokenInvalid
+		}
+		superAdmin := tokenData.GlobalRole == common.GlobalRoleSuperAdmin
+
+		workspaceID, err := resolveWorkspace(ctx, workspaceService)
+		if err != nil {
+			// Outsiders get the same answer whether or not the workspace exists
+			if errors.Is(err, common.ErrWorkspaceNotFound) && !superAdmin {
+				return errNotWorkspaceMember
+			}
+			return err
+		}
+
+		ctx.Locals(common.ContextWorkspaceID, workspaceID)
+
+		if superAdmin {
+			return ctx.Next()
+		}
+
+		for _, membership := range tokenData.WorkspaceMemberships {
+			if membership.WorkspaceID != workspaceID || membership.Status != common.ActiveStatus {
+				continue
+			}
+			if !utils.HasPermission(membership.Permissions, permission) {
+				return common.NewForbiddenError(fmt.Sprintf("Your role %q does not grant the %s permission in this workspace", membership.RoleName, permission))
+			}
+			return ctx.Next()
+		}
+
+		return errNotWorkspaceMember
+	}
+}
+
+func resolveWorkspace(ctx *fiber.Ctx, workspaceService services.WorkspaceServiceInterface) (string, error) {
+	ref := ctx.Params("id")
+	if ref == "" {
+		ref = ctx.Params("slug")
+	}
+	if ref == "" {
+		ref = ctx.Get(common.WorkspaceHeader)
+	}
+	if ref == "" {
+		return "", common.ErrWorkspaceRequired
+	}
+
+	return workspaceService.ResolveWorkspaceID(ref)
+}
@@ -0,0 +1,86 @@
+package middlewares
+
+import (
+	"errors"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SCIMErrorHandler renders errors of the SCIM endpoints in the SCIM error format (RFC 7644
+// section 3.12) instead of the API error format used by the rest of the application
+func SCIMErrorHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		err := ctx.Next()
+		if err == nil {
+			return nil
+		}
+
+		status := common.ErrInternalServer.Status
+		response := dto.SCIMErrorResponse{
+			Schemas: []string{common.SCIMSchemaError},
+			Detail:  common.ErrInternalServer.Message,
+		}
+
+		var scimErr *common.SCIMError
+		var apiErr *common.APIError
+		var fiberErr *fiber.Error
+		switch {
+		case errors.As(err, &scimErr):
+			status = scimErr.Status
+			response.ScimType = scimErr.ScimType
+			response.Detail = scimErr.Detail
+		case errors.As(err, &apiErr):
+			status = apiErr.Status
+			response.Detail = apiErr.Message
+		case errors.As(err, &fiberErr):
+			status = fiberErr.Code
+			response.Detail = fiberErr.Message
+		}
+		response.Status = strconv.Itoa(status)
+
+		if status == http.StatusUnauthorized {
+			ctx.Set(fiber.HeaderWWWAuthenticate, common.SCIMBearerAuthScheme)
+		}
+
+		return writeSCIMJSON(ctx, status, response)
+	}
+}
+
+// SCIMAuth authenticates a workspace-scoped SCIM bearer token and exposes its workspace
+func SCIMAuth(scimService services.SCIMServiceInterface) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		header := ctx.Get(fiber.HeaderAuthorization)
+		if header == "" {
+			return common.ErrTokenRequired
+		}
+
+		scheme, secret, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, common.SCIMBearerAuthScheme) || strings.TrimSpace(secret) == "" {
+			return common.ErrInvalidTokenFormat
+		}
+
+		token, err := scimService.AuthenticateToken(strings.TrimSpace(secret))
+		if err != nil {
+			return err
+		}
+
+		ctx.Locals(common.ContextWorkspaceID, token.WorkspaceID)
+		ctx.Locals(common.ContextSCIMTokenID, token.ID)
+
+		return ctx.Next()
+	}
+}
+
+func writeSCIMJSON(ctx *fiber.Ctx, status int, body interface{}) error {
+	if err := ctx.Status(status).JSON(body); err != nil {
+		return err
+	}
+	ctx.Set(fiber.HeaderContentType, common.SCIMContentType)
+	return nil
+}
@@ -43,7 +43,8 @@ const (
 	ResourceCategoryIntegration = "integration"
 )
 
-// GetValidActions returns valid actions for any resource
+// GetValidActions returns valid actions for any resource. Besides CRUD, archive, invite and remove
+// name the lifecycle operations of workspaces and members.
 func GetValidActions() []string {
-	return []string{"read", "create", "update", "delete", "all"}
+	return []string{"read", "create", "update", "delete", "archive", "invite", "remove", "all"}
 }
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserDevice represents a device a user has previously signed in from
+type UserDevice struct {
+	ID          string    `gorm:"type:varchar(36);primaryKey" json:"id"`
+	UserID      string    `gorm:"type:varchar(36);not null;uniqueIndex:idx_user_device_fingerprint" json:"user_id"`
+	Fingerprint string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_user_device_fingerprint" json:"fingerprint"`
+	Browser     string    `gorm:"type:varchar(50);not null" json:"browser"`
+	OS          string    `gorm:"type:varchar(50);not null" json:"os"`
+	IPSubnet    string    `gorm:"type:varchar(64);not null" json:"ip_subnet"`
+	LastIP      string    `gorm:"type:varchar(45)" json:"last_ip"`
+	UserAgent   string    `gorm:"type:varchar(512)" json:"user_agent"`
+	FirstSeenAt time.Time `gorm:"not null" json:"first_seen_at"`
+	LastSeenAt  time.Time `gorm:"not null;index" json:"last_seen_at"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relationships
+	User User `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// GORM hooks
+func (ud *UserDevice) BeforeCreate(tx *gorm.DB) (err error) {
+	if ud.ID == "" {
+		ud.ID = uuid.New().String()
+	}
+	return
+}
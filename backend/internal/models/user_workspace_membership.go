@@ -15,6 +15,7 @@ type UserWorkspaceMembership struct {
 	RoleID      string     `gorm:"type:varchar(36);not null" json:"role_id"`
 	Status      string     `gorm:"type:varchar(50);not null;default:'active';index" json:"status"`
 	InvitedBy   *string    `gorm:"type:varchar(36)" json:"invited_by,omitempty"`
+	ExternalID  *string    `gorm:"type:varchar(255);index" json:"external_id,omitempty"` // identifier assigned by the SCIM client
 	JoinedAt    *time.Time `gorm:"type:timestamp" json:"joined_at,omitempty"`
 	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
@@ -46,21 +46,26 @@ func (ws WorkspaceSettings) Value() (driver.Value, error) {
 
 // Workspace represents a multi-tenant workspace
 type Workspace struct {
-	ID          string             `gorm:"type:varchar(36);primaryKey" json:"id"`
-	Name        string             `gorm:"type:varchar(255);not null" json:"name"`
-	Slug        string             `gorm:"type:varchar(100);uniqueIndex;not null" json:"slug"`
-	Description *string            `gorm:"type:text" json:"description,omitempty"`
-	AvatarURL   *string            `gorm:"type:varchar(500)" json:"avatar_url,omitempty"`
-	OwnerID     string             `gorm:"type:varchar(36);not null;index" json:"owner_id"`
-	Settings    *WorkspaceSettings `gorm:"type:json" json:"settings,omitempty"`
-	Status      string             `gorm:"type:varchar(50);not null;default:'active';index" json:"status"`
-	CreatedAt   time.Time          `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt   time.Time          `gorm:"autoUpdateTime" json:"updated_at"`
+	ID                 string             `gorm:"type:varchar(36);primaryKey" json:"id"`
+	Name               string             `gorm:"type:varchar(255);not null" json:"name"`
+	Slug               string             `gorm:"type:varchar(100);uniqueIndex;not null" json:"slug"`
+	Description        *string            `gorm:"type:text" json:"description,omitempty"`
+	AvatarURL          *string            `gorm:"type:varchar(500)" json:"avatar_url,omitempty"`
+	AvatarThumbnailURL *string            `gorm:"type:varchar(500)" json:"avatar_thumbnail_url,omitempty"`
+	AvatarKey          *string            `gorm:"type:varchar(255)" json:"-"` // storage key of an uploaded avatar, the thumbnail sits next to it
+	OwnerID            string             `gorm:"type:varchar(36);not null;index" json:"owner_id"`
+	Settings           *WorkspaceSettings `gorm:"type:json" json:"settings,omitempty"`
+	Plan               string             `gorm:"type:varchar(50);not null;default:''" json:"plan"` // key of workspace.plans, sets the member limit; empty for workspaces that predate plans
+	Status             string             `gorm:"type:varchar(50);not null;default:'active';index" json:"status"`
+	ArchivedAt         *time.Time         `gorm:"type:timestamp" json:"archived_at,omitempty"`
+	CreatedAt          time.Time          `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt          time.Time          `gorm:"autoUpdateTime" json:"updated_at"`
 
 	// Relationships
 	Owner       User                      `gorm:"constraint:OnDelete:RESTRICT" json:"owner,omitempty"`
 	Roles       []WorkspaceRole           `gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE" json:"roles,omitempty"`
 	Memberships []UserWorkspaceMembership `gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE" json:"memberships,omitempty"`
+	SAMLConfig  *WorkspaceSAMLConfig      `gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE" json:"saml_config,omitempty"`
 }
 
 // GORM hooks
@@ -76,5 +81,6 @@ const (
 	WorkspaceStatusActive    = "active"
 	WorkspaceStatusInactive  = "inactive"
 	WorkspaceStatusSuspended = "suspended"
+	WorkspaceStatusArchived  = "archived" // read-only until unarchived
 	WorkspaceStatusDeleted   = "deleted"
 )
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WorkspaceDomain is an email domain claimed by a workspace. Once its DNS TXT record proves the
+// workspace controls the domain, users with a verified email there can join without an invitation.
+type WorkspaceDomain struct {
+	ID                string     `gorm:"type:varchar(36);primaryKey" json:"id"`
+	WorkspaceID       string     `gorm:"type:varchar(36);not null;uniqueIndex:idx_workspace_domain" json:"workspace_id"`
+	Domain            string     `gorm:"type:varchar(253);not null;uniqueIndex:idx_workspace_domain;index" json:"domain"`
+	VerificationToken string     `gorm:"type:varchar(100);not null" json:"-"`
+	Status            string     `gorm:"type:varchar(50);not null;default:'pending';index" json:"status"`
+	VerifiedAt        *time.Time `gorm:"type:timestamp" json:"verified_at,omitempty"`
+	CreatedBy         string     `gorm:"type:varchar(36);not null" json:"created_by"`
+	CreatedAt         time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relationships
+	Workspace Workspace `gorm:"constraint:OnDelete:CASCADE" json:"workspace,omitempty"`
+}
+
+// GORM hooks
+func (d *WorkspaceDomain) BeforeCreate(tx *gorm.DB) (err error) {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	return
+}
+
+// Constants for workspace domain status
+const (
+	DomainStatusPending  = "pending"
+	DomainStatusVerified = "verified"
+)
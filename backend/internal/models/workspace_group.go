@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WorkspaceGroup gathers members of a workspace so that roles can be assigned to all of them at
+// once. Members hold the permissions of their own role and of every role of their groups.
+type WorkspaceGroup struct {
+	ID          string    `gorm:"type:varchar(36);primaryKey" json:"id"`
+	WorkspaceID string    `gorm:"type:varchar(36);not null;uniqueIndex:idx_workspace_group_name" json:"workspace_id"`
+	Name        string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_workspace_group_name" json:"name"`
+	Description *string   `gorm:"type:text" json:"description,omitempty"`
+	CreatedBy   string    `gorm:"type:varchar(36);not null" json:"created_by"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relationships
+	Workspace  Workspace              `gorm:"constraint:OnDelete:CASCADE" json:"workspace,omitempty"`
+	Members    []WorkspaceGroupMember `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE" json:"members,omitempty"`
+	RoleGrants []WorkspaceGroupRole   `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE" json:"role_grants,omitempty"`
+}
+
+// WorkspaceGroupMember puts a workspace member in a group. It only grants permissions while the
+// workspace membership of the user is active.
+type WorkspaceGroupMember struct {
+	ID        string    `gorm:"type:varchar(36);primaryKey" json:"id"`
+	GroupID   string    `gorm:"type:varchar(36);not null;uniqueIndex:idx_group_member" json:"group_id"`
+	UserID    string    `gorm:"type:varchar(36);not null;uniqueIndex:idx_group_member;index" json:"user_id"`
+	AddedBy   *string   `gorm:"type:varchar(36)" json:"added_by,omitempty"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// Relationships
+	Group WorkspaceGroup `gorm:"constraint:OnDelete:CASCADE" json:"group,omitempty"`
+	User  User           `gorm:"constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+// WorkspaceGroupRole assigns a role of the workspace to every member of a group
+type WorkspaceGroupRole struct {
+	ID        string    `gorm:"type:varchar(36);primaryKey" json:"id"`
+	GroupID   string    `gorm:"type:varchar(36);not null;uniqueIndex:idx_group_role" json:"group_id"`
+	RoleID    string    `gorm:"type:varchar(36);not null;uniqueIndex:idx_group_role;index" json:"role_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// Relationships
+	Group WorkspaceGroup `gorm:"constraint:OnDelete:CASCADE" json:"group,omitempty"`
+	Role  WorkspaceRole  `gorm:"constraint:OnDelete:RESTRICT" json:"role,omitempty"`
+}
+
+// GORM hooks
+func (g *WorkspaceGroup) BeforeCreate(tx *gorm.DB) (err error) {
+	if g.ID == "" {
+		g.ID = uuid.New().String()
+	}
+	return
+}
+
+func (m *WorkspaceGroupMember) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	return
+}
+
+func (r *WorkspaceGroupRole) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WorkspaceInvitation invites an email address, registered or not, to join a workspace with a role
+type WorkspaceInvitation struct {
+	ID          string     `gorm:"type:varchar(36);primaryKey" json:"id"`
+	WorkspaceID string     `gorm:"type:varchar(36);not null;index:idx_invitation_workspace_email" json:"workspace_id"`
+	Email       string     `gorm:"type:varchar(255);not null;index:idx_invitation_workspace_email;index" json:"email"`
+	RoleID      string     `gorm:"type:varchar(36);not null" json:"role_id"`
+	InvitedBy   string     `gorm:"type:varchar(36);not null" json:"invited_by"`
+	Status      string     `gorm:"type:varchar(50);not null;default:'pending';index" json:"status"`
+	TokenNonce  string     `gorm:"type:varchar(64);not null" json:"-"` // ID of the only link token still valid
+	ExpiresAt   time.Time  `gorm:"type:timestamp;not null" json:"expires_at"`
+	SentAt      time.Time  `gorm:"type:timestamp;not null" json:"sent_at"`
+	SendCount   int        `gorm:"not null;default:1" json:"send_count"`
+	RespondedAt *time.Time `gorm:"type:timestamp" json:"responded_at,omitempty"`
+	AcceptedBy  *string    `gorm:"type:varchar(36)" json:"accepted_by,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relationships
+	Workspace Workspace     `gorm:"constraint:OnDelete:CASCADE" json:"workspace,omitempty"`
+	Role      WorkspaceRole `gorm:"constraint:OnDelete:CASCADE" json:"role,omitempty"`
+	Inviter   User          `gorm:"foreignKey:InvitedBy" json:"inviter,omitempty"`
+}
+
+// GORM hooks
+func (wi *WorkspaceInvitation) BeforeCreate(tx *gorm.DB) (err error) {
+	if wi.ID == "" {
+		wi.ID = uuid.New().String()
+	}
+	return
+}
+
+// Constants for invitation status. Pending invitations past ExpiresAt are reported as expired.
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusDeclined = "declined"
+	InvitationStatusRevoked  = "revoked"
+	InvitationStatusExpired  = "expired"
+)
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WorkspaceOwnershipTransfer nominates an active member as the next owner of a workspace. The
+// ownership only moves once the nominee accepts.
+type WorkspaceOwnershipTransfer struct {
+	ID          string     `gorm:"type:varchar(36);primaryKey" json:"id"`
+	WorkspaceID string     `gorm:"type:varchar(36);not null;index" json:"workspace_id"`
+	FromUserID  string     `gorm:"type:varchar(36);not null" json:"from_user_id"`
+	ToUserID    string     `gorm:"type:varchar(36);not null;index" json:"to_user_id"`
+	InitiatedBy string     `gorm:"type:varchar(36);not null" json:"initiated_by"` // the owner or a super admin
+	Status      string     `gorm:"type:varchar(50);not null;default:'pending';index" json:"status"`
+	ExpiresAt   time.Time  `gorm:"type:timestamp;not null" json:"expires_at"`
+	RespondedAt *time.Time `gorm:"type:timestamp" json:"responded_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relationships
+	Workspace Workspace `gorm:"constraint:OnDelete:CASCADE" json:"workspace,omitempty"`
+}
+
+// GORM hooks
+func (t *WorkspaceOwnershipTransfer) BeforeCreate(tx *gorm.DB) (err error) {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return
+}
+
+// Constants for ownership transfer status. Pending transfers past ExpiresAt are reported as expired.
+const (
+	OwnershipTransferStatusPending   = "pending"
+	OwnershipTransferStatusAccepted  = "accepted"
+	OwnershipTransferStatusDeclined  = "declined"
+	OwnershipTransferStatusCancelled = "cancelled"
+	OwnershipTransferStatusExpired   = "expired"
+)
@@ -26,9 +26,12 @@ type WorkspaceRole struct {
 	Memberships []UserWorkspaceMembership `gorm:"foreignKey:RoleID" json:"memberships,omitempty"`
 }
 
-// RolePermissions represents the JSON structure for permissions
+// RolePermissions represents the JSON structure for permissions. Permissions follow the grammar of
+// utils.ParsePermission; Inherits lists roles of the same workspace whose permissions the role
+// grants as well.
 type RolePermissions struct {
 	Permissions []string           `json:"permissions"`
+	Inherits    []string           `json:"inherits,omitempty"`
 	Metadata    PermissionMetadata `json:"metadata"`
 }
 
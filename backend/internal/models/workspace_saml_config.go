@@ -0,0 +1,78 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SAMLAttributeMapping names the IdP assertion attributes that hold each profile field
+type SAMLAttributeMapping struct {
+	Email       string `json:"email,omitempty"`
+	FirstName   string `json:"first_name,omitempty"`
+	LastName    string `json:"last_name,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+	Groups      string `json:"groups,omitempty"`
+}
+
+func (m *SAMLAttributeMapping) Scan(value interface{}) error {
+	if value == nil {
+		*m = SAMLAttributeMapping{}
+		return nil
+	}
+
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into SAMLAttributeMapping", value)
+	}
+
+	if len(bytes) == 0 {
+		*m = SAMLAttributeMapping{}
+		return nil
+	}
+
+	return json.Unmarshal(bytes, m)
+}
+
+func (m SAMLAttributeMapping) Value() (driver.Value, error) {
+	return json.Marshal(m)
+}
+
+// WorkspaceSAMLConfig holds the SAML 2.0 service provider configuration of a workspace
+type WorkspaceSAMLConfig struct {
+	ID                string               `gorm:"type:varchar(36);primaryKey" json:"id"`
+	WorkspaceID       string               `gorm:"type:varchar(36);uniqueIndex;not null" json:"workspace_id"`
+	Enabled           bool                 `gorm:"not null;default:false" json:"enabled"`
+	IdPEntityID       string               `gorm:"type:varchar(500);not null" json:"idp_entity_id"`
+	IdPSSOURL         string               `gorm:"type:varchar(1000);not null" json:"idp_sso_url"`
+	IdPMetadataXML    *string              `gorm:"type:mediumtext" json:"idp_metadata_xml,omitempty"`
+	IdPCertificate    *string              `gorm:"type:text" json:"idp_certificate,omitempty"`
+	SPCertificate     string               `gorm:"type:text;not null" json:"sp_certificate"`
+	SPPrivateKey      string               `gorm:"type:text;not null" json:"-"` // AES-GCM encrypted PEM
+	AttributeMapping  SAMLAttributeMapping `gorm:"type:json" json:"attribute_mapping"`
+	DefaultRoleID     *string              `gorm:"type:varchar(36)" json:"default_role_id,omitempty"`
+	AllowIDPInitiated bool                 `gorm:"not null;default:false" json:"allow_idp_initiated"`
+	JITProvisioning   bool                 `gorm:"not null;default:true" json:"jit_provisioning"`
+	CreatedAt         time.Time            `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time            `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relationships
+	Workspace Workspace `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// GORM hooks
+func (c *WorkspaceSAMLConfig) BeforeCreate(tx *gorm.DB) (err error) {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WorkspaceSCIMToken is a bearer token a workspace hands to its identity provider for SCIM
+// provisioning. Only the SHA-256 of the token is stored.
+type WorkspaceSCIMToken struct {
+	ID          string     `gorm:"type:varchar(36);primaryKey" json:"id"`
+	WorkspaceID string     `gorm:"type:varchar(36);not null;index" json:"workspace_id"`
+	Name        string     `gorm:"type:varchar(100);not null" json:"name"`
+	TokenHash   string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	TokenPrefix string     `gorm:"type:varchar(16);not null" json:"token_prefix"`
+	CreatedBy   string     `gorm:"type:varchar(36);not null" json:"created_by"`
+	ExpiresAt   *time.Time `gorm:"type:timestamp" json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `gorm:"type:timestamp" json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `gorm:"type:timestamp" json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relationships
+	Workspace Workspace `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// GORM hooks
+func (t *WorkspaceSCIMToken) BeforeCreate(tx *gorm.DB) (err error) {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return
+}
+
+// IsUsable reports whether the token is neither revoked nor expired
+func (t *WorkspaceSCIMToken) IsUsable(now time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	return t.ExpiresAt == nil || now.Before(*t.ExpiresAt)
+}
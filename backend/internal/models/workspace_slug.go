@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WorkspaceSlug records every slug a workspace has had. Former slugs keep resolving to the
+// workspace after a rename, and no other workspace can take them over.
+type WorkspaceSlug struct {
+	ID          string    `gorm:"type:varchar(36);primaryKey" json:"id"`
+	Slug        string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"slug"`
+	WorkspaceID string    `gorm:"type:varchar(36);not null;index" json:"workspace_id"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// Relationships
+	Workspace Workspace `gorm:"constraint:OnDelete:CASCADE" json:"workspace,omitempty"`
+}
+
+// GORM hooks
+func (s *WorkspaceSlug) BeforeCreate(tx *gorm.DB) (err error) {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return
+}
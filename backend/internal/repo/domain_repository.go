@@ -0,0 +1,118 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrDuplicateDomain is returned when a workspace adds a domain it already has
+var ErrDuplicateDomain = errors.New("workspace already has this domain")
+
+type DomainRepository struct {
+	db *gorm.DB
+}
+
+func NewDomainRepository() DomainRepositoryInterface {
+	return &DomainRepository{
+		db: global.DB,
+	}
+}
+
+// CreateDomain inserts a domain, returning ErrDuplicateDomain when the workspace already has it
+func (r *DomainRepository) CreateDomain(domain *models.WorkspaceDomain) error {
+	if err := r.db.Create(domain).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return ErrDuplicateDomain
+		}
+		return fmt.Errorf("failed to create workspace domain: %w", err)
+	}
+	return nil
+}
+
+func (r *DomainRepository) GetDomain(workspaceID, domainID string) (*models.WorkspaceDomain, error) {
+	var domain models.WorkspaceDomain
+
+	err := r.db.Where("id = ? AND workspace_id = ?", domainID, workspaceID).First(&domain).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get workspace domain: %w", err)
+	}
+
+	return &domain, nil
+}
+
+func (r *DomainRepository) ListDomains(workspaceID string) ([]models.WorkspaceDomain, error) {
+	var domains []models.WorkspaceDomain
+
+	err := r.db.Where("workspace_id = ?", workspaceID).Order("domain ASC").Find(&domains).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspace domains: %w", err)
+	}
+
+	return domains, nil
+}
+
+// GetVerifiedDomain retrieves the verified entry of a domain in a workspace
+func (r *DomainRepository) GetVerifiedDomain(workspaceID, domain string) (*models.WorkspaceDomain, error) {
+	var workspaceDomain models.WorkspaceDomain
+
+	err := r.db.Where("workspace_id = ? AND domain = ? AND status = ?", workspaceID, domain, models.DomainStatusVerified).
+		First(&workspaceDomain).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get workspace domain: %w", err)
+	}
+
+	return &workspaceDomain, nil
+}
+
+// GetWorkspacesWithVerifiedDomain retrieves the active workspaces that verified a domain
+func (r *DomainRepository) GetWorkspacesWithVerifiedDomain(domain string) ([]models.Workspace, error) {
+	var workspaces []models.Workspace
+
+	err := r.db.Joins("JOIN workspace_domains ON workspace_domains.workspace_id = workspaces.id").
+		Where("workspace_domains.domain = ? AND workspace_domains.status = ? AND workspaces.status = ?",
+			domain, models.DomainStatusVerified, common.ActiveStatus).
+		Order("workspaces.name ASC").
+		Find(&workspaces).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspaces by domain: %w", err)
+	}
+
+	return workspaces, nil
+}
+
+func (r *DomainRepository) UpdateDomain(domainID string, updates map[string]interface{}) error {
+	result := r.db.Model(&models.WorkspaceDomain{}).Where("id = ?", domainID).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update workspace domain: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *DomainRepository) DeleteDomain(domainID string) error {
+	result := r.db.Where("id = ?", domainID).Delete(&models.WorkspaceDomain{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete workspace domain: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
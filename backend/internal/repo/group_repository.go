@@ -0,0 +1,186 @@
+package repo
+
+import (
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type GroupRepository struct {
+	db *gorm.DB
+}
+
+func NewGroupRepository() GroupRepositoryInterface {
+	return &GroupRepository{
+		db: global.DB,
+	}
+}
+
+// CreateGroup inserts a group together with its role grants
+func (r *GroupRepository) CreateGroup(tx *gorm.DB, group *models.WorkspaceGroup) error {
+	if err := tx.Create(group).Error; err != nil {
+		return fmt.Errorf("failed to create group: %w", err)
+	}
+	return nil
+}
+
+// GetGroup retrieves a group of a workspace with its roles
+func (r *GroupRepository) GetGroup(workspaceID, groupID string) (*models.WorkspaceGroup, error) {
+	var group models.WorkspaceGroup
+
+	err := r.db.Preload("RoleGrants.Role").
+		Where("id = ? AND workspace_id = ?", groupID, workspaceID).
+		First(&group).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+
+	return &group, nil
+}
+
+func (r *GroupRepository) GetGroupByName(workspaceID, name string) (*models.WorkspaceGroup, error) {
+	var group models.WorkspaceGroup
+
+	err := r.db.Where("workspace_id = ? AND name = ?", workspaceID, name).First(&group).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get group by name: %w", err)
+	}
+
+	return &group, nil
+}
+
+// ListGroups retrieves the groups of a workspace with their roles, by name
+func (r *GroupRepository) ListGroups(workspaceID string) ([]models.WorkspaceGroup, error) {
+	var groups []models.WorkspaceGroup
+
+	err := r.db.Preload("RoleGrants.Role").
+		Where("workspace_id = ?", workspaceID).
+		Order("name ASC").
+		Find(&groups).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+func (r *GroupRepository) UpdateGroup(groupID string, updates map[string]interface{}) error {
+	result := r.db.Model(&models.WorkspaceGroup{}).Where("id = ?", groupID).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update group: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// DeleteGroup removes a group with its members and role grants
+func (r *GroupRepository) DeleteGroup(groupID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", groupID).Delete(&models.WorkspaceGroupMember{}).Error; err != nil {
+			return fmt.Errorf("failed to delete group members: %w", err)
+		}
+		if err := tx.Where("group_id = ?", groupID).Delete(&models.WorkspaceGroupRole{}).Error; err != nil {
+			return fmt.Errorf("failed to delete group roles: %w", err)
+		}
+
+		result := tx.Where("id = ?", groupID).Delete(&models.WorkspaceGroup{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete group: %w", result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return nil
+	})
+}
+
+// ListGroupMembers retrieves the members of a group with their user and profile, in the order they were added
+func (r *GroupRepository) ListGroupMembers(groupID string) ([]models.WorkspaceGroupMember, error) {
+	var members []models.WorkspaceGroupMember
+
+	err := r.db.Preload("User.Profile").
+		Where("group_id = ?", groupID).
+		Order("created_at ASC").
+		Find(&members).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list group members: %w", err)
+	}
+
+	return members, nil
+}
+
+func (r *GroupRepository) GetGroupMember(groupID, userID string) (*models.WorkspaceGroupMember, error) {
+	var member models.WorkspaceGroupMember
+
+	err := r.db.Where("group_id = ? AND user_id = ?", groupID, userID).First(&member).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get group member: %w", err)
+	}
+
+	return &member, nil
+}
+
+// AddGroupMember inserts a group member, doing nothing when the user is already in the group
+func (r *GroupRepository) AddGroupMember(member *models.WorkspaceGroupMember) error {
+	if err := r.db.Create(member).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to add group member: %w", err)
+	}
+	return nil
+}
+
+func (r *GroupRepository) RemoveGroupMember(groupID, userID string) error {
+	result := r.db.Where("group_id = ? AND user_id = ?", groupID, userID).Delete(&models.WorkspaceGroupMember{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove group member: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// AddGroupRole assigns a role to a group, doing nothing when the group already has it
+func (r *GroupRepository) AddGroupRole(groupID, roleID string) error {
+	if err := r.db.Create(&models.WorkspaceGroupRole{GroupID: groupID, RoleID: roleID}).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to add group role: %w", err)
+	}
+	return nil
+}
+
+func (r *GroupRepository) RemoveGroupRole(groupID, roleID string) error {
+	result := r.db.Where("group_id = ? AND role_id = ?", groupID, roleID).Delete(&models.WorkspaceGroupRole{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove group role: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
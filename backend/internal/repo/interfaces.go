@@ -2,6 +2,7 @@ package repo
 
 import (
 	"go-backend-v2/internal/models"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -10,12 +11,19 @@ type UserRepositoryInterface interface {
 	CreateUserWithAuth(tx *gorm.DB, user *models.User, profile *models.UserProfile, authProvider *models.UserAuthProvider) error
 	GetUserByEmail(email string) (*models.User, error)
 	GetUserByID(userID string) (*models.User, error)
+	LockUser(tx *gorm.DB, userID string) error // row lock held until the transaction ends
 	GetUserWithProfile(userID string) (*models.User, error)
-	GetUserWithWorkspaces(userID string) (*models.User, error)
+	GetUserWithWorkspaces(userID string) (*models.User, error)           // active memberships and the role grants of the user's groups
+	GetUserWorkspaceRoles(userID string) ([]models.WorkspaceRole, error) // active roles of the workspaces of the active memberships
 	UpdateUser(userID string, updates map[string]interface{}) error
-	DeleteUser(userID string) error
+	ChangeEmail(tx *gorm.DB, userID, oldEmail, newEmail string) error
+	ScheduleUserDeletion(userID string, purgeAt time.Time) error
+	RestoreUser(userID string) error
+	GetUsersDueForPurge(now time.Time, limit int) ([]models.User, error)
+	PurgeUser(tx *gorm.DB, userID string) error
 
 	GetUserAuthProvider(userID, provider string) (*models.UserAuthProvider, error)
+	GetAuthProviderByProviderUserID(provider, providerUserID string) (*models.UserAuthProvider, error)
 	GetUserAuthProviders(userID string) ([]models.UserAuthProvider, error)
 	CreateAuthProvider(authProvider *models.UserAuthProvider) error
 	UpdateAuthProvider(providerID string, updates map[string]interface{}) error
@@ -31,25 +39,47 @@ type UserRepositoryInterface interface {
 type WorkspaceRepositoryInterface interface {
 	CreateWorkspace(tx *gorm.DB, workspace *models.Workspace) error
 	GetWorkspaceByID(workspaceID string) (*models.Workspace, error)
-	GetWorkspaceBySlug(slug string) (*models.Workspace, error)
+	GetWorkspaceBySlug(slug string) (*models.Workspace, error)              // former slugs resolve too
+	ClaimSlug(tx *gorm.DB, workspaceID, slug string) error                  // utils.ErrSlugTaken when another workspace has or had it
+	RenameSlug(tx *gorm.DB, workspaceID, currentSlug, newSlug string) error // keeps the current slug in the history
 	GetWorkspacesByOwnerID(ownerID string) ([]models.Workspace, error)
+	ListWorkspaces(status, search string, offset, limit int) ([]models.Workspace, int64, error) // empty filters match all
+	GetWorkspacesWithSettingsBelow(version int, afterID string, limit int) ([]models.Workspace, error)
+	LockWorkspace(tx *gorm.DB, workspaceID string) (*models.Workspace, error) // row lock held until the transaction ends
+	CountOwnedWorkspaces(tx *gorm.DB, ownerID string) (int64, error)          // not deleted
 	UpdateWorkspace(workspaceID string, updates map[string]interface{}) error
+	TransferOwnership(tx *gorm.DB, workspaceID, fromUserID, toUserID string) error // fails unless fromUserID still owns it
 	DeleteWorkspace(workspaceID string) error
 
 	CreateMembership(tx *gorm.DB, membership *models.UserWorkspaceMembership) error
 	GetMembership(userID, workspaceID string) (*models.UserWorkspaceMembership, error)
 	GetUserMemberships(userID string) ([]models.UserWorkspaceMembership, error)
+	GetUserMembershipsWithDetails(userID string) ([]models.UserWorkspaceMembership, error) // preloads workspace and role
 	GetWorkspaceMemberships(workspaceID string) ([]models.UserWorkspaceMembership, error)
+	ListWorkspaceMembers(workspaceID string, filter *MemberFilter) ([]models.UserWorkspaceMembership, error) // keyset page with user, profile and role
+	GetWorkspaceMember(workspaceID, userID string) (*models.UserWorkspaceMembership, error)                  // with user, profile and role
+	CountSeatedMembers(tx *gorm.DB, workspaceID string) (int64, error)                                       // active and suspended
+	RejoinMembership(tx *gorm.DB, membershipID, roleID string, invitedBy *string) error                      // reactivates a former membership
+	ActivatePendingMembership(tx *gorm.DB, membershipID, roleID string) error                                // fails unless the membership is still pending
+	RejectPendingMembership(membershipID string) error                                                       // fails unless the membership is still pending
 	UpdateMembership(membershipID string, updates map[string]interface{}) error
-	DeleteMembership(membershipID string) error
+	SetMembershipRole(tx *gorm.DB, membershipID, roleID string) error
+	DeleteMembership(membershipID string) error // also takes the user out of the workspace groups
 
 	CreateWorkspaceRole(tx *gorm.DB, role *models.WorkspaceRole) error
 	GetWorkspaceRole(roleID string) (*models.WorkspaceRole, error)
 	GetWorkspaceRoles(workspaceID string) ([]models.WorkspaceRole, error)
 	UpdateWorkspaceRole(roleID string, updates map[string]interface{}) error
 	DeleteWorkspaceRole(roleID string) error
+	GetRoleMemberships(roleID string) ([]models.UserWorkspaceMembership, error)   // active, suspended and pending
+	GetUserGroupRoles(workspaceID, userID string) ([]models.WorkspaceRole, error) // active roles assigned to the user's groups
+	CountRoleGroups(roleID string) (int64, error)
+	GetRoleGroupMemberIDs(roleID string) ([]string, error)
+	GetWorkspaceRoleByName(workspaceID, name string) (*models.WorkspaceRole, error)
+
+	GetSAMLConfig(workspaceID string) (*models.WorkspaceSAMLConfig, error)
+	SaveSAMLConfig(config *models.WorkspaceSAMLConfig) error
 
-	ExistsBySlug(slug string) (bool, error)
 	ExistsByID(workspaceID string) (bool, error)
 }
 
@@ -61,11 +91,78 @@ type ResourceRepositoryInterface interface {
 	GetResourcesByCategory(category string) ([]models.Resource, error)
 	UpdateResource(resourceID string, updates map[string]interface{}) error
 	DeleteResource(resourceID string) error
+	CountReferencingRoles(name string) (int64, error) // active roles granting any action on the resource
 
 	ExistsByName(name string) (bool, error)
 	ExistsByID(resourceID string) (bool, error)
 }
 
+type UserDeviceRepositoryInterface interface {
+	CreateDevice(device *models.UserDevice) error
+	GetDeviceByFingerprint(userID, fingerprint string) (*models.UserDevice, error)
+	GetUserDevices(userID string) ([]models.UserDevice, error)
+	CountUserDevices(userID string) (int64, error)
+	UpdateDevice(deviceID string, updates map[string]interface{}) error
+	DeleteDevice(userID, deviceID string) error
+}
+
+type SCIMRepositoryInterface interface {
+	CreateToken(token *models.WorkspaceSCIMToken) error
+	GetTokenByHash(tokenHash string) (*models.WorkspaceSCIMToken, error)
+	GetToken(workspaceID, tokenID string) (*models.WorkspaceSCIMToken, error)
+	GetWorkspaceTokens(workspaceID string) ([]models.WorkspaceSCIMToken, error)
+	UpdateToken(tokenID string, updates map[string]interface{}) error
+
+	ListMembers(workspaceID, condition string, args []interface{}, offset, limit int) ([]models.UserWorkspaceMembership, int64, error)
+	GetMember(workspaceID, userID string) (*models.UserWorkspaceMembership, error)
+	ListRoles(workspaceID, condition string, args []interface{}, offset, limit int) ([]models.WorkspaceRole, int64, error)
+	GetRole(workspaceID, roleID string) (*models.WorkspaceRole, error)
+}
+
+type InvitationRepositoryInterface interface {
+	CreateInvitation(invitation *models.WorkspaceInvitation) error
+	GetInvitation(workspaceID, invitationID string) (*models.WorkspaceInvitation, error)
+	GetInvitationWithDetails(invitationID string) (*models.WorkspaceInvitation, error) // preloads workspace, role and inviter profile
+	GetPendingInvitation(workspaceID, email string) (*models.WorkspaceInvitation, error)
+	ListInvitations(workspaceID, status string) ([]models.WorkspaceInvitation, error) // empty status lists all
+	UpdateInvitation(invitationID string, updates map[string]interface{}) error
+	// RespondToInvitation moves a pending invitation to status, failing with gorm.ErrRecordNotFound
+	// when it is no longer pending
+	RespondToInvitation(tx *gorm.DB, invitationID, status string, acceptedBy *string) error
+}
+
+type OwnershipTransferRepositoryInterface interface {
+	CreateTransfer(tx *gorm.DB, transfer *models.WorkspaceOwnershipTransfer) error
+	GetPendingTransfer(workspaceID string) (*models.WorkspaceOwnershipTransfer, error)
+	CloseTransfer(tx *gorm.DB, transferID, status string) error // only closes pending transfers
+}
+
+type GroupRepositoryInterface interface {
+	CreateGroup(tx *gorm.DB, group *models.WorkspaceGroup) error          // with its role grants
+	GetGroup(workspaceID, groupID string) (*models.WorkspaceGroup, error) // with its roles
+	GetGroupByName(workspaceID, name string) (*models.WorkspaceGroup, error)
+	ListGroups(workspaceID string) ([]models.WorkspaceGroup, error) // with their roles
+	UpdateGroup(groupID string, updates map[string]interface{}) error
+	DeleteGroup(groupID string) error // with its members and role grants
+
+	ListGroupMembers(groupID string) ([]models.WorkspaceGroupMember, error) // with user and profile
+	GetGroupMember(groupID, userID string) (*models.WorkspaceGroupMember, error)
+	AddGroupMember(member *models.WorkspaceGroupMember) error
+	RemoveGroupMember(groupID, userID string) error
+	AddGroupRole(groupID, roleID string) error
+	RemoveGroupRole(groupID, roleID string) error
+}
+
+type DomainRepositoryInterface interface {
+	CreateDomain(domain *models.WorkspaceDomain) error // ErrDuplicateDomain when the workspace already has it
+	GetDomain(workspaceID, domainID string) (*models.WorkspaceDomain, error)
+	ListDomains(workspaceID string) ([]models.WorkspaceDomain, error)
+	GetVerifiedDomain(workspaceID, domain string) (*models.WorkspaceDomain, error)
+	GetWorkspacesWithVerifiedDomain(domain string) ([]models.Workspace, error) // active workspaces only
+	UpdateDomain(domainID string, updates map[string]interface{}) error
+	DeleteDomain(domainID string) error
+}
+
 type TransactionRepositoryInterface interface {
 	BeginTransaction() *gorm.DB
 	CommitTransaction(tx *gorm.DB) error
@@ -0,0 +1,120 @@
+package repo
+
+import (
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type InvitationRepository struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository() InvitationRepositoryInterface {
+	return &InvitationRepository{
+		db: global.DB,
+	}
+}
+
+func (r *InvitationRepository) CreateInvitation(invitation *models.WorkspaceInvitation) error {
+	if err := r.db.Create(invitation).Error; err != nil {
+		return fmt.Errorf("failed to create invitation: %w", err)
+	}
+	return nil
+}
+
+func (r *InvitationRepository) GetInvitation(workspaceID, invitationID string) (*models.WorkspaceInvitation, error) {
+	var invitation models.WorkspaceInvitation
+
+	err := r.db.Preload("Role").Where("id = ? AND workspace_id = ?", invitationID, workspaceID).First(&invitation).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	return &invitation, nil
+}
+
+func (r *InvitationRepository) GetInvitationWithDetails(invitationID string) (*models.WorkspaceInvitation, error) {
+	var invitation models.WorkspaceInvitation
+
+	err := r.db.Preload("Workspace").Preload("Role").Preload("Inviter.Profile").
+		Where("id = ?", invitationID).
+		First(&invitation).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	return &invitation, nil
+}
+
+func (r *InvitationRepository) GetPendingInvitation(workspaceID, email string) (*models.WorkspaceInvitation, error) {
+	var invitation models.WorkspaceInvitation
+
+	err := r.db.Where("workspace_id = ? AND email = ? AND status = ?", workspaceID, email, models.InvitationStatusPending).
+		Order("created_at DESC").
+		First(&invitation).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get pending invitation: %w", err)
+	}
+
+	return &invitation, nil
+}
+
+func (r *InvitationRepository) ListInvitations(workspaceID, status string) ([]models.WorkspaceInvitation, error) {
+	var invitations []models.WorkspaceInvitation
+
+	query := r.db.Preload("Role").Where("workspace_id = ?", workspaceID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Order("created_at DESC").Find(&invitations).Error; err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+
+	return invitations, nil
+}
+
+func (r *InvitationRepository) UpdateInvitation(invitationID string, updates map[string]interface{}) error {
+	result := r.db.Model(&models.WorkspaceInvitation{}).Where("id = ?", invitationID).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update invitation: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *InvitationRepository) RespondToInvitation(tx *gorm.DB, invitationID, status string, acceptedBy *string) error {
+	result := tx.Model(&models.WorkspaceInvitation{}).
+		Where("id = ? AND status = ?", invitationID, models.InvitationStatusPending).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"responded_at": time.Now(),
+			"accepted_by":  acceptedBy,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to respond to invitation: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
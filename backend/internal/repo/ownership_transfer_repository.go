@@ -0,0 +1,63 @@
+package repo
+
+import (
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type OwnershipTransferRepository struct {
+	db *gorm.DB
+}
+
+func NewOwnershipTransferRepository() OwnershipTransferRepositoryInterface {
+	return &OwnershipTransferRepository{
+		db: global.DB,
+	}
+}
+
+func (r *OwnershipTransferRepository) CreateTransfer(tx *gorm.DB, transfer *models.WorkspaceOwnershipTransfer) error {
+	if err := tx.Create(transfer).Error; err != nil {
+		return fmt.Errorf("failed to create ownership transfer: %w", err)
+	}
+	return nil
+}
+
+// GetPendingTransfer retrieves the pending transfer of a workspace, expired or not
+func (r *OwnershipTransferRepository) GetPendingTransfer(workspaceID string) (*models.WorkspaceOwnershipTransfer, error) {
+	var transfer models.WorkspaceOwnershipTransfer
+
+	err := r.db.Where("workspace_id = ? AND status = ?", workspaceID, models.OwnershipTransferStatusPending).
+		Order("created_at DESC").
+		First(&transfer).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get ownership transfer: %w", err)
+	}
+
+	return &transfer, nil
+}
+
+// CloseTransfer moves a pending transfer to its final status
+func (r *OwnershipTransferRepository) CloseTransfer(tx *gorm.DB, transferID, status string) error {
+	result := tx.Model(&models.WorkspaceOwnershipTransfer{}).
+		Where("id = ? AND status = ?", transferID, models.OwnershipTransferStatusPending).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"responded_at": time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to close ownership transfer: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
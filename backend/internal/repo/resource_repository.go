@@ -0,0 +1,144 @@
+package repo
+
+import (
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+type ResourceRepository struct {
+	db *gorm.DB
+}
+
+func NewResourceRepository() ResourceRepositoryInterface {
+	return &ResourceRepository{
+		db: global.DB,
+	}
+}
+
+// CreateResource adds a resource to the catalog
+func (r *ResourceRepository) CreateResource(resource *models.Resource) error {
+	if err := r.db.Create(resource).Error; err != nil {
+		return fmt.Errorf("failed to create resource: %w", err)
+	}
+	return nil
+}
+
+// GetResourceByID retrieves a resource by its ID
+func (r *ResourceRepository) GetResourceByID(resourceID string) (*models.Resource, error) {
+	var resource models.Resource
+
+	err := r.db.Where("id = ?", resourceID).First(&resource).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get resource: %w", err)
+	}
+
+	return &resource, nil
+}
+
+// GetResourceByName retrieves a resource by the name used in permission strings
+func (r *ResourceRepository) GetResourceByName(name string) (*models.Resource, error) {
+	var resource models.Resource
+
+	err := r.db.Where("name = ?", name).First(&resource).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get resource by name: %w", err)
+	}
+
+	return &resource, nil
+}
+
+// GetResources retrieves the whole catalog in any status, ordered by name
+func (r *ResourceRepository) GetResources() ([]models.Resource, error) {
+	var resources []models.Resource
+
+	if err := r.db.Order("name ASC").Find(&resources).Error; err != nil {
+		return nil, fmt.Errorf("failed to get resources: %w", err)
+	}
+
+	return resources, nil
+}
+
+// GetResourcesByCategory retrieves the resources of a category in any status, ordered by name
+func (r *ResourceRepository) GetResourcesByCategory(category string) ([]models.Resource, error) {
+	var resources []models.Resource
+
+	if err := r.db.Where("category = ?", category).Order("name ASC").Find(&resources).Error; err != nil {
+		return nil, fmt.Errorf("failed to get resources by category: %w", err)
+	}
+
+	return resources, nil
+}
+
+// UpdateResource updates a resource with the given updates
+func (r *ResourceRepository) UpdateResource(resourceID string, updates map[string]interface{}) error {
+	result := r.db.Model(&models.Resource{}).Where("id = ?", resourceID).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update resource: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// DeleteResource soft deletes a resource by updating its status
+func (r *ResourceRepository) DeleteResource(resourceID string) error {
+	result := r.db.Model(&models.Resource{}).Where("id = ?", resourceID).Update("status", models.ResourceStatusInactive)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete resource: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// CountReferencingRoles counts the active roles with a resource:action permission on the resource,
+// allowed or denied
+func (r *ResourceRepository) CountReferencingRoles(name string) (int64, error) {
+	var count int64
+	pattern := utils.EscapeLike(name) + ":%"
+	err := r.db.Model(&models.WorkspaceRole{}).
+		Where("status = ? AND (JSON_SEARCH(permissions, 'one', ?, NULL, '$.permissions') IS NOT NULL OR JSON_SEARCH(permissions, 'one', ?, NULL, '$.permissions') IS NOT NULL)",
+			common.ActiveStatus, pattern, utils.PermissionDenyPrefix+pattern).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count roles referencing resource: %w", err)
+	}
+	return count, nil
+}
+
+// ExistsByName checks if a resource with the given name exists
+func (r *ResourceRepository) ExistsByName(name string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Resource{}).Where("name = ?", name).Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check resource name existence: %w", err)
+	}
+	return count > 0, nil
+}
+
+// ExistsByID checks if a resource with the given ID exists
+func (r *ResourceRepository) ExistsByID(resourceID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Resource{}).Where("id = ?", resourceID).Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check resource existence: %w", err)
+	}
+	return count > 0, nil
+}
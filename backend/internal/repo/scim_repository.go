@@ -0,0 +1,178 @@
+package repo
+
+import (
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// scimVisibleMembershipStatuses are the memberships exposed as SCIM users; inactive and
+// rejected memberships are treated as deleted. Pending memberships are join requests from users
+// outside of the workspace, which its IdP does not manage.
+var scimVisibleMembershipStatuses = []string{
+	models.MembershipStatusActive,
+	models.MembershipStatusSuspended,
+}
+
+type SCIMRepository struct {
+	db *gorm.DB
+}
+
+func NewSCIMRepository() SCIMRepositoryInterface {
+	return &SCIMRepository{
+		db: global.DB,
+	}
+}
+
+func (r *SCIMRepository) CreateToken(token *models.WorkspaceSCIMToken) error {
+	if err := r.db.Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create SCIM token: %w", err)
+	}
+	return nil
+}
+
+// GetTokenByHash retrieves a token by the SHA-256 of its secret
+func (r *SCIMRepository) GetTokenByHash(tokenHash string) (*models.WorkspaceSCIMToken, error) {
+	var token models.WorkspaceSCIMToken
+
+	err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get SCIM token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// GetToken retrieves a token of a workspace by its ID
+func (r *SCIMRepository) GetToken(workspaceID, tokenID string) (*models.WorkspaceSCIMToken, error) {
+	var token models.WorkspaceSCIMToken
+
+	err := r.db.Where("id = ? AND workspace_id = ?", tokenID, workspaceID).First(&token).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get SCIM token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// GetWorkspaceTokens retrieves all tokens of a workspace, newest first
+func (r *SCIMRepository) GetWorkspaceTokens(workspaceID string) ([]models.WorkspaceSCIMToken, error) {
+	var tokens []models.WorkspaceSCIMToken
+
+	err := r.db.Where("workspace_id = ?", workspaceID).Order("created_at DESC").Find(&tokens).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SCIM tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// UpdateToken updates a token with the given updates
+func (r *SCIMRepository) UpdateToken(tokenID string, updates map[string]interface{}) error {
+	result := r.db.Model(&models.WorkspaceSCIMToken{}).Where("id = ?", tokenID).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update SCIM token: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ListMembers retrieves a page of workspace members matching a SQL condition over the
+// users, user_profiles and user_workspace_memberships tables
+func (r *SCIMRepository) ListMembers(workspaceID, condition string, args []interface{}, offset, limit int) ([]models.UserWorkspaceMembership, int64, error) {
+	query := r.db.Model(&models.UserWorkspaceMembership{}).
+		Joins("JOIN users ON users.id = user_workspace_memberships.user_id").
+		Joins("LEFT JOIN user_profiles ON user_profiles.user_id = users.id").
+		Where("user_workspace_memberships.workspace_id = ? AND user_workspace_memberships.status IN ?", workspaceID, scimVisibleMembershipStatuses)
+	if condition != "" {
+		query = query.Where(condition, args...)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count members: %w", err)
+	}
+
+	var memberships []models.UserWorkspaceMembership
+	err := query.Preload("User.Profile").Preload("Role").
+		Order("user_workspace_memberships.created_at ASC, user_workspace_memberships.id ASC").
+		Offset(offset).Limit(limit).
+		Find(&memberships).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list members: %w", err)
+	}
+
+	return memberships, total, nil
+}
+
+// GetMember retrieves the membership of a user together with the user, profile and role
+func (r *SCIMRepository) GetMember(workspaceID, userID string) (*models.UserWorkspaceMembership, error) {
+	var membership models.UserWorkspaceMembership
+
+	err := r.db.Preload("User.Profile").Preload("Role").
+		Where("workspace_id = ? AND user_id = ? AND status IN ?", workspaceID, userID, scimVisibleMembershipStatuses).
+		First(&membership).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+
+	return &membership, nil
+}
+
+// ListRoles retrieves a page of active workspace roles matching a SQL condition over workspace_roles
+func (r *SCIMRepository) ListRoles(workspaceID, condition string, args []interface{}, offset, limit int) ([]models.WorkspaceRole, int64, error) {
+	query := r.db.Model(&models.WorkspaceRole{}).
+		Where("workspace_roles.workspace_id = ? AND workspace_roles.status = ?", workspaceID, common.ActiveStatus)
+	if condition != "" {
+		query = query.Where(condition, args...)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count roles: %w", err)
+	}
+
+	var roles []models.WorkspaceRole
+	err := query.Preload("Memberships", "status IN ?", scimVisibleMembershipStatuses).Preload("Memberships.User.Profile").
+		Order("workspace_roles.created_at ASC, workspace_roles.id ASC").
+		Offset(offset).Limit(limit).
+		Find(&roles).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	return roles, total, nil
+}
+
+// GetRole retrieves an active workspace role together with its members
+func (r *SCIMRepository) GetRole(workspaceID, roleID string) (*models.WorkspaceRole, error) {
+	var role models.WorkspaceRole
+
+	err := r.db.Preload("Memberships", "status IN ?", scimVisibleMembershipStatuses).Preload("Memberships.User.Profile").
+		Where("id = ? AND workspace_id = ? AND status = ?", roleID, workspaceID, common.ActiveStatus).
+		First(&role).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	return &role, nil
+}
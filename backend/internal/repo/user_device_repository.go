@@ -0,0 +1,92 @@
+package repo
+
+import (
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type UserDeviceRepository struct {
+	db *gorm.DB
+}
+
+func NewUserDeviceRepository() UserDeviceRepositoryInterface {
+	return &UserDeviceRepository{
+		db: global.DB,
+	}
+}
+
+// CreateDevice stores a newly seen device
+func (r *UserDeviceRepository) CreateDevice(device *models.UserDevice) error {
+	if err := r.db.Create(device).Error; err != nil {
+		return fmt.Errorf("failed to create user device: %w", err)
+	}
+	return nil
+}
+
+// GetDeviceByFingerprint retrieves a known device of a user by its fingerprint
+func (r *UserDeviceRepository) GetDeviceByFingerprint(userID, fingerprint string) (*models.UserDevice, error) {
+	var device models.UserDevice
+
+	err := r.db.Where("user_id = ? AND fingerprint = ?", userID, fingerprint).First(&device).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user device: %w", err)
+	}
+
+	return &device, nil
+}
+
+// GetUserDevices retrieves all known devices of a user, most recently used first
+func (r *UserDeviceRepository) GetUserDevices(userID string) ([]models.UserDevice, error) {
+	var devices []models.UserDevice
+
+	err := r.db.Where("user_id = ?", userID).Order("last_seen_at DESC").Find(&devices).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user devices: %w", err)
+	}
+
+	return devices, nil
+}
+
+// CountUserDevices counts the known devices of a user
+func (r *UserDeviceRepository) CountUserDevices(userID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.UserDevice{}).Where("user_id = ?", userID).Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count user devices: %w", err)
+	}
+	return count, nil
+}
+
+// UpdateDevice updates a device with the given updates
+func (r *UserDeviceRepository) UpdateDevice(deviceID string, updates map[string]interface{}) error {
+	result := r.db.Model(&models.UserDevice{}).Where("id = ?", deviceID).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update user device: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// DeleteDevice removes a device so that the next login from it is treated as new
+func (r *UserDeviceRepository) DeleteDevice(userID, deviceID string) error {
+	result := r.db.Where("id = ? AND user_id = ?", deviceID, userID).Delete(&models.UserDevice{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete user device: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
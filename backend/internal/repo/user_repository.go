@@ -5,8 +5,10 @@ import (
 	"go-backend-v2/global"
 	"go-backend-v2/internal/common"
 	"go-backend-v2/internal/models"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository struct {
@@ -52,6 +54,44 @@ func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
+// ChangeEmail moves the primary email, the local provider login and the email of every linked
+// provider still known by oldEmail to newEmail.
+// It fails with gorm.ErrRecordNotFound when the user no longer has oldEmail.
+func (r *UserRepository) ChangeEmail(tx *gorm.DB, userID, oldEmail, newEmail string) error {
+	result := tx.Model(&models.User{}).
+		Where("id = ? AND email = ?", userID, oldEmail).
+		Updates(map[string]interface{}{
+			"email":             newEmail,
+			"email_verified_at": gorm.Expr("CURRENT_TIMESTAMP"),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update user email: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	err := tx.Model(&models.UserAuthProvider{}).
+		Where("user_id = ? AND provider = ?", userID, common.AuthProviderLocal).
+		Updates(map[string]interface{}{
+			"provider_user_id": newEmail,
+			"provider_email":   newEmail,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update local auth provider: %w", err)
+	}
+
+	// Linked providers keep their own user IDs, only the address they know the user by changes
+	err = tx.Model(&models.UserAuthProvider{}).
+		Where("user_id = ? AND provider <> ? AND provider_email = ?", userID, common.AuthProviderLocal, oldEmail).
+		Update("provider_email", newEmail).Error
+	if err != nil {
+		return fmt.Errorf("failed to update linked auth providers: %w", err)
+	}
+
+	return nil
+}
+
 func (r *UserRepository) GetUserByID(userID string) (*models.User, error) {
 	var user models.User
 
@@ -66,6 +106,19 @@ func (r *UserRepository) GetUserByID(userID string) (*models.User, error) {
 	return &user, nil
 }
 
+// LockUser holds a row lock on the user until the transaction ends, which serializes
+// checks of the per user quotas
+func (r *UserRepository) LockUser(tx *gorm.DB, userID string) error {
+	var user models.User
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", userID).First(&user).Error
+	if err != nil {
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	return nil
+}
+
 func (r *UserRepository) GetUserWithProfile(userID string) (*models.User, error) {
 	var user models.User
 
@@ -88,6 +141,7 @@ func (r *UserRepository) GetUserWithWorkspaces(userID string) (*models.User, err
 		Preload("WorkspaceMemberships", "status = ?", common.ActiveStatus).
 		Preload("WorkspaceMemberships.Workspace", "status = ?", common.ActiveStatus).
 		Preload("WorkspaceMemberships.Role", "status = ?", common.ActiveStatus).
+		Preload("GroupMemberships.Group.RoleGrants").
 		Where("id = ?", userID).
 		First(&user).Error
 
@@ -101,6 +155,22 @@ func (r *UserRepository) GetUserWithWorkspaces(userID string) (*models.User, err
 	return &user, nil
 }
 
+// GetUserWorkspaceRoles retrieves every active role of the workspaces the user is an active member
+// of, which resolving inherited permissions needs beyond the roles the user holds
+func (r *UserRepository) GetUserWorkspaceRoles(userID string) ([]models.WorkspaceRole, error) {
+	var roles []models.WorkspaceRole
+
+	workspaceIDs := r.db.Model(&models.UserWorkspaceMembership{}).
+		Select("workspace_id").
+		Where("user_id = ? AND status = ?", userID, common.ActiveStatus)
+	err := r.db.Where("workspace_id IN (?) AND status = ?", workspaceIDs, common.ActiveStatus).Find(&roles).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user workspace roles: %w", err)
+	}
+
+	return roles, nil
+}
+
 func (r *UserRepository) UpdateUser(userID string, updates map[string]interface{}) error {
 	result := r.db.Model(&models.User{}).Where("id = ?", userID).Updates(updates)
 	if result.Error != nil {
@@ -114,12 +184,35 @@ func (r *UserRepository) UpdateUser(userID string, updates map[string]interface{
 	return nil
 }
 
-// DeleteUser soft deletes a user
-func (r *UserRepository) DeleteUser(userID string) error {
-	// result := r.db.Delete(&models.User{}, "id = ?", userID)
-	result := r.db.Model(&models.User{}).Where("id = ?", userID).Update("status", "deleted")
+// ScheduleUserDeletion moves an active account into its deletion grace period
+func (r *UserRepository) ScheduleUserDeletion(userID string, purgeAt time.Time) error {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND status = ?", userID, common.UserStatusActive).
+		Updates(map[string]interface{}{
+			"status":                common.UserStatusPendingDeletion,
+			"deletion_scheduled_at": purgeAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to schedule user deletion: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// RestoreUser cancels a scheduled deletion
+func (r *UserRepository) RestoreUser(userID string) error {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND status = ?", userID, common.UserStatusPendingDeletion).
+		Updates(map[string]interface{}{
+			"status":                common.UserStatusActive,
+			"deletion_scheduled_at": nil,
+		})
 	if result.Error != nil {
-		return fmt.Errorf("failed to delete user: %w", result.Error)
+		return fmt.Errorf("failed to restore user: %w", result.Error)
 	}
 
 	if result.RowsAffected == 0 {
@@ -129,6 +222,62 @@ func (r *UserRepository) DeleteUser(userID string) error {
 	return nil
 }
 
+// GetUsersDueForPurge returns accounts whose grace period is over, as well as accounts that were
+// deleted before there was a grace period
+func (r *UserRepository) GetUsersDueForPurge(now time.Time, limit int) ([]models.User, error) {
+	var users []models.User
+
+	err := r.db.
+		Where("purged_at IS NULL AND ((status = ? AND deletion_scheduled_at <= ?) OR status = ?)",
+			common.UserStatusPendingDeletion, now, common.UserStatusDeleted).
+		Order("deletion_scheduled_at").
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users due for purge: %w", err)
+	}
+
+	return users, nil
+}
+
+// PurgeUser removes the personal data of an account and frees its email. The row itself stays,
+// anonymized, so that references such as invited_by remain valid.
+func (r *UserRepository) PurgeUser(tx *gorm.DB, userID string) error {
+	now := time.Now()
+
+	result := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"email":                 fmt.Sprintf("deleted-%s@deleted.invalid", userID),
+		"phone":                 nil,
+		"email_verified_at":     nil,
+		"phone_verified_at":     nil,
+		"last_login_at":         nil,
+		"status":                common.UserStatusDeleted,
+		"deletion_scheduled_at": nil,
+		"purged_at":             now,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to anonymize user: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	related := []interface{}{
+		&models.UserProfile{},
+		&models.UserAuthProvider{},
+		&models.UserDevice{},
+		&models.UserWorkspaceMembership{},
+		&models.WorkspaceGroupMember{},
+	}
+	for _, model := range related {
+		if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+			return fmt.Errorf("failed to delete %T: %w", model, err)
+		}
+	}
+
+	return nil
+}
+
 func (r *UserRepository) GetUserAuthProvider(userID, provider string) (*models.UserAuthProvider, error) {
 	var authProvider models.UserAuthProvider
 
@@ -143,6 +292,21 @@ func (r *UserRepository) GetUserAuthProvider(userID, provider string) (*models.U
 	return &authProvider, nil
 }
 
+// GetAuthProviderByProviderUserID finds the identity an external provider knows the user by
+func (r *UserRepository) GetAuthProviderByProviderUserID(provider, providerUserID string) (*models.UserAuthProvider, error) {
+	var authProvider models.UserAuthProvider
+
+	err := r.db.Where("provider = ? AND provider_user_id = ? AND status = ?", provider, providerUserID, common.ActiveStatus).First(&authProvider).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get auth provider by provider user ID: %w", err)
+	}
+
+	return &authProvider, nil
+}
+
 func (r *UserRepository) GetUserAuthProviders(userID string) ([]models.UserAuthProvider, error) {
 	var authProviders []models.UserAuthProvider
 
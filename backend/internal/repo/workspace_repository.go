@@ -3,9 +3,13 @@ package repo
 import (
 	"fmt"
 	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
 	"go-backend-v2/internal/models"
+	"go-backend-v2/pkg/utils"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type WorkspaceRepository struct {
@@ -18,8 +22,12 @@ func NewWorkspaceRepository() WorkspaceRepositoryInterface {
 	}
 }
 
+// CreateWorkspace inserts a workspace, returning utils.ErrSlugTaken when its slug is in use
 func (r *WorkspaceRepository) CreateWorkspace(tx *gorm.DB, workspace *models.Workspace) error {
 	if err := tx.Create(workspace).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return utils.ErrSlugTaken
+		}
 		return fmt.Errorf("failed to create workspace: %w", err)
 	}
 	return nil
@@ -39,11 +47,16 @@ func (r *WorkspaceRepository) GetWorkspaceByID(workspaceID string) (*models.Work
 	return &workspace, nil
 }
 
-// GetWorkspaceBySlug retrieves a workspace by its slug
+// GetWorkspaceBySlug retrieves a workspace by its current slug or, after a rename, a former one
 func (r *WorkspaceRepository) GetWorkspaceBySlug(slug string) (*models.Workspace, error) {
 	var workspace models.Workspace
 
 	err := r.db.Where("slug = ?", slug).First(&workspace).Error
+	if err == gorm.ErrRecordNotFound {
+		err = r.db.Joins("JOIN workspace_slugs ON workspace_slugs.workspace_id = workspaces.id").
+			Where("workspace_slugs.slug = ?", slug).
+			First(&workspace).Error
+	}
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -54,6 +67,60 @@ func (r *WorkspaceRepository) GetWorkspaceBySlug(slug string) (*models.Workspace
 	return &workspace, nil
 }
 
+// ClaimSlug records a slug in the history of a workspace. It returns utils.ErrSlugTaken when
+// another workspace has or had the slug.
+func (r *WorkspaceRepository) ClaimSlug(tx *gorm.DB, workspaceID, slug string) error {
+	var claimed models.WorkspaceSlug
+
+	err := tx.Where("slug = ?", slug).First(&claimed).Error
+	if err == nil {
+		if claimed.WorkspaceID != workspaceID {
+			return utils.ErrSlugTaken
+		}
+		return nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return fmt.Errorf("failed to get workspace slug: %w", err)
+	}
+
+	if err := tx.Create(&models.WorkspaceSlug{Slug: slug, WorkspaceID: workspaceID}).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			return utils.ErrSlugTaken
+		}
+		return fmt.Errorf("failed to claim workspace slug: %w", err)
+	}
+	return nil
+}
+
+// RenameSlug gives a workspace a new slug and keeps the current one in its history. It returns
+// utils.ErrSlugTaken when the new slug belongs to another workspace, and gorm.ErrRecordNotFound
+// when the workspace no longer has the expected current slug.
+func (r *WorkspaceRepository) RenameSlug(tx *gorm.DB, workspaceID, currentSlug, newSlug string) error {
+	// Workspaces created before the history existed have no entry for their current slug yet
+	if err := r.ClaimSlug(tx, workspaceID, currentSlug); err != nil {
+		return err
+	}
+	if err := r.ClaimSlug(tx, workspaceID, newSlug); err != nil {
+		return err
+	}
+
+	result := tx.Model(&models.Workspace{}).
+		Where("id = ? AND slug = ?", workspaceID, currentSlug).
+		Update("slug", newSlug)
+	if result.Error != nil {
+		if isDuplicateKeyError(result.Error) {
+			return utils.ErrSlugTaken
+		}
+		return fmt.Errorf("failed to rename workspace slug: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
 // GetWorkspacesByOwnerID retrieves all workspaces owned by a user
 func (r *WorkspaceRepository) GetWorkspacesByOwnerID(ownerID string) ([]models.Workspace, error) {
 	var workspaces []models.Workspace
@@ -66,6 +133,32 @@ func (r *WorkspaceRepository) GetWorkspacesByOwnerID(ownerID string) ([]models.W
 	return workspaces, nil
 }
 
+// ListWorkspaces retrieves a page of workspaces, newest first, optionally filtered by status and by
+// a search term matched against name and slug
+func (r *WorkspaceRepository) ListWorkspaces(status, search string, offset, limit int) ([]models.Workspace, int64, error) {
+	query := r.db.Model(&models.Workspace{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if search != "" {
+		pattern := "%" + utils.EscapeLike(search) + "%"
+		query = query.Where("name LIKE ? OR slug LIKE ?", pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count workspaces: %w", err)
+	}
+
+	var workspaces []models.Workspace
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&workspaces).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+
+	return workspaces, total, nil
+}
+
 // UpdateWorkspace updates a workspace with the given updates
 func (r *WorkspaceRepository) UpdateWorkspace(workspaceID string, updates map[string]interface{}) error {
 	result := r.db.Model(&models.Workspace{}).Where("id = ?", workspaceID).Updates(updates)
@@ -80,6 +173,67 @@ func (r *WorkspaceRepository) UpdateWorkspace(workspaceID string, updates map[st
 	return nil
 }
 
+// LockWorkspace reads a workspace and holds a row lock on it until the transaction ends, which
+// serializes checks of its member limit
+func (r *WorkspaceRepository) LockWorkspace(tx *gorm.DB, workspaceID string) (*models.Workspace, error) {
+	var workspace models.Workspace
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", workspaceID).First(&workspace).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to lock workspace: %w", err)
+	}
+
+	return &workspace, nil
+}
+
+// GetWorkspacesWithSettingsBelow returns a page of workspaces, ordered by ID, whose stored settings
+// are older than the given schema version. Settings without a version count as version 0.
+func (r *WorkspaceRepository) GetWorkspacesWithSettingsBelow(version int, afterID string, limit int) ([]models.Workspace, error) {
+	var workspaces []models.Workspace
+
+	err := r.db.Where("settings IS NOT NULL AND COALESCE(CAST(JSON_EXTRACT(settings, '$.version') AS UNSIGNED), 0) < ?", version).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&workspaces).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspaces with outdated settings: %w", err)
+	}
+
+	return workspaces, nil
+}
+
+// CountOwnedWorkspaces counts the workspaces owned by a user that are not deleted
+func (r *WorkspaceRepository) CountOwnedWorkspaces(tx *gorm.DB, ownerID string) (int64, error) {
+	var count int64
+	err := tx.Model(&models.Workspace{}).
+		Where("owner_id = ? AND status <> ?", ownerID, models.WorkspaceStatusDeleted).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count owned workspaces: %w", err)
+	}
+	return count, nil
+}
+
+// TransferOwnership makes another user the owner, as long as the expected one still owns the workspace
+func (r *WorkspaceRepository) TransferOwnership(tx *gorm.DB, workspaceID, fromUserID, toUserID string) error {
+	result := tx.Model(&models.Workspace{}).
+		Where("id = ? AND owner_id = ?", workspaceID, fromUserID).
+		Update("owner_id", toUserID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to transfer workspace ownership: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
 // DeleteWorkspace soft deletes a workspace by updating its status
 func (r *WorkspaceRepository) DeleteWorkspace(workspaceID string) error {
 	result := r.db.Model(&models.Workspace{}).Where("id = ?", workspaceID).Update("status", "deleted")
@@ -129,6 +283,18 @@ func (r *WorkspaceRepository) GetUserMemberships(userID string) ([]models.UserWo
 	return memberships, nil
 }
 
+// GetUserMembershipsWithDetails retrieves all memberships of a user in any status, with their workspace and role
+func (r *WorkspaceRepository) GetUserMembershipsWithDetails(userID string) ([]models.UserWorkspaceMembership, error) {
+	var memberships []models.UserWorkspaceMembership
+
+	err := r.db.Preload("Workspace").Preload("Role").Where("user_id = ?", userID).Find(&memberships).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user memberships: %w", err)
+	}
+
+	return memberships, nil
+}
+
 // GetWorkspaceMemberships retrieves all memberships for a workspace
 func (r *WorkspaceRepository) GetWorkspaceMemberships(workspaceID string) ([]models.UserWorkspaceMembership, error) {
 	var memberships []models.UserWorkspaceMembership
@@ -141,6 +307,141 @@ func (r *WorkspaceRepository) GetWorkspaceMemberships(workspaceID string) ([]mod
 	return memberships, nil
 }
 
+// MemberFilter selects a page of workspace members. Empty fields match all; After continues from
+// the last member of the previous page.
+type MemberFilter struct {
+	Statuses []string
+	RoleID   string
+	Search   string // matches email, first, last and display name
+	After    *utils.Cursor
+	Limit    int
+}
+
+// ListWorkspaceMembers retrieves a page of memberships in the order they were created
+func (r *WorkspaceRepository) ListWorkspaceMembers(workspaceID string, filter *MemberFilter) ([]models.UserWorkspaceMembership, error) {
+	query := r.db.Model(&models.UserWorkspaceMembership{}).
+		Where("user_workspace_memberships.workspace_id = ?", workspaceID)
+	if len(filter.Statuses) > 0 {
+		query = query.Where("user_workspace_memberships.status IN ?", filter.Statuses)
+	}
+	if filter.RoleID != "" {
+		query = query.Where("user_workspace_memberships.role_id = ?", filter.RoleID)
+	}
+	if filter.Search != "" {
+		pattern := "%" + utils.EscapeLike(filter.Search) + "%"
+		query = query.
+			Joins("JOIN users ON users.id = user_workspace_memberships.user_id").
+			Joins("LEFT JOIN user_profiles ON user_profiles.user_id = users.id").
+			Where("users.email LIKE ? OR user_profiles.first_name LIKE ? OR user_profiles.last_name LIKE ? OR user_profiles.display_name LIKE ? OR CONCAT(user_profiles.first_name, ' ', user_profiles.last_name) LIKE ?",
+				pattern, pattern, pattern, pattern, pattern)
+	}
+	if filter.After != nil {
+		query = query.Where("(user_workspace_memberships.created_at > ?) OR (user_workspace_memberships.created_at = ? AND user_workspace_memberships.id > ?)",
+			filter.After.CreatedAt, filter.After.CreatedAt, filter.After.ID)
+	}
+
+	var memberships []models.UserWorkspaceMembership
+	err := query.Preload("User.Profile").Preload("Role").
+		Order("user_workspace_memberships.created_at ASC, user_workspace_memberships.id ASC").
+		Limit(filter.Limit).
+		Find(&memberships).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspace members: %w", err)
+	}
+
+	return memberships, nil
+}
+
+// GetWorkspaceMember retrieves the membership of a user in any status, with the user, profile and role
+func (r *WorkspaceRepository) GetWorkspaceMember(workspaceID, userID string) (*models.UserWorkspaceMembership, error) {
+	var membership models.UserWorkspaceMembership
+
+	err := r.db.Preload("User.Profile").Preload("Role").
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		First(&membership).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get workspace member: %w", err)
+	}
+
+	return &membership, nil
+}
+
+// CountSeatedMembers counts the memberships that take a seat of the plan: active and suspended.
+// Pending memberships only take one once they are activated.
+func (r *WorkspaceRepository) CountSeatedMembers(tx *gorm.DB, workspaceID string) (int64, error) {
+	var count int64
+	err := tx.Model(&models.UserWorkspaceMembership{}).
+		Where("workspace_id = ? AND status IN ?", workspaceID, []string{
+			models.MembershipStatusActive,
+			models.MembershipStatusSuspended,
+		}).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count workspace members: %w", err)
+	}
+	return count, nil
+}
+
+// RejoinMembership reactivates a membership that ended, with a new role and join date
+func (r *WorkspaceRepository) RejoinMembership(tx *gorm.DB, membershipID, roleID string, invitedBy *string) error {
+	result := tx.Model(&models.UserWorkspaceMembership{}).
+		Where("id = ? AND status <> ?", membershipID, models.MembershipStatusActive).
+		Updates(map[string]interface{}{
+			"status":     models.MembershipStatusActive,
+			"role_id":    roleID,
+			"invited_by": invitedBy,
+			"joined_at":  time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to rejoin membership: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ActivatePendingMembership makes a pending membership active with the given role
+func (r *WorkspaceRepository) ActivatePendingMembership(tx *gorm.DB, membershipID, roleID string) error {
+	result := tx.Model(&models.UserWorkspaceMembership{}).
+		Where("id = ? AND status = ?", membershipID, models.MembershipStatusPending).
+		Updates(map[string]interface{}{
+			"status":    models.MembershipStatusActive,
+			"role_id":   roleID,
+			"joined_at": time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to activate membership: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// RejectPendingMembership turns a pending membership down, keeping it so the request is not filed again
+func (r *WorkspaceRepository) RejectPendingMembership(membershipID string) error {
+	result := r.db.Model(&models.UserWorkspaceMembership{}).
+		Where("id = ? AND status = ?", membershipID, models.MembershipStatusPending).
+		Update("status", models.MembershipStatusRejected)
+	if result.Error != nil {
+		return fmt.Errorf("failed to reject membership: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
 // UpdateMembership updates a membership with the given updates
 func (r *WorkspaceRepository) UpdateMembership(membershipID string, updates map[string]interface{}) error {
 	result := r.db.Model(&models.UserWorkspaceMembership{}).Where("id = ?", membershipID).Updates(updates)
@@ -155,11 +456,11 @@ func (r *WorkspaceRepository) UpdateMembership(membershipID string, updates map[
 	return nil
 }
 
-// DeleteMembership soft deletes a membership by updating its status
-func (r *WorkspaceRepository) DeleteMembership(membershipID string) error {
-	result := r.db.Model(&models.UserWorkspaceMembership{}).Where("id = ?", membershipID).Update("status", "inactive")
+// SetMembershipRole changes the role of a membership within a transaction
+func (r *WorkspaceRepository) SetMembershipRole(tx *gorm.DB, membershipID, roleID string) error {
+	result := tx.Model(&models.UserWorkspaceMembership{}).Where("id = ?", membershipID).Update("role_id", roleID)
 	if result.Error != nil {
-		return fmt.Errorf("failed to delete membership: %w", result.Error)
+		return fmt.Errorf("failed to set membership role: %w", result.Error)
 	}
 
 	if result.RowsAffected == 0 {
@@ -169,6 +470,34 @@ func (r *WorkspaceRepository) DeleteMembership(membershipID string) error {
 	return nil
 }
 
+// DeleteMembership soft deletes a membership by updating its status
+func (r *WorkspaceRepository) DeleteMembership(membershipID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var membership models.UserWorkspaceMembership
+		if err := tx.Where("id = ?", membershipID).First(&membership).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return err
+			}
+			return fmt.Errorf("failed to get membership: %w", err)
+		}
+
+		result := tx.Model(&models.UserWorkspaceMembership{}).Where("id = ?", membershipID).Update("status", "inactive")
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete membership: %w", result.Error)
+		}
+
+		// Former members would otherwise get the roles of their groups back when they rejoin
+		err := tx.Where("user_id = ? AND group_id IN (?)", membership.UserID,
+			tx.Model(&models.WorkspaceGroup{}).Select("id").Where("workspace_id = ?", membership.WorkspaceID)).
+			Delete(&models.WorkspaceGroupMember{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to remove user from workspace groups: %w", err)
+		}
+
+		return nil
+	})
+}
+
 // CreateWorkspaceRole creates a new workspace role within a transaction
 func (r *WorkspaceRepository) CreateWorkspaceRole(tx *gorm.DB, role *models.WorkspaceRole) error {
 	if err := tx.Create(role).Error; err != nil {
@@ -232,14 +561,100 @@ func (r *WorkspaceRepository) DeleteWorkspaceRole(roleID string) error {
 	return nil
 }
 
-// ExistsBySlug checks if a workspace with the given slug exists
-func (r *WorkspaceRepository) ExistsBySlug(slug string) (bool, error) {
+// GetRoleMemberships retrieves the memberships that still hold a role, including suspended and pending ones
+func (r *WorkspaceRepository) GetRoleMemberships(roleID string) ([]models.UserWorkspaceMembership, error) {
+	var memberships []models.UserWorkspaceMembership
+
+	err := r.db.Where("role_id = ? AND status IN ?", roleID, []string{
+		models.MembershipStatusActive,
+		models.MembershipStatusSuspended,
+		models.MembershipStatusPending,
+	}).Find(&memberships).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role memberships: %w", err)
+	}
+
+	return memberships, nil
+}
+
+// GetUserGroupRoles retrieves the active roles a user holds through groups of a workspace
+func (r *WorkspaceRepository) GetUserGroupRoles(workspaceID, userID string) ([]models.WorkspaceRole, error) {
+	var roles []models.WorkspaceRole
+
+	err := r.db.Distinct("workspace_roles.*").
+		Joins("JOIN workspace_group_roles ON workspace_group_roles.role_id = workspace_roles.id").
+		Joins("JOIN workspace_group_members ON workspace_group_members.group_id = workspace_group_roles.group_id").
+		Where("workspace_roles.workspace_id = ? AND workspace_roles.status = ? AND workspace_group_members.user_id = ?",
+			workspaceID, common.ActiveStatus, userID).
+		Find(&roles).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group roles of user: %w", err)
+	}
+
+	return roles, nil
+}
+
+// CountRoleGroups counts the groups a role is assigned to
+func (r *WorkspaceRepository) CountRoleGroups(roleID string) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.Workspace{}).Where("slug = ?", slug).Count(&count).Error
+	if err := r.db.Model(&models.WorkspaceGroupRole{}).Where("role_id = ?", roleID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count role groups: %w", err)
+	}
+	return count, nil
+}
+
+// GetRoleGroupMemberIDs retrieves the users that hold a role through a group
+func (r *WorkspaceRepository) GetRoleGroupMemberIDs(roleID string) ([]string, error) {
+	var userIDs []string
+
+	err := r.db.Model(&models.WorkspaceGroupMember{}).
+		Distinct("workspace_group_members.user_id").
+		Joins("JOIN workspace_group_roles ON workspace_group_roles.group_id = workspace_group_members.group_id").
+		Where("workspace_group_roles.role_id = ?", roleID).
+		Pluck("workspace_group_members.user_id", &userIDs).Error
 	if err != nil {
-		return false, fmt.Errorf("failed to check workspace existence by slug: %w", err)
+		return nil, fmt.Errorf("failed to get group members of role: %w", err)
 	}
-	return count > 0, nil
+
+	return userIDs, nil
+}
+
+// GetWorkspaceRoleByName retrieves an active workspace role by its name
+func (r *WorkspaceRepository) GetWorkspaceRoleByName(workspaceID, name string) (*models.WorkspaceRole, error) {
+	var role models.WorkspaceRole
+
+	err := r.db.Where("workspace_id = ? AND name = ? AND status = ?", workspaceID, name, common.ActiveStatus).First(&role).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get workspace role by name: %w", err)
+	}
+
+	return &role, nil
+}
+
+// GetSAMLConfig retrieves the SAML configuration of a workspace
+func (r *WorkspaceRepository) GetSAMLConfig(workspaceID string) (*models.WorkspaceSAMLConfig, error) {
+	var config models.WorkspaceSAMLConfig
+
+	err := r.db.Where("workspace_id = ?", workspaceID).First(&config).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get SAML config: %w", err)
+	}
+
+	return &config, nil
+}
+
+// SaveSAMLConfig creates or replaces the SAML configuration of a workspace
+func (r *WorkspaceRepository) SaveSAMLConfig(config *models.WorkspaceSAMLConfig) error {
+	if err := r.db.Save(config).Error; err != nil {
+		return fmt.Errorf("failed to save SAML config: %w", err)
+	}
+	return nil
 }
 
 // ExistsByID checks if a workspace with the given ID exists
@@ -22,6 +22,12 @@ func NewRouteManager() *RouteManager {
 		routes.NewAuthRoutes(),
 		routes.NewUserRoutes(),
 		routes.NewAdminRoutes(),
+		routes.NewSSORoutes(),
+		routes.NewSCIMRoutes(),
+		routes.NewExportRoutes(),
+		routes.NewAvatarRoutes(),
+		routes.NewWorkspaceRoutes(),
+		routes.NewInvitationRoutes(),
 	}
 
 	return &RouteManager{
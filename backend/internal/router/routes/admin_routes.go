@@ -10,22 +10,35 @@ import (
 )
 
 type AdminRoutes struct {
-	adminController *controllers.AdminController
-	authService     services.AuthServiceInterface
+	adminController     *controllers.AdminController
+	workspaceController *controllers.WorkspaceController
+	resourceController  *controllers.ResourceController
+	authService         services.AuthServiceInterface
 }
 
 func NewAdminRoutes() *AdminRoutes {
 	workspaceRepo := repo.NewWorkspaceRepository()
+	domainRepo := repo.NewDomainRepository()
 	userRepo := repo.NewUserRepository()
+	deviceRepo := repo.NewUserDeviceRepository()
+	scimRepo := repo.NewSCIMRepository()
 
-	workspaceService := services.NewWorkspaceService(workspaceRepo, userRepo)
-	authService := services.NewAuthService(userRepo)
+	authCache := services.NewAuthCache(userRepo)
 
-	adminController := controllers.NewAdminController(workspaceService)
+	workspaceService := services.NewWorkspaceService(workspaceRepo, userRepo, authCache)
+	deviceService := services.NewDeviceService(deviceRepo)
+	authService := services.NewAuthService(userRepo, deviceService, authCache)
+
+	samlService := services.NewSAMLService(workspaceRepo, domainRepo, userRepo, authService, authCache)
+	scimService := services.NewSCIMService(scimRepo, workspaceRepo, userRepo, authCache)
+
+	adminController := controllers.NewAdminController(workspaceService, samlService, scimService)
 
 	return &AdminRoutes{
-		adminController: adminController,
-		authService:     authService,
+		adminController:     adminController,
+		workspaceController: controllers.NewWorkspaceController(workspaceService),
+		resourceController:  controllers.NewResourceController(services.NewResourceService(repo.NewResourceRepository())),
+		authService:         authService,
 	}
 }
 
@@ -38,5 +51,24 @@ func (r *AdminRoutes) SetupRoutes(router fiber.Router) {
 	adminGroup.Use(middlewares.RequireSuperAdmin(r.authService))
 
 	workspacesGroup := adminGroup.Group("/workspaces")
+	workspacesGroup.Get("/", r.adminController.ListWorkspaces)
 	workspacesGroup.Post("/", r.adminController.CreateWorkspace)
+	workspacesGroup.Get("/:id", r.adminController.GetWorkspace)
+	// Super admins hold every workspace permission, so the member facing handlers serve them too
+	workspacesGroup.Patch("/:id", r.workspaceController.UpdateWorkspace)
+	workspacesGroup.Post("/:id/archive", r.workspaceController.ArchiveWorkspace)
+	workspacesGroup.Post("/:id/unarchive", r.workspaceController.UnarchiveWorkspace)
+	workspacesGroup.Put("/:id/plan", r.adminController.UpdateWorkspacePlan)
+	workspacesGroup.Get("/:id/saml", r.adminController.GetSAMLConfig)
+	workspacesGroup.Put("/:id/saml", r.adminController.UpsertSAMLConfig)
+	workspacesGroup.Get("/:id/scim/tokens", r.adminController.ListSCIMTokens)
+	workspacesGroup.Post("/:id/scim/tokens", r.adminController.CreateSCIMToken)
+	workspacesGroup.Delete("/:id/scim/tokens/:tokenId", r.adminController.RevokeSCIMToken)
+
+	resourcesGroup := adminGroup.Group("/resources")
+	resourcesGroup.Get("/", r.resourceController.ListResources)
+	resourcesGroup.Post("/", r.resourceController.CreateResource)
+	resourcesGroup.Get("/:id", r.resourceController.GetResource)
+	resourcesGroup.Patch("/:id", r.resourceController.UpdateResource)
+	resourcesGroup.Delete("/:id", r.resourceController.DeleteResource)
 }
@@ -10,18 +10,37 @@ import (
 )
 
 type AuthRoutes struct {
-	controller  *controllers.AuthController
-	authService services.AuthServiceInterface
+	controller            *controllers.AuthController
+	ldapController        *controllers.LDAPController
+	emailChangeController *controllers.EmailChangeController
+	deletionController    *controllers.AccountDeletionController
+	authService           services.AuthServiceInterface
 }
 
 func NewAuthRoutes() *AuthRoutes {
 	userRepo := repo.NewUserRepository()
-	authService := services.NewAuthService(userRepo)
-	authController := controllers.NewAuthController(authService)
+	workspaceRepo := repo.NewWorkspaceRepository()
+	deviceRepo := repo.NewUserDeviceRepository()
+
+	authCache := services.NewAuthCache(userRepo)
+
+	deviceService := services.NewDeviceService(deviceRepo)
+	authService := services.NewAuthService(userRepo, deviceService, authCache)
+	invitationService := services.NewInvitationService(repo.NewInvitationRepository(), workspaceRepo, userRepo, authCache)
+	authController := controllers.NewAuthController(authService, invitationService)
+	ldapService := services.NewLDAPService(userRepo, workspaceRepo, authService, authCache)
+	ldapController := controllers.NewLDAPController(ldapService)
+	emailChangeService := services.NewEmailChangeService(userRepo, authService)
+	emailChangeController := controllers.NewEmailChangeController(emailChangeService)
+	accountDeletionService := services.NewAccountDeletionService(userRepo, workspaceRepo, authService, authCache)
+	deletionController := controllers.NewAccountDeletionController(accountDeletionService)
 
 	return &AuthRoutes{
-		controller:  authController,
-		authService: authService,
+		controller:            authController,
+		ldapController:        ldapController,
+		emailChangeController: emailChangeController,
+		deletionController:    deletionController,
+		authService:           authService,
 	}
 }
 
@@ -34,5 +53,10 @@ func (r *AuthRoutes) SetupRoutes(router fiber.Router) {
 
 	authGroup.Post("/signup", r.controller.Signup)
 	authGroup.Post("/login", r.controller.Login)
+	authGroup.Post("/ldap/login", r.ldapController.Login)
+	authGroup.Post("/email-change/confirm", r.emailChangeController.ConfirmChange)
+	authGroup.Post("/email-change/revert", r.emailChangeController.RevertChange)
+	authGroup.Post("/account/restore", r.deletionController.RestoreAccount)
+	authGroup.Get("/session", middlewares.AuthMiddleware(r.authService), r.controller.GetSession)
 	authGroup.Post("/logout", middlewares.AuthMiddleware(r.authService), r.controller.Logout)
 }
@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"go-backend-v2/global"
+	"go-backend-v2/internal/controllers"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/internal/services"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AvatarRoutes serves stored avatars publicly, like any other profile picture on the web
+type AvatarRoutes struct {
+	controller *controllers.AvatarController
+}
+
+func NewAvatarRoutes() *AvatarRoutes {
+	userRepo := repo.NewUserRepository()
+	avatarService := services.NewAvatarService(userRepo, repo.NewWorkspaceRepository(), services.NewAuthCache(userRepo), AvatarBaseURL())
+
+	return &AvatarRoutes{
+		controller: controllers.NewAvatarController(avatarService),
+	}
+}
+
+func (r *AvatarRoutes) GetPrefix() string {
+	return "/avatars"
+}
+
+func (r *AvatarRoutes) SetupRoutes(router fiber.Router) {
+	avatarGroup := router.Group(r.GetPrefix())
+
+	avatarGroup.Get("/*", r.controller.Serve)
+}
+
+// AvatarBaseURL is the external URL stored avatars are served below
+func AvatarBaseURL() string {
+	config := NewRouteConfig()
+	return strings.TrimSuffix(global.Config.Server.PublicURL, "/") + config.BaseURL + "/" + config.APIVersion + "/avatars"
+}
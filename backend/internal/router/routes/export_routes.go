@@ -0,0 +1,45 @@
+package routes
+
+import (
+	"go-backend-v2/global"
+	"go-backend-v2/internal/controllers"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/internal/services"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ExportRoutes serves downloads of data exports, which are authorized by the link token alone
+type ExportRoutes struct {
+	controller *controllers.DataExportController
+}
+
+func NewExportRoutes() *ExportRoutes {
+	dataExportService := services.NewDataExportService(
+		repo.NewUserRepository(),
+		repo.NewWorkspaceRepository(),
+		repo.NewUserDeviceRepository(),
+		DataExportDownloadURL(),
+	)
+
+	return &ExportRoutes{
+		controller: controllers.NewDataExportController(dataExportService),
+	}
+}
+
+func (r *ExportRoutes) GetPrefix() string {
+	return "/exports"
+}
+
+func (r *ExportRoutes) SetupRoutes(router fiber.Router) {
+	exportGroup := router.Group(r.GetPrefix())
+
+	exportGroup.Get("/download/:token", r.controller.Download)
+}
+
+// DataExportDownloadURL is the external URL of the download endpoint, without the token
+func DataExportDownloadURL() string {
+	config := NewRouteConfig()
+	return strings.TrimSuffix(global.Config.Server.PublicURL, "/") + config.BaseURL + "/" + config.APIVersion + "/exports/download"
+}
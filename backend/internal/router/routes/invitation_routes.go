@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"go-backend-v2/internal/controllers"
+	"go-backend-v2/internal/middlewares"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// InvitationRoutes are used by invitees through the link they received. Previewing and declining
+// only need the link, accepting needs an account with the invited email.
+type InvitationRoutes struct {
+	controller  *controllers.InvitationController
+	authService services.AuthServiceInterface
+}
+
+func NewInvitationRoutes() *InvitationRoutes {
+	userRepo := repo.NewUserRepository()
+	workspaceRepo := repo.NewWorkspaceRepository()
+	authCache := services.NewAuthCache(userRepo)
+
+	deviceService := services.NewDeviceService(repo.NewUserDeviceRepository())
+	authService := services.NewAuthService(userRepo, deviceService, authCache)
+	invitationService := services.NewInvitationService(repo.NewInvitationRepository(), workspaceRepo, userRepo, authCache)
+
+	return &InvitationRoutes{
+		controller:  controllers.NewInvitationController(invitationService),
+		authService: authService,
+	}
+}
+
+func (r *InvitationRoutes) GetPrefix() string {
+	return "/invitations"
+}
+
+func (r *InvitationRoutes) SetupRoutes(router fiber.Router) {
+	invitationGroup := router.Group(r.GetPrefix())
+
+	invitationGroup.Post("/preview", r.controller.PreviewInvitation)
+	invitationGroup.Post("/decline", r.controller.DeclineInvitation)
+	invitationGroup.Post("/accept", middlewares.AuthMiddleware(r.authService), r.controller.AcceptInvitation)
+}
@@ -0,0 +1,59 @@
+package routes
+
+import (
+	"go-backend-v2/internal/controllers"
+	"go-backend-v2/internal/middlewares"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SCIMRoutes struct {
+	controller  *controllers.SCIMController
+	scimService services.SCIMServiceInterface
+}
+
+func NewSCIMRoutes() *SCIMRoutes {
+	scimRepo := repo.NewSCIMRepository()
+	workspaceRepo := repo.NewWorkspaceRepository()
+	userRepo := repo.NewUserRepository()
+
+	authCache := services.NewAuthCache(userRepo)
+
+	scimService := services.NewSCIMService(scimRepo, workspaceRepo, userRepo, authCache)
+	scimController := controllers.NewSCIMController(scimService)
+
+	return &SCIMRoutes{
+		controller:  scimController,
+		scimService: scimService,
+	}
+}
+
+func (r *SCIMRoutes) GetPrefix() string {
+	return "/scim/v2"
+}
+
+func (r *SCIMRoutes) SetupRoutes(router fiber.Router) {
+	scimGroup := router.Group(r.GetPrefix())
+	scimGroup.Use(middlewares.SCIMErrorHandler())
+	scimGroup.Use(middlewares.SCIMAuth(r.scimService))
+
+	scimGroup.Get("/ServiceProviderConfig", r.controller.ServiceProviderConfig)
+
+	usersGroup := scimGroup.Group("/Users")
+	usersGroup.Get("/", r.controller.ListUsers)
+	usersGroup.Post("/", r.controller.CreateUser)
+	usersGroup.Get("/:id", r.controller.GetUser)
+	usersGroup.Put("/:id", r.controller.ReplaceUser)
+	usersGroup.Patch("/:id", r.controller.PatchUser)
+	usersGroup.Delete("/:id", r.controller.DeleteUser)
+
+	groupsGroup := scimGroup.Group("/Groups")
+	groupsGroup.Get("/", r.controller.ListGroups)
+	groupsGroup.Post("/", r.controller.CreateGroup)
+	groupsGroup.Get("/:id", r.controller.GetGroup)
+	groupsGroup.Put("/:id", r.controller.ReplaceGroup)
+	groupsGroup.Patch("/:id", r.controller.PatchGroup)
+	groupsGroup.Delete("/:id", r.controller.DeleteGroup)
+}
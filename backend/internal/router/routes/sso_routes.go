@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"go-backend-v2/internal/controllers"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SSORoutes struct {
+	controller *controllers.SSOController
+}
+
+func NewSSORoutes() *SSORoutes {
+	workspaceRepo := repo.NewWorkspaceRepository()
+	domainRepo := repo.NewDomainRepository()
+	userRepo := repo.NewUserRepository()
+	deviceRepo := repo.NewUserDeviceRepository()
+
+	authCache := services.NewAuthCache(userRepo)
+
+	deviceService := services.NewDeviceService(deviceRepo)
+	authService := services.NewAuthService(userRepo, deviceService, authCache)
+	samlService := services.NewSAMLService(workspaceRepo, domainRepo, userRepo, authService, authCache)
+	ssoController := controllers.NewSSOController(samlService)
+
+	return &SSORoutes{
+		controller: ssoController,
+	}
+}
+
+func (r *SSORoutes) GetPrefix() string {
+	return "/sso"
+}
+
+func (r *SSORoutes) SetupRoutes(router fiber.Router) {
+	ssoGroup := router.Group(r.GetPrefix())
+
+	samlGroup := ssoGroup.Group("/saml/:workspace")
+	samlGroup.Get("/metadata", r.controller.SAMLMetadata)
+	samlGroup.Get("/login", r.controller.SAMLLogin)
+	samlGroup.Post("/acs", r.controller.SAMLACS)
+}
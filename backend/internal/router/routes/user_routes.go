@@ -10,20 +10,42 @@ import (
 )
 
 type UserRoutes struct {
-	controller  *controllers.UserController
-	authService services.AuthServiceInterface
+	controller            *controllers.UserController
+	emailChangeController *controllers.EmailChangeController
+	dataExportController  *controllers.DataExportController
+	deletionController    *controllers.AccountDeletionController
+	avatarController      *controllers.AvatarController
+	authService           services.AuthServiceInterface
 }
 
 func NewUserRoutes() *UserRoutes {
 	userRepo := repo.NewUserRepository()
+	workspaceRepo := repo.NewWorkspaceRepository()
+	deviceRepo := repo.NewUserDeviceRepository()
+
+	authCache := services.NewAuthCache(userRepo)
+
 	userService := services.NewUserService(userRepo)
-	userController := controllers.NewUserController(userService)
+	deviceService := services.NewDeviceService(deviceRepo)
+	userController := controllers.NewUserController(userService, deviceService)
 
-	authService := services.NewAuthService(userRepo)
+	authService := services.NewAuthService(userRepo, deviceService, authCache)
+	emailChangeService := services.NewEmailChangeService(userRepo, authService)
+	emailChangeController := controllers.NewEmailChangeController(emailChangeService)
+	dataExportService := services.NewDataExportService(userRepo, workspaceRepo, deviceRepo, DataExportDownloadURL())
+	dataExportController := controllers.NewDataExportController(dataExportService)
+	accountDeletionService := services.NewAccountDeletionService(userRepo, workspaceRepo, authService, authCache)
+	deletionController := controllers.NewAccountDeletionController(accountDeletionService)
+	avatarService := services.NewAvatarService(userRepo, workspaceRepo, authCache, AvatarBaseURL())
+	avatarController := controllers.NewAvatarController(avatarService)
 
 	return &UserRoutes{
-		controller:  userController,
-		authService: authService,
+		controller:            userController,
+		emailChangeController: emailChangeController,
+		dataExportController:  dataExportController,
+		deletionController:    deletionController,
+		avatarController:      avatarController,
+		authService:           authService,
 	}
 }
 
@@ -36,5 +58,14 @@ func (r *UserRoutes) SetupRoutes(router fiber.Router) {
 	userGroup.Use(middlewares.AuthMiddleware(r.authService))
 
 	userGroup.Get("/me", r.controller.GetCurrentUser)
-	userGroup.Delete("/me", r.controller.DeleteUser)
+	userGroup.Delete("/me", r.deletionController.DeleteAccount)
+	userGroup.Patch("/me/profile", r.controller.UpdateProfile)
+	userGroup.Put("/me/avatar", r.avatarController.UploadUserAvatar)
+	userGroup.Delete("/me/avatar", r.avatarController.DeleteUserAvatar)
+	userGroup.Post("/me/email", r.emailChangeController.RequestChange)
+	userGroup.Post("/me/export", r.dataExportController.RequestExport)
+	userGroup.Get("/me/exports/:exportId", r.dataExportController.GetExport)
+
+	userGroup.Get("/me/devices", r.controller.ListDevices)
+	userGroup.Delete("/me/devices/:deviceId", r.controller.ForgetDevice)
 }
@@ -0,0 +1,136 @@
+package routes
+
+import (
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/controllers"
+	"go-backend-v2/internal/middlewares"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/internal/services"
+	"net"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WorkspaceRoutes holds the member facing workspace endpoints, guarded by workspace permissions
+type WorkspaceRoutes struct {
+	controller           *controllers.WorkspaceController
+	memberController     *controllers.MemberController
+	roleController       *controllers.RoleController
+	groupController      *controllers.GroupController
+	invitationController *controllers.InvitationController
+	transferController   *controllers.OwnershipTransferController
+	domainController     *controllers.DomainController
+	joinController       *controllers.JoinController
+	avatarController     *controllers.AvatarController
+	authService          services.AuthServiceInterface
+	workspaceService     services.WorkspaceServiceInterface
+}
+
+func NewWorkspaceRoutes() *WorkspaceRoutes {
+	userRepo := repo.NewUserRepository()
+	workspaceRepo := repo.NewWorkspaceRepository()
+	authCache := services.NewAuthCache(userRepo)
+
+	deviceService := services.NewDeviceService(repo.NewUserDeviceRepository())
+	authService := services.NewAuthService(userRepo, deviceService, authCache)
+	workspaceService := services.NewWorkspaceService(workspaceRepo, userRepo, authCache)
+	memberService := services.NewMemberService(workspaceRepo, authCache)
+	roleService := services.NewRoleService(workspaceRepo, repo.NewResourceRepository(), authCache)
+	groupService := services.NewGroupService(repo.NewGroupRepository(), workspaceRepo, authCache)
+	invitationService := services.NewInvitationService(repo.NewInvitationRepository(), workspaceRepo, userRepo, authCache)
+	transferService := services.NewOwnershipTransferService(repo.NewOwnershipTransferRepository(), workspaceRepo, userRepo, authCache)
+	avatarService := services.NewAvatarService(userRepo, workspaceRepo, authCache, AvatarBaseURL())
+	domainRepo := repo.NewDomainRepository()
+	domainService := services.NewDomainService(domainRepo, workspaceRepo, authCache, net.DefaultResolver)
+	joinService := services.NewJoinService(workspaceRepo, domainRepo, userRepo, authCache)
+
+	return &WorkspaceRoutes{
+		controller:           controllers.NewWorkspaceController(workspaceService),
+		memberController:     controllers.NewMemberController(memberService),
+		roleController:       controllers.NewRoleController(roleService),
+		groupController:      controllers.NewGroupController(groupService),
+		invitationController: controllers.NewInvitationController(invitationService),
+		transferController:   controllers.NewOwnershipTransferController(transferService),
+		domainController:     controllers.NewDomainController(domainService),
+		joinController:       controllers.NewJoinController(joinService),
+		avatarController:     controllers.NewAvatarController(avatarService),
+		authService:          authService,
+		workspaceService:     workspaceService,
+	}
+}
+
+func (r *WorkspaceRoutes) GetPrefix() string {
+	return "/workspaces"
+}
+
+func (r *WorkspaceRoutes) SetupRoutes(router fiber.Router) {
+	workspaceGroup := router.Group(r.GetPrefix())
+	workspaceGroup.Use(middlewares.AuthMiddleware(r.authService))
+
+	workspaceGroup.Get("/", r.controller.ListMyWorkspaces)
+	workspaceGroup.Post("/", r.controller.CreateWorkspace) // subject to workspace.creation_mode and the owned workspace quota
+	workspaceGroup.Get("/settings/schema", r.controller.GetSettingsSchema)
+	workspaceGroup.Get("/joinable", r.joinController.ListJoinableWorkspaces) // verified domains matching the user's verified email
+	workspaceGroup.Get("/:slug", r.controller.GetWorkspace)
+	workspaceGroup.Patch("/:id", r.require(common.PermissionWorkspaceUpdate), r.controller.UpdateWorkspace)
+	workspaceGroup.Put("/:id/slug", r.require(common.PermissionWorkspaceUpdate), r.controller.RenameSlug)
+	workspaceGroup.Post("/:id/archive", r.require(common.PermissionWorkspaceArchive), r.controller.ArchiveWorkspace)
+	workspaceGroup.Post("/:id/unarchive", r.require(common.PermissionWorkspaceArchive), r.controller.UnarchiveWorkspace)
+	workspaceGroup.Get("/:id/settings", r.require(common.PermissionWorkspaceRead), r.controller.GetSettings)
+	workspaceGroup.Patch("/:id/settings", r.require(common.PermissionWorkspaceUpdate), r.controller.UpdateSettings)
+
+	workspaceGroup.Get("/:id/members", r.require(common.PermissionMemberRead), r.memberController.ListMembers)
+	workspaceGroup.Patch("/:id/members/:userId", r.require(common.PermissionMemberUpdate), r.memberController.UpdateMemberRole)
+	workspaceGroup.Post("/:id/members/:userId/suspend", r.require(common.PermissionMemberUpdate), r.memberController.SuspendMember)
+	workspaceGroup.Post("/:id/members/:userId/reactivate", r.require(common.PermissionMemberUpdate), r.memberController.ReactivateMember)
+	workspaceGroup.Delete("/:id/members/:userId", r.require(common.PermissionMemberRemove), r.memberController.RemoveMember)
+
+	workspaceGroup.Get("/:id/roles", r.require(common.PermissionRoleRead), r.roleController.ListRoles)
+	workspaceGroup.Post("/:id/roles", r.require(common.PermissionRoleCreate), r.roleController.CreateRole)
+	workspaceGroup.Get("/:id/roles/:roleId", r.require(common.PermissionRoleRead), r.roleController.GetRole)
+	workspaceGroup.Patch("/:id/roles/:roleId", r.require(common.PermissionRoleUpdate), r.roleController.UpdateRole)
+	workspaceGroup.Delete("/:id/roles/:roleId", r.require(common.PermissionRoleDelete), r.roleController.DeleteRole)
+
+	workspaceGroup.Get("/:id/groups", r.require(common.PermissionGroupRead), r.groupController.ListGroups)
+	workspaceGroup.Post("/:id/groups", r.require(common.PermissionGroupCreate), r.groupController.CreateGroup)
+	workspaceGroup.Get("/:id/groups/:groupId", r.require(common.PermissionGroupRead), r.groupController.GetGroup)
+	workspaceGroup.Patch("/:id/groups/:groupId", r.require(common.PermissionGroupUpdate), r.groupController.UpdateGroup)
+	workspaceGroup.Delete("/:id/groups/:groupId", r.require(common.PermissionGroupDelete), r.groupController.DeleteGroup)
+	workspaceGroup.Get("/:id/groups/:groupId/members", r.require(common.PermissionGroupRead), r.groupController.ListGroupMembers)
+	workspaceGroup.Put("/:id/groups/:groupId/members/:userId", r.require(common.PermissionGroupUpdate), r.groupController.AddGroupMember)
+	workspaceGroup.Delete("/:id/groups/:groupId/members/:userId", r.require(common.PermissionGroupUpdate), r.groupController.RemoveGroupMember)
+	workspaceGroup.Put("/:id/groups/:groupId/roles/:roleId", r.require(common.PermissionGroupUpdate), r.groupController.AssignGroupRole)
+	workspaceGroup.Delete("/:id/groups/:groupId/roles/:roleId", r.require(common.PermissionGroupUpdate), r.groupController.UnassignGroupRole)
+
+	workspaceGroup.Get("/:id/invitations", r.require(common.PermissionMemberInvite), r.invitationController.ListInvitations)
+	workspaceGroup.Post("/:id/invitations", r.require(common.PermissionMemberInvite), r.invitationController.CreateInvitation)
+	workspaceGroup.Post("/:id/invitations/:invitationId/resend", r.require(common.PermissionMemberInvite), r.invitationController.ResendInvitation)
+	workspaceGroup.Delete("/:id/invitations/:invitationId", r.require(common.PermissionMemberInvite), r.invitationController.RevokeInvitation)
+
+	workspaceGroup.Get("/:id/domains", r.require(common.PermissionWorkspaceRead), r.domainController.ListDomains)
+	workspaceGroup.Post("/:id/domains", r.require(common.PermissionWorkspaceUpdate), r.domainController.AddDomain)
+	workspaceGroup.Post("/:id/domains/:domainId/verify", r.require(common.PermissionWorkspaceUpdate), r.domainController.VerifyDomain)
+	workspaceGroup.Delete("/:id/domains/:domainId", r.require(common.PermissionWorkspaceUpdate), r.domainController.RemoveDomain)
+
+	// Joining is for users outside the workspace, who either join at once through a verified
+	// domain or file a join request. Pending requests are listed with GET /:id/members?status=pending.
+	workspaceGroup.Post("/:id/join", r.joinController.JoinWorkspace)
+	workspaceGroup.Post("/:id/join-requests/:userId/approve", r.require(common.PermissionMemberInvite), r.joinController.ApproveJoinRequest)
+	workspaceGroup.Post("/:id/join-requests/:userId/reject", r.require(common.PermissionMemberInvite), r.joinController.RejectJoinRequest)
+
+	// Ownership is reserved to the owner and super admins, or to the nominee, not to a permission
+	workspaceGroup.Get("/:id/ownership-transfer", r.transferController.GetTransfer)
+	workspaceGroup.Post("/:id/ownership-transfer", r.transferController.InitiateTransfer)
+	workspaceGroup.Delete("/:id/ownership-transfer", r.transferController.CancelTransfer)
+	workspaceGroup.Post("/:id/ownership-transfer/accept", r.transferController.AcceptTransfer)
+	workspaceGroup.Post("/:id/ownership-transfer/decline", r.transferController.DeclineTransfer)
+
+	workspaceGroup.Put("/:id/avatar", r.require(common.PermissionWorkspaceUpdate), r.avatarController.UploadWorkspaceAvatar)
+	workspaceGroup.Delete("/:id/avatar", r.require(common.PermissionWorkspaceUpdate), r.avatarController.DeleteWorkspaceAvatar)
+}
+
+// require rejects requests early from the cached permissions; services still check against the
+// database, which also covers what the cache cannot know, such as archived workspaces
+func (r *WorkspaceRoutes) require(permission string) fiber.Handler {
+	return middlewares.RequirePermission(r.authService, r.workspaceService, permission)
+}
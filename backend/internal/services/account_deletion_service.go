@@ -0,0 +1,322 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/pkg/utils"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	accountRestoreTokenPrefix = "ar_"
+
+	defaultDeletionGracePeriod = 30 * 24 * time.Hour
+
+	// accountPurgeBatchSize limits the accounts purged per run, the rest follow on the next run
+	accountPurgeBatchSize = 100
+)
+
+type AccountDeletionService struct {
+	userRepo      repo.UserRepositoryInterface
+	workspaceRepo repo.WorkspaceRepositoryInterface
+	authService   AuthServiceInterface
+	authCache     AuthCacheInterface
+}
+
+func NewAccountDeletionService(userRepo repo.UserRepositoryInterface, workspaceRepo repo.WorkspaceRepositoryInterface, authService AuthServiceInterface, authCache AuthCacheInterface) AccountDeletionServiceInterface {
+	return &AccountDeletionService{
+		userRepo:      userRepo,
+		workspaceRepo: workspaceRepo,
+		authService:   authService,
+		authCache:     authCache,
+	}
+}
+
+// ScheduleDeletion ends all sessions of the user and mails a link to restore the account until
+// the grace period is over
+func (s *AccountDeletionService) ScheduleDeletion(userID string) (*time.Time, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, common.ErrUserNotFound
+	}
+
+	gracePeriod := deletionGracePeriod()
+	purgeAt := time.Now().Add(gracePeriod)
+
+	if err := s.userRepo.ScheduleUserDeletion(userID, purgeAt); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.ErrUserInactive
+		}
+		return nil, err
+	}
+
+	s.authCache.InvalidateUsers(userID)
+	if err := s.authService.InvalidateUserTokens(userID); err != nil {
+		fmt.Printf("Warning: failed to invalidate sessions of user scheduled for deletion: %v\n", err)
+	}
+
+	restoreURL := ""
+	token, err := utils.GenerateSecureToken(accountRestoreTokenPrefix, 32)
+	if err == nil {
+		err = global.RedisClient.Set(context.Background(), accountRestoreKey(utils.HashToken(token)), userID, gracePeriod).Err()
+	}
+	if err != nil {
+		fmt.Printf("Warning: failed to store account restore token: %v\n", err)
+	} else {
+		restoreURL = frontendURL("/account/restore", token)
+	}
+
+	if global.EventTopicPublisher != nil {
+		payload := &dto.UserDeletionScheduledPayload{
+			UserID:  userID,
+			PurgeAt: purgeAt,
+		}
+		go func() {
+			if err := global.EventTopicPublisher.Publish(common.UserDeletionScheduledLog, payload); err != nil {
+				fmt.Printf("Error publishing user deletion scheduled event: %v\n", err)
+			}
+		}()
+	}
+
+	if global.UserNotifier != nil {
+		data := map[string]interface{}{
+			"purge_at":    purgeAt,
+			"restore_url": restoreURL,
+		}
+		go func() {
+			if err := global.UserNotifier.Send(user.Email, "Your account will be deleted", common.NotificationTemplateDeletionScheduled, data); err != nil {
+				fmt.Printf("Error sending deletion notification: %v\n", err)
+			}
+		}()
+	}
+
+	return &purgeAt, nil
+}
+
+// RestoreAccount cancels a scheduled deletion with the token from the deletion notice
+func (s *AccountDeletionService) RestoreAccount(token string) error {
+	ctx := context.Background()
+
+	userID, err := global.RedisClient.GetDel(ctx, accountRestoreKey(utils.HashToken(token))).Result()
+	if err == redis.Nil {
+		return common.ErrAccountRestoreTokenInvalid
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get account restore token: %w", err)
+	}
+
+	if err := s.userRepo.RestoreUser(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrAccountRestoreTokenInvalid
+		}
+		return err
+	}
+
+	s.authCache.InvalidateUsers(userID)
+
+	if global.EventTopicPublisher != nil {
+		payload := &dto.UserRestoredPayload{UserID: userID}
+		go func() {
+			if err := global.EventTopicPublisher.Publish(common.UserRestoredLog, payload); err != nil {
+				fmt.Printf("Error publishing user restored event: %v\n", err)
+			}
+		}()
+	}
+
+	return nil
+}
+
+// PurgeDueAccounts purges the accounts whose grace period is over
+func (s *AccountDeletionService) PurgeDueAccounts() {
+	users, err := s.userRepo.GetUsersDueForPurge(time.Now(), accountPurgeBatchSize)
+	if err != nil {
+		fmt.Printf("Warning: failed to list accounts due for purge: %v\n", err)
+		return
+	}
+
+	for i := range users {
+		if err := s.purge(&users[i]); err != nil {
+			fmt.Printf("Warning: failed to purge user %s: %v\n", users[i].ID, err)
+		}
+	}
+}
+
+// RunAccountPurger calls PurgeDueAccounts every interval until ctx is done
+func RunAccountPurger(ctx context.Context, service AccountDeletionServiceInterface, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			service.PurgeDueAccounts()
+		}
+	}
+}
+
+// purge releases the owned workspaces first and then removes the personal data, including the
+// avatar files. Both steps can be repeated, so an interrupted purge is completed by the next run.
+func (s *AccountDeletionService) purge(user *models.User) error {
+	transferred, closed, affected, err := s.releaseOwnedWorkspaces(user.ID)
+	if err != nil {
+		return err
+	}
+
+	withProfile, err := s.userRepo.GetUserWithProfile(user.ID)
+	if err != nil {
+		return err
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		return s.userRepo.PurgeUser(tx, user.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	if withProfile != nil && withProfile.Profile != nil {
+		deleteAvatarFiles(getStringValue(withProfile.Profile.AvatarKey))
+	}
+
+	s.authCache.InvalidateUsers(append(affected, user.ID)...)
+
+	if global.EventTopicPublisher != nil {
+		payload := &dto.UserPurgedPayload{
+			UserID:                user.ID,
+			TransferredWorkspaces: transferred,
+			ClosedWorkspaces:      closed,
+		}
+		go func() {
+			if err := global.EventTopicPublisher.Publish(common.UserPurgedLog, payload); err != nil {
+				fmt.Printf("Error publishing user purged event: %v\n", err)
+			}
+		}()
+	}
+
+	return nil
+}
+
+// releaseOwnedWorkspaces hands each workspace owned by the user to its longest standing active
+// admin within the owned workspace quota. Workspaces without one are closed. It returns the transferred and closed workspaces and
+// the users whose access changed.
+func (s *AccountDeletionService) releaseOwnedWorkspaces(userID string) (transferred, closed, affected []string, err error) {
+	workspaces, err := s.workspaceRepo.GetWorkspacesByOwnerID(userID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	for _, workspace := range workspaces {
+		if workspace.Status == common.DeletedStatus {
+			continue
+		}
+
+		memberships, err := s.workspaceRepo.GetWorkspaceMemberships(workspace.ID)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		successor, err := s.handOver(workspace.ID, userID, memberships)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		if successor != "" {
+			transferred = append(transferred, workspace.ID)
+			affected = append(affected, successor)
+			continue
+		}
+
+		if err := s.workspaceRepo.UpdateWorkspace(workspace.ID, map[string]interface{}{"status": common.DeletedStatus}); err != nil {
+			return nil, nil, nil, err
+		}
+		closed = append(closed, workspace.ID)
+		for _, membership := range memberships {
+			if membership.UserID != userID {
+				affected = append(affected, membership.UserID)
+			}
+		}
+	}
+
+	return transferred, closed, affected, nil
+}
+
+// handOver makes the longest standing active admin who may own another workspace the owner and
+// returns them, or the empty ID when no admin qualifies
+func (s *AccountDeletionService) handOver(workspaceID, ownerID string, memberships []models.UserWorkspaceMembership) (string, error) {
+	adminRole, err := s.workspaceRepo.GetWorkspaceRoleByName(workspaceID, common.WorkspaceRoleAdmin)
+	if err != nil {
+		return "", err
+	}
+	if adminRole == nil {
+		return "", nil
+	}
+
+	candidates := make([]models.UserWorkspaceMembership, 0, len(memberships))
+	for _, membership := range memberships {
+		if membership.UserID != ownerID && membership.RoleID == adminRole.ID && membership.Status == models.MembershipStatusActive {
+			candidates = append(candidates, membership)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return membershipSince(&candidates[i]).Before(membershipSince(&candidates[j]))
+	})
+
+	for _, candidate := range candidates {
+		user, err := s.userRepo.GetUserByID(candidate.UserID)
+		if err != nil {
+			return "", err
+		}
+		if user == nil || user.Status != common.UserStatusActive {
+			continue
+		}
+
+		err = global.DB.Transaction(func(tx *gorm.DB) error {
+			if err := checkOwnedWorkspaceQuota(tx, s.userRepo, s.workspaceRepo, user); err != nil {
+				return err
+			}
+			return s.workspaceRepo.TransferOwnership(tx, workspaceID, ownerID, user.ID)
+		})
+		if errors.Is(err, common.ErrWorkspaceQuotaExceeded) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return user.ID, nil
+	}
+
+	return "", nil
+}
+
+func membershipSince(membership *models.UserWorkspaceMembership) time.Time {
+	if membership.JoinedAt != nil {
+		return *membership.JoinedAt
+	}
+	return membership.CreatedAt
+}
+
+func accountRestoreKey(tokenHash string) string {
+	return fmt.Sprintf("auth:account_restore:%s", tokenHash)
+}
+
+func deletionGracePeriod() time.Duration {
+	if period := global.Config.Profile.DeletionGracePeriod; period > 0 {
+		return period
+	}
+	return defaultDeletionGracePeriod
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/pkg/utils"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// userStateTTL bounds how long a missed invalidation can serve stale data
+	userStateTTL = 10 * time.Minute
+	// userStateVersionTTL keeps the invalidation counter far longer than any read-through lasts
+	userStateVersionTTL = 24 * time.Hour
+
+	tokenDataUpdateAttempts = 3
+)
+
+// AuthCache keeps what the authenticated request path reads in Redis: the user state behind
+// auth:user:{user_id} and the RBAC data of every session behind auth:token:{user_id}:{token}.
+// Services call InvalidateUsers after changing a user, a membership or a role. Every invalidation
+// bumps auth:user:{user_id}:version, which read-through watches so that a state read from the
+// database before the change is never cached after it.
+type AuthCache struct {
+	userRepo repo.UserRepositoryInterface
+}
+
+func NewAuthCache(userRepo repo.UserRepositoryInterface) AuthCacheInterface {
+	return &AuthCache{
+		userRepo: userRepo,
+	}
+}
+
+// GetUserState reads through the cache, returning nil when the user does not exist
+func (c *AuthCache) GetUserState(userID string) (*dto.UserState, error) {
+	ctx := context.Background()
+	key := userStateKey(userID)
+
+	data, err := global.RedisClient.Get(ctx, key).Bytes()
+	if err == nil {
+		var state dto.UserState
+		if err := json.Unmarshal(data, &state); err == nil {
+			return &state, nil
+		}
+	} else if err != redis.Nil {
+		fmt.Printf("Warning: failed to read cached user state: %v\n", err)
+	}
+
+	var state *dto.UserState
+	var loadErr error
+	loaded := false
+	err = global.RedisClient.Watch(ctx, func(tx *redis.Tx) error {
+		state, loadErr = c.loadUserState(userID)
+		loaded = true
+		if loadErr != nil || state == nil {
+			return nil
+		}
+
+		data, err := json.Marshal(state)
+		if err != nil {
+			return fmt.Errorf("failed to marshal user state: %w", err)
+		}
+		// fails when an invalidation bumped the version since the watch began
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, data, userStateTTL)
+			return nil
+		})
+		return err
+	}, userStateVersionKey(userID))
+	if err != nil && err != redis.TxFailedErr {
+		fmt.Printf("Warning: failed to cache user state: %v\n", err)
+	}
+	if !loaded { // Redis failed before the read-through ran
+		return c.loadUserState(userID)
+	}
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	return state, nil
+}
+
+func (c *AuthCache) loadUserState(userID string) (*dto.UserState, error) {
+	user, err := c.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, nil
+	}
+
+	return &dto.UserState{
+		Status:     user.Status,
+		GlobalRole: user.GlobalRole,
+	}, nil
+}
+
+// InvalidateUsers drops the cached state of the given users and rebuilds the RBAC data of their
+// live sessions. Sessions of users that no longer exist or are not active are removed.
+func (c *AuthCache) InvalidateUsers(userIDs ...string) {
+	for _, userID := range userIDs {
+		if err := c.invalidateUser(userID); err != nil {
+			fmt.Printf("Warning: failed to invalidate auth cache of user %s: %v\n", userID, err)
+		}
+	}
+}
+
+func (c *AuthCache) invalidateUser(userID string) error {
+	ctx := context.Background()
+
+	versionKey := userStateVersionKey(userID)
+	_, err := global.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, versionKey)
+		pipe.Expire(ctx, versionKey, userStateVersionTTL)
+		pipe.Del(ctx, userStateKey(userID))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete user state: %w", err)
+	}
+
+	keys, err := global.RedisClient.Keys(ctx, tokenDataKey(userID, "*")).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get user token keys: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	user, err := c.userRepo.GetUserWithWorkspaces(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil || user.Status != common.UserStatusActive {
+		return global.RedisClient.Del(ctx, keys...).Err()
+	}
+
+	roles, err := c.userRepo.GetUserWorkspaceRoles(userID)
+	if err != nil {
+		return err
+	}
+
+	fresh := buildTokenData(user, roles)
+	for _, key := range keys {
+		err := updateTokenData(ctx, key, func(tokenData *dto.UserTokenData) time.Duration {
+			tokenData.GlobalRole = fresh.GlobalRole
+			tokenData.WorkspaceMemberships = fresh.WorkspaceMemberships
+			return redis.KeepTTL
+		})
+		if err != nil {
+			return fmt.Errorf("failed to refresh token data: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// updateTokenData rewrites the data of one session with optimistic locking, so concurrent RBAC
+// refreshes and activity updates do not overwrite each other. update returns the new key TTL.
+// Sessions that expired or logged out in the meantime are left deleted.
+func updateTokenData(ctx context.Context, key string, update func(tokenData *dto.UserTokenData) time.Duration) error {
+	for attempt := 0; attempt < tokenDataUpdateAttempts; attempt++ {
+		err := global.RedisClient.Watch(ctx, func(tx *redis.Tx) error {
+			data, err := tx.Get(ctx, key).Bytes()
+			if err == redis.Nil {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			var tokenData dto.UserTokenData
+			if err := json.Unmarshal(data, &tokenData); err != nil {
+				return fmt.Errorf("failed to unmarshal token data: %w", err)
+			}
+
+			ttl := update(&tokenData)
+
+			data, err = json.Marshal(&tokenData)
+			if err != nil {
+				return fmt.Errorf("failed to marshal token data: %w", err)
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.SetXX(ctx, key, data, ttl)
+				return nil
+			})
+			return err
+		}, key)
+		if err != redis.TxFailedErr {
+			return err
+		}
+	}
+
+	return redis.TxFailedErr
+}
+
+// buildTokenData creates RBAC data from a user loaded with its workspaces and from the active roles
+// of those workspaces. The permissions of a workspace are those of the membership role and of the
+// roles of the user's groups there, with the ones they inherit.
+func buildTokenData(user *models.User, roles []models.WorkspaceRole) *dto.UserTokenData {
+	graphs := make(map[string]map[string]utils.RoleDefinition)
+	for i := range roles {
+		graph, ok := graphs[roles[i].WorkspaceID]
+		if !ok {
+			graph = make(map[string]utils.RoleDefinition)
+			graphs[roles[i].WorkspaceID] = graph
+		}
+		graph[roles[i].ID] = roleDefinition(&roles[i])
+	}
+
+	groupRoleIDs := make(map[string][]string)
+	for _, groupMember := range user.GroupMemberships {
+		group := groupMember.Group
+		for _, grant := range group.RoleGrants {
+			groupRoleIDs[group.WorkspaceID] = append(groupRoleIDs[group.WorkspaceID], grant.RoleID)
+		}
+	}
+
+	tokenData := &dto.UserTokenData{
+		GlobalRole: user.GlobalRole,
+	}
+	for _, membership := range user.WorkspaceMemberships {
+		if membership.Status == "active" && membership.RoleID != "" {
+			roleIDs := append([]string{membership.RoleID}, groupRoleIDs[membership.WorkspaceID]...)
+			permissions, err := utils.ResolveRolePermissions(graphs[membership.WorkspaceID], roleIDs...)
+			if err != nil {
+				fmt.Printf("Warning: failed to resolve permissions of user %s in workspace %s: %v\n", user.ID, membership.WorkspaceID, err)
+				permissions = []string{}
+			}
+			workspaceMembership := dto.WorkspaceMembershipTokenData{
+				WorkspaceID: membership.WorkspaceID,
+				RoleName:    membership.Role.Name,
+				Permissions: permissions,
+				Status:      membership.Status,
+			}
+			tokenData.WorkspaceMemberships = append(tokenData.WorkspaceMemberships, workspaceMembership)
+		}
+	}
+	return tokenData
+}
+
+func userStateKey(userID string) string {
+	return fmt.Sprintf("auth:user:%s", userID)
+}
+
+func userStateVersionKey(userID string) string {
+	return fmt.Sprintf("auth:user:%s:version", userID)
+}
+
+func tokenDataKey(userID, encryptedToken string) string {
+	return fmt.Sprintf("auth:token:%s:%s", userID, encryptedToken)
+}
@@ -10,37 +10,52 @@ import (
 	"go-backend-v2/internal/models"
 	"go-backend-v2/internal/repo"
 	"go-backend-v2/pkg/utils"
+	"net/http"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
+const (
+	// sessionTouchInterval throttles how often request activity is written back to a session
+	sessionTouchInterval = time.Minute
+
+	// DPoP proofs are accepted for this long after they were issued
+	dpopProofMaxAge = 5 * time.Minute
+	dpopClockSkew   = 30 * time.Second
+)
+
 type AuthService struct {
-	userRepo repo.UserRepositoryInterface
+	userRepo      repo.UserRepositoryInterface
+	deviceService DeviceServiceInterface
+	authCache     AuthCacheInterface
 }
 
-func NewAuthService(userRepo repo.UserRepositoryInterface) AuthServiceInterface {
+func NewAuthService(userRepo repo.UserRepositoryInterface, deviceService DeviceServiceInterface, authCache AuthCacheInterface) AuthServiceInterface {
 	return &AuthService{
-		userRepo: userRepo,
+		userRepo:      userRepo,
+		deviceService: deviceService,
+		authCache:     authCache,
 	}
 }
 
-func (s *AuthService) Signup(req *dto.SignupRequest) error {
+func (s *AuthService) Signup(req *dto.SignupRequest) (*models.User, error) {
 	if err := utils.ValidatePassword(req.Password); err != nil {
-		return err
+		return nil, err
 	}
 
 	exists, err := s.userRepo.ExistsByEmail(req.Email)
 	if err != nil {
-		return fmt.Errorf("failed to check email existence: %w", err)
+		return nil, fmt.Errorf("failed to check email existence: %w", err)
 	}
 	if exists {
-		return common.ErrEmailAlreadyExists
+		return nil, common.ErrEmailAlreadyExists
 	}
 
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
-		return fmt.Errorf("failed to hash password: %w", err)
+		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
 	user := &models.User{
@@ -65,12 +80,22 @@ func (s *AuthService) Signup(req *dto.SignupRequest) error {
 		IsPrimary:      true,
 	}
 
-	return global.DB.Transaction(func(tx *gorm.DB) error {
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
 		return s.userRepo.CreateUserWithAuth(tx, user, profile, authProvider)
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
 }
 
 func (s *AuthService) Login(req *dto.LoginRequest) (*dto.LoginResponse, error) {
+	dpopJKT, err := s.VerifyDPoPProof(req.DPoPProof, http.MethodPost, req.RequestURL, "")
+	if err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.GetUserByEmail(req.Email)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
@@ -95,6 +120,22 @@ func (s *AuthService) Login(req *dto.LoginRequest) (*dto.LoginResponse, error) {
 		return nil, common.ErrInvalidCredentials
 	}
 
+	return s.IssueSession(user, &dto.SessionOptions{
+		Provider:   common.AuthProviderLocal,
+		UserAgent:  req.UserAgent,
+		IPAddress:  req.IPAddress,
+		RememberMe: req.RememberMe,
+		DPoPJKT:    dpopJKT,
+	})
+}
+
+// IssueSession signs in a user that has already been authenticated by one of the auth providers
+func (s *AuthService) IssueSession(user *models.User, opts *dto.SessionOptions) (*dto.LoginResponse, error) {
+	device, newDevice, err := s.deviceService.RecordLogin(user, opts.UserAgent, opts.IPAddress)
+	if err != nil {
+		fmt.Printf("Warning: failed to record login device: %v\n", err)
+	}
+
 	err = s.userRepo.UpdateUser(user.ID, map[string]interface{}{
 		"last_login_at": time.Now(),
 	})
@@ -102,7 +143,19 @@ func (s *AuthService) Login(req *dto.LoginRequest) (*dto.LoginResponse, error) {
 		fmt.Printf("Warning: failed to update last login time: %v\n", err)
 	}
 
-	token, err := utils.GenerateToken(user.ID)
+	now := time.Now()
+	idleTimeout, absoluteTimeout := sessionPolicy(opts.RememberMe)
+	session := &dto.SessionMetadata{
+		Provider:       opts.Provider,
+		RememberMe:     opts.RememberMe,
+		CreatedAt:      now,
+		LastActivityAt: now,
+		ExpiresAt:      now.Add(absoluteTimeout),
+		DPoPJKT:        opts.DPoPJKT,
+	}
+	session.IdleExpiresAt = utils.SessionIdleDeadline(now, idleTimeout, session.ExpiresAt)
+
+	token, err := utils.GenerateDPoPBoundToken(user.ID, session.ExpiresAt, opts.DPoPJKT)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
@@ -117,16 +170,28 @@ func (s *AuthService) Login(req *dto.LoginRequest) (*dto.LoginResponse, error) {
 		return nil, fmt.Errorf("failed to get user with workspaces: %w", err)
 	}
 
-	tokenData := s.BuildTokenData(userWithWorkspaces)
-
-	err = s.StoreTokenData(user.ID, encryptedToken, tokenData)
+	roles, err := s.userRepo.GetUserWorkspaceRoles(user.ID)
 	if err != nil {
-		fmt.Printf("Warning: failed to cache token data in Redis: %v\n", err)
+		return nil, fmt.Errorf("failed to get workspace roles: %w", err)
+	}
+
+	tokenData := s.BuildTokenData(userWithWorkspaces, roles)
+	tokenData.Session = session
+
+	// Requests are only accepted while the Redis session exists, so a login without one is useless
+	if err := s.StoreTokenData(user.ID, encryptedToken, tokenData); err != nil {
+		return nil, err
 	}
 
 	if global.EventTopicPublisher != nil {
 		payload := &dto.UserLoginPayload{
-			UserID: user.ID,
+			UserID:    user.ID,
+			Provider:  opts.Provider,
+			IPAddress: opts.IPAddress,
+			NewDevice: newDevice,
+		}
+		if device != nil {
+			payload.DeviceID = device.ID
 		}
 		go func() {
 			if err := global.EventTopicPublisher.Publish(common.UserLoginLog, payload); err != nil {
@@ -139,6 +204,7 @@ func (s *AuthService) Login(req *dto.LoginRequest) (*dto.LoginResponse, error) {
 		AccessToken:    token,
 		User:           userWithWorkspaces,
 		EncryptedToken: encryptedToken,
+		Session:        session,
 	}, nil
 }
 
@@ -148,20 +214,142 @@ func (s *AuthService) ValidateToken(token string) (string, error) {
 		return "", common.ErrTokenInvalid
 	}
 
-	user, err := s.userRepo.GetUserByID(userID)
+	state, err := s.authCache.GetUserState(userID)
 	if err != nil {
-		return "", fmt.Errorf("failed to get user: %w", err)
+		return "", err
 	}
-	if user == nil {
+	if state == nil {
 		return "", common.ErrUserNotFound
 	}
-	if user.Status != common.UserStatusActive {
+	if state.Status != common.UserStatusActive {
 		return "", common.ErrUserInactive
 	}
 
 	return userID, nil
 }
 
+// GetUserState returns the cached status and global role of a user, nil when it does not exist
+func (s *AuthService) GetUserState(userID string) (*dto.UserState, error) {
+	return s.authCache.GetUserState(userID)
+}
+
+// VerifyDPoPProof validates a DPoP proof for the given request and records its jti so the proof
+// cannot be replayed
+func (s *AuthService) VerifyDPoPProof(proof, method, requestURL, accessToken string) (string, error) {
+	if proof == "" {
+		return "", nil
+	}
+
+	verified, err := utils.ParseDPoPProof(proof, s.dpopProofRequest(method, requestURL, accessToken))
+	if err != nil {
+		return "", common.ErrDPoPProofInvalid
+	}
+
+	if err := s.recordDPoPProof(verified); err != nil {
+		return "", err
+	}
+
+	return verified.JKT, nil
+}
+
+// CheckDPoPBinding requires a proof signed by the bound key for access tokens bound to a DPoP key.
+// The token travels in the HttpOnly session cookie, which the client cannot read, so the proof is
+// not expected to carry its ath hash.
+func (s *AuthService) CheckDPoPBinding(accessToken, proof, method, requestURL string) error {
+	claims, err := utils.ParseToken(accessToken)
+	if err != nil {
+		return common.ErrTokenInvalid
+	}
+	if claims.Confirmation == nil || claims.Confirmation.JKT == "" {
+		return nil
+	}
+
+	if proof == "" {
+		return common.ErrDPoPProofRequired
+	}
+
+	verified, err := utils.VerifyDPoPBinding(claims, proof, s.dpopProofRequest(method, requestURL, ""))
+	if err != nil {
+		return common.ErrDPoPProofInvalid
+	}
+
+	return s.recordDPoPProof(verified)
+}
+
+func (s *AuthService) dpopProofRequest(method, requestURL, accessToken string) *utils.DPoPProofRequest {
+	return &utils.DPoPProofRequest{
+		Method:      method,
+		URL:         requestURL,
+		AccessToken: accessToken,
+		Now:         time.Now(),
+		MaxAge:      dpopProofMaxAge,
+		ClockSkew:   dpopClockSkew,
+	}
+}
+
+// recordDPoPProof remembers the jti of a verified proof, failing when it has already been used
+func (s *AuthService) recordDPoPProof(verified *utils.DPoPProof) error {
+	// The jti only has to be remembered while its proof would pass the iat window
+	key := fmt.Sprintf("dpop:jti:%s:%s", verified.JKT, utils.HashToken(verified.JTI))
+	fresh, err := global.RedisClient.SetNX(context.Background(), key, "1", dpopProofMaxAge+dpopClockSkew).Result()
+	if err != nil {
+		return fmt.Errorf("failed to record DPoP proof: %w", err)
+	}
+	if !fresh {
+		return common.ErrDPoPProofInvalid
+	}
+	return nil
+}
+
+// TouchSession checks that the access token belongs to a live session and records the activity,
+// which slides the idle deadline forward up to the absolute expiry
+func (s *AuthService) TouchSession(userID, accessToken, encryptedToken string) (*dto.SessionMetadata, error) {
+	decrypted, err := utils.DecryptToken(encryptedToken, global.Config.JWT.EncryptionKey)
+	if err != nil || decrypted != accessToken {
+		return nil, common.ErrTokenInvalid
+	}
+
+	tokenData, err := s.GetTokenData(userID, encryptedToken)
+	if err != nil {
+		return nil, common.ErrSessionExpired
+	}
+
+	session := tokenData.Session
+	if session == nil {
+		// Issued before idle timeouts existed, the key TTL still bounds it
+		return nil, nil
+	}
+
+	now := time.Now()
+	if !now.Before(session.ExpiresAt) || !now.Before(session.IdleExpiresAt) {
+		if err := s.DeleteTokenData(userID, encryptedToken); err != nil {
+			fmt.Printf("Warning: failed to delete expired session: %v\n", err)
+		}
+		return nil, common.ErrSessionExpired
+	}
+
+	if now.Sub(session.LastActivityAt) < sessionTouchInterval {
+		return session, nil
+	}
+
+	idleTimeout, _ := sessionPolicy(session.RememberMe)
+	err = updateTokenData(context.Background(), tokenDataKey(userID, encryptedToken), func(tokenData *dto.UserTokenData) time.Duration {
+		if tokenData.Session == nil {
+			return redis.KeepTTL
+		}
+		tokenData.Session.LastActivityAt = now
+		tokenData.Session.IdleExpiresAt = utils.SessionIdleDeadline(now, idleTimeout, tokenData.Session.ExpiresAt)
+		session = tokenData.Session
+		return time.Until(tokenData.Session.IdleExpiresAt)
+	})
+	if err != nil {
+		// The session is still valid, the next request gets another chance to extend it
+		fmt.Printf("Warning: failed to extend session: %v\n", err)
+	}
+
+	return session, nil
+}
+
 func (s *AuthService) StoreTokenData(userID, encryptedToken string, tokenData *dto.UserTokenData) error {
 	ctx := context.Background()
 
@@ -170,10 +358,15 @@ func (s *AuthService) StoreTokenData(userID, encryptedToken string, tokenData *d
 		return fmt.Errorf("failed to marshal token data: %w", err)
 	}
 
-	tokenKey := fmt.Sprintf("auth:token:%s:%s", userID, encryptedToken)
+	tokenKey := tokenDataKey(userID, encryptedToken)
 
-	expire := global.Config.JWT.ExpirationTime
-	if expire == 0 {
+	var expire time.Duration
+	if tokenData.Session != nil {
+		expire = time.Until(tokenData.Session.IdleExpiresAt)
+	} else {
+		expire = global.Config.JWT.ExpirationTime
+	}
+	if expire <= 0 {
 		expire = 72 * time.Hour // fallback default
 	}
 
@@ -188,7 +381,7 @@ func (s *AuthService) StoreTokenData(userID, encryptedToken string, tokenData *d
 func (s *AuthService) GetTokenData(userID, encryptedToken string) (*dto.UserTokenData, error) {
 	ctx := context.Background()
 
-	tokenKey := fmt.Sprintf("auth:token:%s:%s", userID, encryptedToken)
+	tokenKey := tokenDataKey(userID, encryptedToken)
 
 	jsonData, err := global.RedisClient.Get(ctx, tokenKey).Result()
 	if err != nil {
@@ -207,7 +400,7 @@ func (s *AuthService) GetTokenData(userID, encryptedToken string) (*dto.UserToke
 func (s *AuthService) DeleteTokenData(userID, encryptedToken string) error {
 	ctx := context.Background()
 
-	tokenKey := fmt.Sprintf("auth:token:%s:%s", userID, encryptedToken)
+	tokenKey := tokenDataKey(userID, encryptedToken)
 	err := global.RedisClient.Del(ctx, tokenKey).Err()
 	if err != nil {
 		return fmt.Errorf("failed to delete token data from Redis: %w", err)
@@ -219,7 +412,7 @@ func (s *AuthService) DeleteTokenData(userID, encryptedToken string) error {
 func (s *AuthService) InvalidateUserTokens(userID string) error {
 	ctx := context.Background()
 
-	pattern := fmt.Sprintf("auth:token:%s:*", userID)
+	pattern := tokenDataKey(userID, "*")
 
 	pipe := global.RedisClient.Pipeline()
 
@@ -240,23 +433,9 @@ func (s *AuthService) InvalidateUserTokens(userID string) error {
 	return nil
 }
 
-// BuildTokenData creates RBAC data from user with workspaces
-func (s *AuthService) BuildTokenData(user *models.User) *dto.UserTokenData {
-	tokenData := &dto.UserTokenData{
-		GlobalRole: user.GlobalRole,
-	}
-	for _, membership := range user.WorkspaceMemberships {
-		if membership.Status == "active" && membership.RoleID != "" {
-			workspaceMembership := dto.WorkspaceMembershipTokenData{
-				WorkspaceID: membership.WorkspaceID,
-				RoleName:    membership.Role.Name,
-				Permissions: membership.Role.Permissions.Permissions,
-				Status:      membership.Status,
-			}
-			tokenData.WorkspaceMemberships = append(tokenData.WorkspaceMemberships, workspaceMembership)
-		}
-	}
-	return tokenData
+// BuildTokenData creates RBAC data from user with workspaces and the roles of those workspaces
+func (s *AuthService) BuildTokenData(user *models.User, roles []models.WorkspaceRole) *dto.UserTokenData {
+	return buildTokenData(user, roles)
 }
 
 func (s *AuthService) Logout(userID, encryptedToken string) error {
@@ -266,3 +445,22 @@ func (s *AuthService) Logout(userID, encryptedToken string) error {
 	}
 	return nil
 }
+
+// sessionPolicy returns the idle and absolute timeouts of a session
+func sessionPolicy(rememberMe bool) (time.Duration, time.Duration) {
+	config := global.Config.Session
+
+	idleTimeout, absoluteTimeout := config.IdleTimeout, config.AbsoluteTimeout
+	if rememberMe {
+		idleTimeout, absoluteTimeout = config.RememberMeIdleTimeout, config.RememberMeAbsoluteTimeout
+	}
+
+	if absoluteTimeout <= 0 {
+		absoluteTimeout = global.Config.JWT.ExpirationTime
+	}
+	if absoluteTimeout <= 0 {
+		absoluteTimeout = 72 * time.Hour // fallback default
+	}
+
+	return idleTimeout, absoluteTimeout
+}
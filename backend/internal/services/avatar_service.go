@@ -0,0 +1,265 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/pkg/utils"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const avatarKeyPrefix = "avatars/"
+
+type AvatarService struct {
+	userRepo      repo.UserRepositoryInterface
+	workspaceRepo repo.WorkspaceRepositoryInterface
+	authCache     AuthCacheInterface
+	baseURL       string // external URL avatar keys are served below
+}
+
+func NewAvatarService(userRepo repo.UserRepositoryInterface, workspaceRepo repo.WorkspaceRepositoryInterface, authCache AuthCacheInterface, baseURL string) AvatarServiceInterface {
+	return &AvatarService{
+		userRepo:      userRepo,
+		workspaceRepo: workspaceRepo,
+		authCache:     authCache,
+		baseURL:       strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// UploadUserAvatar replaces the avatar of a user and removes the files of the previous one
+func (s *AvatarService) UploadUserAvatar(userID string, data []byte) (*dto.AvatarResponse, error) {
+	user, err := s.userRepo.GetUserWithProfile(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || user.Profile == nil {
+		return nil, common.ErrUserNotFound
+	}
+
+	key, avatar, err := s.store("users/"+userID, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateUserProfile(userID, avatarUpdates(&avatar.AvatarURL, &avatar.ThumbnailURL, &key)); err != nil {
+		deleteAvatarFiles(key)
+		return nil, fmt.Errorf("failed to update avatar: %w", err)
+	}
+	deleteAvatarFiles(getStringValue(user.Profile.AvatarKey))
+
+	publishAvatarChanged(userID)
+
+	return avatar, nil
+}
+
+// DeleteUserAvatar clears the avatar of a user
+func (s *AvatarService) DeleteUserAvatar(userID string) error {
+	user, err := s.userRepo.GetUserWithProfile(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || user.Profile == nil {
+		return common.ErrUserNotFound
+	}
+	if user.Profile.AvatarURL == nil {
+		return common.ErrAvatarNotFound
+	}
+
+	if err := s.userRepo.UpdateUserProfile(userID, avatarUpdates(nil, nil, nil)); err != nil {
+		return fmt.Errorf("failed to delete avatar: %w", err)
+	}
+	deleteAvatarFiles(getStringValue(user.Profile.AvatarKey))
+
+	publishAvatarChanged(userID)
+
+	return nil
+}
+
+// UploadWorkspaceAvatar replaces the avatar of a workspace on behalf of one of its admins
+func (s *AvatarService) UploadWorkspaceAvatar(userID, workspaceID string, data []byte) (*dto.AvatarResponse, error) {
+	workspace, err := s.getManagedWorkspace(userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	key, avatar, err := s.store("workspaces/"+workspaceID, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.workspaceRepo.UpdateWorkspace(workspaceID, avatarUpdates(&avatar.AvatarURL, &avatar.ThumbnailURL, &key)); err != nil {
+		deleteAvatarFiles(key)
+		return nil, fmt.Errorf("failed to update avatar: %w", err)
+	}
+	deleteAvatarFiles(getStringValue(workspace.AvatarKey))
+
+	return avatar, nil
+}
+
+// DeleteWorkspaceAvatar clears the avatar of a workspace on behalf of one of its admins
+func (s *AvatarService) DeleteWorkspaceAvatar(userID, workspaceID string) error {
+	workspace, err := s.getManagedWorkspace(userID, workspaceID)
+	if err != nil {
+		return err
+	}
+	if workspace.AvatarURL == nil {
+		return common.ErrAvatarNotFound
+	}
+
+	if err := s.workspaceRepo.UpdateWorkspace(workspaceID, avatarUpdates(nil, nil, nil)); err != nil {
+		return fmt.Errorf("failed to delete avatar: %w", err)
+	}
+	deleteAvatarFiles(getStringValue(workspace.AvatarKey))
+
+	return nil
+}
+
+// OpenAvatar streams a stored avatar by the path it is served under
+func (s *AvatarService) OpenAvatar(name string) (io.ReadCloser, string, error) {
+	if global.Storage == nil {
+		return nil, "", common.ErrStorageUnavailable
+	}
+
+	contentType := ""
+	switch path.Ext(name) {
+	case ".jpg":
+		contentType = "image/jpeg"
+	case ".png":
+		contentType = "image/png"
+	}
+	if contentType == "" || strings.Contains(name, "..") {
+		return nil, "", common.ErrAvatarNotFound
+	}
+
+	body, err := global.Storage.Open(context.Background(), avatarKeyPrefix+name)
+	if errors.Is(err, utils.ErrStorageObjectNotFound) {
+		return nil, "", common.ErrAvatarNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open avatar: %w", err)
+	}
+
+	return body, contentType, nil
+}
+
+// store processes an upload and writes the avatar and its thumbnail under a fresh key, so that
+// cached copies of the previous avatar never outlive a change
+func (s *AvatarService) store(owner string, data []byte) (string, *dto.AvatarResponse, error) {
+	if global.Storage == nil {
+		return "", nil, common.ErrStorageUnavailable
+	}
+
+	processed, err := utils.ProcessAvatar(data, avatarOptions())
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrAvatarUnsupportedType):
+			return "", nil, common.ErrAvatarUnsupportedType
+		case errors.Is(err, utils.ErrAvatarTooLarge):
+			return "", nil, common.ErrAvatarTooLarge
+		case errors.Is(err, utils.ErrAvatarInvalidImage):
+			return "", nil, common.ErrAvatarInvalidImage
+		}
+		return "", nil, fmt.Errorf("failed to process avatar: %w", err)
+	}
+
+	name := fmt.Sprintf("%s/%s%s", owner, uuid.New().String(), processed.Image.Extension)
+	key := avatarKeyPrefix + name
+	thumbnailName := utils.AvatarThumbnailKey(name)
+
+	ctx := context.Background()
+	if err := putAvatarImage(ctx, key, &processed.Image); err != nil {
+		return "", nil, err
+	}
+	if err := putAvatarImage(ctx, avatarKeyPrefix+thumbnailName, &processed.Thumbnail); err != nil {
+		deleteAvatarFiles(key)
+		return "", nil, err
+	}
+
+	return key, &dto.AvatarResponse{
+		AvatarURL:    s.baseURL + "/" + name,
+		ThumbnailURL: s.baseURL + "/" + thumbnailName,
+	}, nil
+}
+
+// getManagedWorkspace returns a workspace the user may change, which excludes archived ones
+func (s *AvatarService) getManagedWorkspace(userID, workspaceID string) (*models.Workspace, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, common.PermissionWorkspaceUpdate); err != nil {
+		return nil, err
+	}
+	if workspace.Status == common.ArchivedStatus {
+		return nil, common.ErrWorkspaceArchived
+	}
+
+	return workspace, nil
+}
+
+func putAvatarImage(ctx context.Context, key string, image *utils.AvatarImage) error {
+	if err := global.Storage.Put(ctx, key, bytes.NewReader(image.Data), int64(len(image.Data)), image.ContentType); err != nil {
+		return fmt.Errorf("failed to store avatar: %w", err)
+	}
+	return nil
+}
+
+// deleteAvatarFiles removes an avatar and its thumbnail. Failures only leave an orphaned file
+// behind, so they are logged rather than returned.
+func deleteAvatarFiles(key string) {
+	if key == "" || global.Storage == nil {
+		return
+	}
+
+	ctx := context.Background()
+	for _, k := range []string{key, utils.AvatarThumbnailKey(key)} {
+		if err := global.Storage.Delete(ctx, k); err != nil {
+			fmt.Printf("Warning: failed to delete avatar file %s: %v\n", k, err)
+		}
+	}
+}
+
+func avatarUpdates(avatarURL, thumbnailURL, key *string) map[string]interface{} {
+	return map[string]interface{}{
+		"avatar_url":           avatarURL,
+		"avatar_thumbnail_url": thumbnailURL,
+		"avatar_key":           key,
+	}
+}
+
+func avatarOptions() *utils.AvatarOptions {
+	cfg := global.Config.Avatar
+	return &utils.AvatarOptions{
+		MaxBytes:      cfg.MaxSize,
+		MaxDimension:  cfg.MaxDimension,
+		Size:          cfg.Size,
+		ThumbnailSize: cfg.ThumbnailSize,
+	}
+}
+
+func publishAvatarChanged(userID string) {
+	if global.EventTopicPublisher == nil {
+		return
+	}
+
+	payload := &dto.UserUpdatedPayload{
+		UserID:        userID,
+		ChangedFields: []string{"avatar_url"},
+	}
+	go func() {
+		if err := global.EventTopicPublisher.Publish(common.UserUpdatedLog, payload); err != nil {
+			fmt.Printf("Error publishing user updated event: %v\n", err)
+		}
+	}()
+}
@@ -0,0 +1,492 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/pkg/utils"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// dataExportJobTTL bounds how long a job may stay pending, a crashed worker frees the user after it
+	dataExportJobTTL = time.Hour
+
+	defaultDataExportLinkTTL   = 15 * time.Minute
+	defaultDataExportRetention = 7 * 24 * time.Hour
+
+	dataExportExpiringKey = "export:expiring" // sorted set of storage keys by deletion time
+)
+
+type DataExportService struct {
+	userRepo        repo.UserRepositoryInterface
+	workspaceRepo   repo.WorkspaceRepositoryInterface
+	deviceRepo      repo.UserDeviceRepositoryInterface
+	downloadBaseURL string
+}
+
+// NewDataExportService creates the export service. downloadBaseURL is the URL of the download
+// endpoint, used when the storage backend cannot sign URLs itself.
+func NewDataExportService(userRepo repo.UserRepositoryInterface, workspaceRepo repo.WorkspaceRepositoryInterface, deviceRepo repo.UserDeviceRepositoryInterface, downloadBaseURL string) DataExportServiceInterface {
+	return &DataExportService{
+		userRepo:        userRepo,
+		workspaceRepo:   workspaceRepo,
+		deviceRepo:      deviceRepo,
+		downloadBaseURL: downloadBaseURL,
+	}
+}
+
+// RequestExport starts building an archive in the background. While an export is still running
+// the running one is returned instead of starting another.
+func (s *DataExportService) RequestExport(userID, format string) (*dto.DataExport, error) {
+	if global.Storage == nil {
+		return nil, common.ErrStorageUnavailable
+	}
+	if format == "" {
+		format = common.DataExportFormatJSON
+	}
+
+	exists, err := s.userRepo.ExistsByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check user: %w", err)
+	}
+	if !exists {
+		return nil, common.ErrUserNotFound
+	}
+
+	ctx := context.Background()
+	latestID, err := global.RedisClient.Get(ctx, dataExportUserKey(userID)).Result()
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get latest data export: %w", err)
+	}
+	if latestID != "" {
+		latest, err := s.getJob(ctx, latestID)
+		if err != nil {
+			return nil, err
+		}
+		if latest != nil && (latest.Status == common.DataExportStatusPending || latest.Status == common.DataExportStatusProcessing) {
+			return latest, nil
+		}
+	}
+
+	id := uuid.New().String()
+	job := &dto.DataExport{
+		ID:         id,
+		UserID:     userID,
+		Format:     format,
+		Status:     common.DataExportStatusPending,
+		StorageKey: fmt.Sprintf("exports/%s/%s.%s", userID, id, format),
+		CreatedAt:  time.Now(),
+	}
+	if err := s.saveJob(ctx, job, dataExportJobTTL); err != nil {
+		return nil, err
+	}
+
+	queued := *job
+	go s.run(&queued)
+
+	return job, nil
+}
+
+func (s *DataExportService) GetExport(userID, exportID string) (*dto.DataExport, error) {
+	ctx := context.Background()
+
+	job, err := s.getJob(ctx, exportID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil || job.UserID != userID {
+		return nil, common.ErrDataExportNotFound
+	}
+	if job.Status != common.DataExportStatusReady {
+		return job, nil
+	}
+
+	if global.Storage == nil {
+		return nil, common.ErrStorageUnavailable
+	}
+
+	ttl := dataExportLinkTTL()
+	if job.ExpiresAt != nil && time.Until(*job.ExpiresAt) < ttl {
+		ttl = time.Until(*job.ExpiresAt)
+	}
+
+	signed, err := global.Storage.SignedURL(ctx, job.StorageKey, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	if signed == "" {
+		token, err := utils.GenerateSecureToken("", 32)
+		if err != nil {
+			return nil, err
+		}
+		if err := global.RedisClient.Set(ctx, dataExportDownloadKey(utils.HashToken(token)), job.ID, ttl).Err(); err != nil {
+			return nil, fmt.Errorf("failed to store download token: %w", err)
+		}
+		signed = s.downloadBaseURL + "/" + token
+	}
+
+	downloadExpiresAt := time.Now().Add(ttl)
+	job.DownloadURL = signed
+	job.DownloadExpiresAt = &downloadExpiresAt
+
+	return job, nil
+}
+
+// OpenDownload resolves a download link handed out by GetExport
+func (s *DataExportService) OpenDownload(token string) (io.ReadCloser, *dto.DataExport, error) {
+	if global.Storage == nil {
+		return nil, nil, common.ErrStorageUnavailable
+	}
+
+	ctx := context.Background()
+	exportID, err := global.RedisClient.Get(ctx, dataExportDownloadKey(utils.HashToken(token))).Result()
+	if err == redis.Nil {
+		return nil, nil, common.ErrDataExportLinkInvalid
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get download token: %w", err)
+	}
+
+	job, err := s.getJob(ctx, exportID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job == nil || job.Status != common.DataExportStatusReady {
+		return nil, nil, common.ErrDataExportLinkInvalid
+	}
+
+	body, err := global.Storage.Open(ctx, job.StorageKey)
+	if errors.Is(err, utils.ErrStorageObjectNotFound) {
+		return nil, nil, common.ErrDataExportLinkInvalid
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return body, job, nil
+}
+
+// SweepExpired deletes archives that are past their retention
+func (s *DataExportService) SweepExpired() {
+	ctx := context.Background()
+
+	keys, err := global.RedisClient.ZRangeByScore(ctx, dataExportExpiringKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(time.Now().Unix(), 10),
+	}).Result()
+	if err != nil {
+		fmt.Printf("Warning: failed to list expired data exports: %v\n", err)
+		return
+	}
+
+	for _, key := range keys {
+		if err := global.Storage.Delete(ctx, key); err != nil {
+			fmt.Printf("Warning: failed to delete data export %s: %v\n", key, err)
+			continue
+		}
+		global.RedisClient.ZRem(ctx, dataExportExpiringKey, key)
+	}
+}
+
+// RunDataExportSweeper calls SweepExpired every interval until ctx is done
+func RunDataExportSweeper(ctx context.Context, service DataExportServiceInterface, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if global.Storage != nil {
+				service.SweepExpired()
+			}
+		}
+	}
+}
+
+func (s *DataExportService) run(job *dto.DataExport) {
+	ctx := context.Background()
+
+	job.Status = common.DataExportStatusProcessing
+	if err := s.saveJob(ctx, job, dataExportJobTTL); err != nil {
+		fmt.Printf("Warning: failed to update data export %s: %v\n", job.ID, err)
+	}
+
+	if err := s.build(ctx, job); err != nil {
+		fmt.Printf("Error building data export %s: %v\n", job.ID, err)
+
+		job.Status = common.DataExportStatusFailed
+		if err := s.saveJob(ctx, job, dataExportJobTTL); err != nil {
+			fmt.Printf("Warning: failed to update data export %s: %v\n", job.ID, err)
+		}
+		return
+	}
+
+	if global.EventTopicPublisher != nil {
+		payload := &dto.UserDataExportedPayload{
+			UserID:   job.UserID,
+			ExportID: job.ID,
+			Format:   job.Format,
+		}
+		if err := global.EventTopicPublisher.Publish(common.UserDataExportedLog, payload); err != nil {
+			fmt.Printf("Error publishing data exported event: %v\n", err)
+		}
+	}
+
+	if global.UserNotifier != nil {
+		user, err := s.userRepo.GetUserByID(job.UserID)
+		if err == nil && user != nil {
+			data := map[string]interface{}{
+				"export_id":  job.ID,
+				"expires_at": job.ExpiresAt,
+			}
+			if err := global.UserNotifier.Send(user.Email, "Your data export is ready", common.NotificationTemplateDataExportReady, data); err != nil {
+				fmt.Printf("Error sending data export notification: %v\n", err)
+			}
+		}
+	}
+}
+
+func (s *DataExportService) build(ctx context.Context, job *dto.DataExport) error {
+	archive, err := s.collect(ctx, job.UserID)
+	if err != nil {
+		return err
+	}
+
+	data, contentType, err := encodeDataExport(archive, job.Format)
+	if err != nil {
+		return err
+	}
+
+	if err := global.Storage.Put(ctx, job.StorageKey, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(dataExportRetention())
+	job.Status = common.DataExportStatusReady
+	job.CompletedAt = &now
+	job.ExpiresAt = &expiresAt
+
+	// Register for deletion before anything else can fail, the archive must not outlive its retention
+	err = global.RedisClient.ZAdd(ctx, dataExportExpiringKey, redis.Z{
+		Score:  float64(expiresAt.Unix()),
+		Member: job.StorageKey,
+	}).Err()
+	if err != nil {
+		global.Storage.Delete(ctx, job.StorageKey)
+		return fmt.Errorf("failed to schedule data export deletion: %w", err)
+	}
+
+	return s.saveJob(ctx, job, dataExportRetention())
+}
+
+// collect gathers everything stored about the user. Secrets such as password hashes and session
+// tokens are left out.
+func (s *DataExportService) collect(ctx context.Context, userID string) (*dto.DataExportArchive, error) {
+	user, err := s.userRepo.GetUserWithProfile(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, common.ErrUserNotFound
+	}
+
+	archive := &dto.DataExportArchive{
+		ExportedAt:           time.Now(),
+		Profile:              user.Profile,
+		AuthProviders:        []dto.ExportedAuthProvider{},
+		WorkspaceMemberships: []dto.ExportedMembership{},
+		Sessions:             []dto.SessionMetadata{},
+	}
+	user.Profile = nil
+	archive.User = user
+
+	providers, err := s.userRepo.GetUserAuthProviders(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get auth providers: %w", err)
+	}
+	for _, provider := range providers {
+		archive.AuthProviders = append(archive.AuthProviders, dto.ExportedAuthProvider{
+			Provider:       provider.Provider,
+			ProviderUserID: provider.ProviderUserID,
+			ProviderEmail:  provider.ProviderEmail,
+			ProviderData:   provider.ProviderData,
+			IsPrimary:      provider.IsPrimary,
+			Status:         provider.Status,
+			CreatedAt:      provider.CreatedAt,
+		})
+	}
+
+	memberships, err := s.workspaceRepo.GetUserMembershipsWithDetails(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, membership := range memberships {
+		archive.WorkspaceMemberships = append(archive.WorkspaceMemberships, dto.ExportedMembership{
+			WorkspaceID:   membership.WorkspaceID,
+			WorkspaceName: membership.Workspace.Name,
+			WorkspaceSlug: membership.Workspace.Slug,
+			Role:          membership.Role.Name,
+			Status:        membership.Status,
+			InvitedBy:     membership.InvitedBy,
+			JoinedAt:      membership.JoinedAt,
+			CreatedAt:     membership.CreatedAt,
+		})
+	}
+
+	keys, err := global.RedisClient.Keys(ctx, tokenDataKey(userID, "*")).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	for _, key := range keys {
+		raw, err := global.RedisClient.Get(ctx, key).Result()
+		if err != nil {
+			continue // ended while we were reading
+		}
+		var tokenData dto.UserTokenData
+		if err := json.Unmarshal([]byte(raw), &tokenData); err != nil || tokenData.Session == nil {
+			continue
+		}
+		archive.Sessions = append(archive.Sessions, *tokenData.Session)
+	}
+
+	devices, err := s.deviceRepo.GetUserDevices(userID)
+	if err != nil {
+		return nil, err
+	}
+	archive.LoginHistory = devices
+
+	return archive, nil
+}
+
+func (s *DataExportService) getJob(ctx context.Context, exportID string) (*dto.DataExport, error) {
+	raw, err := global.RedisClient.Get(ctx, dataExportJobKey(exportID)).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data export: %w", err)
+	}
+
+	var record dataExportRecord
+	if err := json.Unmarshal([]byte(raw), &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal data export: %w", err)
+	}
+
+	job := record.DataExport
+	job.StorageKey = record.StorageKey
+	return &job, nil
+}
+
+// dataExportRecord is the stored form of a job, StorageKey is hidden from API responses
+type dataExportRecord struct {
+	dto.DataExport
+	StorageKey string `json:"storage_key"`
+}
+
+func (s *DataExportService) saveJob(ctx context.Context, job *dto.DataExport, ttl time.Duration) error {
+	data, err := json.Marshal(&dataExportRecord{DataExport: *job, StorageKey: job.StorageKey})
+	if err != nil {
+		return fmt.Errorf("failed to marshal data export: %w", err)
+	}
+
+	pipe := global.RedisClient.TxPipeline()
+	pipe.Set(ctx, dataExportJobKey(job.ID), data, ttl)
+	pipe.Set(ctx, dataExportUserKey(job.UserID), job.ID, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store data export: %w", err)
+	}
+
+	return nil
+}
+
+// encodeDataExport renders the archive as a single JSON document or as a ZIP with one file per section
+func encodeDataExport(archive *dto.DataExportArchive, format string) ([]byte, string, error) {
+	if format != common.DataExportFormatZIP {
+		data, err := json.MarshalIndent(archive, "", "  ")
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode data export: %w", err)
+		}
+		return data, "application/json", nil
+	}
+
+	sections := []struct {
+		name  string
+		value interface{}
+	}{
+		{"user.json", archive.User},
+		{"profile.json", archive.Profile},
+		{"auth_providers.json", archive.AuthProviders},
+		{"workspace_memberships.json", archive.WorkspaceMemberships},
+		{"sessions.json", archive.Sessions},
+		{"login_history.json", archive.LoginHistory},
+	}
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, section := range sections {
+		data, err := json.MarshalIndent(section.value, "", "  ")
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode %s: %w", section.name, err)
+		}
+
+		file, err := writer.CreateHeader(&zip.FileHeader{
+			Name:     section.name,
+			Method:   zip.Deflate,
+			Modified: archive.ExportedAt,
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to add %s: %w", section.name, err)
+		}
+		if _, err := file.Write(data); err != nil {
+			return nil, "", fmt.Errorf("failed to write %s: %w", section.name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to finish zip archive: %w", err)
+	}
+
+	return buf.Bytes(), "application/zip", nil
+}
+
+func dataExportJobKey(exportID string) string {
+	return fmt.Sprintf("export:job:%s", exportID)
+}
+
+func dataExportUserKey(userID string) string {
+	return fmt.Sprintf("export:user:%s", userID)
+}
+
+func dataExportDownloadKey(tokenHash string) string {
+	return fmt.Sprintf("export:download:%s", tokenHash)
+}
+
+func dataExportLinkTTL() time.Duration {
+	if ttl := global.Config.DataExport.LinkTTL; ttl > 0 {
+		return ttl
+	}
+	return defaultDataExportLinkTTL
+}
+
+func dataExportRetention() time.Duration {
+	if retention := global.Config.DataExport.Retention; retention > 0 {
+		return retention
+	}
+	return defaultDataExportRetention
+}
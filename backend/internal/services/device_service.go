@@ -0,0 +1,130 @@
+package services
+
+import (
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/pkg/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DeviceService struct {
+	deviceRepo repo.UserDeviceRepositoryInterface
+}
+
+func NewDeviceService(deviceRepo repo.UserDeviceRepositoryInterface) DeviceServiceInterface {
+	return &DeviceService{
+		deviceRepo: deviceRepo,
+	}
+}
+
+// RecordLogin remembers the device a user signed in from and reports whether it was unknown.
+// The very first device of an account is trusted silently, there is nothing to compare it with.
+func (s *DeviceService) RecordLogin(user *models.User, userAgent, ipAddress string) (*models.UserDevice, bool, error) {
+	info := utils.FingerprintDevice(userAgent, ipAddress)
+	now := time.Now()
+
+	device, err := s.deviceRepo.GetDeviceByFingerprint(user.ID, info.Fingerprint)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get device: %w", err)
+	}
+
+	if device != nil {
+		err = s.deviceRepo.UpdateDevice(device.ID, map[string]interface{}{
+			"last_ip":      ipAddress,
+			"user_agent":   utils.TruncateUTF8(userAgent, 512),
+			"last_seen_at": now,
+		})
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to update device: %w", err)
+		}
+		return device, false, nil
+	}
+
+	knownDevices, err := s.deviceRepo.CountUserDevices(user.ID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to count devices: %w", err)
+	}
+
+	device = &models.UserDevice{
+		UserID:      user.ID,
+		Fingerprint: info.Fingerprint,
+		Browser:     info.Browser,
+		OS:          info.OS,
+		IPSubnet:    info.IPSubnet,
+		LastIP:      ipAddress,
+		UserAgent:   utils.TruncateUTF8(userAgent, 512),
+		FirstSeenAt: now,
+		LastSeenAt:  now,
+	}
+
+	if err := s.deviceRepo.CreateDevice(device); err != nil {
+		return nil, false, fmt.Errorf("failed to create device: %w", err)
+	}
+
+	if knownDevices == 0 {
+		return device, false, nil
+	}
+
+	s.notifyNewDevice(user, device, ipAddress)
+
+	return device, true, nil
+}
+
+func (s *DeviceService) ListDevices(userID string) ([]models.UserDevice, error) {
+	devices, err := s.deviceRepo.GetUserDevices(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+	return devices, nil
+}
+
+// ForgetDevice removes a trusted device; the next login from it triggers a new device alert again
+func (s *DeviceService) ForgetDevice(userID, deviceID string) error {
+	err := s.deviceRepo.DeleteDevice(userID, deviceID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return common.ErrDeviceNotFound
+		}
+		return fmt.Errorf("failed to forget device: %w", err)
+	}
+	return nil
+}
+
+func (s *DeviceService) notifyNewDevice(user *models.User, device *models.UserDevice, ipAddress string) {
+	if global.EventTopicPublisher != nil {
+		payload := &dto.UserNewDeviceLoginPayload{
+			UserID:    user.ID,
+			DeviceID:  device.ID,
+			Browser:   device.Browser,
+			OS:        device.OS,
+			IPAddress: ipAddress,
+			IPSubnet:  device.IPSubnet,
+		}
+		go func() {
+			if err := global.EventTopicPublisher.Publish(common.UserNewDeviceLoginLog, payload); err != nil {
+				fmt.Printf("Error publishing new device login event: %v\n", err)
+			}
+		}()
+	}
+
+	if global.UserNotifier != nil {
+		data := map[string]interface{}{
+			"browser":    device.Browser,
+			"os":         device.OS,
+			"ip_address": ipAddress,
+			"login_at":   device.FirstSeenAt,
+		}
+		go func() {
+			err := global.UserNotifier.Send(user.Email, "New sign-in to your account", common.NotificationTemplateNewDeviceLogin, data)
+			if err != nil {
+				fmt.Printf("Error sending new device notification: %v\n", err)
+			}
+		}()
+	}
+}
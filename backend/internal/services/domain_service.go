@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/pkg/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	domainVerificationTokenSize = 24
+	domainLookupTimeout         = 10 * time.Second
+)
+
+type DomainService struct {
+	domainRepo    repo.DomainRepositoryInterface
+	workspaceRepo repo.WorkspaceRepositoryInterface
+	authCache     AuthCacheInterface
+	resolver      utils.TXTResolver
+}
+
+func NewDomainService(domainRepo repo.DomainRepositoryInterface, workspaceRepo repo.WorkspaceRepositoryInterface, authCache AuthCacheInterface, resolver utils.TXTResolver) DomainServiceInterface {
+	return &DomainService{
+		domainRepo:    domainRepo,
+		workspaceRepo: workspaceRepo,
+		authCache:     authCache,
+		resolver:      resolver,
+	}
+}
+
+func (s *DomainService) ListDomains(userID, workspaceID string) ([]dto.DomainResponse, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, common.PermissionWorkspaceRead); err != nil {
+		return nil, err
+	}
+
+	domains, err := s.domainRepo.ListDomains(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.DomainResponse, 0, len(domains))
+	for i := range domains {
+		responses = append(responses, *toDomainResponse(&domains[i]))
+	}
+	return responses, nil
+}
+
+// AddDomain claims a domain for the workspace. It stays pending until VerifyDomain finds the TXT
+// record of the response in DNS.
+func (s *DomainService) AddDomain(userID, workspaceID string, req *dto.AddDomainRequest) (*dto.DomainResponse, error) {
+	if _, err := s.getEditableWorkspace(userID, workspaceID); err != nil {
+		return nil, err
+	}
+
+	name := utils.NormalizeDomain(req.Domain)
+	if name == "" {
+		return nil, common.ErrDomainInvalid
+	}
+
+	token, err := utils.GenerateSecureToken("", domainVerificationTokenSize)
+	if err != nil {
+		return nil, err
+	}
+
+	domain := &models.WorkspaceDomain{
+		WorkspaceID:       workspaceID,
+		Domain:            name,
+		VerificationToken: token,
+		Status:            models.DomainStatusPending,
+		CreatedBy:         userID,
+	}
+	if err := s.domainRepo.CreateDomain(domain); err != nil {
+		if errors.Is(err, repo.ErrDuplicateDomain) {
+			return nil, common.ErrDomainExists
+		}
+		return nil, err
+	}
+
+	return toDomainResponse(domain), nil
+}
+
+// VerifyDomain looks up the TXT record of a domain and marks it verified when it holds the token
+func (s *DomainService) VerifyDomain(userID, workspaceID, domainID string) (*dto.DomainResponse, error) {
+	if _, err := s.getEditableWorkspace(userID, workspaceID); err != nil {
+		return nil, err
+	}
+
+	domain, err := s.getDomain(workspaceID, domainID)
+	if err != nil {
+		return nil, err
+	}
+	if domain.Status == models.DomainStatusVerified {
+		return toDomainResponse(domain), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), domainLookupTimeout)
+	defer cancel()
+
+	verified, err := utils.VerifyDomainTXT(ctx, s.resolver, domain.Domain, domain.VerificationToken)
+	if err != nil {
+		fmt.Printf("Warning: failed to verify domain %s: %v\n", domain.Domain, err)
+		return nil, common.ErrDomainLookupFailed
+	}
+	if !verified {
+		return nil, common.ErrDomainVerificationFailed
+	}
+
+	now := time.Now()
+	if err := s.domainRepo.UpdateDomain(domain.ID, map[string]interface{}{
+		"status":      models.DomainStatusVerified,
+		"verified_at": &now,
+	}); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.ErrDomainNotFound
+		}
+		return nil, err
+	}
+
+	domain.Status = models.DomainStatusVerified
+	domain.VerifiedAt = &now
+	return toDomainResponse(domain), nil
+}
+
+// RemoveDomain stops users of the domain from joining on their own; members who already joined stay
+func (s *DomainService) RemoveDomain(userID, workspaceID, domainID string) error {
+	if _, err := s.getEditableWorkspace(userID, workspaceID); err != nil {
+		return err
+	}
+
+	domain, err := s.getDomain(workspaceID, domainID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.domainRepo.DeleteDomain(domain.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrDomainNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// getEditableWorkspace returns an active workspace whose domains the user may change
+func (s *DomainService) getEditableWorkspace(userID, workspaceID string) (*models.Workspace, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, common.PermissionWorkspaceUpdate); err != nil {
+		return nil, err
+	}
+
+	switch workspace.Status {
+	case common.ActiveStatus:
+		return workspace, nil
+	case common.ArchivedStatus:
+		return nil, common.ErrWorkspaceArchived
+	default:
+		return nil, common.ErrWorkspaceInactive
+	}
+}
+
+func (s *DomainService) getDomain(workspaceID, domainID string) (*models.WorkspaceDomain, error) {
+	domain, err := s.domainRepo.GetDomain(workspaceID, domainID)
+	if err != nil {
+		return nil, err
+	}
+	if domain == nil {
+		return nil, common.ErrDomainNotFound
+	}
+	return domain, nil
+}
+
+func toDomainResponse(domain *models.WorkspaceDomain) *dto.DomainResponse {
+	return &dto.DomainResponse{
+		ID:          domain.ID,
+		WorkspaceID: domain.WorkspaceID,
+		Domain:      domain.Domain,
+		Status:      domain.Status,
+		RecordName:  utils.DomainVerificationRecord(domain.Domain),
+		RecordValue: utils.DomainVerificationValue(domain.VerificationToken),
+		VerifiedAt:  domain.VerifiedAt,
+		CreatedBy:   domain.CreatedBy,
+		CreatedAt:   domain.CreatedAt,
+	}
+}
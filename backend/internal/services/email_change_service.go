@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/pkg/utils"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	emailChangeTokenPrefix = "ec_"
+	emailRevertTokenPrefix = "er_"
+
+	defaultEmailChangeTTL    = 24 * time.Hour
+	defaultEmailRevertWindow = 72 * time.Hour
+
+	// emailChangeSignInWindow is how recent the sign-in of an account without a password must be
+	emailChangeSignInWindow = 10 * time.Minute
+)
+
+type EmailChangeService struct {
+	userRepo    repo.UserRepositoryInterface
+	authService AuthServiceInterface
+}
+
+func NewEmailChangeService(userRepo repo.UserRepositoryInterface, authService AuthServiceInterface) EmailChangeServiceInterface {
+	return &EmailChangeService{
+		userRepo:    userRepo,
+		authService: authService,
+	}
+}
+
+// RequestChange sends a confirmation link to the new address and a notice to the current one.
+// A user has at most one pending change, a new request replaces the previous one. session is the
+// session the request was made with.
+func (s *EmailChangeService) RequestChange(userID string, session *dto.SessionMetadata, req *dto.ChangeEmailRequest) error {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return common.ErrUserNotFound
+	}
+
+	newEmail := strings.ToLower(strings.TrimSpace(req.NewEmail))
+	if strings.EqualFold(newEmail, user.Email) {
+		return common.ErrEmailUnchanged
+	}
+
+	if err := s.reauthenticate(user.ID, session, req.Password); err != nil {
+		return err
+	}
+
+	exists, err := s.userRepo.ExistsByEmail(newEmail)
+	if err != nil {
+		return fmt.Errorf("failed to check email: %w", err)
+	}
+	if exists {
+		return common.ErrEmailAlreadyExists
+	}
+
+	token, err := utils.GenerateSecureToken(emailChangeTokenPrefix, 32)
+	if err != nil {
+		return err
+	}
+
+	change := &dto.EmailChange{
+		UserID:   user.ID,
+		OldEmail: user.Email,
+		NewEmail: newEmail,
+	}
+	ttl := emailChangeTTL()
+
+	ctx := context.Background()
+	previous, err := global.RedisClient.Get(ctx, emailChangePendingKey(user.ID)).Result()
+	if err != nil && err != redis.Nil {
+		return fmt.Errorf("failed to get pending email change: %w", err)
+	}
+
+	data, err := json.Marshal(change)
+	if err != nil {
+		return fmt.Errorf("failed to marshal email change: %w", err)
+	}
+
+	pipe := global.RedisClient.TxPipeline()
+	if previous != "" {
+		pipe.Del(ctx, emailChangeConfirmKey(previous))
+	}
+	pipe.Set(ctx, emailChangeConfirmKey(utils.HashToken(token)), data, ttl)
+	pipe.Set(ctx, emailChangePendingKey(user.ID), utils.HashToken(token), ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store email change: %w", err)
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	s.notify(newEmail, "Confirm your new email address", common.NotificationTemplateEmailChangeConfirm, map[string]interface{}{
+		"confirm_url": frontendURL("/email-change/confirm", token),
+		"old_email":   user.Email,
+		"expires_at":  expiresAt,
+	})
+	s.notify(user.Email, "Email change requested", common.NotificationTemplateEmailChangeNotice, map[string]interface{}{
+		"new_email":  newEmail,
+		"expires_at": expiresAt,
+	})
+
+	return nil
+}
+
+// ConfirmChange switches the account to the new address and gives the old address a link to undo it
+func (s *EmailChangeService) ConfirmChange(token string) error {
+	ctx := context.Background()
+
+	change, err := s.consume(ctx, emailChangeConfirmKey(utils.HashToken(token)))
+	if err != nil {
+		return err
+	}
+	global.RedisClient.Del(ctx, emailChangePendingKey(change.UserID))
+
+	if err := s.switchEmail(change.UserID, change.OldEmail, change.NewEmail); err != nil {
+		return err
+	}
+
+	revertToken, err := utils.GenerateSecureToken(emailRevertTokenPrefix, 32)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(change)
+	if err != nil {
+		return fmt.Errorf("failed to marshal email change: %w", err)
+	}
+
+	window := emailRevertWindow()
+	if err := global.RedisClient.Set(ctx, emailChangeRevertKey(utils.HashToken(revertToken)), data, window).Err(); err != nil {
+		// The change itself went through, only the way back is missing
+		fmt.Printf("Warning: failed to store email revert token: %v\n", err)
+		revertToken = ""
+	}
+
+	notice := map[string]interface{}{
+		"new_email":  change.NewEmail,
+		"changed_at": time.Now(),
+	}
+	if revertToken != "" {
+		notice["revert_url"] = frontendURL("/email-change/revert", revertToken)
+		notice["revert_expires_at"] = time.Now().Add(window)
+	}
+	s.notify(change.OldEmail, "Your email address was changed", common.NotificationTemplateEmailChanged, notice)
+
+	return nil
+}
+
+// RevertChange restores the previous address. As the change may have come from a hijacked
+// session, all sessions of the user are ended.
+func (s *EmailChangeService) RevertChange(token string) error {
+	ctx := context.Background()
+
+	change, err := s.consume(ctx, emailChangeRevertKey(utils.HashToken(token)))
+	if err != nil {
+		return err
+	}
+
+	if err := s.switchEmail(change.UserID, change.NewEmail, change.OldEmail); err != nil {
+		return err
+	}
+
+	if err := s.authService.InvalidateUserTokens(change.UserID); err != nil {
+		fmt.Printf("Warning: failed to invalidate sessions after email revert: %v\n", err)
+	}
+
+	// A change requested from the hijacked session must not survive the revert either
+	pending, err := global.RedisClient.GetDel(ctx, emailChangePendingKey(change.UserID)).Result()
+	if err == nil {
+		global.RedisClient.Del(ctx, emailChangeConfirmKey(pending))
+	}
+
+	return nil
+}
+
+// switchEmail updates the user and its local login in one transaction, failing when the user
+// no longer has the expected address or the target address was taken in the meantime
+func (s *EmailChangeService) switchEmail(userID, from, to string) error {
+	exists, err := s.userRepo.ExistsByEmail(to)
+	if err != nil {
+		return fmt.Errorf("failed to check email: %w", err)
+	}
+	if exists {
+		return common.ErrEmailAlreadyExists
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		return s.userRepo.ChangeEmail(tx, userID, from, to)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return common.ErrEmailChangeTokenInvalid
+	}
+	if err != nil {
+		return fmt.Errorf("failed to change email: %w", err)
+	}
+
+	if global.EventTopicPublisher != nil {
+		payload := &dto.UserUpdatedPayload{
+			UserID:        userID,
+			ChangedFields: []string{"email"},
+		}
+		go func() {
+			if err := global.EventTopicPublisher.Publish(common.UserUpdatedLog, payload); err != nil {
+				fmt.Printf("Error publishing user updated event: %v\n", err)
+			}
+		}()
+	}
+
+	return nil
+}
+
+// consume reads and deletes a token in one step so that a link works only once
+func (s *EmailChangeService) consume(ctx context.Context, key string) (*dto.EmailChange, error) {
+	data, err := global.RedisClient.GetDel(ctx, key).Result()
+	if err == redis.Nil {
+		return nil, common.ErrEmailChangeTokenInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get email change: %w", err)
+	}
+
+	var change dto.EmailChange
+	if err := json.Unmarshal([]byte(data), &change); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal email change: %w", err)
+	}
+
+	return &change, nil
+}
+
+// reauthenticate checks that the user is present before their recovery address moves. Accounts
+// with a local password prove it with the password. Accounts without one, signed in through SSO or
+// LDAP only, prove it with a session their provider issued within emailChangeSignInWindow.
+func (s *EmailChangeService) reauthenticate(userID string, session *dto.SessionMetadata, password string) error {
+	authProvider, err := s.userRepo.GetUserAuthProvider(userID, common.AuthProviderLocal)
+	if err != nil {
+		return fmt.Errorf("failed to get auth provider: %w", err)
+	}
+	if authProvider == nil || authProvider.PasswordHash == nil {
+		if session == nil || session.Provider == common.AuthProviderLocal || time.Since(session.CreatedAt) > emailChangeSignInWindow {
+			return common.ErrRecentSignInRequired
+		}
+		return nil
+	}
+
+	if !utils.CheckPassword(password, *authProvider.PasswordHash) {
+		return common.ErrIncorrectPassword
+	}
+
+	return nil
+}
+
+func (s *EmailChangeService) notify(recipient, subject, template string, data map[string]interface{}) {
+	if global.UserNotifier == nil {
+		return
+	}
+
+	go func() {
+		if err := global.UserNotifier.Send(recipient, subject, template, data); err != nil {
+			fmt.Printf("Error sending email change notification: %v\n", err)
+		}
+	}()
+}
+
+func emailChangeConfirmKey(tokenHash string) string {
+	return fmt.Sprintf("auth:email_change:confirm:%s", tokenHash)
+}
+
+func emailChangeRevertKey(tokenHash string) string {
+	return fmt.Sprintf("auth:email_change:revert:%s", tokenHash)
+}
+
+func emailChangePendingKey(userID string) string {
+	return fmt.Sprintf("auth:email_change:user:%s", userID)
+}
+
+func emailChangeTTL() time.Duration {
+	if ttl := global.Config.Profile.EmailChangeTTL; ttl > 0 {
+		return ttl
+	}
+	return defaultEmailChangeTTL
+}
+
+func emailRevertWindow() time.Duration {
+	if window := global.Config.Profile.EmailRevertWindow; window > 0 {
+		return window
+	}
+	return defaultEmailRevertWindow
+}
+
+// frontendURL builds a link into the web client carrying a one-time token
+func frontendURL(path, token string) string {
+	return strings.TrimRight(global.Config.Server.FrontendURL, "/") + path + "?token=" + token
+}
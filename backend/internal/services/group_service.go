@@ -0,0 +1,505 @@
+package services
+
+import (
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/repo"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type GroupService struct {
+	groupRepo     repo.GroupRepositoryInterface
+	workspaceRepo repo.WorkspaceRepositoryInterface
+	authCache     AuthCacheInterface
+}
+
+func NewGroupService(groupRepo repo.GroupRepositoryInterface, workspaceRepo repo.WorkspaceRepositoryInterface, authCache AuthCacheInterface) GroupServiceInterface {
+	return &GroupService{
+		groupRepo:     groupRepo,
+		workspaceRepo: workspaceRepo,
+		authCache:     authCache,
+	}
+}
+
+// ListGroups returns the groups of a workspace by name, which archived workspaces still allow
+func (s *GroupService) ListGroups(userID, workspaceID string) ([]dto.GroupResponse, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, common.PermissionGroupRead); err != nil {
+		return nil, err
+	}
+
+	groups, err := s.groupRepo.ListGroups(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.GroupResponse, 0, len(groups))
+	for i := range groups {
+		response = append(response, *toGroupResponse(&groups[i]))
+	}
+	return response, nil
+}
+
+func (s *GroupService) GetGroup(userID, workspaceID, groupID string) (*dto.GroupResponse, error) {
+	_, group, err := s.getReadableGroup(userID, workspaceID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return toGroupResponse(group), nil
+}
+
+// CreateGroup adds a group with its roles. The caller must hold every permission of the roles.
+func (s *GroupService) CreateGroup(userID, workspaceID string, req *dto.CreateGroupRequest) (*dto.GroupResponse, error) {
+	workspace, err := s.getManagedWorkspace(userID, workspaceID, common.PermissionGroupCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if err := s.ensureNameAvailable(workspaceID, name, ""); err != nil {
+		return nil, err
+	}
+
+	group := &models.WorkspaceGroup{
+		WorkspaceID: workspaceID,
+		Name:        name,
+		CreatedBy:   userID,
+	}
+	if description := strings.TrimSpace(req.Description); description != "" {
+		group.Description = &description
+	}
+	roles := make([]*models.WorkspaceRole, 0, len(req.RoleIDs))
+	for _, roleID := range req.RoleIDs {
+		role, err := getAssignableRole(s.workspaceRepo, workspaceID, roleID)
+		if err != nil {
+			return nil, err
+		}
+		if err := authorizeRoleGrant(s.workspaceRepo, s.authCache, workspace, userID, role); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+		group.RoleGrants = append(group.RoleGrants, models.WorkspaceGroupRole{RoleID: role.ID})
+	}
+
+	if err := s.groupRepo.CreateGroup(global.DB, group); err != nil {
+		return nil, err
+	}
+	for i, role := range roles {
+		group.RoleGrants[i].Role = *role
+	}
+
+	s.publish(common.GroupCreatedLog, group, userID)
+
+	return toGroupResponse(group), nil
+}
+
+// UpdateGroup renames a group or changes its description
+func (s *GroupService) UpdateGroup(userID, workspaceID, groupID string, req *dto.UpdateGroupRequest) (*dto.GroupResponse, error) {
+	_, group, err := s.getManagedGroup(userID, workspaceID, groupID, common.PermissionGroupUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name != group.Name {
+			if err := s.ensureNameAvailable(workspaceID, name, group.ID); err != nil {
+				return nil, err
+			}
+			updates["name"] = name
+			group.Name = name
+		}
+	}
+	if req.Description != nil {
+		description := strings.TrimSpace(*req.Description)
+		if description != getStringValue(group.Description) {
+			updates["description"] = stringPtr(description)
+			group.Description = stringPtr(description)
+		}
+	}
+
+	if len(updates) == 0 {
+		return toGroupResponse(group), nil
+	}
+
+	if err := s.groupRepo.UpdateGroup(group.ID, updates); err != nil {
+		return nil, err
+	}
+
+	s.publish(common.GroupUpdatedLog, group, userID)
+
+	return toGroupResponse(group), nil
+}
+
+// DeleteGroup removes a group, taking its roles away from its members. The caller must hold every
+// permission of the roles.
+func (s *GroupService) DeleteGroup(userID, workspaceID, groupID string) error {
+	workspace, group, err := s.getManagedGroup(userID, workspaceID, groupID, common.PermissionGroupDelete)
+	if err != nil {
+		return err
+	}
+	if err := s.authorizeGroupRoles(workspace, userID, group); err != nil {
+		return err
+	}
+
+	members, err := s.groupRepo.ListGroupMembers(group.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.groupRepo.DeleteGroup(group.ID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return common.ErrGroupNotFound
+		}
+		return err
+	}
+
+	userIDs := make([]string, 0, len(members))
+	for _, member := range members {
+		userIDs = append(userIDs, member.UserID)
+	}
+	s.authCache.InvalidateUsers(userIDs...)
+
+	s.publish(common.GroupDeletedLog, group, userID)
+
+	return nil
+}
+
+func (s *GroupService) ListGroupMembers(userID, workspaceID, groupID string) ([]dto.GroupMemberResponse, error) {
+	_, group, err := s.getReadableGroup(userID, workspaceID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.groupRepo.ListGroupMembers(group.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.GroupMemberResponse, 0, len(members))
+	for i := range members {
+		response = append(response, *toGroupMemberResponse(&members[i]))
+	}
+	return response, nil
+}
+
+// AddGroupMember puts an active or suspended member of the workspace in a group, which is a no-op
+// for users already in it. The caller must hold every permission of the group's roles.
+func (s *GroupService) AddGroupMember(userID, workspaceID, groupID, memberID string) error {
+	workspace, group, err := s.getManagedGroup(userID, workspaceID, groupID, common.PermissionGroupUpdate)
+	if err != nil {
+		return err
+	}
+	if err := s.authorizeGroupRoles(workspace, userID, group); err != nil {
+		return err
+	}
+
+	membership, err := s.workspaceRepo.GetWorkspaceMember(workspaceID, memberID)
+	if err != nil {
+		return err
+	}
+	if membership == nil || (membership.Status != models.MembershipStatusActive && membership.Status != models.MembershipStatusSuspended) {
+		return common.ErrMemberNotFound
+	}
+
+	existing, err := s.groupRepo.GetGroupMember(group.ID, memberID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+
+	member := &models.WorkspaceGroupMember{
+		GroupID: group.ID,
+		UserID:  memberID,
+		AddedBy: &userID,
+	}
+	if err := s.groupRepo.AddGroupMember(member); err != nil {
+		return err
+	}
+	s.authCache.InvalidateUsers(memberID)
+
+	s.publishMember(common.GroupMemberAddedLog, group, memberID, userID)
+
+	return nil
+}
+
+// RemoveGroupMember takes a user out of a group. The caller must hold every permission of the
+// group's roles.
+func (s *GroupService) RemoveGroupMember(userID, workspaceID, groupID, memberID string) error {
+	workspace, group, err := s.getManagedGroup(userID, workspaceID, groupID, common.PermissionGroupUpdate)
+	if err != nil {
+		return err
+	}
+	if err := s.authorizeGroupRoles(workspace, userID, group); err != nil {
+		return err
+	}
+
+	if err := s.groupRepo.RemoveGroupMember(group.ID, memberID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return common.ErrGroupMemberNotFound
+		}
+		return err
+	}
+	s.authCache.InvalidateUsers(memberID)
+
+	s.publishMember(common.GroupMemberRemovedLog, group, memberID, userID)
+
+	return nil
+}
+
+// AssignGroupRole gives a role to every member of a group, which is a no-op when the group already
+// has it. The caller must hold every permission of the role.
+func (s *GroupService) AssignGroupRole(userID, workspaceID, groupID, roleID string) (*dto.GroupResponse, error) {
+	workspace, group, err := s.getManagedGroup(userID, workspaceID, groupID, common.PermissionGroupUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := getAssignableRole(s.workspaceRepo, workspaceID, roleID)
+	if err != nil {
+		return nil, err
+	}
+	for _, grant := range group.RoleGrants {
+		if grant.RoleID == role.ID {
+			return toGroupResponse(group), nil
+		}
+	}
+	if err := authorizeRoleGrant(s.workspaceRepo, s.authCache, workspace, userID, role); err != nil {
+		return nil, err
+	}
+
+	if err := s.groupRepo.AddGroupRole(group.ID, role.ID); err != nil {
+		return nil, err
+	}
+	s.invalidateGroupMembers(group.ID)
+
+	group.RoleGrants = append(group.RoleGrants, models.WorkspaceGroupRole{GroupID: group.ID, RoleID: role.ID, Role: *role})
+	s.publish(common.GroupUpdatedLog, group, userID)
+
+	return toGroupResponse(group), nil
+}
+
+// UnassignGroupRole takes a role away from the members of a group. The caller must hold every
+// permission of the role.
+func (s *GroupService) UnassignGroupRole(userID, workspaceID, groupID, roleID string) (*dto.GroupResponse, error) {
+	workspace, group, err := s.getManagedGroup(userID, workspaceID, groupID, common.PermissionGroupUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	index := -1
+	for i, grant := range group.RoleGrants {
+		if grant.RoleID == roleID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, common.ErrWorkspaceRoleNotFound
+	}
+	if err := authorizeRoleGrant(s.workspaceRepo, s.authCache, workspace, userID, &group.RoleGrants[index].Role); err != nil {
+		return nil, err
+	}
+
+	if err := s.groupRepo.RemoveGroupRole(group.ID, roleID); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, common.ErrWorkspaceRoleNotFound
+		}
+		return nil, err
+	}
+	s.invalidateGroupMembers(group.ID)
+
+	group.RoleGrants = append(group.RoleGrants[:index], group.RoleGrants[index+1:]...)
+	s.publish(common.GroupUpdatedLog, group, userID)
+
+	return toGroupResponse(group), nil
+}
+
+// authorizeGroupRoles checks that a user may change who holds the roles of a group
+func (s *GroupService) authorizeGroupRoles(workspace *models.Workspace, userID string, group *models.WorkspaceGroup) error {
+	for i := range group.RoleGrants {
+		role := &group.RoleGrants[i].Role
+		if role.Status != common.ActiveStatus {
+			continue
+		}
+		if err := authorizeRoleGrant(s.workspaceRepo, s.authCache, workspace, userID, role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *GroupService) ensureNameAvailable(workspaceID, name, groupID string) error {
+	if name == "" {
+		return common.ErrValidationFailed
+	}
+
+	existing, err := s.groupRepo.GetGroupByName(workspaceID, name)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != groupID {
+		return common.ErrGroupExists
+	}
+	return nil
+}
+
+func (s *GroupService) getReadableGroup(userID, workspaceID, groupID string) (*models.Workspace, *models.WorkspaceGroup, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, common.PermissionGroupRead); err != nil {
+		return nil, nil, err
+	}
+
+	group, err := s.getGroup(workspaceID, groupID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return workspace, group, nil
+}
+
+func (s *GroupService) getManagedGroup(userID, workspaceID, groupID, permission string) (*models.Workspace, *models.WorkspaceGroup, error) {
+	workspace, err := s.getManagedWorkspace(userID, workspaceID, permission)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	group, err := s.getGroup(workspaceID, groupID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return workspace, group, nil
+}
+
+func (s *GroupService) getGroup(workspaceID, groupID string) (*models.WorkspaceGroup, error) {
+	group, err := s.groupRepo.GetGroup(workspaceID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, common.ErrGroupNotFound
+	}
+	return group, nil
+}
+
+// getManagedWorkspace authorizes a group change, which archived workspaces do not accept
+func (s *GroupService) getManagedWorkspace(userID, workspaceID, permission string) (*models.Workspace, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, permission); err != nil {
+		return nil, err
+	}
+
+	switch workspace.Status {
+	case common.ActiveStatus:
+		return workspace, nil
+	case common.ArchivedStatus:
+		return nil, common.ErrWorkspaceArchived
+	default:
+		return nil, common.ErrWorkspaceInactive
+	}
+}
+
+func (s *GroupService) invalidateGroupMembers(groupID string) {
+	members, err := s.groupRepo.ListGroupMembers(groupID)
+	if err != nil {
+		fmt.Printf("Warning: failed to get members of group %s: %v\n", groupID, err)
+		return
+	}
+
+	userIDs := make([]string, 0, len(members))
+	for _, member := range members {
+		userIDs = append(userIDs, member.UserID)
+	}
+	s.authCache.InvalidateUsers(userIDs...)
+}
+
+func (s *GroupService) publish(event string, group *models.WorkspaceGroup, userID string) {
+	if global.EventTopicPublisher == nil {
+		return
+	}
+
+	roleIDs := make([]string, 0, len(group.RoleGrants))
+	for _, grant := range group.RoleGrants {
+		roleIDs = append(roleIDs, grant.RoleID)
+	}
+	payload := &dto.GroupChangedPayload{
+		GroupID:     group.ID,
+		WorkspaceID: group.WorkspaceID,
+		Name:        group.Name,
+		RoleIDs:     roleIDs,
+		ChangedByID: userID,
+	}
+	go func() {
+		if err := global.EventTopicPublisher.Publish(event, payload); err != nil {
+			fmt.Printf("Error publishing %s event: %v\n", event, err)
+		}
+	}()
+}
+
+func (s *GroupService) publishMember(event string, group *models.WorkspaceGroup, memberID, userID string) {
+	if global.EventTopicPublisher == nil {
+		return
+	}
+
+	payload := &dto.GroupMemberChangedPayload{
+		GroupID:     group.ID,
+		WorkspaceID: group.WorkspaceID,
+		UserID:      memberID,
+		ChangedByID: userID,
+	}
+	go func() {
+		if err := global.EventTopicPublisher.Publish(event, payload); err != nil {
+			fmt.Printf("Error publishing %s event: %v\n", event, err)
+		}
+	}()
+}
+
+func toGroupResponse(group *models.WorkspaceGroup) *dto.GroupResponse {
+	response := &dto.GroupResponse{
+		ID:          group.ID,
+		WorkspaceID: group.WorkspaceID,
+		Name:        group.Name,
+		Description: getStringValue(group.Description),
+		Roles:       make([]dto.GroupRoleResponse, 0, len(group.RoleGrants)),
+		CreatedBy:   group.CreatedBy,
+		CreatedAt:   group.CreatedAt,
+		UpdatedAt:   group.UpdatedAt,
+	}
+	for _, grant := range group.RoleGrants {
+		if grant.Role.Status == common.ActiveStatus {
+			response.Roles = append(response.Roles, dto.GroupRoleResponse{ID: grant.Role.ID, Name: grant.Role.Name})
+		}
+	}
+	return response
+}
+
+func toGroupMemberResponse(member *models.WorkspaceGroupMember) *dto.GroupMemberResponse {
+	response := &dto.GroupMemberResponse{
+		UserID:  member.UserID,
+		Email:   member.User.Email,
+		AddedBy: getStringValue(member.AddedBy),
+		AddedAt: member.CreatedAt,
+	}
+	if profile := member.User.Profile; profile != nil {
+		response.FirstName = profile.FirstName
+		response.LastName = profile.LastName
+		response.DisplayName = getStringValue(profile.DisplayName)
+	}
+	return response
+}
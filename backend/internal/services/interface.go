@@ -3,17 +3,50 @@ package services
 import (
 	"go-backend-v2/internal/dto"
 	"go-backend-v2/internal/models"
+	"io"
+	"time"
 )
 
 type WorkspaceServiceInterface interface {
 	CreateWorkspace(userID string, req *dto.CreateWorkspaceRequest) (*dto.WorkspaceResponse, error)
+
+	// Member facing, each call checks the workspace permissions of userID
+	ListMyWorkspaces(userID string) ([]dto.WorkspaceResponse, error)
+	GetWorkspace(userID, slug string) (*dto.WorkspaceResponse, error)
+	UpdateWorkspace(userID, workspaceID string, req *dto.UpdateWorkspaceRequest) (*dto.WorkspaceResponse, error)
+	RenameSlug(userID, workspaceID string, req *dto.RenameWorkspaceSlugRequest) (*dto.WorkspaceResponse, error)
+	ArchiveWorkspace(userID, workspaceID string) (*dto.WorkspaceResponse, error)
+	UnarchiveWorkspace(userID, workspaceID string) (*dto.WorkspaceResponse, error)
+	GetSettings(userID, workspaceID string) (map[string]interface{}, error)
+	UpdateSettings(userID, workspaceID string, patch map[string]interface{}) (map[string]interface{}, error) // JSON merge patch
+
+	// ResolveWorkspaceID returns the ID of a live workspace given its ID or slug
+	ResolveWorkspaceID(ref string) (string, error)
+
+	// Admin API, the caller must be a super admin
+	ListWorkspaces(query *dto.ListWorkspacesQuery) (*dto.WorkspaceListResponse, error)
+	GetWorkspaceByID(workspaceID string) (*dto.WorkspaceResponse, error)
+	UpdatePlan(userID, workspaceID string, req *dto.UpdateWorkspacePlanRequest) (*dto.WorkspaceResponse, error)
 }
 
 type AuthServiceInterface interface {
-	Signup(req *dto.SignupRequest) error
+	Signup(req *dto.SignupRequest) (*models.User, error)
 	Login(req *dto.LoginRequest) (*dto.LoginResponse, error) // returns login response with tokens
 	Logout(userID, encryptedToken string) error              // logout specific token
 	ValidateToken(token string) (string, error)              // returns userID
+	GetUserState(userID string) (*dto.UserState, error)      // cached status and global role
+
+	// TouchSession validates the Redis session of an access token and records activity on it
+	TouchSession(userID, accessToken, encryptedToken string) (*dto.SessionMetadata, error)
+
+	// IssueSession signs in a user already authenticated by an external provider (SSO, directory, ...)
+	IssueSession(user *models.User, opts *dto.SessionOptions) (*dto.LoginResponse, error)
+
+	// VerifyDPoPProof checks a DPoP proof and returns its key thumbprint, empty when there is no proof
+	VerifyDPoPProof(proof, method, requestURL, accessToken string) (string, error)
+	// CheckDPoPBinding requires a proof signed by the bound key for access tokens bound to a DPoP
+	// key, without ath since the token is carried by the HttpOnly cookie
+	CheckDPoPBinding(accessToken, proof, method, requestURL string) error
 
 	// Redis token operations
 	StoreTokenData(userID, encryptedToken string, tokenData *dto.UserTokenData) error
@@ -22,8 +55,174 @@ type AuthServiceInterface interface {
 	InvalidateUserTokens(userID string) error
 }
 
+// InvitationServiceInterface invites people, registered or not, to join a workspace by email
+type InvitationServiceInterface interface {
+	CreateInvitation(userID, workspaceID string, req *dto.CreateInvitationRequest) (*dto.InvitationResponse, error)
+	ListInvitations(userID, workspaceID, status string) ([]dto.InvitationResponse, error)
+	ResendInvitation(userID, workspaceID, invitationID string) (*dto.InvitationResponse, error)
+	RevokeInvitation(userID, workspaceID, invitationID string) error
+
+	// Link token operations, for the invitee
+	PreviewInvitation(token string) (*dto.InvitationPreview, error)
+	CheckSignupInvitation(token, email string) error
+	AcceptInvitation(userID, token string) (*dto.WorkspaceResponse, error)
+	DeclineInvitation(token string) error
+}
+
+// OwnershipTransferServiceInterface hands a workspace over to another member, who must accept it
+type OwnershipTransferServiceInterface interface {
+	InitiateTransfer(userID, workspaceID string, req *dto.CreateOwnershipTransferRequest) (*dto.OwnershipTransferResponse, error)
+	GetTransfer(userID, workspaceID string) (*dto.OwnershipTransferResponse, error)
+	CancelTransfer(userID, workspaceID string) error
+
+	// Nominee operations
+	AcceptTransfer(userID, workspaceID string) (*dto.WorkspaceResponse, error)
+	DeclineTransfer(userID, workspaceID string) error
+}
+
+// MemberServiceInterface lets workspace members with the member permissions manage the others
+type MemberServiceInterface interface {
+	ListMembers(userID, workspaceID string, query *dto.ListMembersQuery) (*dto.MemberListResponse, error)
+	UpdateMemberRole(userID, workspaceID, memberID string, req *dto.UpdateMemberRoleRequest) (*dto.MemberResponse, error)
+	SuspendMember(userID, workspaceID, memberID string) (*dto.MemberResponse, error)
+	ReactivateMember(userID, workspaceID, memberID string) (*dto.MemberResponse, error)
+	RemoveMember(userID, workspaceID, memberID string) error
+}
+
+// DomainServiceInterface lets workspace admins prove they control email domains, whose users can
+// then join the workspace without an invitation
+type DomainServiceInterface interface {
+	ListDomains(userID, workspaceID string) ([]dto.DomainResponse, error)
+	AddDomain(userID, workspaceID string, req *dto.AddDomainRequest) (*dto.DomainResponse, error)
+	VerifyDomain(userID, workspaceID, domainID string) (*dto.DomainResponse, error) // checks the DNS TXT record
+	RemoveDomain(userID, workspaceID, domainID string) error
+}
+
+// JoinServiceInterface lets users join workspaces without an invitation: at once with a verified
+// email on a verified domain of the workspace, otherwise through a join request members approve
+type JoinServiceInterface interface {
+	ListJoinableWorkspaces(userID string) ([]dto.WorkspaceSummary, error)
+	JoinWorkspace(userID, workspaceRef string) (*dto.JoinWorkspaceResponse, error)
+
+	// Member operations, join requests are listed as members with the pending status
+	ApproveJoinRequest(userID, workspaceID, requesterID string, req *dto.ApproveJoinRequest) (*dto.MemberResponse, error)
+	RejectJoinRequest(userID, workspaceID, requesterID string) error
+}
+
+// RoleServiceInterface manages the roles of a workspace, whose permissions come from the resource catalog
+type RoleServiceInterface interface {
+	ListRoles(userID, workspaceID string) ([]dto.RoleResponse, error)
+	GetRole(userID, workspaceID, roleID string) (*dto.RoleResponse, error)
+	CreateRole(userID, workspaceID string, req *dto.CreateRoleRequest) (*dto.RoleResponse, error)
+	UpdateRole(userID, workspaceID, roleID string, req *dto.UpdateRoleRequest) (*dto.RoleResponse, error)
+	DeleteRole(userID, workspaceID, roleID string) error
+}
+
+// GroupServiceInterface manages the groups of a workspace, whose members hold the roles of the group
+type GroupServiceInterface interface {
+	ListGroups(userID, workspaceID string) ([]dto.GroupResponse, error)
+	GetGroup(userID, workspaceID, groupID string) (*dto.GroupResponse, error)
+	CreateGroup(userID, workspaceID string, req *dto.CreateGroupRequest) (*dto.GroupResponse, error)
+	UpdateGroup(userID, workspaceID, groupID string, req *dto.UpdateGroupRequest) (*dto.GroupResponse, error)
+	DeleteGroup(userID, workspaceID, groupID string) error
+	ListGroupMembers(userID, workspaceID, groupID string) ([]dto.GroupMemberResponse, error)
+	AddGroupMember(userID, workspaceID, groupID, memberID string) error
+	RemoveGroupMember(userID, workspaceID, groupID, memberID string) error
+	AssignGroupRole(userID, workspaceID, groupID, roleID string) (*dto.GroupResponse, error)
+	UnassignGroupRole(userID, workspaceID, groupID, roleID string) (*dto.GroupResponse, error)
+}
+
+// ResourceServiceInterface manages the catalog of resources that permissions refer to, for super admins
+type ResourceServiceInterface interface {
+	ListResources(query *dto.ListResourcesQuery) ([]dto.ResourceResponse, error)
+	GetResource(resourceID string) (*dto.ResourceResponse, error)
+	CreateResource(req *dto.CreateResourceRequest) (*dto.ResourceResponse, error)
+	UpdateResource(resourceID string, req *dto.UpdateResourceRequest) (*dto.ResourceResponse, error)
+	DeleteResource(resourceID string) (*dto.ResourceResponse, error) // deprecates resources still granted by roles
+}
+
+// AuthCacheInterface caches the data read on every authenticated request
+type AuthCacheInterface interface {
+	GetUserState(userID string) (*dto.UserState, error) // nil when the user does not exist
+	InvalidateUsers(userIDs ...string)                  // call after changing a user, membership or role
+}
+
 type UserServiceInterface interface {
 	GetUserWithWorkspaces(userID string) (*models.User, error)
 	GetUserProfile(userID string) (*models.User, error)
-	DeleteUser(userID string) error
+	UpdateProfile(userID string, req *dto.UpdateProfileRequest) (*models.User, error)
+}
+
+// AccountDeletionServiceInterface runs the account lifecycle from deletion request to purge
+type AccountDeletionServiceInterface interface {
+	ScheduleDeletion(userID string) (*time.Time, error) // returns when the account will be purged
+	RestoreAccount(token string) error
+	PurgeDueAccounts()
+}
+
+// AvatarServiceInterface stores uploaded user and workspace avatars
+type AvatarServiceInterface interface {
+	UploadUserAvatar(userID string, data []byte) (*dto.AvatarResponse, error)
+	DeleteUserAvatar(userID string) error
+	UploadWorkspaceAvatar(userID, workspaceID string, data []byte) (*dto.AvatarResponse, error)
+	DeleteWorkspaceAvatar(userID, workspaceID string) error
+	OpenAvatar(name string) (io.ReadCloser, string, error) // returns the image and its content type
+}
+
+// EmailChangeServiceInterface changes the primary email, confirmed by the new address and
+// revertible from the old one
+type EmailChangeServiceInterface interface {
+	RequestChange(userID string, session *dto.SessionMetadata, req *dto.ChangeEmailRequest) error
+	ConfirmChange(token string) error
+	RevertChange(token string) error
+}
+
+// DataExportServiceInterface builds data subject access request archives in the background
+type DataExportServiceInterface interface {
+	RequestExport(userID, format string) (*dto.DataExport, error)
+	GetExport(userID, exportID string) (*dto.DataExport, error) // includes a download link once ready
+	OpenDownload(token string) (io.ReadCloser, *dto.DataExport, error)
+	SweepExpired()
+}
+
+type DeviceServiceInterface interface {
+	RecordLogin(user *models.User, userAgent, ipAddress string) (*models.UserDevice, bool, error) // returns device and whether it is new
+	ListDevices(userID string) ([]models.UserDevice, error)
+	ForgetDevice(userID, deviceID string) error
+}
+
+type SAMLServiceInterface interface {
+	GetConfig(workspaceID string) (*dto.SAMLConfigResponse, error)
+	UpsertConfig(workspaceID string, req *dto.UpsertSAMLConfigRequest) (*dto.SAMLConfigResponse, error)
+	GetMetadata(workspaceRef string) ([]byte, error)
+	StartLogin(workspaceRef, relayState string) (string, error) // returns IdP redirect URL
+	ConsumeResponse(workspaceRef, samlResponse, userAgent, ipAddress string) (*dto.LoginResponse, error)
+}
+
+type LDAPServiceInterface interface {
+	Login(req *dto.LDAPLoginRequest) (*dto.LoginResponse, error)
+}
+
+type SCIMServiceInterface interface {
+	// Token management
+	CreateToken(workspaceID, createdBy string, req *dto.CreateSCIMTokenRequest) (*dto.CreateSCIMTokenResponse, error)
+	ListTokens(workspaceID string) ([]models.WorkspaceSCIMToken, error)
+	RevokeToken(workspaceID, tokenID string) error
+	AuthenticateToken(token string) (*models.WorkspaceSCIMToken, error)
+
+	// Users map to workspace memberships
+	ListUsers(workspaceID string, query *dto.SCIMListQuery) (*dto.SCIMListResponse, error)
+	GetUser(workspaceID, userID string) (*dto.SCIMUser, error)
+	CreateUser(workspaceID string, req *dto.SCIMUser) (*dto.SCIMUser, error)
+	ReplaceUser(workspaceID, userID string, req *dto.SCIMUser) (*dto.SCIMUser, error)
+	PatchUser(workspaceID, userID string, req *dto.SCIMPatchRequest) (*dto.SCIMUser, error)
+	DeleteUser(workspaceID, userID string) error
+
+	// Groups map to workspace roles
+	ListGroups(workspaceID string, query *dto.SCIMListQuery) (*dto.SCIMListResponse, error)
+	GetGroup(workspaceID, groupID string) (*dto.SCIMGroup, error)
+	CreateGroup(workspaceID string, req *dto.SCIMGroup) (*dto.SCIMGroup, error)
+	ReplaceGroup(workspaceID, groupID string, req *dto.SCIMGroup) (*dto.SCIMGroup, error)
+	PatchGroup(workspaceID, groupID string, req *dto.SCIMPatchRequest) (*dto.SCIMGroup, error)
+	DeleteGroup(workspaceID, groupID string) error
 }
@@ -0,0 +1,525 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/pkg/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultInvitationTTL            = 7 * 24 * time.Hour
+	defaultInvitationResendCooldown = time.Minute
+)
+
+type InvitationService struct {
+	invitationRepo repo.InvitationRepositoryInterface
+	workspaceRepo  repo.WorkspaceRepositoryInterface
+	userRepo       repo.UserRepositoryInterface
+	authCache      AuthCacheInterface
+}
+
+func NewInvitationService(invitationRepo repo.InvitationRepositoryInterface, workspaceRepo repo.WorkspaceRepositoryInterface, userRepo repo.UserRepositoryInterface, authCache AuthCacheInterface) InvitationServiceInterface {
+	return &InvitationService{
+		invitationRepo: invitationRepo,
+		workspaceRepo:  workspaceRepo,
+		userRepo:       userRepo,
+		authCache:      authCache,
+	}
+}
+
+// CreateInvitation invites an email address and mails it a signed link. The inviter needs the
+// member:invite permission and every permission of the role handed out.
+func (s *InvitationService) CreateInvitation(userID, workspaceID string, req *dto.CreateInvitationRequest) (*dto.InvitationResponse, error) {
+	workspace, err := s.getInvitingWorkspace(userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.resolveRole(workspaceID, req.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeRoleGrant(s.workspaceRepo, s.authCache, workspace, userID, role); err != nil {
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	invitee, err := s.userRepo.GetUserByEmail(email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if invitee != nil {
+		membership, err := s.workspaceRepo.GetMembership(invitee.ID, workspaceID)
+		if err != nil {
+			return nil, err
+		}
+		if membership != nil && membership.Status == models.MembershipStatusActive {
+			return nil, common.ErrAlreadyWorkspaceMember
+		}
+	}
+
+	pending, err := s.invitationRepo.GetPendingInvitation(workspaceID, email)
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		if pending.ExpiresAt.After(time.Now()) {
+			return nil, common.ErrInvitationPending
+		}
+		if err := s.invitationRepo.UpdateInvitation(pending.ID, map[string]interface{}{"status": models.InvitationStatusExpired}); err != nil {
+			return nil, err
+		}
+	}
+
+	nonce, err := utils.GenerateSecureToken("", 16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	invitation := &models.WorkspaceInvitation{
+		WorkspaceID: workspaceID,
+		Email:       email,
+		RoleID:      role.ID,
+		InvitedBy:   userID,
+		Status:      models.InvitationStatusPending,
+		TokenNonce:  nonce,
+		ExpiresAt:   now.Add(invitationTTL()),
+		SentAt:      now,
+		SendCount:   1,
+	}
+	if err := s.invitationRepo.CreateInvitation(invitation); err != nil {
+		return nil, err
+	}
+	invitation.Role = *role
+
+	if err := s.send(invitation, workspace, userID, invitee != nil); err != nil {
+		return nil, err
+	}
+
+	if global.EventTopicPublisher != nil {
+		payload := &dto.MembershipInvitedPayload{
+			InvitationID: invitation.ID,
+			WorkspaceID:  workspaceID,
+			Email:        email,
+			RoleID:       role.ID,
+			InvitedByID:  userID,
+		}
+		go func() {
+			if err := global.EventTopicPublisher.Publish(common.MembershipInvitedLog, payload); err != nil {
+				fmt.Printf("Error publishing membership invited event: %v\n", err)
+			}
+		}()
+	}
+
+	return toInvitationResponse(invitation), nil
+}
+
+// ListInvitations returns the invitations of a workspace, optionally only those in one status
+func (s *InvitationService) ListInvitations(userID, workspaceID, status string) ([]dto.InvitationResponse, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, common.PermissionMemberInvite); err != nil {
+		return nil, err
+	}
+
+	// Expired invitations are stored as pending, so both are read and told apart afterwards
+	storedStatus := status
+	if status == models.InvitationStatusExpired {
+		storedStatus = ""
+	}
+
+	invitations, err := s.invitationRepo.ListInvitations(workspaceID, storedStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.InvitationResponse, 0, len(invitations))
+	for i := range invitations {
+		response := toInvitationResponse(&invitations[i])
+		if status == "" || response.Status == status {
+			result = append(result, *response)
+		}
+	}
+
+	return result, nil
+}
+
+// ResendInvitation mails a fresh link that is valid for a full TTL again. Links sent before stop
+// working.
+func (s *InvitationService) ResendInvitation(userID, workspaceID, invitationID string) (*dto.InvitationResponse, error) {
+	workspace, err := s.getInvitingWorkspace(userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.invitationRepo.GetInvitation(workspaceID, invitationID)
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil {
+		return nil, common.ErrInvitationNotFound
+	}
+	if invitation.Status != models.InvitationStatusPending {
+		return nil, common.ErrInvitationInvalid
+	}
+	if time.Since(invitation.SentAt) < invitationResendCooldown() {
+		return nil, common.ErrInvitationResendTooSoon
+	}
+
+	nonce, err := utils.GenerateSecureToken("", 16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	invitation.TokenNonce = nonce
+	invitation.ExpiresAt = now.Add(invitationTTL())
+	invitation.SentAt = now
+	invitation.SendCount++
+
+	err = s.invitationRepo.UpdateInvitation(invitation.ID, map[string]interface{}{
+		"token_nonce": invitation.TokenNonce,
+		"expires_at":  invitation.ExpiresAt,
+		"sent_at":     invitation.SentAt,
+		"send_count":  invitation.SendCount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	registered, err := s.userRepo.ExistsByEmail(invitation.Email)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.send(invitation, workspace, userID, registered); err != nil {
+		return nil, err
+	}
+
+	return toInvitationResponse(invitation), nil
+}
+
+// RevokeInvitation withdraws a pending invitation
+func (s *InvitationService) RevokeInvitation(userID, workspaceID, invitationID string) error {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return err
+	}
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, common.PermissionMemberInvite); err != nil {
+		return err
+	}
+
+	invitation, err := s.invitationRepo.GetInvitation(workspaceID, invitationID)
+	if err != nil {
+		return err
+	}
+	if invitation == nil {
+		return common.ErrInvitationNotFound
+	}
+
+	if err := s.invitationRepo.RespondToInvitation(global.DB, invitation.ID, models.InvitationStatusRevoked, nil); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrInvitationInvalid
+		}
+		return err
+	}
+
+	return nil
+}
+
+// PreviewInvitation describes the invitation behind a link, so the client can offer to sign in
+// or to sign up
+func (s *InvitationService) PreviewInvitation(token string) (*dto.InvitationPreview, error) {
+	invitation, err := s.resolveToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	registered, err := s.userRepo.ExistsByEmail(invitation.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.InvitationPreview{
+		WorkspaceID:        invitation.WorkspaceID,
+		WorkspaceName:      invitation.Workspace.Name,
+		WorkspaceSlug:      invitation.Workspace.Slug,
+		WorkspaceAvatarURL: getStringValue(invitation.Workspace.AvatarURL),
+		Email:              invitation.Email,
+		RoleName:           invitation.Role.Name,
+		InviterName:        userDisplayName(&invitation.Inviter),
+		ExpiresAt:          invitation.ExpiresAt,
+		Registered:         registered,
+	}, nil
+}
+
+// CheckSignupInvitation makes sure an invitation can be accepted by an account about to be
+// created with email, before the account exists
+func (s *InvitationService) CheckSignupInvitation(token, email string) error {
+	invitation, err := s.resolveToken(token)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(invitation.Email, strings.TrimSpace(email)) {
+		return common.ErrInvitationEmailMismatch
+	}
+	return nil
+}
+
+// AcceptInvitation joins the workspace with the invited role. The account must use the invited
+// email, which the link proves the user can read, so the email counts as verified from then on.
+func (s *InvitationService) AcceptInvitation(userID, token string) (*dto.WorkspaceResponse, error) {
+	invitation, err := s.resolveToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, common.ErrUserNotFound
+	}
+	if user.Status != common.UserStatusActive {
+		return nil, common.ErrUserInactive
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, common.ErrInvitationEmailMismatch
+	}
+
+	if invitation.Workspace.Status != common.ActiveStatus {
+		return nil, common.ErrWorkspaceInactive
+	}
+
+	// The invited role may have been deleted since, members then join with the default one
+	role := &invitation.Role
+	if role.Status != common.ActiveStatus {
+		if role, err = resolveDefaultRole(global.DB, s.workspaceRepo, invitation.WorkspaceID); err != nil {
+			return nil, err
+		}
+	}
+
+	membership, err := s.workspaceRepo.GetMembership(userID, invitation.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if membership != nil {
+		switch membership.Status {
+		case models.MembershipStatusActive:
+			return nil, common.ErrAlreadyWorkspaceMember
+		case models.MembershipStatusSuspended:
+			return nil, common.ErrMembershipInactive
+		}
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.invitationRepo.RespondToInvitation(tx, invitation.ID, models.InvitationStatusAccepted, &userID); err != nil {
+			return err
+		}
+		if membership != nil {
+			if err := reserveMemberSeat(tx, s.workspaceRepo, invitation.WorkspaceID); err != nil {
+				return err
+			}
+			return s.workspaceRepo.RejoinMembership(tx, membership.ID, role.ID, &invitation.InvitedBy)
+		}
+		_, err := addWorkspaceMember(tx, s.workspaceRepo, invitation.WorkspaceID, userID, role.ID, &invitation.InvitedBy)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.ErrInvitationInvalid
+		}
+		return nil, err
+	}
+
+	if user.EmailVerifiedAt == nil {
+		if err := s.userRepo.UpdateUser(userID, map[string]interface{}{"email_verified_at": time.Now()}); err != nil {
+			fmt.Printf("Warning: failed to mark email verified: %v\n", err)
+		}
+	}
+
+	s.authCache.InvalidateUsers(userID)
+
+	if global.EventTopicPublisher != nil {
+		payload := &dto.MembershipJoinedPayload{
+			UserID:      userID,
+			WorkspaceID: invitation.WorkspaceID,
+			RoleID:      role.ID,
+			InvitedByID: invitation.InvitedBy,
+			Source:      common.MembershipSourceInvitation,
+		}
+		go func() {
+			if err := global.EventTopicPublisher.Publish(common.MembershipJoinedLog, payload); err != nil {
+				fmt.Printf("Error publishing membership joined event: %v\n", err)
+			}
+		}()
+	}
+
+	workspace := toWorkspaceResponse(&invitation.Workspace)
+	workspace.Role = role.Name
+	return workspace, nil
+}
+
+// DeclineInvitation turns an invitation down. The link is the credential, so no sign in is needed.
+func (s *InvitationService) DeclineInvitation(token string) error {
+	invitation, err := s.resolveToken(token)
+	if err != nil {
+		return err
+	}
+
+	if err := s.invitationRepo.RespondToInvitation(global.DB, invitation.ID, models.InvitationStatusDeclined, nil); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrInvitationInvalid
+		}
+		return err
+	}
+
+	return nil
+}
+
+// resolveToken returns the pending invitation a link token was issued for
+func (s *InvitationService) resolveToken(token string) (*models.WorkspaceInvitation, error) {
+	claims, err := utils.ParseInvitationToken(token)
+	if err != nil {
+		return nil, common.ErrInvitationInvalid
+	}
+
+	invitation, err := s.invitationRepo.GetInvitationWithDetails(claims.InvitationID)
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil ||
+		invitation.TokenNonce != claims.ID ||
+		invitation.Status != models.InvitationStatusPending ||
+		!invitation.ExpiresAt.After(time.Now()) ||
+		invitation.Workspace.Status == common.DeletedStatus {
+		return nil, common.ErrInvitationInvalid
+	}
+
+	return invitation, nil
+}
+
+// getInvitingWorkspace returns a workspace the user may invite to, which must be active
+func (s *InvitationService) getInvitingWorkspace(userID, workspaceID string) (*models.Workspace, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, common.PermissionMemberInvite); err != nil {
+		return nil, err
+	}
+
+	switch workspace.Status {
+	case common.ActiveStatus:
+		return workspace, nil
+	case common.ArchivedStatus:
+		return nil, common.ErrWorkspaceArchived
+	default:
+		return nil, common.ErrWorkspaceInactive
+	}
+}
+
+// resolveRole returns the requested active role of the workspace, or its member role
+func (s *InvitationService) resolveRole(workspaceID, roleID string) (*models.WorkspaceRole, error) {
+	if roleID == "" {
+		return resolveDefaultRole(global.DB, s.workspaceRepo, workspaceID)
+	}
+	return getAssignableRole(s.workspaceRepo, workspaceID, roleID)
+}
+
+// send mails the invitation link. Unregistered addresses are led through signup by the client.
+func (s *InvitationService) send(invitation *models.WorkspaceInvitation, workspace *models.Workspace, inviterID string, registered bool) error {
+	token, err := utils.GenerateInvitationToken(invitation.ID, invitation.TokenNonce, invitation.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	if global.UserNotifier == nil {
+		return nil
+	}
+
+	inviterName := ""
+	if inviter, err := s.userRepo.GetUserWithProfile(inviterID); err == nil && inviter != nil {
+		inviterName = userDisplayName(inviter)
+	}
+
+	data := map[string]interface{}{
+		"workspace_name": workspace.Name,
+		"inviter_name":   inviterName,
+		"role_name":      invitation.Role.Name,
+		"accept_url":     frontendURL("/invitations/accept", token),
+		"expires_at":     invitation.ExpiresAt,
+		"registered":     registered,
+	}
+	recipient := invitation.Email
+	subject := fmt.Sprintf("You have been invited to join %s", workspace.Name)
+	go func() {
+		if err := global.UserNotifier.Send(recipient, subject, common.NotificationTemplateWorkspaceInvite, data); err != nil {
+			fmt.Printf("Error sending workspace invitation: %v\n", err)
+		}
+	}()
+
+	return nil
+}
+
+func toInvitationResponse(invitation *models.WorkspaceInvitation) *dto.InvitationResponse {
+	status := invitation.Status
+	if status == models.InvitationStatusPending && !invitation.ExpiresAt.After(time.Now()) {
+		status = models.InvitationStatusExpired
+	}
+
+	return &dto.InvitationResponse{
+		ID:          invitation.ID,
+		WorkspaceID: invitation.WorkspaceID,
+		Email:       invitation.Email,
+		RoleID:      invitation.RoleID,
+		RoleName:    invitation.Role.Name,
+		InvitedBy:   invitation.InvitedBy,
+		Status:      status,
+		ExpiresAt:   invitation.ExpiresAt,
+		SentAt:      invitation.SentAt,
+		SendCount:   invitation.SendCount,
+		RespondedAt: invitation.RespondedAt,
+		CreatedAt:   invitation.CreatedAt,
+	}
+}
+
+// userDisplayName returns the name a user is shown with to others, falling back to the email
+func userDisplayName(user *models.User) string {
+	if user.Profile != nil {
+		if name := getStringValue(user.Profile.DisplayName); name != "" {
+			return name
+		}
+		if name := strings.TrimSpace(user.Profile.FirstName + " " + user.Profile.LastName); name != "" {
+			return name
+		}
+	}
+	return user.Email
+}
+
+func invitationTTL() time.Duration {
+	if ttl := global.Config.Invitation.TTL; ttl > 0 {
+		return ttl
+	}
+	return defaultInvitationTTL
+}
+
+func invitationResendCooldown() time.Duration {
+	if cooldown := global.Config.Invitation.ResendCooldown; cooldown > 0 {
+		return cooldown
+	}
+	return defaultInvitationResendCooldown
+}
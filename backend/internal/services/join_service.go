@@ -0,0 +1,380 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+type JoinService struct {
+	workspaceRepo repo.WorkspaceRepositoryInterface
+	domainRepo    repo.DomainRepositoryInterface
+	userRepo      repo.UserRepositoryInterface
+	authCache     AuthCacheInterface
+}
+
+func NewJoinService(workspaceRepo repo.WorkspaceRepositoryInterface, domainRepo repo.DomainRepositoryInterface, userRepo repo.UserRepositoryInterface, authCache AuthCacheInterface) JoinServiceInterface {
+	return &JoinService{
+		workspaceRepo: workspaceRepo,
+		domainRepo:    domainRepo,
+		userRepo:      userRepo,
+		authCache:     authCache,
+	}
+}
+
+// ListJoinableWorkspaces returns the active workspaces that verified the email domain of the
+// user and that the user is not yet a member of
+func (s *JoinService) ListJoinableWorkspaces(userID string) ([]dto.WorkspaceSummary, error) {
+	user, err := s.getActiveUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.EmailVerifiedAt == nil {
+		return nil, common.ErrEmailNotVerified
+	}
+
+	summaries := []dto.WorkspaceSummary{}
+	domain := utils.EmailDomain(user.Email)
+	if domain == "" {
+		return summaries, nil
+	}
+
+	workspaces, err := s.domainRepo.GetWorkspacesWithVerifiedDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	memberships, err := s.workspaceRepo.GetUserMemberships(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	joined := make(map[string]bool, len(memberships))
+	for _, membership := range memberships {
+		if membership.Status == models.MembershipStatusActive || membership.Status == models.MembershipStatusSuspended {
+			joined[membership.WorkspaceID] = true
+		}
+	}
+
+	for i := range workspaces {
+		if !joined[workspaces[i].ID] {
+			summaries = append(summaries, *toWorkspaceSummary(&workspaces[i]))
+		}
+	}
+	return summaries, nil
+}
+
+// JoinWorkspace adds the user to a workspace with its default role when the workspace verified the
+// domain of the user's verified email. Other users file a join request instead, a pending
+// membership that members with the member:invite permission approve or reject.
+func (s *JoinService) JoinWorkspace(userID, workspaceRef string) (*dto.JoinWorkspaceResponse, error) {
+	user, err := s.getActiveUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace, err := resolveLiveWorkspace(s.workspaceRepo, workspaceRef)
+	if err != nil {
+		return nil, err
+	}
+	switch workspace.Status {
+	case common.ActiveStatus:
+	case common.ArchivedStatus:
+		return nil, common.ErrWorkspaceArchived
+	default:
+		return nil, common.ErrWorkspaceInactive
+	}
+
+	membership, err := s.workspaceRepo.GetMembership(userID, workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+	if membership != nil {
+		switch membership.Status {
+		case models.MembershipStatusActive:
+			return nil, common.ErrAlreadyWorkspaceMember
+		case models.MembershipStatusSuspended:
+			return nil, common.ErrMembershipInactive
+		}
+	}
+
+	domainMatch, err := s.hasVerifiedDomain(user, workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+	if domainMatch {
+		return s.join(workspace, userID, membership)
+	}
+
+	if membership != nil {
+		switch membership.Status {
+		case models.MembershipStatusPending:
+			return nil, common.ErrJoinRequestPending
+		case models.MembershipStatusRejected:
+			return nil, common.ErrJoinRequestRejected
+		default:
+			return nil, common.ErrMembershipInactive
+		}
+	}
+	return s.requestToJoin(workspace, userID)
+}
+
+// ApproveJoinRequest activates a pending membership, with the requested role or else the
+// workspace default role. The approver needs every permission of the role handed out.
+func (s *JoinService) ApproveJoinRequest(userID, workspaceID, requesterID string, req *dto.ApproveJoinRequest) (*dto.MemberResponse, error) {
+	workspace, membership, err := s.getJoinRequest(userID, workspaceID, requesterID)
+	if err != nil {
+		return nil, err
+	}
+
+	var role *models.WorkspaceRole
+	if req.RoleID != "" {
+		role, err = getAssignableRole(s.workspaceRepo, workspaceID, req.RoleID)
+	} else {
+		role, err = resolveDefaultRole(global.DB, s.workspaceRepo, workspaceID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeRoleGrant(s.workspaceRepo, s.authCache, workspace, userID, role); err != nil {
+		return nil, err
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		return activatePendingMembership(tx, s.workspaceRepo, membership, role.ID)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.ErrJoinRequestNotFound
+		}
+		return nil, err
+	}
+
+	s.authCache.InvalidateUsers(requesterID)
+	s.publishJoined(requesterID, workspaceID, role.ID, userID, common.MembershipSourceJoinRequest)
+
+	if global.UserNotifier != nil {
+		data := map[string]interface{}{
+			"workspace_name": workspace.Name,
+			"workspace_slug": workspace.Slug,
+			"role_name":      role.Name,
+		}
+		recipient := membership.User.Email
+		subject := fmt.Sprintf("You have joined %s", workspace.Name)
+		go func() {
+			if err := global.UserNotifier.Send(recipient, subject, common.NotificationTemplateJoinApproved, data); err != nil {
+				fmt.Printf("Error sending join request approval notification: %v\n", err)
+			}
+		}()
+	}
+
+	membership.Status = models.MembershipStatusActive
+	membership.RoleID = role.ID
+	membership.Role = *role
+	return toMemberResponse(membership, workspace), nil
+}
+
+// RejectJoinRequest turns a join request down. The membership stays rejected, so the user cannot
+// file the request again, but can still be invited.
+func (s *JoinService) RejectJoinRequest(userID, workspaceID, requesterID string) error {
+	_, membership, err := s.getJoinRequest(userID, workspaceID, requesterID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.workspaceRepo.RejectPendingMembership(membership.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrJoinRequestNotFound
+		}
+		return err
+	}
+
+	if global.EventTopicPublisher != nil {
+		payload := &dto.MembershipStatusChangedPayload{
+			UserID:      requesterID,
+			WorkspaceID: workspaceID,
+			OldStatus:   models.MembershipStatusPending,
+			NewStatus:   models.MembershipStatusRejected,
+			ChangedByID: userID,
+			Source:      common.MembershipSourceJoinRequest,
+		}
+		go func() {
+			if err := global.EventTopicPublisher.Publish(common.MembershipStatusChangedLog, payload); err != nil {
+				fmt.Printf("Error publishing membership status changed event: %v\n", err)
+			}
+		}()
+	}
+
+	return nil
+}
+
+// join makes the user an active member with the default role, reusing a former membership
+func (s *JoinService) join(workspace *models.Workspace, userID string, membership *models.UserWorkspaceMembership) (*dto.JoinWorkspaceResponse, error) {
+	var role *models.WorkspaceRole
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if role, err = resolveDefaultRole(tx, s.workspaceRepo, workspace.ID); err != nil {
+			return err
+		}
+
+		switch {
+		case membership == nil:
+			_, err = addWorkspaceMember(tx, s.workspaceRepo, workspace.ID, userID, role.ID, nil)
+			return err
+		case membership.Status == models.MembershipStatusPending:
+			return activatePendingMembership(tx, s.workspaceRepo, membership, role.ID)
+		default:
+			if err := reserveMemberSeat(tx, s.workspaceRepo, workspace.ID); err != nil {
+				return err
+			}
+			return s.workspaceRepo.RejoinMembership(tx, membership.ID, role.ID, nil)
+		}
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.ErrAlreadyWorkspaceMember
+		}
+		return nil, err
+	}
+
+	s.authCache.InvalidateUsers(userID)
+	s.publishJoined(userID, workspace.ID, role.ID, "", common.MembershipSourceDomain)
+
+	return &dto.JoinWorkspaceResponse{
+		Status:    models.MembershipStatusActive,
+		Role:      role.Name,
+		Workspace: toWorkspaceSummary(workspace),
+	}, nil
+}
+
+// requestToJoin files a join request as a pending membership with the default role, which
+// approvers may change. Pending memberships grant no access and take no seat.
+func (s *JoinService) requestToJoin(workspace *models.Workspace, userID string) (*dto.JoinWorkspaceResponse, error) {
+	role, err := resolveDefaultRole(global.DB, s.workspaceRepo, workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	membership := &models.UserWorkspaceMembership{
+		UserID:      userID,
+		WorkspaceID: workspace.ID,
+		RoleID:      role.ID,
+		Status:      models.MembershipStatusPending,
+	}
+	if err := s.workspaceRepo.CreateMembership(global.DB, membership); err != nil {
+		return nil, fmt.Errorf("failed to create join request: %w", err)
+	}
+
+	if global.EventTopicPublisher != nil {
+		payload := &dto.MembershipRequestedPayload{
+			UserID:      userID,
+			WorkspaceID: workspace.ID,
+		}
+		go func() {
+			if err := global.EventTopicPublisher.Publish(common.MembershipRequestedLog, payload); err != nil {
+				fmt.Printf("Error publishing membership requested event: %v\n", err)
+			}
+		}()
+	}
+
+	return &dto.JoinWorkspaceResponse{
+		Status:    models.MembershipStatusPending,
+		Workspace: toWorkspaceSummary(workspace),
+	}, nil
+}
+
+// hasVerifiedDomain reports whether the user's email is verified and on a verified domain of the workspace
+func (s *JoinService) hasVerifiedDomain(user *models.User, workspaceID string) (bool, error) {
+	if user.EmailVerifiedAt == nil {
+		return false, nil
+	}
+	domain := utils.EmailDomain(user.Email)
+	if domain == "" {
+		return false, nil
+	}
+
+	verified, err := s.domainRepo.GetVerifiedDomain(workspaceID, domain)
+	if err != nil {
+		return false, err
+	}
+	return verified != nil, nil
+}
+
+// getJoinRequest returns the pending membership of a user in an active workspace where the
+// caller holds the member:invite permission
+func (s *JoinService) getJoinRequest(userID, workspaceID, requesterID string) (*models.Workspace, *models.UserWorkspaceMembership, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, common.PermissionMemberInvite); err != nil {
+		return nil, nil, err
+	}
+
+	switch workspace.Status {
+	case common.ActiveStatus:
+	case common.ArchivedStatus:
+		return nil, nil, common.ErrWorkspaceArchived
+	default:
+		return nil, nil, common.ErrWorkspaceInactive
+	}
+
+	membership, err := s.workspaceRepo.GetWorkspaceMember(workspaceID, requesterID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if membership == nil || membership.Status != models.MembershipStatusPending {
+		return nil, nil, common.ErrJoinRequestNotFound
+	}
+
+	return workspace, membership, nil
+}
+
+func (s *JoinService) getActiveUser(userID string) (*models.User, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, common.ErrUserNotFound
+	}
+	if user.Status != common.UserStatusActive {
+		return nil, common.ErrUserInactive
+	}
+	return user, nil
+}
+
+func (s *JoinService) publishJoined(userID, workspaceID, roleID, approvedByID, source string) {
+	if global.EventTopicPublisher == nil {
+		return
+	}
+
+	payload := &dto.MembershipJoinedPayload{
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		RoleID:      roleID,
+		InvitedByID: approvedByID,
+		Source:      source,
+	}
+	go func() {
+		if err := global.EventTopicPublisher.Publish(common.MembershipJoinedLog, payload); err != nil {
+			fmt.Printf("Error publishing membership joined event: %v\n", err)
+		}
+	}()
+}
+
+func toWorkspaceSummary(workspace *models.Workspace) *dto.WorkspaceSummary {
+	return &dto.WorkspaceSummary{
+		ID:          workspace.ID,
+		Name:        workspace.Name,
+		Slug:        workspace.Slug,
+		Description: getStringValue(workspace.Description),
+		AvatarURL:   getStringValue(workspace.AvatarURL),
+	}
+}
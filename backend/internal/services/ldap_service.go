@@ -0,0 +1,310 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/pkg/setting"
+	"go-backend-v2/pkg/utils"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+type LDAPService struct {
+	userRepo      repo.UserRepositoryInterface
+	workspaceRepo repo.WorkspaceRepositoryInterface
+	authService   AuthServiceInterface
+	authCache     AuthCacheInterface
+}
+
+func NewLDAPService(userRepo repo.UserRepositoryInterface, workspaceRepo repo.WorkspaceRepositoryInterface, authService AuthServiceInterface, authCache AuthCacheInterface) LDAPServiceInterface {
+	return &LDAPService{
+		userRepo:      userRepo,
+		workspaceRepo: workspaceRepo,
+		authService:   authService,
+		authCache:     authCache,
+	}
+}
+
+// Login verifies the credentials against the configured directory and signs the matching local
+// account in, provisioning it on first use
+func (s *LDAPService) Login(req *dto.LDAPLoginRequest) (*dto.LoginResponse, error) {
+	config := global.Config.LDAP
+	if !config.Enabled {
+		return nil, common.ErrLDAPDisabled
+	}
+
+	dpopJKT, err := s.authService.VerifyDPoPProof(req.DPoPProof, http.MethodPost, req.RequestURL, "")
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := utils.AuthenticateLDAP(ldapOptions(&config), req.Username, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrLDAPInvalidCredentials),
+			errors.Is(err, utils.ErrLDAPUserNotFound),
+			errors.Is(err, utils.ErrLDAPAmbiguousUser):
+			return nil, common.ErrInvalidCredentials
+		default:
+			fmt.Printf("Warning: LDAP authentication failed: %v\n", err)
+			return nil, common.ErrLDAPUnavailable
+		}
+	}
+
+	if identity.Email == "" {
+		return nil, common.ErrLDAPMissingEmail
+	}
+
+	user, err := s.provisionUser(&config, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Status != common.UserStatusActive {
+		return nil, common.ErrUserInactive
+	}
+
+	s.applyGroupMappings(&config, user.ID, identity.Groups)
+
+	return s.authService.IssueSession(user, &dto.SessionOptions{
+		Provider:   common.AuthProviderLDAP,
+		UserAgent:  req.UserAgent,
+		IPAddress:  req.IPAddress,
+		RememberMe: req.RememberMe,
+		DPoPJKT:    dpopJKT,
+	})
+}
+
+// provisionUser resolves the local account of a directory entry, which is identified by its DN
+func (s *LDAPService) provisionUser(config *setting.LDAP, identity *utils.LDAPIdentity) (*models.User, error) {
+	link, err := s.userRepo.GetAuthProviderByProviderUserID(common.AuthProviderLDAP, identity.DN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get LDAP identity: %w", err)
+	}
+
+	var user *models.User
+	if link != nil {
+		user, err = s.userRepo.GetUserByID(link.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+	}
+
+	if user == nil {
+		user, err = s.userRepo.GetUserByEmail(identity.Email)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+
+		if user != nil {
+			if !config.AllowAccountLinking {
+				return nil, common.ErrLDAPAccountConflict
+			}
+
+			if err := s.userRepo.CreateAuthProvider(s.ldapAuthProvider(user.ID, identity)); err != nil {
+				return nil, fmt.Errorf("failed to link LDAP identity: %w", err)
+			}
+		}
+	}
+
+	if user == nil {
+		return s.createUser(identity)
+	}
+
+	s.syncProfile(user.ID, identity)
+
+	return user, nil
+}
+
+func (s *LDAPService) createUser(identity *utils.LDAPIdentity) (*models.User, error) {
+	firstName, lastName := deriveProfileNames(identity.FirstName, identity.LastName, identity.DisplayName, identity.Email)
+
+	user := &models.User{
+		Email:      identity.Email,
+		GlobalRole: common.GlobalRoleCustomer,
+		Status:     common.UserStatusActive,
+	}
+
+	profile := &models.UserProfile{
+		FirstName: firstName,
+		LastName:  lastName,
+		Timezone:  "UTC",
+		Locale:    "en",
+	}
+	if identity.DisplayName != "" {
+		profile.DisplayName = &identity.DisplayName
+	}
+
+	authProvider := s.ldapAuthProvider("", identity)
+	authProvider.IsPrimary = true
+
+	err := global.DB.Transaction(func(tx *gorm.DB) error {
+		return s.userRepo.CreateUserWithAuth(tx, user, profile, authProvider)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to provision LDAP user: %w", err)
+	}
+
+	if global.EventTopicPublisher != nil {
+		payload := &dto.UserProvisionedPayload{
+			UserID:   user.ID,
+			Provider: common.AuthProviderLDAP,
+		}
+		go func() {
+			if err := global.EventTopicPublisher.Publish(common.UserProvisionedLog, payload); err != nil {
+				fmt.Printf("Error publishing user provisioned event: %v\n", err)
+			}
+		}()
+	}
+
+	return user, nil
+}
+
+// applyGroupMappings grants the workspace roles configured for the user's directory groups. The
+// first matching mapping wins for each workspace. Memberships that were suspended or removed by a
+// workspace administrator are left alone, and mapping failures never block the login.
+func (s *LDAPService) applyGroupMappings(config *setting.LDAP, userID string, groups []string) {
+	handled := make(map[string]bool)
+
+	for _, mapping := range config.GroupMappings {
+		if handled[mapping.WorkspaceID] || !ldapHasGroup(groups, mapping.Group) {
+			continue
+		}
+		handled[mapping.WorkspaceID] = true
+
+		if err := s.applyGroupMapping(userID, mapping); err != nil {
+			fmt.Printf("Warning: failed to apply LDAP group mapping %s: %v\n", mapping.Group, err)
+		}
+	}
+
+	if len(handled) > 0 {
+		s.authCache.InvalidateUsers(userID)
+	}
+}
+
+func (s *LDAPService) applyGroupMapping(userID string, mapping setting.LDAPGroupMapping) error {
+	workspace, err := s.workspaceRepo.GetWorkspaceByID(mapping.WorkspaceID)
+	if err != nil {
+		return fmt.Errorf("failed to get workspace: %w", err)
+	}
+	if workspace == nil {
+		return common.ErrWorkspaceNotFound
+	}
+	if workspace.Status != common.ActiveStatus {
+		return common.ErrWorkspaceInactive
+	}
+
+	role, err := s.workspaceRepo.GetWorkspaceRoleByName(workspace.ID, mapping.Role)
+	if err != nil {
+		return fmt.Errorf("failed to get workspace role: %w", err)
+	}
+	if role == nil && mapping.Role == common.WorkspaceRoleMember {
+		role, err = resolveDefaultRole(global.DB, s.workspaceRepo, workspace.ID)
+		if err != nil {
+			return err
+		}
+	}
+	if role == nil || role.Status != common.ActiveStatus {
+		return common.ErrWorkspaceRoleNotFound
+	}
+
+	membership, err := s.workspaceRepo.GetMembership(userID, workspace.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get membership: %w", err)
+	}
+
+	if membership == nil {
+		return global.DB.Transaction(func(tx *gorm.DB) error {
+			_, err := addWorkspaceMember(tx, s.workspaceRepo, workspace.ID, userID, role.ID, nil)
+			return err
+		})
+	}
+
+	// The owner keeps full control no matter what the directory says
+	if workspace.OwnerID == userID {
+		return nil
+	}
+
+	switch membership.Status {
+	case models.MembershipStatusActive:
+		if membership.RoleID == role.ID {
+			return nil
+		}
+		return s.workspaceRepo.UpdateMembership(membership.ID, map[string]interface{}{"role_id": role.ID})
+	case models.MembershipStatusPending:
+		return global.DB.Transaction(func(tx *gorm.DB) error {
+			return activatePendingMembership(tx, s.workspaceRepo, membership, role.ID)
+		})
+	default:
+		return nil
+	}
+}
+
+// syncProfile keeps the profile in line with the directory, which is the source of truth for LDAP users
+func (s *LDAPService) syncProfile(userID string, identity *utils.LDAPIdentity) {
+	updates := map[string]interface{}{}
+	if identity.FirstName != "" {
+		updates["first_name"] = identity.FirstName
+	}
+	if identity.LastName != "" {
+		updates["last_name"] = identity.LastName
+	}
+	if identity.DisplayName != "" {
+		updates["display_name"] = identity.DisplayName
+	}
+	if len(updates) == 0 {
+		return
+	}
+
+	if err := s.userRepo.UpdateUserProfile(userID, updates); err != nil && err != gorm.ErrRecordNotFound {
+		fmt.Printf("Warning: failed to sync LDAP profile: %v\n", err)
+	}
+}
+
+func (s *LDAPService) ldapAuthProvider(userID string, identity *utils.LDAPIdentity) *models.UserAuthProvider {
+	return &models.UserAuthProvider{
+		UserID:         userID,
+		Provider:       common.AuthProviderLDAP,
+		ProviderUserID: identity.DN,
+		ProviderEmail:  &identity.Email,
+		ProviderData: models.ProviderData{
+			"dn": identity.DN,
+		},
+		Status: common.ActiveStatus,
+	}
+}
+
+func ldapOptions(config *setting.LDAP) *utils.LDAPOptions {
+	return &utils.LDAPOptions{
+		URL:                config.URL,
+		StartTLS:           config.StartTLS,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+		BindDN:             config.BindDN,
+		BindPassword:       config.BindPassword,
+		BaseDN:             config.BaseDN,
+		UserFilter:         config.UserFilter,
+		Timeout:            config.Timeout,
+		Attributes: utils.LDAPAttributeMapping{
+			Email:       config.Attributes.Email,
+			FirstName:   config.Attributes.FirstName,
+			LastName:    config.Attributes.LastName,
+			DisplayName: config.Attributes.DisplayName,
+			Groups:      config.Attributes.Groups,
+		},
+	}
+}
+
+func ldapHasGroup(groups []string, group string) bool {
+	for _, candidate := range groups {
+		if utils.LDAPDNEqual(candidate, group) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/pkg/utils"
+)
+
+// listedMemberStatuses are listed when the query does not filter by status
+var listedMemberStatuses = []string{models.MembershipStatusActive, models.MembershipStatusSuspended}
+
+type MemberService struct {
+	workspaceRepo repo.WorkspaceRepositoryInterface
+	authCache     AuthCacheInterface
+}
+
+func NewMemberService(workspaceRepo repo.WorkspaceRepositoryInterface, authCache AuthCacheInterface) MemberServiceInterface {
+	return &MemberService{
+		workspaceRepo: workspaceRepo,
+		authCache:     authCache,
+	}
+}
+
+// ListMembers returns a page of members in the order they joined, which archived workspaces still allow
+func (s *MemberService) ListMembers(userID, workspaceID string, query *dto.ListMembersQuery) (*dto.MemberListResponse, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, common.PermissionMemberRead); err != nil {
+		return nil, err
+	}
+
+	filter := &repo.MemberFilter{
+		Statuses: listedMemberStatuses,
+		RoleID:   query.RoleID,
+		Search:   query.Search,
+		Limit:    query.Limit + 1, // one more tells whether a next page exists
+	}
+	if query.Status != "" {
+		filter.Statuses = []string{query.Status}
+	}
+	if query.Cursor != "" {
+		cursor, err := utils.DecodeCursor(query.Cursor)
+		if err != nil {
+			return nil, common.ErrInvalidCursor
+		}
+		filter.After = cursor
+	}
+
+	memberships, err := s.workspaceRepo.ListWorkspaceMembers(workspaceID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.MemberListResponse{
+		Members: make([]dto.MemberResponse, 0, len(memberships)),
+	}
+	if len(memberships) > query.Limit {
+		memberships = memberships[:query.Limit]
+		last := memberships[len(memberships)-1]
+		response.NextCursor = utils.EncodeCursor(utils.Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	for i := range memberships {
+		response.Members = append(response.Members, *toMemberResponse(&memberships[i], workspace))
+	}
+
+	return response, nil
+}
+
+// UpdateMemberRole moves a member to another role. The caller needs every permission of both the
+// current and the new role, so that nobody can promote past or demote above themselves.
+func (s *MemberService) UpdateMemberRole(userID, workspaceID, memberID string, req *dto.UpdateMemberRoleRequest) (*dto.MemberResponse, error) {
+	workspace, membership, err := s.getManagedMember(userID, workspaceID, memberID, common.PermissionMemberUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := getAssignableRole(s.workspaceRepo, workspaceID, req.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	if role.ID == membership.RoleID {
+		return toMemberResponse(membership, workspace), nil
+	}
+	if err := authorizeRoleGrant(s.workspaceRepo, s.authCache, workspace, userID, role); err != nil {
+		return nil, err
+	}
+
+	if err := s.workspaceRepo.UpdateMembership(membership.ID, map[string]interface{}{"role_id": role.ID}); err != nil {
+		return nil, err
+	}
+	s.authCache.InvalidateUsers(memberID)
+
+	if global.EventTopicPublisher != nil {
+		payload := &dto.MembershipRoleChangedPayload{
+			UserID:      memberID,
+			WorkspaceID: workspaceID,
+			OldRoleID:   membership.RoleID,
+			NewRoleID:   role.ID,
+			ChangedByID: userID,
+		}
+		go func() {
+			if err := global.EventTopicPublisher.Publish(common.MembershipRoleChangedLog, payload); err != nil {
+				fmt.Printf("Error publishing membership role changed event: %v\n", err)
+			}
+		}()
+	}
+
+	membership.RoleID = role.ID
+	membership.Role = *role
+	return toMemberResponse(membership, workspace), nil
+}
+
+// SuspendMember keeps the membership but denies access to the workspace until it is reactivated
+func (s *MemberService) SuspendMember(userID, workspaceID, memberID string) (*dto.MemberResponse, error) {
+	return s.setMemberStatus(userID, workspaceID, memberID, models.MembershipStatusSuspended)
+}
+
+func (s *MemberService) ReactivateMember(userID, workspaceID, memberID string) (*dto.MemberResponse, error) {
+	return s.setMemberStatus(userID, workspaceID, memberID, models.MembershipStatusActive)
+}
+
+// RemoveMember ends the membership; the member can come back through a new invitation
+func (s *MemberService) RemoveMember(userID, workspaceID, memberID string) error {
+	_, membership, err := s.getManagedMember(userID, workspaceID, memberID, common.PermissionMemberRemove)
+	if err != nil {
+		return err
+	}
+
+	if err := s.workspaceRepo.DeleteMembership(membership.ID); err != nil {
+		return err
+	}
+	s.authCache.InvalidateUsers(memberID)
+
+	if global.EventTopicPublisher != nil {
+		payload := &dto.MembershipRemovedPayload{
+			UserID:      memberID,
+			WorkspaceID: workspaceID,
+			RemovedByID: userID,
+			Source:      common.MembershipSourceMemberAPI,
+		}
+		go func() {
+			if err := global.EventTopicPublisher.Publish(common.MembershipRemovedLog, payload); err != nil {
+				fmt.Printf("Error publishing membership removed event: %v\n", err)
+			}
+		}()
+	}
+
+	return nil
+}
+
+func (s *MemberService) setMemberStatus(userID, workspaceID, memberID, status string) (*dto.MemberResponse, error) {
+	workspace, membership, err := s.getManagedMember(userID, workspaceID, memberID, common.PermissionMemberUpdate)
+	if err != nil {
+		return nil, err
+	}
+	if membership.Status == status {
+		return toMemberResponse(membership, workspace), nil
+	}
+
+	if err := s.workspaceRepo.UpdateMembership(membership.ID, map[string]interface{}{"status": status}); err != nil {
+		return nil, err
+	}
+	s.authCache.InvalidateUsers(memberID)
+
+	if global.EventTopicPublisher != nil {
+		payload := &dto.MembershipStatusChangedPayload{
+			UserID:      memberID,
+			WorkspaceID: workspaceID,
+			OldStatus:   membership.Status,
+			NewStatus:   status,
+			ChangedByID: userID,
+			Source:      common.MembershipSourceMemberAPI,
+		}
+		go func() {
+			if err := global.EventTopicPublisher.Publish(common.MembershipStatusChangedLog, payload); err != nil {
+				fmt.Printf("Error publishing membership status changed event: %v\n", err)
+			}
+		}()
+	}
+
+	membership.Status = status
+	return toMemberResponse(membership, workspace), nil
+}
+
+// getManagedMember returns an active or suspended member of a workspace that is neither archived
+// nor inactive. The caller needs the given permission as well as every permission the member holds
+// through their role and groups. The owner's membership cannot be changed.
+func (s *MemberService) getManagedMember(userID, workspaceID, memberID, permission string) (*models.Workspace, *models.UserWorkspaceMembership, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, permission); err != nil {
+		return nil, nil, err
+	}
+
+	switch workspace.Status {
+	case common.ActiveStatus:
+	case common.ArchivedStatus:
+		return nil, nil, common.ErrWorkspaceArchived
+	default:
+		return nil, nil, common.ErrWorkspaceInactive
+	}
+
+	membership, err := s.workspaceRepo.GetWorkspaceMember(workspaceID, memberID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if membership == nil || (membership.Status != models.MembershipStatusActive && membership.Status != models.MembershipStatusSuspended) {
+		return nil, nil, common.ErrMemberNotFound
+	}
+	if workspace.OwnerID == memberID {
+		return nil, nil, common.ErrOwnerMembershipFixed
+	}
+	if err := authorizeMemberManagement(s.workspaceRepo, s.authCache, workspace, userID, membership); err != nil {
+		return nil, nil, err
+	}
+
+	return workspace, membership, nil
+}
+
+func toMemberResponse(membership *models.UserWorkspaceMembership, workspace *models.Workspace) *dto.MemberResponse {
+	response := &dto.MemberResponse{
+		UserID:    membership.UserID,
+		Email:     membership.User.Email,
+		RoleID:    membership.RoleID,
+		RoleName:  membership.Role.Name,
+		Status:    membership.Status,
+		IsOwner:   membership.UserID == workspace.OwnerID,
+		InvitedBy: getStringValue(membership.InvitedBy),
+		JoinedAt:  membership.JoinedAt,
+		CreatedAt: membership.CreatedAt,
+	}
+	if profile := membership.User.Profile; profile != nil {
+		response.FirstName = profile.FirstName
+		response.LastName = profile.LastName
+		response.DisplayName = getStringValue(profile.DisplayName)
+		response.AvatarURL = getStringValue(profile.AvatarURL)
+	}
+	return response
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/repo"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultMemberPermissions are granted by the built-in member role
+var defaultMemberPermissions = []string{common.PermissionWorkspaceRead, common.PermissionMemberRead}
+
+// resolveDefaultRole returns the role members get when they join without an explicit one: the
+// default member role of the workspace settings while it is still active, otherwise the built-in
+// member role, created the first time a workspace needs it
+func resolveDefaultRole(tx *gorm.DB, workspaceRepo repo.WorkspaceRepositoryInterface, workspaceID string) (*models.WorkspaceRole, error) {
+	role, err := settingsDefaultRole(workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if role != nil {
+		return role, nil
+	}
+
+	role, err = workspaceRepo.GetWorkspaceRoleByName(workspaceID, common.WorkspaceRoleMember)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get member role: %w", err)
+	}
+	if role != nil {
+		return role, nil
+	}
+
+	role = &models.WorkspaceRole{
+		WorkspaceID: workspaceID,
+		Name:        common.WorkspaceRoleMember,
+		Description: stringPtr("Default role for workspace members"),
+		Permissions: models.RolePermissions{
+			Permissions: defaultMemberPermissions,
+			Metadata: models.PermissionMetadata{
+				Version:     "1.0",
+				CreatedBy:   "system",
+				UpdatedBy:   "system",
+				UpdatedAt:   time.Now(),
+				Description: "Default member role with read access",
+			},
+		},
+		Status: common.ActiveStatus,
+	}
+
+	if err := workspaceRepo.CreateWorkspaceRole(tx, role); err != nil {
+		return nil, fmt.Errorf("failed to create member role: %w", err)
+	}
+
+	return role, nil
+}
+
+// settingsDefaultRole returns the role chosen as default_member_role_id in the workspace settings,
+// nil when there is none or it can no longer be assigned
+func settingsDefaultRole(workspaceRepo repo.WorkspaceRepositoryInterface, workspaceID string) (*models.WorkspaceRole, error) {
+	workspace, err := workspaceRepo.GetWorkspaceByID(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if workspace == nil || workspace.Settings == nil {
+		return nil, nil
+	}
+
+	roleID, _ := (*workspace.Settings)["default_member_role_id"].(string)
+	if roleID == "" {
+		return nil, nil
+	}
+
+	role, err := getAssignableRole(workspaceRepo, workspaceID, roleID)
+	if err != nil {
+		if errors.Is(err, common.ErrWorkspaceRoleNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if role.Name == common.WorkspaceRoleAdmin {
+		return nil, nil
+	}
+
+	return role, nil
+}
+
+// createAdminRole creates the built-in admin role, which holds every permission and is given to the owner
+func createAdminRole(tx *gorm.DB, workspaceRepo repo.WorkspaceRepositoryInterface, workspaceID string) (*models.WorkspaceRole, error) {
+	role := &models.WorkspaceRole{
+		WorkspaceID: workspaceID,
+		Name:        common.WorkspaceRoleAdmin,
+		Description: stringPtr("Full administrative access to workspace"),
+		Permissions: models.RolePermissions{
+			Permissions: []string{common.PermissionAll},
+			Metadata: models.PermissionMetadata{
+				Version:     "1.0",
+				CreatedBy:   "system",
+				UpdatedBy:   "system",
+				UpdatedAt:   time.Now(),
+				Description: "Default admin role with full permissions",
+			},
+		},
+		Status: common.ActiveStatus,
+	}
+
+	if err := workspaceRepo.CreateWorkspaceRole(tx, role); err != nil {
+		return nil, fmt.Errorf("failed to create admin role: %w", err)
+	}
+
+	return role, nil
+}
+
+// getAssignableRole returns an active role of the workspace
+func getAssignableRole(workspaceRepo repo.WorkspaceRepositoryInterface, workspaceID, roleID string) (*models.WorkspaceRole, error) {
+	role, err := workspaceRepo.GetWorkspaceRole(roleID)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil || role.WorkspaceID != workspaceID || role.Status != common.ActiveStatus {
+		return nil, common.ErrWorkspaceRoleNotFound
+	}
+	return role, nil
+}
+
+// defaultWorkspacePlan returns the plan given to newly created workspaces
+func defaultWorkspacePlan() string {
+	if plan := global.Config.Workspace.DefaultPlan; plan != "" {
+		return plan
+	}
+	return common.WorkspaceDefaultPlan
+}
+
+// planMemberLimit returns the member limit of a plan, 0 meaning no limit. Workspaces created before
+// plans existed have none and keep no limit until a super admin assigns them one. Workspaces on a
+// plan that is no longer configured get the limit of the default plan.
+func planMemberLimit(plan string) int {
+	if plan == "" {
+		return 0
+	}
+	plans := global.Config.Workspace.Plans
+	if p, ok := plans[plan]; ok {
+		return p.MaxMembers
+	}
+	return plans[defaultWorkspacePlan()].MaxMembers
+}
+
+// reserveMemberSeat fails when the plan of the workspace has no seat left for another member.
+// It locks the workspace row, so concurrent joins in other transactions wait for this one.
+func reserveMemberSeat(tx *gorm.DB, workspaceRepo repo.WorkspaceRepositoryInterface, workspaceID string) error {
+	workspace, err := workspaceRepo.LockWorkspace(tx, workspaceID)
+	if err != nil {
+		return err
+	}
+	if workspace == nil {
+		return common.ErrWorkspaceNotFound
+	}
+
+	limit := planMemberLimit(workspace.Plan)
+	if limit <= 0 {
+		return nil
+	}
+
+	seated, err := workspaceRepo.CountSeatedMembers(tx, workspaceID)
+	if err != nil {
+		return err
+	}
+	if seated >= int64(limit) {
+		return common.ErrMemberLimitReached
+	}
+
+	return nil
+}
+
+// addWorkspaceMember creates an active membership for a user that is not yet part of the workspace,
+// within the member limit of its plan
+func addWorkspaceMember(tx *gorm.DB, workspaceRepo repo.WorkspaceRepositoryInterface, workspaceID, userID, roleID string, invitedBy *string) (*models.UserWorkspaceMembership, error) {
+	if err := reserveMemberSeat(tx, workspaceRepo, workspaceID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	membership := &models.UserWorkspaceMembership{
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		RoleID:      roleID,
+		Status:      models.MembershipStatusActive,
+		InvitedBy:   invitedBy,
+		JoinedAt:    &now,
+	}
+
+	if err := workspaceRepo.CreateMembership(tx, membership); err != nil {
+		return nil, fmt.Errorf("failed to create membership: %w", err)
+	}
+
+	return membership, nil
+}
+
+// activatePendingMembership turns a pending membership, such as a join request, into an active
+// one within the member limit of the plan
+func activatePendingMembership(tx *gorm.DB, workspaceRepo repo.WorkspaceRepositoryInterface, membership *models.UserWorkspaceMembership, roleID string) error {
+	if err := reserveMemberSeat(tx, workspaceRepo, membership.WorkspaceID); err != nil {
+		return err
+	}
+	return workspaceRepo.ActivatePendingMembership(tx, membership.ID, roleID)
+}
+
+// deriveProfileNames fills in the mandatory first and last name of externally provisioned users
+// when the identity provider does not send them
+func deriveProfileNames(firstName, lastName, displayName, email string) (string, string) {
+	if firstName == "" && displayName != "" {
+		parts := strings.Fields(displayName)
+		firstName = parts[0]
+		if lastName == "" && len(parts) > 1 {
+			lastName = strings.Join(parts[1:], " ")
+		}
+	}
+	if firstName == "" {
+		firstName = strings.Split(email, "@")[0]
+	}
+	return firstName, lastName
+}
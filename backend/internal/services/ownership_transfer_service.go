@@ -0,0 +1,319 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/repo"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ownershipTransferTTL is how long the nominee has to accept
+const ownershipTransferTTL = 7 * 24 * time.Hour
+
+type OwnershipTransferService struct {
+	transferRepo  repo.OwnershipTransferRepositoryInterface
+	workspaceRepo repo.WorkspaceRepositoryInterface
+	userRepo      repo.UserRepositoryInterface
+	authCache     AuthCacheInterface
+}
+
+func NewOwnershipTransferService(transferRepo repo.OwnershipTransferRepositoryInterface, workspaceRepo repo.WorkspaceRepositoryInterface, userRepo repo.UserRepositoryInterface, authCache AuthCacheInterface) OwnershipTransferServiceInterface {
+	return &OwnershipTransferService{
+		transferRepo:  transferRepo,
+		workspaceRepo: workspaceRepo,
+		userRepo:      userRepo,
+		authCache:     authCache,
+	}
+}
+
+// InitiateTransfer nominates an active member as the next owner, replacing any pending nomination
+func (s *OwnershipTransferService) InitiateTransfer(userID, workspaceID string, req *dto.CreateOwnershipTransferRequest) (*dto.OwnershipTransferResponse, error) {
+	workspace, err := s.getTransferableWorkspace(userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.UserID == workspace.OwnerID {
+		return nil, common.ErrOwnershipTransferTarget
+	}
+	target, err := s.getActiveMember(workspaceID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := s.transferRepo.GetPendingTransfer(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := &models.WorkspaceOwnershipTransfer{
+		WorkspaceID: workspaceID,
+		FromUserID:  workspace.OwnerID,
+		ToUserID:    target.ID,
+		InitiatedBy: userID,
+		Status:      models.OwnershipTransferStatusPending,
+		ExpiresAt:   time.Now().Add(ownershipTransferTTL),
+	}
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if pending != nil {
+			if err := s.transferRepo.CloseTransfer(tx, pending.ID, models.OwnershipTransferStatusCancelled); err != nil {
+				return err
+			}
+		}
+		return s.transferRepo.CreateTransfer(tx, transfer)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if global.UserNotifier != nil {
+		data := map[string]interface{}{
+			"workspace_name": workspace.Name,
+			"workspace_slug": workspace.Slug,
+			"expires_at":     transfer.ExpiresAt,
+		}
+		recipient := target.Email
+		subject := fmt.Sprintf("You have been asked to take over %s", workspace.Name)
+		go func() {
+			if err := global.UserNotifier.Send(recipient, subject, common.NotificationTemplateOwnershipTransfer, data); err != nil {
+				fmt.Printf("Error sending ownership transfer notification: %v\n", err)
+			}
+		}()
+	}
+
+	return toOwnershipTransferResponse(transfer), nil
+}
+
+// GetTransfer returns the pending transfer to the owner, super admins and the nominee
+func (s *OwnershipTransferService) GetTransfer(userID, workspaceID string) (*dto.OwnershipTransferResponse, error) {
+	transfer, err := s.getPendingTransfer(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if transfer.ToUserID != userID {
+		if _, err := s.getTransferableWorkspace(userID, workspaceID); err != nil {
+			return nil, err
+		}
+	}
+
+	return toOwnershipTransferResponse(transfer), nil
+}
+
+func (s *OwnershipTransferService) CancelTransfer(userID, workspaceID string) error {
+	if _, err := s.getTransferableWorkspace(userID, workspaceID); err != nil {
+		return err
+	}
+
+	transfer, err := s.getPendingTransfer(workspaceID)
+	if err != nil {
+		return err
+	}
+
+	return s.close(transfer, models.OwnershipTransferStatusCancelled)
+}
+
+// AcceptTransfer makes the nominee the owner and gives them the admin role, in one transaction. The
+// nominee must stay within the owned workspace quota.
+func (s *OwnershipTransferService) AcceptTransfer(userID, workspaceID string) (*dto.WorkspaceResponse, error) {
+	transfer, err := s.getNomination(userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if workspace.OwnerID != transfer.FromUserID {
+		return nil, common.ErrOwnershipTransferInvalid
+	}
+
+	membership, err := s.workspaceRepo.GetMembership(userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if membership == nil || membership.Status != models.MembershipStatusActive {
+		return nil, common.ErrOwnershipTransferInvalid
+	}
+
+	nominee, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if nominee == nil {
+		return nil, common.ErrUserNotFound
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkOwnedWorkspaceQuota(tx, s.userRepo, s.workspaceRepo, nominee); err != nil {
+			return err
+		}
+		if err := s.transferRepo.CloseTransfer(tx, transfer.ID, models.OwnershipTransferStatusAccepted); err != nil {
+			return err
+		}
+		if err := s.workspaceRepo.TransferOwnership(tx, workspaceID, transfer.FromUserID, userID); err != nil {
+			return err
+		}
+
+		adminRole, err := s.workspaceRepo.GetWorkspaceRoleByName(workspaceID, common.WorkspaceRoleAdmin)
+		if err != nil {
+			return err
+		}
+		if adminRole == nil {
+			if adminRole, err = createAdminRole(tx, s.workspaceRepo, workspaceID); err != nil {
+				return err
+			}
+		}
+		if membership.RoleID != adminRole.ID {
+			return s.workspaceRepo.SetMembershipRole(tx, membership.ID, adminRole.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, common.ErrOwnershipTransferInvalid
+		}
+		return nil, err
+	}
+
+	s.authCache.InvalidateUsers(transfer.FromUserID, userID)
+
+	if global.EventTopicPublisher != nil {
+		payload := &dto.WorkspaceOwnershipTransferredPayload{
+			WorkspaceID:     workspaceID,
+			TransferID:      transfer.ID,
+			PreviousOwnerID: transfer.FromUserID,
+			NewOwnerID:      userID,
+			InitiatedByID:   transfer.InitiatedBy,
+		}
+		go func() {
+			if err := global.EventTopicPublisher.Publish(common.WorkspaceOwnershipTransferredLog, payload); err != nil {
+				fmt.Printf("Error publishing ownership transferred event: %v\n", err)
+			}
+		}()
+	}
+
+	workspace.OwnerID = userID
+	response := toWorkspaceResponse(workspace)
+	response.Role = common.WorkspaceRoleAdmin
+	return response, nil
+}
+
+func (s *OwnershipTransferService) DeclineTransfer(userID, workspaceID string) error {
+	transfer, err := s.getNomination(userID, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	return s.close(transfer, models.OwnershipTransferStatusDeclined)
+}
+
+// getTransferableWorkspace authorizes the owner and super admins. Other members are told they
+// cannot, and outsiders that the workspace does not exist.
+func (s *OwnershipTransferService) getTransferableWorkspace(userID, workspaceID string) (*models.Workspace, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if workspace.OwnerID == userID {
+		return workspace, nil
+	}
+
+	state, err := s.authCache.GetUserState(userID)
+	if err != nil {
+		return nil, err
+	}
+	if state != nil && state.GlobalRole == common.GlobalRoleSuperAdmin {
+		return workspace, nil
+	}
+
+	membership, err := s.workspaceRepo.GetMembership(userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if membership == nil || membership.Status != models.MembershipStatusActive {
+		return nil, common.ErrWorkspaceNotFound
+	}
+	return nil, common.ErrOwnershipTransferForbidden
+}
+
+// getNomination returns the pending transfer nominating the user
+func (s *OwnershipTransferService) getNomination(userID, workspaceID string) (*models.WorkspaceOwnershipTransfer, error) {
+	transfer, err := s.getPendingTransfer(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.ToUserID != userID {
+		return nil, common.ErrOwnershipTransferNotFound
+	}
+	return transfer, nil
+}
+
+// getPendingTransfer returns the pending transfer of a workspace, expiring it when its time is up
+func (s *OwnershipTransferService) getPendingTransfer(workspaceID string) (*models.WorkspaceOwnershipTransfer, error) {
+	transfer, err := s.transferRepo.GetPendingTransfer(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, common.ErrOwnershipTransferNotFound
+	}
+	if !transfer.ExpiresAt.After(time.Now()) {
+		if err := s.transferRepo.CloseTransfer(global.DB, transfer.ID, models.OwnershipTransferStatusExpired); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		return nil, common.ErrOwnershipTransferNotFound
+	}
+	return transfer, nil
+}
+
+func (s *OwnershipTransferService) getActiveMember(workspaceID, userID string) (*models.User, error) {
+	membership, err := s.workspaceRepo.GetMembership(userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if membership == nil || membership.Status != models.MembershipStatusActive {
+		return nil, common.ErrOwnershipTransferTarget
+	}
+
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || user.Status != common.UserStatusActive {
+		return nil, common.ErrOwnershipTransferTarget
+	}
+	return user, nil
+}
+
+func (s *OwnershipTransferService) close(transfer *models.WorkspaceOwnershipTransfer, status string) error {
+	if err := s.transferRepo.CloseTransfer(global.DB, transfer.ID, status); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrOwnershipTransferNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func toOwnershipTransferResponse(transfer *models.WorkspaceOwnershipTransfer) *dto.OwnershipTransferResponse {
+	return &dto.OwnershipTransferResponse{
+		ID:          transfer.ID,
+		WorkspaceID: transfer.WorkspaceID,
+		FromUserID:  transfer.FromUserID,
+		ToUserID:    transfer.ToUserID,
+		InitiatedBy: transfer.InitiatedBy,
+		Status:      transfer.Status,
+		ExpiresAt:   transfer.ExpiresAt,
+		RespondedAt: transfer.RespondedAt,
+		CreatedAt:   transfer.CreatedAt,
+	}
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/repo"
+	"strings"
+)
+
+type ResourceService struct {
+	resourceRepo repo.ResourceRepositoryInterface
+}
+
+func NewResourceService(resourceRepo repo.ResourceRepositoryInterface) ResourceServiceInterface {
+	return &ResourceService{
+		resourceRepo: resourceRepo,
+	}
+}
+
+func (s *ResourceService) ListResources(query *dto.ListResourcesQuery) ([]dto.ResourceResponse, error) {
+	var (
+		resources []models.Resource
+		err       error
+	)
+	if query.Category != "" {
+		resources, err = s.resourceRepo.GetResourcesByCategory(query.Category)
+	} else {
+		resources, err = s.resourceRepo.GetResources()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.ResourceResponse, 0, len(resources))
+	for i := range resources {
+		if query.Status == "" || resources[i].Status == query.Status {
+			response = append(response, *toResourceResponse(&resources[i]))
+		}
+	}
+	return response, nil
+}
+
+func (s *ResourceService) GetResource(resourceID string) (*dto.ResourceResponse, error) {
+	resource, err := s.getResource(resourceID)
+	if err != nil {
+		return nil, err
+	}
+	return toResourceResponse(resource), nil
+}
+
+func (s *ResourceService) CreateResource(req *dto.CreateResourceRequest) (*dto.ResourceResponse, error) {
+	exists, err := s.resourceRepo.ExistsByName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, common.ErrResourceExists
+	}
+
+	resource := &models.Resource{
+		Name:        req.Name,
+		DisplayName: strings.TrimSpace(req.DisplayName),
+		Description: optionalString(req.Description),
+		Category:    optionalString(req.Category),
+		Icon:        optionalString(req.Icon),
+		Status:      models.ResourceStatusActive,
+	}
+	if err := s.resourceRepo.CreateResource(resource); err != nil {
+		return nil, err
+	}
+
+	return toResourceResponse(resource), nil
+}
+
+func (s *ResourceService) UpdateResource(resourceID string, req *dto.UpdateResourceRequest) (*dto.ResourceResponse, error) {
+	resource, err := s.getResource(resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.DisplayName != nil {
+		resource.DisplayName = strings.TrimSpace(*req.DisplayName)
+		updates["display_name"] = resource.DisplayName
+	}
+	if req.Description != nil {
+		resource.Description = optionalString(*req.Description)
+		updates["description"] = resource.Description
+	}
+	if req.Category != nil {
+		resource.Category = optionalString(*req.Category)
+		updates["category"] = resource.Category
+	}
+	if req.Icon != nil {
+		resource.Icon = optionalString(*req.Icon)
+		updates["icon"] = resource.Icon
+	}
+	if req.Status != nil {
+		resource.Status = *req.Status
+		updates["status"] = resource.Status
+	}
+	if len(updates) == 0 {
+		return toResourceResponse(resource), nil
+	}
+
+	if err := s.resourceRepo.UpdateResource(resource.ID, updates); err != nil {
+		return nil, err
+	}
+
+	return toResourceResponse(resource), nil
+}
+
+// DeleteResource retires a resource. While active roles still grant it, it is only deprecated:
+// the roles keep working but the resource cannot be granted any more.
+func (s *ResourceService) DeleteResource(resourceID string) (*dto.ResourceResponse, error) {
+	resource, err := s.getResource(resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	references, err := s.resourceRepo.CountReferencingRoles(resource.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if references > 0 {
+		resource.Status = models.ResourceStatusDeprecated
+		err = s.resourceRepo.UpdateResource(resource.ID, map[string]interface{}{"status": resource.Status})
+	} else {
+		resource.Status = models.ResourceStatusInactive
+		err = s.resourceRepo.DeleteResource(resource.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return toResourceResponse(resource), nil
+}
+
+func (s *ResourceService) getResource(resourceID string) (*models.Resource, error) {
+	resource, err := s.resourceRepo.GetResourceByID(resourceID)
+	if err != nil {
+		return nil, err
+	}
+	if resource == nil {
+		return nil, common.ErrResourceNotFound
+	}
+	return resource, nil
+}
+
+// optionalString stores blank values as NULL
+func optionalString(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func toResourceResponse(resource *models.Resource) *dto.ResourceResponse {
+	return &dto.ResourceResponse{
+		ID:          resource.ID,
+		Name:        resource.Name,
+		DisplayName: resource.DisplayName,
+		Description: getStringValue(resource.Description),
+		Category:    getStringValue(resource.Category),
+		Icon:        getStringValue(resource.Icon),
+		Status:      resource.Status,
+		Actions:     models.GetValidActions(),
+		CreatedAt:   resource.CreatedAt,
+		UpdatedAt:   resource.UpdatedAt,
+	}
+}
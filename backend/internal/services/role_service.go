@@ -0,0 +1,502 @@
+package services
+
+import (
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type RoleService struct {
+	workspaceRepo repo.WorkspaceRepositoryInterface
+	resourceRepo  repo.ResourceRepositoryInterface
+	authCache     AuthCacheInterface
+}
+
+func NewRoleService(workspaceRepo repo.WorkspaceRepositoryInterface, resourceRepo repo.ResourceRepositoryInterface, authCache AuthCacheInterface) RoleServiceInterface {
+	return &RoleService{
+		workspaceRepo: workspaceRepo,
+		resourceRepo:  resourceRepo,
+		authCache:     authCache,
+	}
+}
+
+// ListRoles returns the active roles of a workspace
+func (s *RoleService) ListRoles(userID, workspaceID string) ([]dto.RoleResponse, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, common.PermissionRoleRead); err != nil {
+		return nil, err
+	}
+
+	roles, err := s.workspaceRepo.GetWorkspaceRoles(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.RoleResponse, 0, len(roles))
+	for i := range roles {
+		if roles[i].Status == common.ActiveStatus {
+			response = append(response, *toRoleResponse(&roles[i]))
+		}
+	}
+	return response, nil
+}
+
+func (s *RoleService) GetRole(userID, workspaceID, roleID string) (*dto.RoleResponse, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, common.PermissionRoleRead); err != nil {
+		return nil, err
+	}
+
+	role, err := getAssignableRole(s.workspaceRepo, workspaceID, roleID)
+	if err != nil {
+		return nil, err
+	}
+	return toRoleResponse(role), nil
+}
+
+// CreateRole adds a custom role. The caller must hold every permission of the role, inherited
+// ones included.
+func (s *RoleService) CreateRole(userID, workspaceID string, req *dto.CreateRoleRequest) (*dto.RoleResponse, error) {
+	workspace, err := s.getManagedWorkspace(userID, workspaceID, common.PermissionRoleCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if err := s.ensureNameAvailable(workspaceID, name, ""); err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.validatePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	inherits, err := s.validateInherits(workspaceID, "", req.Inherits)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	role := &models.WorkspaceRole{
+		WorkspaceID: workspaceID,
+		Name:        name,
+		Permissions: models.RolePermissions{
+			Permissions: permissions,
+			Inherits:    inherits,
+			Metadata: models.PermissionMetadata{
+				Version:   "1.0",
+				CreatedBy: userID,
+				UpdatedBy: userID,
+				UpdatedAt: now,
+			},
+		},
+		Status: common.ActiveStatus,
+	}
+	if description := strings.TrimSpace(req.Description); description != "" {
+		role.Description = &description
+	}
+	if err := authorizeRoleGrant(s.workspaceRepo, s.authCache, workspace, userID, role); err != nil {
+		return nil, err
+	}
+
+	if err := s.workspaceRepo.CreateWorkspaceRole(global.DB, role); err != nil {
+		return nil, err
+	}
+
+	s.publish(common.RoleCreatedLog, role, userID)
+
+	return toRoleResponse(role), nil
+}
+
+// UpdateRole changes a role. The caller must hold every permission of the role before and after
+// the change. Members of the role and of the roles inheriting from it get their cached
+// permissions refreshed.
+func (s *RoleService) UpdateRole(userID, workspaceID, roleID string, req *dto.UpdateRoleRequest) (*dto.RoleResponse, error) {
+	workspace, err := s.getManagedWorkspace(userID, workspaceID, common.PermissionRoleUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := getAssignableRole(s.workspaceRepo, workspaceID, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeRoleGrant(s.workspaceRepo, s.authCache, workspace, userID, role); err != nil {
+		return nil, err
+	}
+
+	builtIn := isBuiltInRole(role)
+	if builtIn && role.Name == common.WorkspaceRoleAdmin {
+		return nil, common.ErrWorkspaceRoleBuiltIn
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name != role.Name {
+			if builtIn {
+				return nil, common.ErrWorkspaceRoleBuiltIn
+			}
+			if err := s.ensureNameAvailable(workspaceID, name, role.ID); err != nil {
+				return nil, err
+			}
+			updates["name"] = name
+			role.Name = name
+		}
+	}
+	if req.Description != nil {
+		description := strings.TrimSpace(*req.Description)
+		if description != getStringValue(role.Description) {
+			updates["description"] = stringPtr(description)
+			role.Description = stringPtr(description)
+		}
+	}
+
+	permissionsChanged := false
+	if req.Permissions != nil {
+		permissions, err := s.validatePermissions(*req.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		if !samePermissions(permissions, role.Permissions.Permissions) {
+			role.Permissions.Permissions = permissions
+			permissionsChanged = true
+		}
+	}
+	if req.Inherits != nil {
+		inherits, err := s.validateInherits(workspaceID, role.ID, *req.Inherits)
+		if err != nil {
+			return nil, err
+		}
+		if !samePermissions(inherits, role.Permissions.Inherits) {
+			role.Permissions.Inherits = inherits
+			permissionsChanged = true
+		}
+	}
+	if permissionsChanged {
+		role.Permissions.Metadata.Version = nextPermissionsVersion(role.Permissions.Metadata.Version)
+		// also fails when the new inherits close a cycle
+		if err := authorizeRoleGrant(s.workspaceRepo, s.authCache, workspace, userID, role); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(updates) == 0 && !permissionsChanged {
+		return toRoleResponse(role), nil
+	}
+
+	role.Permissions.Metadata.UpdatedBy = userID
+	role.Permissions.Metadata.UpdatedAt = time.Now()
+	updates["permissions"] = role.Permissions
+
+	if err := s.workspaceRepo.UpdateWorkspaceRole(role.ID, updates); err != nil {
+		return nil, err
+	}
+
+	if permissionsChanged {
+		s.invalidateRoleMembers(role)
+	}
+	s.publish(common.RoleUpdatedLog, role, userID)
+
+	return toRoleResponse(role), nil
+}
+
+// DeleteRole retires a custom role that neither members, groups nor other roles hold any more
+func (s *RoleService) DeleteRole(userID, workspaceID, roleID string) error {
+	workspace, err := s.getManagedWorkspace(userID, workspaceID, common.PermissionRoleDelete)
+	if err != nil {
+		return err
+	}
+
+	role, err := getAssignableRole(s.workspaceRepo, workspaceID, roleID)
+	if err != nil {
+		return err
+	}
+	if isBuiltInRole(role) {
+		return common.ErrWorkspaceRoleBuiltIn
+	}
+	if err := authorizeRoleGrant(s.workspaceRepo, s.authCache, workspace, userID, role); err != nil {
+		return err
+	}
+
+	memberships, err := s.workspaceRepo.GetRoleMemberships(role.ID)
+	if err != nil {
+		return err
+	}
+	if len(memberships) > 0 {
+		return common.ErrWorkspaceRoleInUse
+	}
+	if err := ensureRoleNotGranted(s.workspaceRepo, role); err != nil {
+		return err
+	}
+
+	if err := s.workspaceRepo.DeleteWorkspaceRole(role.ID); err != nil {
+		return err
+	}
+
+	s.publish(common.RoleDeletedLog, role, userID)
+
+	return nil
+}
+
+// validatePermissions parses every permission and checks it against the active resources of the
+// catalog and the valid actions, returning them without duplicates. Wildcards and denies are
+// accepted in place of a resource or an action.
+func (s *RoleService) validatePermissions(permissions []string) ([]string, error) {
+	resources, err := s.resourceRepo.GetResources()
+	if err != nil {
+		return nil, err
+	}
+	active := make(map[string]bool, len(resources))
+	for _, resource := range resources {
+		if resource.Status == models.ResourceStatusActive {
+			active[resource.Name] = true
+		}
+	}
+	actions := make(map[string]bool)
+	for _, action := range models.GetValidActions() {
+		actions[action] = true
+	}
+
+	seen := make(map[string]bool, len(permissions))
+	valid := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		permission = strings.TrimSpace(permission)
+		if seen[permission] {
+			continue
+		}
+		seen[permission] = true
+
+		p, err := utils.ParsePermission(permission)
+		if err != nil || !(p.Resource == utils.PermissionWildcard || active[p.Resource]) || !(p.Action == utils.PermissionWildcard || actions[p.Action]) {
+			return nil, &common.APIError{
+				Status:  common.ErrInvalidPermission.Status,
+				Code:    common.ErrInvalidPermission.Code,
+				Message: fmt.Sprintf("Permission %q is not a known resource:action", permission),
+			}
+		}
+		valid = append(valid, permission)
+	}
+
+	return valid, nil
+}
+
+// validateInherits checks that every inherited role is an active role of the workspace other than
+// the role itself. Longer cycles are found once the permissions of the role are resolved.
+func (s *RoleService) validateInherits(workspaceID, roleID string, inherits []string) ([]string, error) {
+	valid := make([]string, 0, len(inherits))
+	for _, parentID := range inherits {
+		if parentID == roleID {
+			return nil, common.ErrRoleInheritanceCycle
+		}
+		parent, err := getAssignableRole(s.workspaceRepo, workspaceID, parentID)
+		if err != nil {
+			return nil, err
+		}
+		valid = append(valid, parent.ID)
+	}
+	return valid, nil
+}
+
+func (s *RoleService) ensureNameAvailable(workspaceID, name, roleID string) error {
+	if name == "" {
+		return common.ErrValidationFailed
+	}
+	if name == common.WorkspaceRoleAdmin || name == common.WorkspaceRoleMember {
+		return common.ErrWorkspaceRoleExists
+	}
+
+	existing, err := s.workspaceRepo.GetWorkspaceRoleByName(workspaceID, name)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != roleID {
+		return common.ErrWorkspaceRoleExists
+	}
+	return nil
+}
+
+// getManagedWorkspace authorizes a role change, which archived workspaces do not accept
+func (s *RoleService) getManagedWorkspace(userID, workspaceID, permission string) (*models.Workspace, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, permission); err != nil {
+		return nil, err
+	}
+
+	switch workspace.Status {
+	case common.ActiveStatus:
+		return workspace, nil
+	case common.ArchivedStatus:
+		return nil, common.ErrWorkspaceArchived
+	default:
+		return nil, common.ErrWorkspaceInactive
+	}
+}
+
+func (s *RoleService) invalidateRoleMembers(role *models.WorkspaceRole) {
+	invalidateRoleHolders(s.workspaceRepo, s.authCache, role)
+}
+
+// invalidateRoleHolders drops the cached access of everyone who holds a role: its members, the
+// members of the groups it is granted to and the holders of the roles inheriting from it
+func invalidateRoleHolders(workspaceRepo repo.WorkspaceRepositoryInterface, authCache AuthCacheInterface, role *models.WorkspaceRole) {
+	roles, err := workspaceRepo.GetWorkspaceRoles(role.WorkspaceID)
+	if err != nil {
+		fmt.Printf("Warning: failed to get roles of workspace %s: %v\n", role.WorkspaceID, err)
+		return
+	}
+
+	var userIDs []string
+	for _, roleID := range inheritingRoleIDs(roles, role.ID) {
+		memberships, err := workspaceRepo.GetRoleMemberships(roleID)
+		if err != nil {
+			fmt.Printf("Warning: failed to get members of role %s: %v\n", roleID, err)
+		}
+		for _, membership := range memberships {
+			userIDs = append(userIDs, membership.UserID)
+		}
+
+		groupMemberIDs, err := workspaceRepo.GetRoleGroupMemberIDs(roleID)
+		if err != nil {
+			fmt.Printf("Warning: failed to get group members of role %s: %v\n", roleID, err)
+		}
+		userIDs = append(userIDs, groupMemberIDs...)
+	}
+	authCache.InvalidateUsers(userIDs...)
+}
+
+// ensureRoleNotGranted fails with ErrWorkspaceRoleInUse while a role reaches users other than its
+// own members: through a group it is granted to or a role inheriting from it
+func ensureRoleNotGranted(workspaceRepo repo.WorkspaceRepositoryInterface, role *models.WorkspaceRole) error {
+	groups, err := workspaceRepo.CountRoleGroups(role.ID)
+	if err != nil {
+		return err
+	}
+	if groups > 0 {
+		return common.ErrWorkspaceRoleInUse
+	}
+
+	roles, err := workspaceRepo.GetWorkspaceRoles(role.WorkspaceID)
+	if err != nil {
+		return err
+	}
+	if len(inheritingRoleIDs(roles, role.ID)) > 1 {
+		return common.ErrWorkspaceRoleInUse
+	}
+
+	return nil
+}
+
+func (s *RoleService) publish(event string, role *models.WorkspaceRole, userID string) {
+	if global.EventTopicPublisher == nil {
+		return
+	}
+
+	payload := &dto.RoleChangedPayload{
+		RoleID:      role.ID,
+		WorkspaceID: role.WorkspaceID,
+		Name:        role.Name,
+		Permissions: role.Permissions.Permissions,
+		Inherits:    role.Permissions.Inherits,
+		Version:     role.Permissions.Metadata.Version,
+		ChangedByID: userID,
+	}
+	go func() {
+		if err := global.EventTopicPublisher.Publish(event, payload); err != nil {
+			fmt.Printf("Error publishing %s event: %v\n", event, err)
+		}
+	}()
+}
+
+// nextPermissionsVersion bumps the major part of a "major.minor" version
+func nextPermissionsVersion(version string) string {
+	major, _, _ := strings.Cut(version, ".")
+	n, err := strconv.Atoi(major)
+	if err != nil || n < 1 {
+		return "1.0"
+	}
+	return fmt.Sprintf("%d.0", n+1)
+}
+
+func samePermissions(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[string]bool, len(a))
+	for _, p := range a {
+		set[p] = true
+	}
+	for _, p := range b {
+		if !set[p] {
+			return false
+		}
+	}
+	return true
+}
+
+func toRoleResponse(role *models.WorkspaceRole) *dto.RoleResponse {
+	permissions := role.Permissions.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+	inherits := role.Permissions.Inherits
+	if inherits == nil {
+		inherits = []string{}
+	}
+
+	return &dto.RoleResponse{
+		ID:          role.ID,
+		WorkspaceID: role.WorkspaceID,
+		Name:        role.Name,
+		Description: getStringValue(role.Description),
+		Permissions: permissions,
+		Inherits:    inherits,
+		BuiltIn:     isBuiltInRole(role),
+		Version:     role.Permissions.Metadata.Version,
+		CreatedBy:   role.Permissions.Metadata.CreatedBy,
+		UpdatedBy:   role.Permissions.Metadata.UpdatedBy,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+// inheritingRoleIDs returns a role and the active roles that inherit from it, directly or not
+func inheritingRoleIDs(roles []models.WorkspaceRole, roleID string) []string {
+	children := make(map[string][]string)
+	for i := range roles {
+		if roles[i].Status != common.ActiveStatus {
+			continue
+		}
+		for _, parentID := range roles[i].Permissions.Inherits {
+			children[parentID] = append(children[parentID], roles[i].ID)
+		}
+	}
+
+	ids := []string{roleID}
+	seen := map[string]bool{roleID: true}
+	for i := 0; i < len(ids); i++ {
+		for _, childID := range children[ids[i]] {
+			if !seen[childID] {
+				seen[childID] = true
+				ids = append(ids, childID)
+			}
+		}
+	}
+	return ids
+}
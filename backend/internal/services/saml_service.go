@@ -0,0 +1,560 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/pkg/utils"
+	"strings"
+	"time"
+
+	"github.com/crewjam/saml"
+	"gorm.io/gorm"
+)
+
+const (
+	samlRequestTTL          = 10 * time.Minute
+	samlAssertionReplayTTL  = time.Hour
+	samlCertificateValidity = 10 * 365 * 24 * time.Hour
+)
+
+type SAMLService struct {
+	workspaceRepo repo.WorkspaceRepositoryInterface
+	domainRepo    repo.DomainRepositoryInterface
+	userRepo      repo.UserRepositoryInterface
+	authService   AuthServiceInterface
+	authCache     AuthCacheInterface
+}
+
+func NewSAMLService(workspaceRepo repo.WorkspaceRepositoryInterface, domainRepo repo.DomainRepositoryInterface, userRepo repo.UserRepositoryInterface, authService AuthServiceInterface, authCache AuthCacheInterface) SAMLServiceInterface {
+	return &SAMLService{
+		workspaceRepo: workspaceRepo,
+		domainRepo:    domainRepo,
+		userRepo:      userRepo,
+		authService:   authService,
+		authCache:     authCache,
+	}
+}
+
+func (s *SAMLService) GetConfig(workspaceID string) (*dto.SAMLConfigResponse, error) {
+	workspace, err := s.workspaceRepo.GetWorkspaceByID(workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	if workspace == nil {
+		return nil, common.ErrWorkspaceNotFound
+	}
+
+	config, err := s.workspaceRepo.GetSAMLConfig(workspace.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SAML config: %w", err)
+	}
+	if config == nil {
+		return nil, common.ErrSAMLNotConfigured
+	}
+
+	return s.toConfigResponse(config), nil
+}
+
+// UpsertConfig stores the IdP settings of a workspace. The SP key pair is generated on first
+// use and kept across updates unless a rotation is requested.
+func (s *SAMLService) UpsertConfig(workspaceID string, req *dto.UpsertSAMLConfigRequest) (*dto.SAMLConfigResponse, error) {
+	workspace, err := s.workspaceRepo.GetWorkspaceByID(workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	if workspace == nil {
+		return nil, common.ErrWorkspaceNotFound
+	}
+
+	config, err := s.workspaceRepo.GetSAMLConfig(workspace.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SAML config: %w", err)
+	}
+	if config == nil {
+		config = &models.WorkspaceSAMLConfig{
+			WorkspaceID:     workspace.ID,
+			JITProvisioning: true,
+		}
+	}
+
+	if config.SPPrivateKey == "" || req.RotateSPCertificate {
+		keyPEM, certPEM, err := utils.GenerateSelfSignedKeyPair(s.metadataURL(workspace.ID), samlCertificateValidity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate SP key pair: %w", err)
+		}
+
+		encryptedKey, err := utils.EncryptToken(keyPEM, global.Config.JWT.EncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt SP private key: %w", err)
+		}
+
+		config.SPPrivateKey = encryptedKey
+		config.SPCertificate = certPEM
+	}
+
+	if req.DefaultRoleID != "" {
+		role, err := s.workspaceRepo.GetWorkspaceRole(req.DefaultRoleID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get default role: %w", err)
+		}
+		if role == nil || role.WorkspaceID != workspace.ID || role.Status != common.ActiveStatus {
+			return nil, common.ErrWorkspaceRoleNotFound
+		}
+		config.DefaultRoleID = &req.DefaultRoleID
+	} else {
+		config.DefaultRoleID = nil
+	}
+
+	config.Enabled = req.Enabled
+	config.AttributeMapping = req.AttributeMapping
+	config.AllowIDPInitiated = req.AllowIDPInitiated
+	if req.JITProvisioning != nil {
+		config.JITProvisioning = *req.JITProvisioning
+	}
+
+	config.IdPMetadataXML = nil
+	config.IdPCertificate = nil
+	if strings.TrimSpace(req.IdPMetadataXML) != "" {
+		config.IdPMetadataXML = &req.IdPMetadataXML
+	}
+	if strings.TrimSpace(req.IdPCertificate) != "" {
+		config.IdPCertificate = &req.IdPCertificate
+	}
+
+	// Building the service provider validates keys, certificates and metadata before anything is stored
+	sp, err := s.serviceProvider(workspace, config, req.IdPEntityID, req.IdPSSOURL)
+	if err != nil {
+		return nil, &common.APIError{
+			Status:  common.ErrSAMLConfigInvalid.Status,
+			Code:    common.ErrSAMLConfigInvalid.Code,
+			Message: err.Error(),
+		}
+	}
+
+	config.IdPEntityID = sp.IDPMetadata.EntityID
+	config.IdPSSOURL = sp.GetSSOBindingLocation(saml.HTTPRedirectBinding)
+
+	if err := s.workspaceRepo.SaveSAMLConfig(config); err != nil {
+		return nil, fmt.Errorf("failed to save SAML config: %w", err)
+	}
+
+	return s.toConfigResponse(config), nil
+}
+
+func (s *SAMLService) GetMetadata(workspaceRef string) ([]byte, error) {
+	workspace, config, err := s.loadConfig(workspaceRef, false)
+	if err != nil {
+		return nil, err
+	}
+
+	sp, err := s.serviceProvider(workspace, config, config.IdPEntityID, config.IdPSSOURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build service provider: %w", err)
+	}
+
+	return utils.SAMLMetadataXML(sp)
+}
+
+// StartLogin returns the IdP URL that starts an SP-initiated login and remembers the request ID
+func (s *SAMLService) StartLogin(workspaceRef, relayState string) (string, error) {
+	workspace, config, err := s.loadConfig(workspaceRef, true)
+	if err != nil {
+		return "", err
+	}
+
+	sp, err := s.serviceProvider(workspace, config, config.IdPEntityID, config.IdPSSOURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to build service provider: %w", err)
+	}
+
+	redirectURL, requestID, err := utils.MakeSAMLRedirectRequest(sp, relayState)
+	if err != nil {
+		return "", fmt.Errorf("failed to create SAML request: %w", err)
+	}
+
+	err = global.RedisClient.Set(context.Background(), samlRequestKey(workspace.ID, requestID), "1", samlRequestTTL).Err()
+	if err != nil {
+		return "", fmt.Errorf("failed to store SAML request: %w", err)
+	}
+
+	return redirectURL, nil
+}
+
+// ConsumeResponse verifies the IdP response posted to the ACS endpoint, provisions the user
+// just in time when needed and signs them in
+func (s *SAMLService) ConsumeResponse(workspaceRef, samlResponse, userAgent, ipAddress string) (*dto.LoginResponse, error) {
+	workspace, config, err := s.loadConfig(workspaceRef, true)
+	if err != nil {
+		return nil, err
+	}
+
+	sp, err := s.serviceProvider(workspace, config, config.IdPEntityID, config.IdPSSOURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build service provider: %w", err)
+	}
+
+	ctx := context.Background()
+
+	requestID, err := utils.SAMLResponseRequestID(samlResponse)
+	if err != nil {
+		return nil, common.ErrSAMLInvalidResponse
+	}
+
+	var possibleRequestIDs []string
+	if requestID != "" {
+		// Request IDs are single use, deleting the key also defeats replays of the same response
+		deleted, err := global.RedisClient.Del(ctx, samlRequestKey(workspace.ID, requestID)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check SAML request: %w", err)
+		}
+		if deleted == 0 {
+			return nil, common.ErrSAMLInvalidResponse
+		}
+		possibleRequestIDs = []string{requestID}
+	} else if !config.AllowIDPInitiated {
+		return nil, common.ErrSAMLInvalidResponse
+	}
+
+	assertion, err := utils.ParseSAMLResponse(sp, samlResponse, possibleRequestIDs)
+	if err != nil {
+		fmt.Printf("Warning: rejected SAML response for workspace %s: %v\n", workspace.ID, err)
+		return nil, common.ErrSAMLInvalidResponse
+	}
+
+	replayTTL := samlAssertionReplayTTL
+	if assertion.Conditions != nil && !assertion.Conditions.NotOnOrAfter.IsZero() {
+		replayTTL = time.Until(assertion.Conditions.NotOnOrAfter) + time.Minute
+	}
+	fresh, err := global.RedisClient.SetNX(ctx, samlAssertionKey(workspace.ID, assertion.ID), "1", replayTTL).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to record SAML assertion: %w", err)
+	}
+	if !fresh {
+		return nil, common.ErrSAMLInvalidResponse
+	}
+
+	identity := utils.ExtractSAMLIdentity(assertion, utils.SAMLAttributeMapping{
+		Email:       config.AttributeMapping.Email,
+		FirstName:   config.AttributeMapping.FirstName,
+		LastName:    config.AttributeMapping.LastName,
+		DisplayName: config.AttributeMapping.DisplayName,
+		Groups:      config.AttributeMapping.Groups,
+	})
+	if identity.NameID == "" {
+		return nil, common.ErrSAMLInvalidResponse
+	}
+	if identity.Email == "" {
+		return nil, common.ErrSAMLMissingEmail
+	}
+
+	user, err := s.provisionUser(workspace, config, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Status != common.UserStatusActive {
+		return nil, common.ErrUserInactive
+	}
+
+	return s.authService.IssueSession(user, &dto.SessionOptions{
+		Provider:  common.AuthProviderSAML,
+		UserAgent: userAgent,
+		IPAddress: ipAddress,
+	})
+}
+
+// provisionUser resolves the local account for an asserted identity. Existing accounts are only
+// linked by email when they already belong to the workspace, otherwise any workspace IdP could
+// take over accounts it does not own. For the same reason new accounts are only created for
+// emails of a domain the workspace verified.
+func (s *SAMLService) provisionUser(workspace *models.Workspace, config *models.WorkspaceSAMLConfig, identity *utils.SAMLIdentity) (*models.User, error) {
+	providerUserID := fmt.Sprintf("%s:%s", workspace.ID, identity.NameID)
+
+	link, err := s.userRepo.GetAuthProviderByProviderUserID(common.AuthProviderSAML, providerUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SAML identity: %w", err)
+	}
+
+	var user *models.User
+	if link != nil {
+		user, err = s.userRepo.GetUserByID(link.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+	}
+
+	if user == nil {
+		user, err = s.userRepo.GetUserByEmail(identity.Email)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+
+		if user != nil {
+			membership, err := s.workspaceRepo.GetMembership(user.ID, workspace.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get membership: %w", err)
+			}
+			if membership == nil {
+				return nil, common.ErrSAMLAccountConflict
+			}
+
+			err = s.userRepo.CreateAuthProvider(s.samlAuthProvider(user.ID, providerUserID, workspace, identity))
+			if err != nil {
+				return nil, fmt.Errorf("failed to link SAML identity: %w", err)
+			}
+		}
+	}
+
+	if user == nil {
+		if !config.JITProvisioning {
+			return nil, common.ErrSAMLProvisioningDisabled
+		}
+		return s.createUser(workspace, config, identity, providerUserID)
+	}
+
+	if err := s.ensureMembership(workspace, config, user.ID); err != nil {
+		return nil, err
+	}
+
+	s.syncProfile(user.ID, identity)
+
+	return user, nil
+}
+
+func (s *SAMLService) createUser(workspace *models.Workspace, config *models.WorkspaceSAMLConfig, identity *utils.SAMLIdentity, providerUserID string) (*models.User, error) {
+	domain, err := s.domainRepo.GetVerifiedDomain(workspace.ID, utils.EmailDomain(identity.Email))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verified domain: %w", err)
+	}
+	if domain == nil {
+		return nil, common.ErrSAMLDomainNotVerified
+	}
+
+	firstName, lastName := deriveProfileNames(identity.FirstName, identity.LastName, identity.DisplayName, identity.Email)
+
+	user := &models.User{
+		Email:      identity.Email,
+		GlobalRole: common.GlobalRoleCustomer,
+		Status:     common.UserStatusActive,
+	}
+
+	profile := &models.UserProfile{
+		FirstName: firstName,
+		LastName:  lastName,
+		Timezone:  "UTC",
+		Locale:    "en",
+	}
+	if identity.DisplayName != "" {
+		profile.DisplayName = &identity.DisplayName
+	}
+
+	authProvider := s.samlAuthProvider("", providerUserID, workspace, identity)
+	authProvider.IsPrimary = true
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.userRepo.CreateUserWithAuth(tx, user, profile, authProvider); err != nil {
+			return err
+		}
+
+		role, err := s.defaultRole(tx, workspace, config)
+		if err != nil {
+			return err
+		}
+
+		_, err = addWorkspaceMember(tx, s.workspaceRepo, workspace.ID, user.ID, role.ID, nil)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to provision SAML user: %w", err)
+	}
+
+	if global.EventTopicPublisher != nil {
+		payload := &dto.UserProvisionedPayload{
+			UserID:      user.ID,
+			WorkspaceID: workspace.ID,
+			Provider:    common.AuthProviderSAML,
+		}
+		go func() {
+			if err := global.EventTopicPublisher.Publish(common.UserProvisionedLog, payload); err != nil {
+				fmt.Printf("Error publishing user provisioned event: %v\n", err)
+			}
+		}()
+	}
+
+	return user, nil
+}
+
+func (s *SAMLService) ensureMembership(workspace *models.Workspace, config *models.WorkspaceSAMLConfig, userID string) error {
+	membership, err := s.workspaceRepo.GetMembership(userID, workspace.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get membership: %w", err)
+	}
+
+	if membership == nil {
+		if !config.JITProvisioning {
+			return common.ErrSAMLProvisioningDisabled
+		}
+		err := global.DB.Transaction(func(tx *gorm.DB) error {
+			role, err := s.defaultRole(tx, workspace, config)
+			if err != nil {
+				return err
+			}
+			_, err = addWorkspaceMember(tx, s.workspaceRepo, workspace.ID, userID, role.ID, nil)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		s.authCache.InvalidateUsers(userID)
+		return nil
+	}
+
+	switch membership.Status {
+	case models.MembershipStatusActive:
+		return nil
+	case models.MembershipStatusPending:
+		// Authenticating through the workspace IdP proves the invitee belongs to the organization
+		err := global.DB.Transaction(func(tx *gorm.DB) error {
+			return activatePendingMembership(tx, s.workspaceRepo, membership, membership.RoleID)
+		})
+		if err != nil {
+			return err
+		}
+
+		s.authCache.InvalidateUsers(userID)
+		return nil
+	default:
+		return common.ErrMembershipInactive
+	}
+}
+
+func (s *SAMLService) defaultRole(tx *gorm.DB, workspace *models.Workspace, config *models.WorkspaceSAMLConfig) (*models.WorkspaceRole, error) {
+	if config.DefaultRoleID != nil {
+		role, err := s.workspaceRepo.GetWorkspaceRole(*config.DefaultRoleID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get default role: %w", err)
+		}
+		if role != nil && role.WorkspaceID == workspace.ID && role.Status == common.ActiveStatus {
+			return role, nil
+		}
+	}
+
+	return resolveDefaultRole(tx, s.workspaceRepo, workspace.ID)
+}
+
+// syncProfile keeps the profile in line with the IdP, which is the source of truth for SSO users
+func (s *SAMLService) syncProfile(userID string, identity *utils.SAMLIdentity) {
+	updates := map[string]interface{}{}
+	if identity.FirstName != "" {
+		updates["first_name"] = identity.FirstName
+	}
+	if identity.LastName != "" {
+		updates["last_name"] = identity.LastName
+	}
+	if identity.DisplayName != "" {
+		updates["display_name"] = identity.DisplayName
+	}
+	if len(updates) == 0 {
+		return
+	}
+
+	if err := s.userRepo.UpdateUserProfile(userID, updates); err != nil && err != gorm.ErrRecordNotFound {
+		fmt.Printf("Warning: failed to sync SAML profile: %v\n", err)
+	}
+}
+
+func (s *SAMLService) samlAuthProvider(userID, providerUserID string, workspace *models.Workspace, identity *utils.SAMLIdentity) *models.UserAuthProvider {
+	return &models.UserAuthProvider{
+		UserID:         userID,
+		Provider:       common.AuthProviderSAML,
+		ProviderUserID: providerUserID,
+		ProviderEmail:  &identity.Email,
+		ProviderData: models.ProviderData{
+			"workspace_id": workspace.ID,
+			"name_id":      identity.NameID,
+		},
+		Status: common.ActiveStatus,
+	}
+}
+
+// loadConfig resolves a workspace by ID or slug together with its SAML configuration
+func (s *SAMLService) loadConfig(workspaceRef string, requireEnabled bool) (*models.Workspace, *models.WorkspaceSAMLConfig, error) {
+	workspace, err := s.workspaceRepo.GetWorkspaceByID(workspaceRef)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	if workspace == nil {
+		workspace, err = s.workspaceRepo.GetWorkspaceBySlug(workspaceRef)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get workspace: %w", err)
+		}
+	}
+	if workspace == nil {
+		return nil, nil, common.ErrWorkspaceNotFound
+	}
+	if workspace.Status != common.ActiveStatus {
+		return nil, nil, common.ErrWorkspaceInactive
+	}
+
+	config, err := s.workspaceRepo.GetSAMLConfig(workspace.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get SAML config: %w", err)
+	}
+	if config == nil || (requireEnabled && !config.Enabled) {
+		return nil, nil, common.ErrSAMLNotConfigured
+	}
+
+	return workspace, config, nil
+}
+
+func (s *SAMLService) serviceProvider(workspace *models.Workspace, config *models.WorkspaceSAMLConfig, idpEntityID, idpSSOURL string) (*saml.ServiceProvider, error) {
+	keyPEM, err := utils.DecryptToken(config.SPPrivateKey, global.Config.JWT.EncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt SP private key: %w", err)
+	}
+
+	return utils.NewSAMLServiceProvider(&utils.SAMLServiceProviderOptions{
+		MetadataURL:       s.metadataURL(workspace.ID),
+		ACSURL:            s.acsURL(workspace.ID),
+		PrivateKeyPEM:     keyPEM,
+		CertificatePEM:    config.SPCertificate,
+		IdPMetadataXML:    getStringValue(config.IdPMetadataXML),
+		IdPEntityID:       idpEntityID,
+		IdPSSOURL:         idpSSOURL,
+		IdPCertificatePEM: getStringValue(config.IdPCertificate),
+		AllowIDPInitiated: config.AllowIDPInitiated,
+	})
+}
+
+func (s *SAMLService) toConfigResponse(config *models.WorkspaceSAMLConfig) *dto.SAMLConfigResponse {
+	return &dto.SAMLConfigResponse{
+		WorkspaceSAMLConfig: config,
+		SPEntityID:          s.metadataURL(config.WorkspaceID),
+		SPMetadataURL:       s.metadataURL(config.WorkspaceID),
+		SPACSURL:            s.acsURL(config.WorkspaceID),
+	}
+}
+
+// SP URLs use the workspace ID rather than the slug so that renaming a workspace does not
+// invalidate what is registered at the IdP
+func (s *SAMLService) metadataURL(workspaceID string) string {
+	return fmt.Sprintf("%s/api/v1/sso/saml/%s/metadata", strings.TrimRight(global.Config.Server.PublicURL, "/"), workspaceID)
+}
+
+func (s *SAMLService) acsURL(workspaceID string) string {
+	return fmt.Sprintf("%s/api/v1/sso/saml/%s/acs", strings.TrimRight(global.Config.Server.PublicURL, "/"), workspaceID)
+}
+
+func samlRequestKey(workspaceID, requestID string) string {
+	return fmt.Sprintf("saml:request:%s:%s", workspaceID, requestID)
+}
+
+func samlAssertionKey(workspaceID, assertionID string) string {
+	return fmt.Sprintf("saml:assertion:%s:%s", workspaceID, assertionID)
+}
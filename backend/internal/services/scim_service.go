@@ -0,0 +1,1325 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/pkg/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const scimTokenBytes = 32
+
+var scimUserFilterColumns = map[string]utils.SCIMFilterColumn{
+	"id":                {Column: "users.id"},
+	"username":          {Column: "users.email"},
+	"emails":            {Column: "users.email"},
+	"emails.value":      {Column: "users.email"},
+	"externalid":        {Column: "user_workspace_memberships.external_id"},
+	"displayname":       {Column: "user_profiles.display_name"},
+	"name.givenname":    {Column: "user_profiles.first_name"},
+	"name.familyname":   {Column: "user_profiles.last_name"},
+	"active":            {Column: "user_workspace_memberships.status = 'active'", Kind: utils.SCIMAttributeBoolean},
+	"meta.created":      {Column: "user_workspace_memberships.created_at", Kind: utils.SCIMAttributeDateTime},
+	"meta.lastmodified": {Column: "user_workspace_memberships.updated_at", Kind: utils.SCIMAttributeDateTime},
+}
+
+var scimGroupFilterColumns = map[string]utils.SCIMFilterColumn{
+	"id":                {Column: "workspace_roles.id"},
+	"displayname":       {Column: "workspace_roles.name"},
+	"meta.created":      {Column: "workspace_roles.created_at", Kind: utils.SCIMAttributeDateTime},
+	"meta.lastmodified": {Column: "workspace_roles.updated_at", Kind: utils.SCIMAttributeDateTime},
+}
+
+// SCIMService implements SCIM 2.0 provisioning for a workspace. SCIM users map onto workspace
+// memberships (with their user and profile) and SCIM groups onto workspace roles; since a
+// member holds exactly one role, adding a user to a group moves them out of their previous one.
+type SCIMService struct {
+	scimRepo      repo.SCIMRepositoryInterface
+	workspaceRepo repo.WorkspaceRepositoryInterface
+	userRepo      repo.UserRepositoryInterface
+	authCache     AuthCacheInterface
+}
+
+func NewSCIMService(scimRepo repo.SCIMRepositoryInterface, workspaceRepo repo.WorkspaceRepositoryInterface, userRepo repo.UserRepositoryInterface, authCache AuthCacheInterface) SCIMServiceInterface {
+	return &SCIMService{
+		scimRepo:      scimRepo,
+		workspaceRepo: workspaceRepo,
+		userRepo:      userRepo,
+		authCache:     authCache,
+	}
+}
+
+func (s *SCIMService) CreateToken(workspaceID, createdBy string, req *dto.CreateSCIMTokenRequest) (*dto.CreateSCIMTokenResponse, error) {
+	workspace, err := s.workspaceRepo.GetWorkspaceByID(workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	if workspace == nil {
+		return nil, common.ErrWorkspaceNotFound
+	}
+
+	secret, err := utils.GenerateSecureToken(common.SCIMTokenPrefix, scimTokenBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	token := &models.WorkspaceSCIMToken{
+		WorkspaceID: workspace.ID,
+		Name:        req.Name,
+		TokenHash:   utils.HashToken(secret),
+		TokenPrefix: secret[:len(common.SCIMTokenPrefix)+6],
+		CreatedBy:   createdBy,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := s.scimRepo.CreateToken(token); err != nil {
+		return nil, err
+	}
+
+	return &dto.CreateSCIMTokenResponse{
+		WorkspaceSCIMToken: token,
+		Token:              secret,
+	}, nil
+}
+
+func (s *SCIMService) ListTokens(workspaceID string) ([]models.WorkspaceSCIMToken, error) {
+	exists, err := s.workspaceRepo.ExistsByID(workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check workspace: %w", err)
+	}
+	if !exists {
+		return nil, common.ErrWorkspaceNotFound
+	}
+
+	return s.scimRepo.GetWorkspaceTokens(workspaceID)
+}
+
+func (s *SCIMService) RevokeToken(workspaceID, tokenID string) error {
+	token, err := s.scimRepo.GetToken(workspaceID, tokenID)
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return common.ErrSCIMTokenNotFound
+	}
+	if token.RevokedAt != nil {
+		return nil
+	}
+
+	return s.scimRepo.UpdateToken(token.ID, map[string]interface{}{"revoked_at": time.Now()})
+}
+
+// AuthenticateToken resolves a bearer token to the workspace it was issued for
+func (s *SCIMService) AuthenticateToken(secret string) (*models.WorkspaceSCIMToken, error) {
+	if !strings.HasPrefix(secret, common.SCIMTokenPrefix) {
+		return nil, common.ErrSCIMTokenInvalid
+	}
+
+	token, err := s.scimRepo.GetTokenByHash(utils.HashToken(secret))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if token == nil || !token.IsUsable(now) {
+		return nil, common.ErrSCIMTokenInvalid
+	}
+
+	workspace, err := s.workspaceRepo.GetWorkspaceByID(token.WorkspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	if workspace == nil || workspace.Status != common.ActiveStatus {
+		return nil, common.ErrWorkspaceInactive
+	}
+
+	// Usage tracking is best effort, a failed write must not block provisioning
+	if err := s.scimRepo.UpdateToken(token.ID, map[string]interface{}{"last_used_at": now}); err != nil && err != gorm.ErrRecordNotFound {
+		fmt.Printf("Warning: failed to record SCIM token usage: %v\n", err)
+	}
+
+	return token, nil
+}
+
+func (s *SCIMService) ListUsers(workspaceID string, query *dto.SCIMListQuery) (*dto.SCIMListResponse, error) {
+	condition, args, err := scimFilterCondition(query.Filter, scimUserFilterColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	startIndex, count := scimPage(query)
+	memberships, total, err := s.scimRepo.ListMembers(workspaceID, condition, args, startIndex-1, count)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]*dto.SCIMUser, 0, len(memberships))
+	for i := range memberships {
+		users = append(users, s.toSCIMUser(&memberships[i]))
+	}
+
+	return scimListResponse(total, startIndex, users, len(users)), nil
+}
+
+func (s *SCIMService) GetUser(workspaceID, userID string) (*dto.SCIMUser, error) {
+	membership, err := s.getMember(workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toSCIMUser(membership), nil
+}
+
+// CreateUser provisions a workspace member. Accounts that already exist outside of the workspace
+// are not linked, otherwise any workspace could take over accounts it does not own.
+func (s *SCIMService) CreateUser(workspaceID string, req *dto.SCIMUser) (*dto.SCIMUser, error) {
+	email, err := scimUserEmail(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateSCIMProfile(req); err != nil {
+		return nil, err
+	}
+
+	active := req.Active == nil || *req.Active
+	status := models.MembershipStatusActive
+	if !active {
+		status = models.MembershipStatusSuspended
+	}
+
+	user, err := s.userRepo.GetUserByEmail(email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user != nil {
+		membership, err := s.workspaceRepo.GetMembership(user.ID, workspaceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get membership: %w", err)
+		}
+		// A join request does not make the requester a member the IdP may manage
+		if membership == nil || membership.Status == models.MembershipStatusPending {
+			return nil, common.ErrSCIMAccountExists
+		}
+		if membership.Status != models.MembershipStatusInactive && membership.Status != models.MembershipStatusRejected {
+			return nil, common.ErrSCIMUserExists
+		}
+
+		// Re-provisioning a previously removed member restores the existing membership
+		role, err := resolveDefaultRole(global.DB, s.workspaceRepo, workspaceID)
+		if err != nil {
+			return nil, err
+		}
+		err = global.DB.Transaction(func(tx *gorm.DB) error {
+			if err := reserveMemberSeat(tx, s.workspaceRepo, workspaceID); err != nil {
+				return err
+			}
+			now := time.Now()
+			return tx.Model(membership).Updates(map[string]interface{}{
+				"status":      status,
+				"role_id":     role.ID,
+				"external_id": scimOptionalString(req.ExternalID),
+				"joined_at":   &now,
+			}).Error
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		s.authCache.InvalidateUsers(user.ID)
+		s.updateProfile(workspaceID, user.ID, req, nil)
+		s.publishStatusChanged(user.ID, workspaceID, membership.Status, status)
+
+		return s.GetUser(workspaceID, user.ID)
+	}
+
+	name := req.Name
+	if name == nil {
+		name = &dto.SCIMName{}
+	}
+	firstName, lastName := deriveProfileNames(name.GivenName, name.FamilyName, req.DisplayName, email)
+
+	user = &models.User{
+		ID:         uuid.New().String(),
+		Email:      email,
+		GlobalRole: common.GlobalRoleCustomer,
+		Status:     common.UserStatusActive,
+	}
+
+	profile := &models.UserProfile{
+		FirstName:   firstName,
+		LastName:    lastName,
+		DisplayName: scimOptionalString(req.DisplayName),
+		Timezone:    scimDefault(req.Timezone, "UTC"),
+		Locale:      scimDefault(req.Locale, "en"),
+	}
+
+	authProvider := &models.UserAuthProvider{
+		Provider:       common.AuthProviderSCIM,
+		ProviderUserID: fmt.Sprintf("%s:%s", workspaceID, user.ID),
+		ProviderEmail:  &email,
+		ProviderData: models.ProviderData{
+			"workspace_id": workspaceID,
+		},
+		IsPrimary: true,
+		Status:    common.ActiveStatus,
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.userRepo.CreateUserWithAuth(tx, user, profile, authProvider); err != nil {
+			return err
+		}
+
+		role, err := resolveDefaultRole(tx, s.workspaceRepo, workspaceID)
+		if err != nil {
+			return err
+		}
+
+		membership, err := addWorkspaceMember(tx, s.workspaceRepo, workspaceID, user.ID, role.ID, nil)
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(membership).Updates(map[string]interface{}{
+			"status":      status,
+			"external_id": scimOptionalString(req.ExternalID),
+		}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to provision SCIM user: %w", err)
+	}
+
+	if global.EventTopicPublisher != nil {
+		payload := &dto.UserProvisionedPayload{
+			UserID:      user.ID,
+			WorkspaceID: workspaceID,
+			Provider:    common.AuthProviderSCIM,
+		}
+		go func() {
+			if err := global.EventTopicPublisher.Publish(common.UserProvisionedLog, payload); err != nil {
+				fmt.Printf("Error publishing user provisioned event: %v\n", err)
+			}
+		}()
+	}
+
+	return s.GetUser(workspaceID, user.ID)
+}
+
+// ReplaceUser applies a full SCIM representation to a member
+func (s *SCIMService) ReplaceUser(workspaceID, userID string, req *dto.SCIMUser) (*dto.SCIMUser, error) {
+	return s.replaceUser(workspaceID, userID, req, nil)
+}
+
+// replaceUser applies a representation to a member. previous is the representation a PATCH
+// started from, nil for a PUT.
+func (s *SCIMService) replaceUser(workspaceID, userID string, req, previous *dto.SCIMUser) (*dto.SCIMUser, error) {
+	membership, err := s.getMember(workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	email, err := scimUserEmail(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateSCIMProfile(req); err != nil {
+		return nil, err
+	}
+
+	if email != membership.User.Email {
+		if err := s.changeEmail(workspaceID, membership, email); err != nil {
+			return nil, err
+		}
+	}
+
+	active := req.Active == nil || *req.Active
+	if err := s.setActive(workspaceID, membership, active); err != nil {
+		return nil, err
+	}
+
+	if getStringValue(membership.ExternalID) != req.ExternalID {
+		err := s.workspaceRepo.UpdateMembership(membership.ID, map[string]interface{}{
+			"external_id": scimOptionalString(req.ExternalID),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.updateProfile(workspaceID, userID, req, previous)
+
+	return s.GetUser(workspaceID, userID)
+}
+
+// PatchUser applies PATCH operations on top of the current representation of a member
+func (s *SCIMService) PatchUser(workspaceID, userID string, req *dto.SCIMPatchRequest) (*dto.SCIMUser, error) {
+	if err := validateSCIMPatch(req); err != nil {
+		return nil, err
+	}
+
+	current, err := s.GetUser(workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := *current
+	if current.Name != nil {
+		name := *current.Name
+		previous.Name = &name
+	}
+
+	for _, operation := range req.Operations {
+		if err := applySCIMUserOperation(current, operation); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.replaceUser(workspaceID, userID, current, &previous)
+}
+
+// DeleteUser removes the user from the workspace; the account itself is kept because it may
+// belong to other workspaces
+func (s *SCIMService) DeleteUser(workspaceID, userID string) error {
+	membership, err := s.getMember(workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureNotOwner(workspaceID, userID); err != nil {
+		return err
+	}
+
+	if err := s.workspaceRepo.DeleteMembership(membership.ID); err != nil {
+		return err
+	}
+	s.authCache.InvalidateUsers(userID)
+
+	if global.EventTopicPublisher != nil {
+		payload := &dto.MembershipRemovedPayload{
+			UserID:      userID,
+			WorkspaceID: workspaceID,
+			Source:      common.AuthProviderSCIM,
+		}
+		go func() {
+			if err := global.EventTopicPublisher.Publish(common.MembershipRemovedLog, payload); err != nil {
+				fmt.Printf("Error publishing membership removed event: %v\n", err)
+			}
+		}()
+	}
+
+	return nil
+}
+
+func (s *SCIMService) ListGroups(workspaceID string, query *dto.SCIMListQuery) (*dto.SCIMListResponse, error) {
+	condition, args, err := scimFilterCondition(query.Filter, scimGroupFilterColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	startIndex, count := scimPage(query)
+	roles, total, err := s.scimRepo.ListRoles(workspaceID, condition, args, startIndex-1, count)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]*dto.SCIMGroup, 0, len(roles))
+	for i := range roles {
+		groups = append(groups, s.toSCIMGroup(&roles[i]))
+	}
+
+	return scimListResponse(total, startIndex, groups, len(groups)), nil
+}
+
+func (s *SCIMService) GetGroup(workspaceID, groupID string) (*dto.SCIMGroup, error) {
+	role, err := s.getRole(workspaceID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toSCIMGroup(role), nil
+}
+
+// CreateGroup creates a workspace role without permissions; admins grant them afterwards
+func (s *SCIMService) CreateGroup(workspaceID string, req *dto.SCIMGroup) (*dto.SCIMGroup, error) {
+	name := strings.TrimSpace(req.DisplayName)
+	if name == "" || len(name) > 100 {
+		return nil, common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidValue, "displayName is required and must be at most 100 characters")
+	}
+
+	existing, err := s.workspaceRepo.GetWorkspaceRoleByName(workspaceID, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, common.ErrSCIMGroupExists
+	}
+
+	role := &models.WorkspaceRole{
+		WorkspaceID: workspaceID,
+		Name:        name,
+		Permissions: models.RolePermissions{
+			Permissions: []string{},
+			Metadata: models.PermissionMetadata{
+				Version:     "1.0",
+				CreatedBy:   common.AuthProviderSCIM,
+				UpdatedBy:   common.AuthProviderSCIM,
+				UpdatedAt:   time.Now(),
+				Description: "Provisioned through SCIM",
+			},
+		},
+		Status: common.ActiveStatus,
+	}
+
+	if err := s.workspaceRepo.CreateWorkspaceRole(global.DB, role); err != nil {
+		return nil, err
+	}
+
+	if err := s.addGroupMembers(workspaceID, role, scimMemberIDs(req.Members)); err != nil {
+		return nil, err
+	}
+
+	return s.GetGroup(workspaceID, role.ID)
+}
+
+func (s *SCIMService) ReplaceGroup(workspaceID, groupID string, req *dto.SCIMGroup) (*dto.SCIMGroup, error) {
+	role, err := s.getRole(workspaceID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.renameGroup(workspaceID, role, req.DisplayName); err != nil {
+		return nil, err
+	}
+	if err := s.setGroupMembers(workspaceID, role, scimMemberIDs(req.Members)); err != nil {
+		return nil, err
+	}
+
+	return s.GetGroup(workspaceID, role.ID)
+}
+
+func (s *SCIMService) PatchGroup(workspaceID, groupID string, req *dto.SCIMPatchRequest) (*dto.SCIMGroup, error) {
+	if err := validateSCIMPatch(req); err != nil {
+		return nil, err
+	}
+
+	role, err := s.getRole(workspaceID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, operation := range req.Operations {
+		if err := s.applyGroupOperation(workspaceID, role, operation); err != nil {
+			return nil, err
+		}
+
+		// Reload so the next operation sees the current members
+		if role, err = s.getRole(workspaceID, groupID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.toSCIMGroup(role), nil
+}
+
+// DeleteGroup deactivates the role after moving its members to the default member role
+func (s *SCIMService) DeleteGroup(workspaceID, groupID string) error {
+	role, err := s.getRole(workspaceID, groupID)
+	if err != nil {
+		return err
+	}
+	if isBuiltInRole(role) {
+		return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeMutability, "Built-in groups cannot be deleted")
+	}
+	// The IdP only manages the direct members, the role may still be reached in other ways
+	if err := ensureRoleNotGranted(s.workspaceRepo, role); err != nil {
+		return err
+	}
+
+	if err := s.setGroupMembers(workspaceID, role, nil); err != nil {
+		return err
+	}
+
+	if err := s.workspaceRepo.DeleteWorkspaceRole(role.ID); err != nil {
+		return err
+	}
+	invalidateRoleHolders(s.workspaceRepo, s.authCache, role)
+
+	return nil
+}
+
+func (s *SCIMService) applyGroupOperation(workspaceID string, role *models.WorkspaceRole, operation dto.SCIMPatchOperation) error {
+	op := strings.ToLower(operation.Op)
+	path, valueFilter := splitSCIMPath(operation.Path)
+
+	if path == "" {
+		if op == "remove" {
+			return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeNoTarget, "remove requires a path")
+		}
+
+		var value struct {
+			DisplayName *string              `json:"displayName"`
+			Members     []dto.SCIMMultiValue `json:"members"`
+		}
+		if err := json.Unmarshal(operation.Value, &value); err != nil {
+			return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidValue, "value must be an object")
+		}
+		if value.DisplayName != nil {
+			if err := s.renameGroup(workspaceID, role, *value.DisplayName); err != nil {
+				return err
+			}
+		}
+		if value.Members != nil {
+			if op == "add" {
+				return s.addGroupMembers(workspaceID, role, scimMemberIDs(value.Members))
+			}
+			return s.setGroupMembers(workspaceID, role, scimMemberIDs(value.Members))
+		}
+		return nil
+	}
+
+	switch strings.ToLower(path) {
+	case "displayname":
+		if op == "remove" {
+			return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeMutability, "displayName is required")
+		}
+		var name string
+		if err := json.Unmarshal(operation.Value, &name); err != nil {
+			return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidValue, "displayName must be a string")
+		}
+		return s.renameGroup(workspaceID, role, name)
+	case "members":
+		var members []dto.SCIMMultiValue
+		if len(operation.Value) > 0 {
+			if err := json.Unmarshal(operation.Value, &members); err != nil {
+				return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidValue, "members must be an array")
+			}
+		}
+
+		switch op {
+		case "add":
+			return s.addGroupMembers(workspaceID, role, scimMemberIDs(members))
+		case "replace":
+			return s.setGroupMembers(workspaceID, role, scimMemberIDs(members))
+		default:
+			remove := scimMemberIDs(members)
+			if valueFilter != "" {
+				ids, err := scimMemberFilterIDs(valueFilter)
+				if err != nil {
+					return err
+				}
+				remove = append(remove, ids...)
+			}
+			if len(remove) == 0 && valueFilter == "" {
+				// Removing the attribute itself empties the group
+				return s.setGroupMembers(workspaceID, role, nil)
+			}
+			return s.removeGroupMembers(workspaceID, role, remove)
+		}
+	default:
+		return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidPath, fmt.Sprintf("path %q is not supported", operation.Path))
+	}
+}
+
+func (s *SCIMService) renameGroup(workspaceID string, role *models.WorkspaceRole, displayName string) error {
+	name := strings.TrimSpace(displayName)
+	if name == role.Name {
+		return nil
+	}
+	if name == "" || len(name) > 100 {
+		return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidValue, "displayName is required and must be at most 100 characters")
+	}
+	if isBuiltInRole(role) {
+		return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeMutability, "Built-in groups cannot be renamed")
+	}
+
+	existing, err := s.workspaceRepo.GetWorkspaceRoleByName(workspaceID, name)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != role.ID {
+		return common.ErrSCIMGroupExists
+	}
+
+	if err := s.workspaceRepo.UpdateWorkspaceRole(role.ID, map[string]interface{}{"name": name}); err != nil {
+		return err
+	}
+	role.Name = name
+
+	// Sessions carry the role name
+	memberIDs := make([]string, 0, len(role.Memberships))
+	for _, membership := range role.Memberships {
+		memberIDs = append(memberIDs, membership.UserID)
+	}
+	s.authCache.InvalidateUsers(memberIDs...)
+
+	return nil
+}
+
+// setGroupMembers makes userIDs the exact member list of a role; members that are dropped fall
+// back to the default member role
+func (s *SCIMService) setGroupMembers(workspaceID string, role *models.WorkspaceRole, userIDs []string) error {
+	keep := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		keep[id] = true
+	}
+
+	var remove []string
+	for _, membership := range role.Memberships {
+		if !keep[membership.UserID] {
+			remove = append(remove, membership.UserID)
+		}
+	}
+
+	if err := s.removeGroupMembers(workspaceID, role, remove); err != nil {
+		return err
+	}
+
+	return s.addGroupMembers(workspaceID, role, userIDs)
+}
+
+func (s *SCIMService) addGroupMembers(workspaceID string, role *models.WorkspaceRole, userIDs []string) error {
+	for _, userID := range userIDs {
+		membership, err := s.scimRepo.GetMember(workspaceID, userID)
+		if err != nil {
+			return err
+		}
+		if membership == nil {
+			return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidValue, fmt.Sprintf("member %q is not part of this workspace", userID))
+		}
+		if membership.RoleID == role.ID {
+			continue
+		}
+		if err := s.ensureNotOwner(workspaceID, userID); err != nil {
+			return err
+		}
+
+		if err := s.workspaceRepo.UpdateMembership(membership.ID, map[string]interface{}{"role_id": role.ID}); err != nil {
+			return err
+		}
+		s.authCache.InvalidateUsers(userID)
+	}
+
+	return nil
+}
+
+func (s *SCIMService) removeGroupMembers(workspaceID string, role *models.WorkspaceRole, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	defaultRole, err := resolveDefaultRole(global.DB, s.workspaceRepo, workspaceID)
+	if err != nil {
+		return err
+	}
+	if defaultRole.ID == role.ID {
+		return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeMutability, "Members cannot be removed from the default group")
+	}
+
+	for _, userID := range userIDs {
+		membership, err := s.scimRepo.GetMember(workspaceID, userID)
+		if err != nil {
+			return err
+		}
+		if membership == nil || membership.RoleID != role.ID {
+			continue
+		}
+		if err := s.ensureNotOwner(workspaceID, userID); err != nil {
+			return err
+		}
+
+		if err := s.workspaceRepo.UpdateMembership(membership.ID, map[string]interface{}{"role_id": defaultRole.ID}); err != nil {
+			return err
+		}
+		s.authCache.InvalidateUsers(userID)
+	}
+
+	return nil
+}
+
+// setActive maps the SCIM active flag onto the membership: deactivation suspends the member
+func (s *SCIMService) setActive(workspaceID string, membership *models.UserWorkspaceMembership, active bool) error {
+	status := models.MembershipStatusSuspended
+	if active {
+		status = models.MembershipStatusActive
+	}
+	if membership.Status == status {
+		return nil
+	}
+	if !active {
+		if err := s.ensureNotOwner(workspaceID, membership.UserID); err != nil {
+			return err
+		}
+	}
+
+	if active && membership.Status == models.MembershipStatusPending {
+		// A pending membership holds no seat yet, it takes one within the plan limit
+		err := global.DB.Transaction(func(tx *gorm.DB) error {
+			return activatePendingMembership(tx, s.workspaceRepo, membership, membership.RoleID)
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		updates := map[string]interface{}{"status": status}
+		if active && membership.JoinedAt == nil {
+			now := time.Now()
+			updates["joined_at"] = &now
+		}
+		if err := s.workspaceRepo.UpdateMembership(membership.ID, updates); err != nil {
+			return err
+		}
+	}
+	s.authCache.InvalidateUsers(membership.UserID)
+
+	s.publishStatusChanged(membership.UserID, workspaceID, membership.Status, status)
+	membership.Status = status
+
+	return nil
+}
+
+// provisionedProvider returns the SCIM identity of a user when this workspace provisioned the
+// account, nil otherwise. Only such accounts have their email and profile managed by the IdP.
+func (s *SCIMService) provisionedProvider(workspaceID, userID string) (*models.UserAuthProvider, error) {
+	provider, err := s.userRepo.GetUserAuthProvider(userID, common.AuthProviderSCIM)
+	if err != nil {
+		return nil, err
+	}
+	if provider == nil || provider.ProviderData["workspace_id"] != workspaceID {
+		return nil, nil
+	}
+	return provider, nil
+}
+
+// changeEmail updates the login email, which is only allowed for accounts this workspace provisioned
+func (s *SCIMService) changeEmail(workspaceID string, membership *models.UserWorkspaceMembership, email string) error {
+	provider, err := s.provisionedProvider(workspaceID, membership.UserID)
+	if err != nil {
+		return err
+	}
+	if provider == nil {
+		return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeMutability, "userName can only be changed for accounts provisioned by this workspace")
+	}
+
+	exists, err := s.userRepo.ExistsByEmail(email)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return common.ErrSCIMAccountExists
+	}
+
+	if err := s.userRepo.UpdateUser(membership.UserID, map[string]interface{}{"email": email}); err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdateAuthProvider(provider.ID, map[string]interface{}{"provider_email": email}); err != nil {
+		return err
+	}
+
+	membership.User.Email = email
+	return nil
+}
+
+// updateProfile writes the profile attributes present in a request to the global profile of a
+// user, for accounts this workspace provisioned only: other accounts own their profile. previous
+// is the representation a PATCH started from, only the attributes the PATCH changed are written.
+func (s *SCIMService) updateProfile(workspaceID, userID string, req, previous *dto.SCIMUser) {
+	provider, err := s.provisionedProvider(workspaceID, userID)
+	if err != nil {
+		fmt.Printf("Warning: failed to check SCIM provisioning: %v\n", err)
+		return
+	}
+	if provider == nil {
+		return
+	}
+
+	// An absent previous compares like an empty one, so only non-empty attributes are written
+	var before dto.SCIMUser
+	if previous != nil {
+		before = *previous
+	}
+	var name, beforeName dto.SCIMName
+	if req.Name != nil {
+		name = *req.Name
+	}
+	if before.Name != nil {
+		beforeName = *before.Name
+	}
+	changed := func(value, old string) bool {
+		return value != "" && value != old
+	}
+
+	updates := map[string]interface{}{}
+	if changed(name.GivenName, beforeName.GivenName) {
+		updates["first_name"] = name.GivenName
+	}
+	if changed(name.FamilyName, beforeName.FamilyName) {
+		updates["last_name"] = name.FamilyName
+	}
+	if changed(req.Locale, before.Locale) {
+		updates["locale"] = req.Locale
+	}
+	if changed(req.Timezone, before.Timezone) {
+		updates["timezone"] = req.Timezone
+	}
+	// displayName is optional, a PATCH that removes it clears it
+	if changed(req.DisplayName, before.DisplayName) || (previous != nil && req.DisplayName == "" && before.DisplayName != "") {
+		updates["display_name"] = scimOptionalString(req.DisplayName)
+	}
+	if len(updates) == 0 {
+		return
+	}
+
+	if err := s.userRepo.UpdateUserProfile(userID, updates); err != nil && err != gorm.ErrRecordNotFound {
+		fmt.Printf("Warning: failed to update SCIM profile: %v\n", err)
+	}
+}
+
+func (s *SCIMService) ensureNotOwner(workspaceID, userID string) error {
+	workspace, err := s.workspaceRepo.GetWorkspaceByID(workspaceID)
+	if err != nil {
+		return fmt.Errorf("failed to get workspace: %w", err)
+	}
+	if workspace != nil && workspace.OwnerID == userID {
+		return common.ErrSCIMOwnerImmutable
+	}
+	return nil
+}
+
+func (s *SCIMService) getMember(workspaceID, userID string) (*models.UserWorkspaceMembership, error) {
+	membership, err := s.scimRepo.GetMember(workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if membership == nil {
+		return nil, common.ErrSCIMUserNotFound
+	}
+	return membership, nil
+}
+
+func (s *SCIMService) getRole(workspaceID, roleID string) (*models.WorkspaceRole, error) {
+	role, err := s.scimRepo.GetRole(workspaceID, roleID)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, common.ErrSCIMGroupNotFound
+	}
+	return role, nil
+}
+
+func (s *SCIMService) publishStatusChanged(userID, workspaceID, oldStatus, newStatus string) {
+	if global.EventTopicPublisher == nil || oldStatus == newStatus {
+		return
+	}
+
+	payload := &dto.MembershipStatusChangedPayload{
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		OldStatus:   oldStatus,
+		NewStatus:   newStatus,
+		Source:      common.AuthProviderSCIM,
+	}
+	go func() {
+		if err := global.EventTopicPublisher.Publish(common.MembershipStatusChangedLog, payload); err != nil {
+			fmt.Printf("Error publishing membership status changed event: %v\n", err)
+		}
+	}()
+}
+
+func (s *SCIMService) toSCIMUser(membership *models.UserWorkspaceMembership) *dto.SCIMUser {
+	user := membership.User
+	active := membership.Status == models.MembershipStatusActive
+
+	resource := &dto.SCIMUser{
+		Schemas:    []string{common.SCIMSchemaUser},
+		ID:         user.ID,
+		ExternalID: getStringValue(membership.ExternalID),
+		UserName:   user.Email,
+		Active:     &active,
+		Emails: []dto.SCIMMultiValue{
+			{Value: user.Email, Type: "work", Primary: true},
+		},
+		Meta: &dto.SCIMMeta{
+			ResourceType: "User",
+			Created:      membership.CreatedAt,
+			LastModified: latestTime(membership.UpdatedAt, user.UpdatedAt),
+			Location:     scimLocation("Users", user.ID),
+		},
+	}
+
+	if profile := user.Profile; profile != nil {
+		resource.Name = &dto.SCIMName{
+			GivenName:  profile.FirstName,
+			FamilyName: profile.LastName,
+			Formatted:  strings.TrimSpace(profile.FirstName + " " + profile.LastName),
+		}
+		resource.DisplayName = getStringValue(profile.DisplayName)
+		resource.Locale = profile.Locale
+		resource.Timezone = profile.Timezone
+		resource.Meta.LastModified = latestTime(resource.Meta.LastModified, profile.UpdatedAt)
+	}
+
+	if membership.Role.ID != "" {
+		resource.Groups = []dto.SCIMMultiValue{
+			{Value: membership.Role.ID, Display: membership.Role.Name, Ref: scimLocation("Groups", membership.Role.ID)},
+		}
+	}
+
+	return resource
+}
+
+func (s *SCIMService) toSCIMGroup(role *models.WorkspaceRole) *dto.SCIMGroup {
+	group := &dto.SCIMGroup{
+		Schemas:     []string{common.SCIMSchemaGroup},
+		ID:          role.ID,
+		DisplayName: role.Name,
+		Members:     make([]dto.SCIMMultiValue, 0, len(role.Memberships)),
+		Meta: &dto.SCIMMeta{
+			ResourceType: "Group",
+			Created:      role.CreatedAt,
+			LastModified: role.UpdatedAt,
+			Location:     scimLocation("Groups", role.ID),
+		},
+	}
+
+	for _, membership := range role.Memberships {
+		group.Members = append(group.Members, dto.SCIMMultiValue{
+			Value:   membership.UserID,
+			Display: membership.User.Email,
+			Ref:     scimLocation("Users", membership.UserID),
+		})
+	}
+
+	return group
+}
+
+// applySCIMUserOperation applies one PATCH operation to a user representation
+func applySCIMUserOperation(user *dto.SCIMUser, operation dto.SCIMPatchOperation) error {
+	op := strings.ToLower(operation.Op)
+	if op != "add" && op != "replace" && op != "remove" {
+		return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidSyntax, fmt.Sprintf("unknown operation %q", operation.Op))
+	}
+
+	if operation.Path == "" {
+		if op == "remove" {
+			return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeNoTarget, "remove requires a path")
+		}
+
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(operation.Value, &values); err != nil {
+			return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidValue, "value must be an object")
+		}
+		for path, value := range values {
+			if err := setSCIMUserAttribute(user, op, path, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return setSCIMUserAttribute(user, op, operation.Path, operation.Value)
+}
+
+func setSCIMUserAttribute(user *dto.SCIMUser, op, rawPath string, value json.RawMessage) error {
+	path, _ := splitSCIMPath(rawPath)
+	remove := op == "remove"
+
+	if user.Name == nil {
+		user.Name = &dto.SCIMName{}
+	}
+
+	var err error
+	switch lower := strings.ToLower(path); {
+	case lower == "active":
+		if remove {
+			return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeMutability, "active cannot be removed")
+		}
+		var active bool
+		active, err = scimBoolValue(value)
+		user.Active = &active
+	case lower == "username":
+		if remove {
+			return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeMutability, "userName cannot be removed")
+		}
+		err = scimDecodeString(value, &user.UserName)
+		user.Emails = nil
+	case lower == "emails" || strings.HasPrefix(lower, "emails."):
+		if remove {
+			return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeMutability, "emails cannot be removed")
+		}
+		var email string
+		if err = scimDecodeString(value, &email); err != nil {
+			var emails []dto.SCIMMultiValue
+			if err = json.Unmarshal(value, &emails); err == nil && len(emails) > 0 {
+				email = scimPrimaryValue(emails)
+			}
+		}
+		if email != "" {
+			user.Emails = []dto.SCIMMultiValue{{Value: email, Type: "work", Primary: true}}
+			user.UserName = email
+		}
+	case lower == "externalid":
+		user.ExternalID = ""
+		if !remove {
+			err = scimDecodeString(value, &user.ExternalID)
+		}
+	case lower == "displayname":
+		user.DisplayName = ""
+		if !remove {
+			err = scimDecodeString(value, &user.DisplayName)
+		}
+	case lower == "locale":
+		if !remove {
+			err = scimDecodeString(value, &user.Locale)
+		}
+	case lower == "timezone":
+		if !remove {
+			err = scimDecodeString(value, &user.Timezone)
+		}
+	case lower == "name":
+		if !remove {
+			var name dto.SCIMName
+			if err = json.Unmarshal(value, &name); err == nil {
+				if name.GivenName != "" {
+					user.Name.GivenName = name.GivenName
+				}
+				if name.FamilyName != "" {
+					user.Name.FamilyName = name.FamilyName
+				}
+			}
+		}
+	case lower == "name.givenname":
+		if !remove {
+			err = scimDecodeString(value, &user.Name.GivenName)
+		}
+	case lower == "name.familyname":
+		if !remove {
+			err = scimDecodeString(value, &user.Name.FamilyName)
+		}
+	case lower == "name.formatted":
+		// Derived from the given and family name
+	default:
+		return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidPath, fmt.Sprintf("path %q is not supported", rawPath))
+	}
+
+	if err != nil {
+		return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidValue, fmt.Sprintf("invalid value for %q", rawPath))
+	}
+	return nil
+}
+
+func validateSCIMPatch(req *dto.SCIMPatchRequest) error {
+	if len(req.Operations) == 0 {
+		return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidSyntax, "Operations is required")
+	}
+	if len(req.Operations) > common.SCIMMaxPatchOperation {
+		return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidSyntax, fmt.Sprintf("at most %d operations are allowed", common.SCIMMaxPatchOperation))
+	}
+	return nil
+}
+
+// scimUserEmail picks the login email of a SCIM user: the primary email, else the userName
+func scimUserEmail(user *dto.SCIMUser) (string, error) {
+	email := scimPrimaryValue(user.Emails)
+	if email == "" {
+		email = user.UserName
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	if email == "" || !common.EmailRegex.MatchString(email) {
+		return "", common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidValue, "userName or a primary email must be a valid email address")
+	}
+	return email, nil
+}
+
+func scimPrimaryValue(values []dto.SCIMMultiValue) string {
+	for _, v := range values {
+		if v.Primary && v.Value != "" {
+			return v.Value
+		}
+	}
+	for _, v := range values {
+		if v.Value != "" {
+			return v.Value
+		}
+	}
+	return ""
+}
+
+func scimMemberIDs(members []dto.SCIMMultiValue) []string {
+	ids := make([]string, 0, len(members))
+	for _, member := range members {
+		if member.Value != "" {
+			ids = append(ids, member.Value)
+		}
+	}
+	return ids
+}
+
+// scimMemberFilterIDs extracts the user IDs targeted by a members[value eq "..."] path
+func scimMemberFilterIDs(valueFilter string) ([]string, error) {
+	filter, err := utils.ParseSCIMFilter(valueFilter)
+	if err != nil {
+		return nil, common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidFilter, err.Error())
+	}
+
+	var ids []string
+	var walk func(f *utils.SCIMFilter) error
+	walk = func(f *utils.SCIMFilter) error {
+		switch f.Op {
+		case utils.SCIMFilterOr:
+			if err := walk(f.Left); err != nil {
+				return err
+			}
+			return walk(f.Right)
+		case utils.SCIMFilterEq:
+			if id, ok := f.Value.(string); ok && strings.EqualFold(f.Attribute, "value") {
+				ids = append(ids, id)
+				return nil
+			}
+		}
+		return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidFilter, "only value eq filters are supported on members")
+	}
+
+	if err := walk(filter); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// splitSCIMPath separates `members[value eq "x"]` into the attribute and its value filter and
+// strips the schema URN from fully qualified paths
+func splitSCIMPath(path string) (string, string) {
+	path = strings.TrimSpace(path)
+
+	var valueFilter string
+	if open := strings.Index(path, "["); open >= 0 {
+		if close := strings.LastIndex(path, "]"); close > open {
+			valueFilter = path[open+1 : close]
+			path = path[:open] + path[close+1:]
+		}
+	}
+
+	if strings.HasPrefix(strings.ToLower(path), "urn:") {
+		if i := strings.LastIndex(path, ":"); i >= 0 {
+			path = path[i+1:]
+		}
+	}
+
+	return path, valueFilter
+}
+
+func scimFilterCondition(filter string, columns map[string]utils.SCIMFilterColumn) (string, []interface{}, error) {
+	if strings.TrimSpace(filter) == "" {
+		return "", nil, nil
+	}
+
+	parsed, err := utils.ParseSCIMFilter(filter)
+	if err != nil {
+		return "", nil, common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidFilter, err.Error())
+	}
+
+	condition, args, err := utils.SCIMFilterToSQL(parsed, columns)
+	if err != nil {
+		return "", nil, common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidFilter, err.Error())
+	}
+
+	return condition, args, nil
+}
+
+func scimPage(query *dto.SCIMListQuery) (int, int) {
+	startIndex := query.StartIndex
+	if startIndex < 1 {
+		startIndex = 1
+	}
+
+	count := query.Count
+	if count < 0 {
+		count = 0
+	}
+	if count > common.SCIMMaxPageSize {
+		count = common.SCIMMaxPageSize
+	}
+
+	return startIndex, count
+}
+
+func scimListResponse(total int64, startIndex int, resources interface{}, itemsPerPage int) *dto.SCIMListResponse {
+	return &dto.SCIMListResponse{
+		Schemas:      []string{common.SCIMSchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: itemsPerPage,
+		Resources:    resources,
+	}
+}
+
+func scimLocation(resourceType, id string) string {
+	return fmt.Sprintf("%s/api/v1/scim/v2/%s/%s", strings.TrimRight(global.Config.Server.PublicURL, "/"), resourceType, id)
+}
+
+// scimBoolValue accepts JSON booleans as well as the "True"/"False" strings some clients send
+func scimBoolValue(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(strings.ToLower(s))
+}
+
+func scimDecodeString(value json.RawMessage, target *string) error {
+	return json.Unmarshal(value, target)
+}
+
+func scimOptionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func scimDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func isBuiltInRole(role *models.WorkspaceRole) bool {
+	return role.Name == common.WorkspaceRoleAdmin || role.Name == common.WorkspaceRoleMember
+}
+
+func latestTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// validateSCIMProfile applies the rules of the user API to the profile attributes of a request
+func validateSCIMProfile(req *dto.SCIMUser) error {
+	if req.Locale != "" && !utils.IsLanguageTag(req.Locale) {
+		return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidValue, "locale must be a BCP 47 language tag")
+	}
+	if req.Timezone != "" && !utils.IsIANATimezone(req.Timezone) {
+		return common.NewSCIMError(http.StatusBadRequest, common.SCIMTypeInvalidValue, "timezone must be an IANA time zone")
+	}
+	return nil
+}
@@ -2,8 +2,13 @@ package services
 
 import (
 	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
 	"go-backend-v2/internal/models"
 	"go-backend-v2/internal/repo"
+	"strings"
+	"time"
 )
 
 type UserService struct {
@@ -40,6 +45,136 @@ func (s *UserService) GetUserProfile(userID string) (*models.User, error) {
 	return user, nil
 }
 
-func (s *UserService) DeleteUser(userID string) error {
-	return s.userRepo.DeleteUser(userID)
+// UpdateProfile applies a partial profile update and reports the fields that actually changed
+func (s *UserService) UpdateProfile(userID string, req *dto.UpdateProfileRequest) (*models.User, error) {
+	user, err := s.userRepo.GetUserWithProfile(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user profile: %w", err)
+	}
+	if user == nil || user.Profile == nil {
+		return nil, common.ErrUserNotFound
+	}
+
+	changes := &profileChanges{updates: map[string]interface{}{}}
+	profile := user.Profile
+
+	changes.setRequired("first_name", profile.FirstName, req.FirstName)
+	changes.setRequired("last_name", profile.LastName, req.LastName)
+	changes.setRequired("timezone", profile.Timezone, req.Timezone)
+	changes.setRequired("locale", profile.Locale, req.Locale)
+	changes.setOptional("display_name", profile.DisplayName, req.DisplayName)
+	changes.setOptional("bio", profile.Bio, req.Bio)
+	changes.setOptional("address_line1", profile.AddressLine1, req.AddressLine1)
+	changes.setOptional("address_line2", profile.AddressLine2, req.AddressLine2)
+	changes.setOptional("city", profile.City, req.City)
+	changes.setOptional("state", profile.State, req.State)
+	changes.setOptional("postal_code", profile.PostalCode, req.PostalCode)
+
+	if req.Country != nil {
+		if *req.Country != "" && !countryAllowed(*req.Country) {
+			return nil, common.ErrCountryNotAllowed
+		}
+		changes.setOptional("country", profile.Country, req.Country)
+	}
+
+	if req.DateOfBirth != nil {
+		if err := changes.setDateOfBirth(profile.DateOfBirth, *req.DateOfBirth); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(changes.fields) == 0 {
+		return user, nil
+	}
+
+	if err := s.userRepo.UpdateUserProfile(userID, changes.updates); err != nil {
+		return nil, fmt.Errorf("failed to update user profile: %w", err)
+	}
+
+	if global.EventTopicPublisher != nil {
+		payload := &dto.UserUpdatedPayload{
+			UserID:        userID,
+			ChangedFields: changes.fields,
+		}
+		go func() {
+			if err := global.EventTopicPublisher.Publish(common.UserUpdatedLog, payload); err != nil {
+				fmt.Printf("Error publishing user updated event: %v\n", err)
+			}
+		}()
+	}
+
+	return s.GetUserProfile(userID)
+}
+
+// profileChanges collects the columns of a profile update, skipping values that did not change
+type profileChanges struct {
+	updates map[string]interface{}
+	fields  []string
+}
+
+func (c *profileChanges) set(column string, value interface{}) {
+	c.updates[column] = value
+	c.fields = append(c.fields, column)
+}
+
+func (c *profileChanges) setRequired(column, current string, next *string) {
+	if next == nil {
+		return
+	}
+	value := strings.TrimSpace(*next)
+	if value == "" || value == current {
+		return
+	}
+	c.set(column, value)
+}
+
+func (c *profileChanges) setOptional(column string, current, next *string) {
+	if next == nil {
+		return
+	}
+	value := strings.TrimSpace(*next)
+	if value == getStringValue(current) {
+		return
+	}
+	if value == "" {
+		c.set(column, nil)
+		return
+	}
+	c.set(column, value)
+}
+
+func (c *profileChanges) setDateOfBirth(current *time.Time, next string) error {
+	if next == "" {
+		if current != nil {
+			c.set("date_of_birth", nil)
+		}
+		return nil
+	}
+
+	date, err := time.Parse(time.DateOnly, next)
+	if err != nil {
+		return common.ErrValidationFailed
+	}
+	if !date.Before(time.Now().UTC().Truncate(24*time.Hour)) || date.Year() < 1900 {
+		return common.ErrInvalidDateOfBirth
+	}
+
+	if current != nil && current.Format(time.DateOnly) == next {
+		return nil
+	}
+	c.set("date_of_birth", date)
+	return nil
+}
+
+func countryAllowed(country string) bool {
+	allowed := global.Config.Profile.AllowedCountries
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, code := range allowed {
+		if strings.EqualFold(code, country) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/pkg/utils"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// getLiveWorkspace returns a workspace that has not been deleted
+func getLiveWorkspace(workspaceRepo repo.WorkspaceRepositoryInterface, workspaceID string) (*models.Workspace, error) {
+	workspace, err := workspaceRepo.GetWorkspaceByID(workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	if workspace == nil || workspace.Status == common.DeletedStatus {
+		return nil, common.ErrWorkspaceNotFound
+	}
+	return workspace, nil
+}
+
+// resolveLiveWorkspace returns a workspace that has not been deleted given its ID or slug
+func resolveLiveWorkspace(workspaceRepo repo.WorkspaceRepositoryInterface, ref string) (*models.Workspace, error) {
+	if _, err := uuid.Parse(ref); err == nil {
+		return getLiveWorkspace(workspaceRepo, ref)
+	}
+
+	workspace, err := workspaceRepo.GetWorkspaceBySlug(ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	if workspace == nil || workspace.Status == common.DeletedStatus {
+		return nil, common.ErrWorkspaceNotFound
+	}
+	return workspace, nil
+}
+
+// authorizeWorkspace checks that a user holds a permission in a workspace through the roles of an
+// active membership. Super admins and the owner hold every permission. Users outside the
+// workspace get ErrWorkspaceNotFound, so that its existence is not disclosed.
+func authorizeWorkspace(workspaceRepo repo.WorkspaceRepositoryInterface, authCache AuthCacheInterface, workspace *models.Workspace, userID, permission string) error {
+	granted, err := grantedPermissions(workspaceRepo, authCache, workspace, userID)
+	if err != nil {
+		return err
+	}
+	if !utils.HasPermission(granted, permission) {
+		return common.ErrWorkspacePermissionDenied
+	}
+	return nil
+}
+
+// authorizeRoleGrant checks that a user may hand out a role, which requires holding every
+// permission of the role, so that nobody can give others more access than they have
+func authorizeRoleGrant(workspaceRepo repo.WorkspaceRepositoryInterface, authCache AuthCacheInterface, workspace *models.Workspace, userID string, role *models.WorkspaceRole) error {
+	permissions, err := rolePermissions(workspaceRepo, role)
+	if err != nil {
+		return err
+	}
+	return authorizePermissionGrant(workspaceRepo, authCache, workspace, userID, permissions)
+}
+
+// authorizeMemberManagement checks that a user may change the access of a member, which requires
+// holding every permission the member has through their role and groups
+func authorizeMemberManagement(workspaceRepo repo.WorkspaceRepositoryInterface, authCache AuthCacheInterface, workspace *models.Workspace, userID string, membership *models.UserWorkspaceMembership) error {
+	held, err := memberPermissions(workspaceRepo, membership)
+	if err != nil {
+		return err
+	}
+	return authorizePermissionGrant(workspaceRepo, authCache, workspace, userID, held)
+}
+
+func authorizePermissionGrant(workspaceRepo repo.WorkspaceRepositoryInterface, authCache AuthCacheInterface, workspace *models.Workspace, userID string, permissions []string) error {
+	granted, err := grantedPermissions(workspaceRepo, authCache, workspace, userID)
+	if err != nil {
+		return err
+	}
+	for _, permission := range permissions {
+		if strings.HasPrefix(permission, utils.PermissionDenyPrefix) {
+			continue // denies only take access away
+		}
+		if !utils.HasPermission(granted, permission) {
+			return common.ErrWorkspacePermissionDenied
+		}
+	}
+	return nil
+}
+
+// grantedPermissions returns the workspace permissions of a user
+func grantedPermissions(workspaceRepo repo.WorkspaceRepositoryInterface, authCache AuthCacheInterface, workspace *models.Workspace, userID string) ([]string, error) {
+	state, err := authCache.GetUserState(userID)
+	if err != nil {
+		return nil, err
+	}
+	if state != nil && state.GlobalRole == common.GlobalRoleSuperAdmin {
+		return []string{common.PermissionAll}, nil
+	}
+
+	if workspace.OwnerID == userID {
+		return []string{common.PermissionAll}, nil
+	}
+
+	membership, err := workspaceRepo.GetMembership(userID, workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+	if membership == nil || membership.Status != models.MembershipStatusActive {
+		return nil, common.ErrWorkspaceNotFound
+	}
+
+	return memberPermissions(workspaceRepo, membership)
+}
+
+// memberPermissions returns the union of the permissions of a membership's role and of the roles
+// of the member's groups, with inherited ones, whatever the status of the membership
+func memberPermissions(workspaceRepo repo.WorkspaceRepositoryInterface, membership *models.UserWorkspaceMembership) ([]string, error) {
+	graph, err := workspaceRoleGraph(workspaceRepo, membership.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	groupRoles, err := workspaceRepo.GetUserGroupRoles(membership.WorkspaceID, membership.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	roleIDs := make([]string, 0, len(groupRoles)+1)
+	roleIDs = append(roleIDs, membership.RoleID)
+	for i := range groupRoles {
+		roleIDs = append(roleIDs, groupRoles[i].ID)
+	}
+	return resolveRoles(graph, roleIDs...)
+}
+
+// rolePermissions returns the permissions of a role with inherited ones. The role may differ from
+// the stored one, so that a change can be checked before it is saved.
+func rolePermissions(workspaceRepo repo.WorkspaceRepositoryInterface, role *models.WorkspaceRole) ([]string, error) {
+	graph, err := workspaceRoleGraph(workspaceRepo, role.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	graph[role.ID] = roleDefinition(role)
+
+	return resolveRoles(graph, role.ID)
+}
+
+// workspaceRoleGraph returns the active roles of a workspace by ID, inactive roles grant nothing
+func workspaceRoleGraph(workspaceRepo repo.WorkspaceRepositoryInterface, workspaceID string) (map[string]utils.RoleDefinition, error) {
+	roles, err := workspaceRepo.GetWorkspaceRoles(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return roleGraph(roles), nil
+}
+
+func roleGraph(roles []models.WorkspaceRole) map[string]utils.RoleDefinition {
+	graph := make(map[string]utils.RoleDefinition, len(roles))
+	for i := range roles {
+		if roles[i].Status == common.ActiveStatus {
+			graph[roles[i].ID] = roleDefinition(&roles[i])
+		}
+	}
+	return graph
+}
+
+func roleDefinition(role *models.WorkspaceRole) utils.RoleDefinition {
+	return utils.RoleDefinition{
+		Permissions: role.Permissions.Permissions,
+		Inherits:    role.Permissions.Inherits,
+	}
+}
+
+func resolveRoles(graph map[string]utils.RoleDefinition, roleIDs ...string) ([]string, error) {
+	permissions, err := utils.ResolveRolePermissions(graph, roleIDs...)
+	if errors.Is(err, utils.ErrRoleCycle) {
+		return nil, common.ErrRoleInheritanceCycle
+	}
+	return permissions, err
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"go-backend-v2/global"
 	"go-backend-v2/internal/common"
@@ -8,6 +9,8 @@ import (
 	"go-backend-v2/internal/models"
 	"go-backend-v2/internal/repo"
 	"go-backend-v2/pkg/utils"
+	"sort"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -16,12 +19,14 @@ import (
 type WorkspaceService struct {
 	workspaceRepo repo.WorkspaceRepositoryInterface
 	userRepo      repo.UserRepositoryInterface
+	authCache     AuthCacheInterface
 }
 
-func NewWorkspaceService(workspaceRepo repo.WorkspaceRepositoryInterface, userRepo repo.UserRepositoryInterface) WorkspaceServiceInterface {
+func NewWorkspaceService(workspaceRepo repo.WorkspaceRepositoryInterface, userRepo repo.UserRepositoryInterface, authCache AuthCacheInterface) WorkspaceServiceInterface {
 	return &WorkspaceService{
 		workspaceRepo: workspaceRepo,
 		userRepo:      userRepo,
+		authCache:     authCache,
 	}
 }
 
@@ -36,83 +41,73 @@ func (s *WorkspaceService) CreateWorkspace(userID string, req *dto.CreateWorkspa
 	if user.Status != common.UserStatusActive {
 		return nil, common.ErrUserInactive
 	}
-	if user.GlobalRole != common.GlobalRoleSuperAdmin {
+	// Super admins can always create workspaces and are not bound by the owned workspace quota
+	isSuperAdmin := user.GlobalRole == common.GlobalRoleSuperAdmin
+	if !isSuperAdmin && global.Config.Workspace.CreationMode != common.WorkspaceCreationSelfServe {
 		return nil, common.ErrWorkspaceCreateForbidden
 	}
 
-	uniqueSlug, err := utils.GenerateUniqueSlug(req.Name, s.workspaceRepo.ExistsBySlug)
-	if err != nil {
-		return nil, common.ErrWorkspaceSlugGeneration
-	}
-
 	var result *dto.WorkspaceResponse
 
-	err = global.DB.Transaction(func(tx *gorm.DB) error {
-		workspace := &models.Workspace{
-			Name:        req.Name,
-			Slug:        uniqueSlug,
-			Description: &req.Description,
-			OwnerID:     userID,
-			Status:      common.ActiveStatus,
-		}
+	// The unique index on the slug settles races between workspaces created with the same name
+	_, err = utils.GenerateUniqueSlug(req.Name, func(slug string) error {
+		return global.DB.Transaction(func(tx *gorm.DB) error {
+			if err := checkOwnedWorkspaceQuota(tx, s.userRepo, s.workspaceRepo, user); err != nil {
+				return err
+			}
 
-		if err := s.workspaceRepo.CreateWorkspace(tx, workspace); err != nil {
-			return fmt.Errorf("failed to create workspace: %w", err)
-		}
+			workspace := &models.Workspace{
+				Name:        req.Name,
+				Slug:        slug,
+				Description: &req.Description,
+				OwnerID:     userID,
+				Plan:        defaultWorkspacePlan(),
+				Status:      common.ActiveStatus,
+			}
 
-		adminRole := &models.WorkspaceRole{
-			WorkspaceID: workspace.ID,
-			Name:        common.WorkspaceRoleAdmin,
-			Description: stringPtr("Full administrative access to workspace"),
-			Permissions: models.RolePermissions{
-				Permissions: []string{"all"},
-				Metadata: models.PermissionMetadata{
-					Version:     "1.0",
-					CreatedBy:   "system",
-					UpdatedBy:   "system",
-					UpdatedAt:   time.Now(),
-					Description: "Default admin role with full permissions",
-				},
-			},
-			Status: common.ActiveStatus,
-		}
+			if err := s.workspaceRepo.CreateWorkspace(tx, workspace); err != nil {
+				return err
+			}
+			if err := s.workspaceRepo.ClaimSlug(tx, workspace.ID, slug); err != nil {
+				return err
+			}
 
-		if err := s.workspaceRepo.CreateWorkspaceRole(tx, adminRole); err != nil {
-			return fmt.Errorf("failed to create admin role: %w", err)
-		}
+			adminRole, err := createAdminRole(tx, s.workspaceRepo, workspace.ID)
+			if err != nil {
+				return err
+			}
 
-		membership := &models.UserWorkspaceMembership{
-			UserID:      userID,
-			WorkspaceID: workspace.ID,
-			RoleID:      adminRole.ID,
-			Status:      common.ActiveStatus,
-			JoinedAt:    &time.Time{},
-		}
-		now := time.Now()
-		membership.JoinedAt = &now
+			membership := &models.UserWorkspaceMembership{
+				UserID:      userID,
+				WorkspaceID: workspace.ID,
+				RoleID:      adminRole.ID,
+				Status:      common.ActiveStatus,
+				JoinedAt:    &time.Time{},
+			}
+			now := time.Now()
+			membership.JoinedAt = &now
 
-		if err := s.workspaceRepo.CreateMembership(tx, membership); err != nil {
-			return fmt.Errorf("failed to create membership: %w", err)
-		}
+			if err := s.workspaceRepo.CreateMembership(tx, membership); err != nil {
+				return fmt.Errorf("failed to create membership: %w", err)
+			}
 
-		result = &dto.WorkspaceResponse{
-			ID:          workspace.ID,
-			Name:        workspace.Name,
-			Slug:        workspace.Slug,
-			Description: getStringValue(workspace.Description),
-			OwnerID:     workspace.OwnerID,
-			Status:      workspace.Status,
-			CreatedAt:   workspace.CreatedAt,
-			UpdatedAt:   workspace.UpdatedAt,
-		}
+			result = toWorkspaceResponse(workspace)
 
-		return nil
+			return nil
+		})
 	})
-
 	if err != nil {
+		switch {
+		case errors.Is(err, common.ErrWorkspaceQuotaExceeded):
+			return nil, err
+		case errors.Is(err, utils.ErrSlugTaken):
+			return nil, common.ErrWorkspaceSlugGeneration
+		}
 		return nil, common.ErrWorkspaceCreateFailed
 	}
 
+	s.authCache.InvalidateUsers(userID)
+
 	if global.EventTopicPublisher != nil {
 
 		payload := &dto.WorkspaceCreatedPayload{
@@ -132,6 +127,403 @@ func (s *WorkspaceService) CreateWorkspace(userID string, req *dto.CreateWorkspa
 	return result, nil
 }
 
+// checkOwnedWorkspaceQuota fails when the user already owns as many workspaces as allowed. Super
+// admins are not bound by the quota. The user row stays locked until the transaction ends, so
+// creations and ownership transfers running in parallel cannot both pass the check.
+func checkOwnedWorkspaceQuota(tx *gorm.DB, userRepo repo.UserRepositoryInterface, workspaceRepo repo.WorkspaceRepositoryInterface, user *models.User) error {
+	limit := global.Config.Workspace.MaxOwnedPerUser
+	if limit <= 0 || user.GlobalRole == common.GlobalRoleSuperAdmin {
+		return nil
+	}
+
+	if err := userRepo.LockUser(tx, user.ID); err != nil {
+		return err
+	}
+
+	owned, err := workspaceRepo.CountOwnedWorkspaces(tx, user.ID)
+	if err != nil {
+		return err
+	}
+	if owned >= int64(limit) {
+		return common.ErrWorkspaceQuotaExceeded
+	}
+
+	return nil
+}
+
+// ListMyWorkspaces returns the workspaces the user is an active member of, archived ones included
+func (s *WorkspaceService) ListMyWorkspaces(userID string) ([]dto.WorkspaceResponse, error) {
+	memberships, err := s.workspaceRepo.GetUserMembershipsWithDetails(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaces := make([]dto.WorkspaceResponse, 0, len(memberships))
+	for i := range memberships {
+		membership := &memberships[i]
+		if membership.Status != models.MembershipStatusActive {
+			continue
+		}
+		if status := membership.Workspace.Status; status != common.ActiveStatus && status != common.ArchivedStatus {
+			continue
+		}
+
+		workspace := toWorkspaceResponse(&membership.Workspace)
+		workspace.Role = membership.Role.Name
+		workspaces = append(workspaces, *workspace)
+	}
+
+	sort.Slice(workspaces, func(i, j int) bool {
+		return strings.ToLower(workspaces[i].Name) < strings.ToLower(workspaces[j].Name)
+	})
+
+	return workspaces, nil
+}
+
+// GetWorkspace returns a workspace by slug to a user allowed to read it
+func (s *WorkspaceService) GetWorkspace(userID, slug string) (*dto.WorkspaceResponse, error) {
+	workspace, err := s.workspaceRepo.GetWorkspaceBySlug(slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	if workspace == nil || workspace.Status == common.DeletedStatus {
+		return nil, common.ErrWorkspaceNotFound
+	}
+
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, common.PermissionWorkspaceRead); err != nil {
+		return nil, err
+	}
+
+	return toWorkspaceResponse(workspace), nil
+}
+
+// ResolveWorkspaceID accepts a workspace ID or slug, so that clients can address a workspace either way
+func (s *WorkspaceService) ResolveWorkspaceID(ref string) (string, error) {
+	workspace, err := resolveLiveWorkspace(s.workspaceRepo, ref)
+	if err != nil {
+		return "", err
+	}
+	return workspace.ID, nil
+}
+
+// UpdateWorkspace changes name, description and settings. Archived workspaces are read-only.
+func (s *WorkspaceService) UpdateWorkspace(userID, workspaceID string, req *dto.UpdateWorkspaceRequest) (*dto.WorkspaceResponse, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, common.PermissionWorkspaceUpdate); err != nil {
+		return nil, err
+	}
+	if workspace.Status == common.ArchivedStatus {
+		return nil, common.ErrWorkspaceArchived
+	}
+
+	updates := map[string]interface{}{}
+	var changedFields []string
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, common.ErrWorkspaceNameRequired
+		}
+		if name != workspace.Name {
+			updates["name"] = name
+			changedFields = append(changedFields, "name")
+		}
+	}
+
+	if req.Description != nil && *req.Description != getStringValue(workspace.Description) {
+		if *req.Description == "" {
+			updates["description"] = nil
+		} else {
+			updates["description"] = *req.Description
+		}
+		changedFields = append(changedFields, "description")
+	}
+
+	if req.Settings != nil {
+		settings, changed, err := applyWorkspaceSettingsPatch(s.workspaceRepo, workspace, req.Settings)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			updates["settings"] = settings
+			changedFields = append(changedFields, "settings")
+		}
+	}
+
+	if len(updates) == 0 {
+		return toWorkspaceResponse(workspace), nil
+	}
+
+	if err := s.workspaceRepo.UpdateWorkspace(workspaceID, updates); err != nil {
+		return nil, fmt.Errorf("failed to update workspace: %w", err)
+	}
+
+	s.publishUpdated(workspaceID, userID, changedFields)
+
+	return s.GetWorkspaceByID(workspaceID)
+}
+
+// RenameSlug changes the slug of a workspace. Former slugs stay reserved for the workspace and
+// keep resolving to it, so existing links do not break.
+func (s *WorkspaceService) RenameSlug(userID, workspaceID string, req *dto.RenameWorkspaceSlugRequest) (*dto.WorkspaceResponse, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, common.PermissionWorkspaceUpdate); err != nil {
+		return nil, err
+	}
+	if workspace.Status == common.ArchivedStatus {
+		return nil, common.ErrWorkspaceArchived
+	}
+
+	if req.Slug == workspace.Slug {
+		return toWorkspaceResponse(workspace), nil
+	}
+	if utils.IsReservedSlug(req.Slug) {
+		return nil, common.ErrWorkspaceSlugReserved
+	}
+
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		return s.workspaceRepo.RenameSlug(tx, workspaceID, workspace.Slug, req.Slug)
+	})
+	if err != nil {
+		if errors.Is(err, utils.ErrSlugTaken) {
+			return nil, common.ErrWorkspaceSlugTaken
+		}
+		return nil, fmt.Errorf("failed to rename workspace slug: %w", err)
+	}
+
+	s.publishUpdated(workspaceID, userID, []string{"slug"})
+
+	return s.GetWorkspaceByID(workspaceID)
+}
+
+// GetSettings returns the settings of a workspace in the current schema version
+func (s *WorkspaceService) GetSettings(userID, workspaceID string) (map[string]interface{}, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, common.PermissionWorkspaceRead); err != nil {
+		return nil, err
+	}
+
+	settings, _ := upgradeWorkspaceSettings(workspace.Settings)
+	return settings, nil
+}
+
+// UpdateSettings applies a JSON merge patch to the settings of a workspace. The result must match
+// the settings schema. Archived workspaces are read-only.
+func (s *WorkspaceService) UpdateSettings(userID, workspaceID string, patch map[string]interface{}) (map[string]interface{}, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, common.PermissionWorkspaceUpdate); err != nil {
+		return nil, err
+	}
+	if workspace.Status == common.ArchivedStatus {
+		return nil, common.ErrWorkspaceArchived
+	}
+
+	settings, changed, err := applyWorkspaceSettingsPatch(s.workspaceRepo, workspace, patch)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return settings, nil
+	}
+
+	if err := s.workspaceRepo.UpdateWorkspace(workspaceID, map[string]interface{}{"settings": settings}); err != nil {
+		return nil, fmt.Errorf("failed to update workspace settings: %w", err)
+	}
+
+	s.publishUpdated(workspaceID, userID, []string{"settings"})
+
+	return settings, nil
+}
+
+// ArchiveWorkspace makes an active workspace read-only. Members keep their access for reading.
+func (s *WorkspaceService) ArchiveWorkspace(userID, workspaceID string) (*dto.WorkspaceResponse, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, common.PermissionWorkspaceArchive); err != nil {
+		return nil, err
+	}
+
+	switch workspace.Status {
+	case common.ActiveStatus:
+	case common.ArchivedStatus:
+		return nil, common.ErrWorkspaceArchived
+	default:
+		return nil, common.ErrWorkspaceInactive
+	}
+
+	archivedAt := time.Now()
+	err = s.workspaceRepo.UpdateWorkspace(workspaceID, map[string]interface{}{
+		"status":      common.ArchivedStatus,
+		"archived_at": archivedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive workspace: %w", err)
+	}
+
+	if global.EventTopicPublisher != nil {
+		payload := &dto.WorkspaceArchivedPayload{
+			WorkspaceID:  workspaceID,
+			ArchivedByID: userID,
+			ArchivedAt:   archivedAt,
+		}
+		go func() {
+			if err := global.EventTopicPublisher.Publish(common.WorkspaceArchivedLog, payload); err != nil {
+				fmt.Printf("Error publishing workspace archived event: %v\n", err)
+			}
+		}()
+	}
+
+	return s.GetWorkspaceByID(workspaceID)
+}
+
+// UnarchiveWorkspace makes an archived workspace active again
+func (s *WorkspaceService) UnarchiveWorkspace(userID, workspaceID string) (*dto.WorkspaceResponse, error) {
+	workspace, err := getLiveWorkspace(s.workspaceRepo, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeWorkspace(s.workspaceRepo, s.authCache, workspace, userID, common.PermissionWorkspaceArchive); err != nil {
+		return nil, err
+	}
+	if workspace.Status != common.ArchivedStatus {
+		return nil, common.ErrWorkspaceNotArchived
+	}
+
+	err = s.workspaceRepo.UpdateWorkspace(workspaceID, map[string]interface{}{
+		"status":      common.ActiveStatus,
+		"archived_at": nil,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to unarchive workspace: %w", err)
+	}
+
+	s.publishUpdated(workspaceID, userID, []string{"status"})
+
+	return s.GetWorkspaceByID(workspaceID)
+}
+
+// UpdatePlan moves a workspace to another configured plan. A lower member limit does not remove
+// members, it only stops new ones from joining until the workspace is back under it.
+func (s *WorkspaceService) UpdatePlan(userID, workspaceID string, req *dto.UpdateWorkspacePlanRequest) (*dto.WorkspaceResponse, error) {
+	if _, ok := global.Config.Workspace.Plans[req.Plan]; !ok {
+		return nil, common.ErrWorkspacePlanInvalid
+	}
+
+	workspace, err := s.workspaceRepo.GetWorkspaceByID(workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	if workspace == nil {
+		return nil, common.ErrWorkspaceNotFound
+	}
+	if workspace.Plan == req.Plan {
+		return toWorkspaceResponse(workspace), nil
+	}
+
+	if err := s.workspaceRepo.UpdateWorkspace(workspaceID, map[string]interface{}{"plan": req.Plan}); err != nil {
+		return nil, fmt.Errorf("failed to update workspace plan: %w", err)
+	}
+
+	s.publishUpdated(workspaceID, userID, []string{"plan"})
+
+	return s.GetWorkspaceByID(workspaceID)
+}
+
+// ListWorkspaces returns a page of all workspaces for the admin API
+func (s *WorkspaceService) ListWorkspaces(query *dto.ListWorkspacesQuery) (*dto.WorkspaceListResponse, error) {
+	workspaces, total, err := s.workspaceRepo.ListWorkspaces(query.Status, query.Search, query.Offset, query.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.WorkspaceListResponse{
+		Workspaces: make([]dto.WorkspaceResponse, 0, len(workspaces)),
+		Total:      total,
+		Offset:     query.Offset,
+		Limit:      query.Limit,
+	}
+	for i := range workspaces {
+		result.Workspaces = append(result.Workspaces, *toWorkspaceResponse(&workspaces[i]))
+	}
+
+	return result, nil
+}
+
+// GetWorkspaceByID returns a workspace in any status
+func (s *WorkspaceService) GetWorkspaceByID(workspaceID string) (*dto.WorkspaceResponse, error) {
+	workspace, err := s.workspaceRepo.GetWorkspaceByID(workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	if workspace == nil {
+		return nil, common.ErrWorkspaceNotFound
+	}
+
+	return toWorkspaceResponse(workspace), nil
+}
+
+func (s *WorkspaceService) publishUpdated(workspaceID, userID string, changedFields []string) {
+	if global.EventTopicPublisher == nil {
+		return
+	}
+
+	payload := &dto.WorkspaceUpdatedPayload{
+		WorkspaceID:   workspaceID,
+		UpdatedByID:   userID,
+		ChangedFields: changedFields,
+	}
+	go func() {
+		if err := global.EventTopicPublisher.Publish(common.WorkspaceUpdatedLog, payload); err != nil {
+			fmt.Printf("Error publishing workspace updated event: %v\n", err)
+		}
+	}()
+}
+
+func currentWorkspaceSettings(current *models.WorkspaceSettings) models.WorkspaceSettings {
+	settings := models.WorkspaceSettings{}
+	if current != nil {
+		for key, value := range *current {
+			settings[key] = value
+		}
+	}
+	return settings
+}
+
+func toWorkspaceResponse(workspace *models.Workspace) *dto.WorkspaceResponse {
+	response := &dto.WorkspaceResponse{
+		ID:                 workspace.ID,
+		Name:               workspace.Name,
+		Slug:               workspace.Slug,
+		Description:        getStringValue(workspace.Description),
+		AvatarURL:          getStringValue(workspace.AvatarURL),
+		AvatarThumbnailURL: getStringValue(workspace.AvatarThumbnailURL),
+		OwnerID:            workspace.OwnerID,
+		Plan:               workspace.Plan,
+		Status:             workspace.Status,
+		ArchivedAt:         workspace.ArchivedAt,
+		CreatedAt:          workspace.CreatedAt,
+		UpdatedAt:          workspace.UpdatedAt,
+	}
+	if workspace.Settings != nil && len(*workspace.Settings) > 0 {
+		response.Settings = *workspace.Settings
+	}
+	return response
+}
+
 func stringPtr(s string) *string {
 	return &s
 }
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/repo"
+	"go-backend-v2/pkg/utils"
+	"reflect"
+)
+
+const workspaceSettingsMigrationBatchSize = 100
+
+// workspaceSettingsSchema is the schema served to clients, settings are validated against it alone
+var workspaceSettingsSchema = utils.MustCompileJSONSchema(dto.WorkspaceSettingsSchema)
+
+// workspaceSettingsMigrations upgrade stored settings one version at a time: entry i turns
+// version i into version i+1. Settings written before the schema existed are version 0.
+var workspaceSettingsMigrations = []func(models.WorkspaceSettings) models.WorkspaceSettings{
+	migrateWorkspaceSettingsV0,
+	migrateWorkspaceSettingsV1,
+}
+
+// migrateWorkspaceSettingsV0 keeps the keys of the free-form settings that version 1 defines and
+// drops the others, which the schema would reject
+func migrateWorkspaceSettingsV0(settings models.WorkspaceSettings) models.WorkspaceSettings {
+	known := map[string]bool{
+		"default_member_role_id": true,
+		"allowed_email_domains":  true,
+		"session_policy":         true,
+		"branding":               true,
+	}
+
+	migrated := models.WorkspaceSettings{}
+	for key, value := range settings {
+		if known[key] {
+			migrated[key] = value
+		}
+	}
+	return migrated
+}
+
+// migrateWorkspaceSettingsV1 drops the email domain and session policy settings of version 1, which
+// nothing enforced
+func migrateWorkspaceSettingsV1(settings models.WorkspaceSettings) models.WorkspaceSettings {
+	migrated := models.WorkspaceSettings{}
+	for key, value := range settings {
+		if key != "allowed_email_domains" && key != "session_policy" {
+			migrated[key] = value
+		}
+	}
+	return migrated
+}
+
+// workspaceSettingsVersion returns the schema version of stored settings, 0 when they have none
+func workspaceSettingsVersion(settings map[string]interface{}) int {
+	switch version := settings["version"].(type) {
+	case float64:
+		return int(version)
+	case int:
+		return version
+	}
+	return 0
+}
+
+// upgradeWorkspaceSettings brings stored settings to the current schema version and reports
+// whether they changed. Workspaces without settings get an empty document of the current version.
+func upgradeWorkspaceSettings(current *models.WorkspaceSettings) (models.WorkspaceSettings, bool) {
+	settings := currentWorkspaceSettings(current)
+	if len(settings) == 0 {
+		return models.WorkspaceSettings{"version": common.WorkspaceSettingsVersion}, false
+	}
+
+	version := workspaceSettingsVersion(settings)
+	if version >= common.WorkspaceSettingsVersion {
+		return settings, false
+	}
+
+	for ; version < common.WorkspaceSettingsVersion; version++ {
+		settings = workspaceSettingsMigrations[version](settings)
+		settings["version"] = version + 1
+	}
+
+	// A legacy value the schema rejects would make every later update fail, it is dropped instead
+	settings, dropped := workspaceSettingsSchema.PruneProperties(settings)
+	for key, err := range dropped {
+		fmt.Printf("Warning: dropping invalid workspace setting %s during migration: %v\n", key, err)
+	}
+	return settings, true
+}
+
+// applyWorkspaceSettingsPatch merges a JSON merge patch into the settings of a workspace and
+// validates the result against the schema. It reports whether the stored settings change.
+func applyWorkspaceSettingsPatch(workspaceRepo repo.WorkspaceRepositoryInterface, workspace *models.Workspace, patch map[string]interface{}) (models.WorkspaceSettings, bool, error) {
+	current, upgraded := upgradeWorkspaceSettings(workspace.Settings)
+
+	if _, ok := patch["version"]; ok && workspaceSettingsVersion(patch) != common.WorkspaceSettingsVersion {
+		return nil, false, invalidSettings(fmt.Sprintf("version is managed by the server and must be %d", common.WorkspaceSettingsVersion))
+	}
+
+	merged := models.WorkspaceSettings(utils.MergePatch(current, patch))
+	merged["version"] = current["version"]
+
+	settings, err := parseWorkspaceSettings(merged)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// A stored role that can no longer be assigned is ignored when members join, so it is only
+	// checked when the patch sets it and does not block unrelated updates
+	if _, ok := patch["default_member_role_id"]; ok && settings.DefaultMemberRoleID != nil {
+		role, err := getAssignableRole(workspaceRepo, workspace.ID, *settings.DefaultMemberRoleID)
+		if err != nil {
+			if errors.Is(err, common.ErrWorkspaceRoleNotFound) {
+				return nil, false, invalidSettings("default_member_role_id must be an active role of the workspace")
+			}
+			return nil, false, err
+		}
+		if role.Name == common.WorkspaceRoleAdmin {
+			return nil, false, invalidSettings("default_member_role_id cannot be the admin role")
+		}
+	}
+
+	return merged, upgraded || !reflect.DeepEqual(merged, current), nil
+}
+
+// parseWorkspaceSettings validates settings against the schema and decodes them into their
+// typed form
+func parseWorkspaceSettings(settings models.WorkspaceSettings) (*dto.WorkspaceSettings, error) {
+	if err := workspaceSettingsSchema.Validate(settings); err != nil {
+		var schemaErr *utils.SchemaError
+		if errors.As(err, &schemaErr) {
+			return nil, invalidSettings(schemaErr.Error())
+		}
+		return nil, fmt.Errorf("failed to validate workspace settings: %w", err)
+	}
+
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode workspace settings: %w", err)
+	}
+
+	var parsed dto.WorkspaceSettings
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode workspace settings: %w", err)
+	}
+
+	return &parsed, nil
+}
+
+func invalidSettings(message string) error {
+	return &common.APIError{
+		Status:  common.ErrInvalidSettings.Status,
+		Code:    common.ErrInvalidSettings.Code,
+		Message: message,
+	}
+}
+
+// MigrateWorkspaceSettings upgrades the stored settings of every workspace to the current schema
+// version. It runs at startup, before requests are served, and returns the number of workspaces upgraded.
+func MigrateWorkspaceSettings(workspaceRepo repo.WorkspaceRepositoryInterface) (int, error) {
+	migrated := 0
+	afterID := ""
+	for {
+		workspaces, err := workspaceRepo.GetWorkspacesWithSettingsBelow(common.WorkspaceSettingsVersion, afterID, workspaceSettingsMigrationBatchSize)
+		if err != nil {
+			return migrated, err
+		}
+
+		for i := range workspaces {
+			workspace := &workspaces[i]
+			settings, upgraded := upgradeWorkspaceSettings(workspace.Settings)
+			if !upgraded {
+				continue
+			}
+			if err := workspaceRepo.UpdateWorkspace(workspace.ID, map[string]interface{}{"settings": settings}); err != nil {
+				return migrated, fmt.Errorf("failed to migrate settings of workspace %s: %w", workspace.ID, err)
+			}
+			migrated++
+		}
+
+		if len(workspaces) < workspaceSettingsMigrationBatchSize {
+			return migrated, nil
+		}
+		afterID = workspaces[len(workspaces)-1].ID
+	}
+}
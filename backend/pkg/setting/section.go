@@ -3,7 +3,9 @@ package setting
 import "time"
 
 type Server struct {
-	Port int `mapstructure:"port"`
+	Port        int    `mapstructure:"port"`
+	PublicURL   string `mapstructure:"public_url"`   // externally reachable base URL of this service
+	FrontendURL string `mapstructure:"frontend_url"` // base URL of the web client, used for redirects and links
 }
 
 type Redis struct {
@@ -37,6 +39,15 @@ type JWT struct {
 	EncryptionKey  string        `mapstructure:"encryption_key"`
 }
 
+// Session bounds login sessions by inactivity (sliding) and by total age (absolute). The
+// remember me policy applies when the user opts in on login.
+type Session struct {
+	IdleTimeout               time.Duration `mapstructure:"idle_timeout"`
+	AbsoluteTimeout           time.Duration `mapstructure:"absolute_timeout"`
+	RememberMeIdleTimeout     time.Duration `mapstructure:"remember_me_idle_timeout"`
+	RememberMeAbsoluteTimeout time.Duration `mapstructure:"remember_me_absolute_timeout"`
+}
+
 type Cookie struct {
 	Domain   string `mapstructure:"domain"`
 	Secure   bool   `mapstructure:"secure"`
@@ -52,11 +63,109 @@ type RabbitMQ struct {
 	IamExchange string `mapstructure:"iam_exchange"`
 }
 
+type Notifier struct {
+	Driver string `mapstructure:"driver"`
+	Topic  string `mapstructure:"topic"`
+}
+
+type Profile struct {
+	AllowedCountries  []string      `mapstructure:"allowed_countries"`   // ISO 3166-1 alpha-2 codes, empty allows all
+	EmailChangeTTL    time.Duration `mapstructure:"email_change_ttl"`    // lifetime of the link sent to a new address
+	EmailRevertWindow time.Duration `mapstructure:"email_revert_window"` // how long the old address can undo a change
+
+	DeletionGracePeriod time.Duration `mapstructure:"deletion_grace_period"` // time to restore an account before it is purged
+}
+
+type Storage struct {
+	Driver    string    `mapstructure:"driver"`     // local | s3
+	LocalPath string    `mapstructure:"local_path"` // root directory of the local driver
+	S3        S3Storage `mapstructure:"s3"`
+}
+
+// S3Storage works with AWS S3 and any S3 compatible service (MinIO, R2, ...)
+type S3Storage struct {
+	Endpoint  string `mapstructure:"endpoint"` // host[:port], without scheme
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	AccessKey string `mapstructure:"access_key"`
+	SecretKey string `mapstructure:"secret_key"`
+	UseSSL    bool   `mapstructure:"use_ssl"`
+}
+
+type DataExport struct {
+	LinkTTL   time.Duration `mapstructure:"link_ttl"`  // lifetime of a download link
+	Retention time.Duration `mapstructure:"retention"` // archives are deleted after this
+}
+
+// Avatar limits uploaded user and workspace pictures
+type Avatar struct {
+	MaxSize       int64 `mapstructure:"max_size"`       // upload limit in bytes
+	MaxDimension  int   `mapstructure:"max_dimension"`  // longest accepted side in pixels
+	Size          int   `mapstructure:"size"`           // side of the stored square image
+	ThumbnailSize int   `mapstructure:"thumbnail_size"` // side of the stored thumbnail
+}
+
+type Invitation struct {
+	TTL            time.Duration `mapstructure:"ttl"`             // lifetime of an invitation link
+	ResendCooldown time.Duration `mapstructure:"resend_cooldown"` // minimum time between two mails of one invitation
+}
+
+// Workspace controls who may create workspaces and what each plan allows
+type Workspace struct {
+	CreationMode    string                   `mapstructure:"creation_mode"`      // admin_only | self_serve
+	MaxOwnedPerUser int                      `mapstructure:"max_owned_per_user"` // 0 for no limit, super admins are exempt
+	DefaultPlan     string                   `mapstructure:"default_plan"`       // plan of newly created workspaces
+	Plans           map[string]WorkspacePlan `mapstructure:"plans"`
+}
+
+type WorkspacePlan struct {
+	MaxMembers int `mapstructure:"max_members"` // active and suspended members, 0 for no limit
+}
+
+type LDAP struct {
+	Enabled             bool               `mapstructure:"enabled"`
+	URL                 string             `mapstructure:"url"` // ldap://host:389 or ldaps://host:636
+	StartTLS            bool               `mapstructure:"start_tls"`
+	InsecureSkipVerify  bool               `mapstructure:"insecure_skip_verify"`
+	BindDN              string             `mapstructure:"bind_dn"`
+	BindPassword        string             `mapstructure:"bind_password"`
+	BaseDN              string             `mapstructure:"base_dn"`
+	UserFilter          string             `mapstructure:"user_filter"` // {username} is replaced by the escaped login name
+	Timeout             time.Duration      `mapstructure:"timeout"`
+	AllowAccountLinking bool               `mapstructure:"allow_account_linking"` // link existing accounts with the same email
+	Attributes          LDAPAttributes     `mapstructure:"attributes"`
+	GroupMappings       []LDAPGroupMapping `mapstructure:"group_mappings"`
+}
+
+type LDAPAttributes struct {
+	Email       string `mapstructure:"email"`
+	FirstName   string `mapstructure:"first_name"`
+	LastName    string `mapstructure:"last_name"`
+	DisplayName string `mapstructure:"display_name"`
+	Groups      string `mapstructure:"groups"`
+}
+
+// LDAPGroupMapping grants members of a directory group a role in a workspace
+type LDAPGroupMapping struct {
+	Group       string `mapstructure:"group"` // group DN as listed in the groups attribute
+	WorkspaceID string `mapstructure:"workspace_id"`
+	Role        string `mapstructure:"role"` // workspace role name
+}
+
 type Config struct {
-	Server   Server   `mapstructure:"server"`
-	Redis    Redis    `mapstructure:"redis"`
-	Mysql    Mysql    `mapstructure:"mysql"`
-	JWT      JWT      `mapstructure:"jwt"`
-	Cookie   Cookie   `mapstructure:"cookie"`
-	RabbitMQ RabbitMQ `mapstructure:"rabbitmq"`
+	Server     Server     `mapstructure:"server"`
+	Redis      Redis      `mapstructure:"redis"`
+	Mysql      Mysql      `mapstructure:"mysql"`
+	JWT        JWT        `mapstructure:"jwt"`
+	Session    Session    `mapstructure:"session"`
+	Cookie     Cookie     `mapstructure:"cookie"`
+	RabbitMQ   RabbitMQ   `mapstructure:"rabbitmq"`
+	Notifier   Notifier   `mapstructure:"notifier"`
+	LDAP       LDAP       `mapstructure:"ldap"`
+	Profile    Profile    `mapstructure:"profile"`
+	Storage    Storage    `mapstructure:"storage"`
+	DataExport DataExport `mapstructure:"data_export"`
+	Avatar     Avatar     `mapstructure:"avatar"`
+	Invitation Invitation `mapstructure:"invitation"`
+	Workspace  Workspace  `mapstructure:"workspace"`
 }
@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"path"
+	"strings"
+
+	_ "image/gif"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	defaultAvatarSize          = 512
+	defaultAvatarThumbnailSize = 128
+	defaultAvatarMaxDimension  = 8000
+
+	avatarJPEGQuality = 85
+)
+
+var (
+	ErrAvatarUnsupportedType = errors.New("unsupported avatar image type")
+	ErrAvatarInvalidImage    = errors.New("invalid avatar image")
+	ErrAvatarTooLarge        = errors.New("avatar image is too large")
+)
+
+// avatarContentTypes are the sniffed types accepted for upload
+var avatarContentTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// AvatarOptions bounds an upload and sets the sizes it is resized to
+type AvatarOptions struct {
+	MaxBytes      int64 // zero disables the size check
+	MaxDimension  int   // longest accepted side in pixels, checked before decoding
+	Size          int
+	ThumbnailSize int
+}
+
+// AvatarImage is an encoded square rendition of an avatar
+type AvatarImage struct {
+	Data        []byte
+	ContentType string
+	Extension   string
+}
+
+// ProcessedAvatar holds the renditions stored for one upload
+type ProcessedAvatar struct {
+	Image     AvatarImage
+	Thumbnail AvatarImage
+}
+
+// ProcessAvatar checks an uploaded image by its content rather than its declared type, crops it
+// to a centered square and renders the avatar and its thumbnail. Photos are stored as JPEG, all
+// other images as PNG to keep their transparency.
+func ProcessAvatar(data []byte, opts *AvatarOptions) (*ProcessedAvatar, error) {
+	size, thumbnailSize, maxDimension := defaultAvatarSize, defaultAvatarThumbnailSize, defaultAvatarMaxDimension
+	if opts != nil {
+		if opts.MaxBytes > 0 && int64(len(data)) > opts.MaxBytes {
+			return nil, ErrAvatarTooLarge
+		}
+		if opts.Size > 0 {
+			size = opts.Size
+		}
+		if opts.ThumbnailSize > 0 {
+			thumbnailSize = opts.ThumbnailSize
+		}
+		if opts.MaxDimension > 0 {
+			maxDimension = opts.MaxDimension
+		}
+	}
+
+	contentType := http.DetectContentType(data)
+	if !avatarContentTypes[contentType] {
+		return nil, ErrAvatarUnsupportedType
+	}
+
+	// Read the header first so a small file cannot expand into a huge bitmap
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrAvatarInvalidImage
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, ErrAvatarInvalidImage
+	}
+	if config.Width > maxDimension || config.Height > maxDimension {
+		return nil, ErrAvatarTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrAvatarInvalidImage
+	}
+	square := cropSquare(src)
+
+	asJPEG := contentType == "image/jpeg"
+	full, err := renderAvatar(square, size, asJPEG)
+	if err != nil {
+		return nil, err
+	}
+	thumbnail, err := renderAvatar(square, thumbnailSize, asJPEG)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProcessedAvatar{Image: *full, Thumbnail: *thumbnail}, nil
+}
+
+// AvatarThumbnailKey returns the storage key of the thumbnail stored next to an avatar
+func AvatarThumbnailKey(key string) string {
+	ext := path.Ext(key)
+	return strings.TrimSuffix(key, ext) + "_thumb" + ext
+}
+
+func cropSquare(src image.Image) image.Image {
+	bounds := src.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	x := bounds.Min.X + (bounds.Dx()-side)/2
+	y := bounds.Min.Y + (bounds.Dy()-side)/2
+
+	if sub, ok := src.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(image.Rect(x, y, x+side, y+side))
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(dst, dst.Bounds(), src, image.Pt(x, y), draw.Src)
+	return dst
+}
+
+// renderAvatar scales a square image down to size, never up, and encodes it
+func renderAvatar(square image.Image, size int, asJPEG bool) (*AvatarImage, error) {
+	side := min(square.Bounds().Dx(), size)
+
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), square, square.Bounds(), draw.Src, nil)
+
+	var buf bytes.Buffer
+	if asJPEG {
+		if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: avatarJPEGQuality}); err != nil {
+			return nil, err
+		}
+		return &AvatarImage{Data: buf.Bytes(), ContentType: "image/jpeg", Extension: ".jpg"}, nil
+	}
+
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, err
+	}
+	return &AvatarImage{Data: buf.Bytes(), ContentType: "image/png", Extension: ".png"}, nil
+}
@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/netip"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	DeviceBrowserUnknown = "Unknown"
+	DeviceOSUnknown      = "Unknown"
+	DeviceSubnetUnknown  = "unknown"
+)
+
+// DeviceInfo is the coarse description of a client used for device recognition
+type DeviceInfo struct {
+	Browser     string
+	OS          string
+	IPSubnet    string
+	Fingerprint string
+}
+
+// browserMatchers are checked in order, because most user agents also mention
+// the engines they are compatible with (Edge claims Chrome, Chrome claims Safari)
+var browserMatchers = []struct {
+	token  string
+	family string
+}{
+	{"Edg/", "Edge"},
+	{"Edge/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Opera", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"Firefox/", "Firefox"},
+	{"FxiOS/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Chromium/", "Chrome"},
+	{"Safari/", "Safari"},
+	{"MSIE ", "Internet Explorer"},
+	{"Trident/", "Internet Explorer"},
+	{"PostmanRuntime/", "Postman"},
+	{"curl/", "curl"},
+	{"okhttp/", "OkHttp"},
+}
+
+var osMatchers = []struct {
+	token  string
+	family string
+}{
+	{"iPhone", "iOS"},
+	{"iPad", "iOS"},
+	{"iPod", "iOS"},
+	{"Android", "Android"},
+	{"CrOS", "ChromeOS"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"Macintosh", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// ParseUserAgent extracts the browser and operating system families from a user agent
+func ParseUserAgent(userAgent string) (browser string, os string) {
+	browser = DeviceBrowserUnknown
+	os = DeviceOSUnknown
+
+	for _, m := range browserMatchers {
+		if strings.Contains(userAgent, m.token) {
+			browser = m.family
+			break
+		}
+	}
+
+	for _, m := range osMatchers {
+		if strings.Contains(userAgent, m.token) {
+			os = m.family
+			break
+		}
+	}
+
+	return browser, os
+}
+
+// IPSubnet returns the /24 network for IPv4 addresses and the /64 network for IPv6
+// addresses, so that DHCP renewals inside the same network keep the same device
+func IPSubnet(ipAddress string) string {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ipAddress))
+	if err != nil {
+		return DeviceSubnetUnknown
+	}
+	addr = addr.Unmap()
+
+	bits := 64
+	if addr.Is4() {
+		bits = 24
+	}
+
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return DeviceSubnetUnknown
+	}
+
+	return prefix.String()
+}
+
+// FingerprintDevice builds a stable device fingerprint from the user agent family and IP subnet
+func FingerprintDevice(userAgent, ipAddress string) *DeviceInfo {
+	browser, os := ParseUserAgent(userAgent)
+	subnet := IPSubnet(ipAddress)
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{browser, os, subnet}, "|")))
+
+	return &DeviceInfo{
+		Browser:     browser,
+		OS:          os,
+		IPSubnet:    subnet,
+		Fingerprint: hex.EncodeToString(sum[:]),
+	}
+}
+
+// TruncateUTF8 cuts s to at most max bytes without splitting a multi-byte character, so that the
+// result stays valid UTF-8 for the database
+func TruncateUTF8(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
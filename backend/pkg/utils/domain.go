@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// DomainVerificationLabel is prepended to a domain to name the TXT record that proves its ownership
+const DomainVerificationLabel = "_workspace-verification"
+
+const domainVerificationValuePrefix = "workspace-verification="
+
+var domainNameRegex = regexp.MustCompile(`^(?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+// TXTResolver looks up DNS TXT records. *net.Resolver implements it, tests use a stub.
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// NormalizeDomain lowercases a domain name and drops a trailing dot. It returns an empty string
+// when the result is not a host name with at least two labels.
+func NormalizeDomain(domain string) string {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if len(domain) > 253 || !domainNameRegex.MatchString(domain) {
+		return ""
+	}
+	return domain
+}
+
+// EmailDomain returns the normalized domain of an email address, empty when it has none
+func EmailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return NormalizeDomain(email[at+1:])
+}
+
+// DomainVerificationRecord returns the name of the TXT record checked to verify domain
+func DomainVerificationRecord(domain string) string {
+	return DomainVerificationLabel + "." + domain
+}
+
+// DomainVerificationValue returns the content the TXT record must have for a verification token
+func DomainVerificationValue(token string) string {
+	return domainVerificationValuePrefix + token
+}
+
+// VerifyDomainTXT reports whether the verification record of domain holds the token. A domain
+// without the record is not verified, which is not an error.
+func VerifyDomainTXT(ctx context.Context, resolver TXTResolver, domain, token string) (bool, error) {
+	records, err := resolver.LookupTXT(ctx, DomainVerificationRecord(domain))
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to look up TXT records of %s: %w", domain, err)
+	}
+
+	expected := DomainVerificationValue(token)
+	for _, record := range records {
+		if strings.TrimSpace(record) == expected {
+			return true, nil
+		}
+	}
+	return false, nil
+}
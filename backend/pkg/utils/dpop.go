@@ -0,0 +1,268 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const DPoPProofType = "dpop+jwt"
+
+// DPoPSigningMethods are the asymmetric algorithms accepted for proofs, symmetric ones would let
+// anyone holding the key forge proofs
+var DPoPSigningMethods = []string{"ES256", "ES384", "ES512", "RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "EdDSA"}
+
+var ErrDPoPProofInvalid = errors.New("invalid DPoP proof")
+
+// JWK is the public part of a JSON Web Key as carried in the jwk header of a DPoP proof
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	D   string `json:"d,omitempty"` // private, must never be present
+}
+
+// DPoPProofClaims are the claims of a DPoP proof JWT (RFC 9449 section 4.2)
+type DPoPProofClaims struct {
+	JTI   string `json:"jti"`
+	HTM   string `json:"htm"`
+	HTU   string `json:"htu"`
+	IAT   int64  `json:"iat"`
+	ATH   string `json:"ath,omitempty"`
+	Nonce string `json:"nonce,omitempty"`
+}
+
+// Valid is replaced by the checks in ParseDPoPProof, which need the request context
+func (c *DPoPProofClaims) Valid() error {
+	return nil
+}
+
+// DPoPProofRequest is what a proof must match
+type DPoPProofRequest struct {
+	Method      string
+	URL         string
+	AccessToken string // empty on the token request itself
+	Now         time.Time
+	MaxAge      time.Duration
+	ClockSkew   time.Duration
+}
+
+// DPoPProof is a verified proof
+type DPoPProof struct {
+	JKT      string // JWK SHA-256 thumbprint of the proof key
+	JTI      string
+	IssuedAt time.Time
+}
+
+// ParseDPoPProof verifies a DPoP proof JWT against the request it was sent with. Replay
+// detection on the returned JTI is left to the caller.
+func ParseDPoPProof(proof string, req *DPoPProofRequest) (*DPoPProof, error) {
+	var jwk *JWK
+	claims := &DPoPProofClaims{}
+
+	parser := jwt.NewParser(jwt.WithValidMethods(DPoPSigningMethods))
+	_, err := parser.ParseWithClaims(proof, claims, func(token *jwt.Token) (interface{}, error) {
+		if typ, _ := token.Header["typ"].(string); typ != DPoPProofType {
+			return nil, fmt.Errorf("unexpected typ %q", typ)
+		}
+
+		raw, err := json.Marshal(token.Header["jwk"])
+		if err != nil || token.Header["jwk"] == nil {
+			return nil, errors.New("missing jwk header")
+		}
+		if err := json.Unmarshal(raw, &jwk); err != nil {
+			return nil, fmt.Errorf("invalid jwk header: %w", err)
+		}
+
+		return jwk.PublicKey()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDPoPProofInvalid, err)
+	}
+
+	if claims.JTI == "" {
+		return nil, fmt.Errorf("%w: missing jti", ErrDPoPProofInvalid)
+	}
+	if !strings.EqualFold(claims.HTM, req.Method) {
+		return nil, fmt.Errorf("%w: htm does not match the request method", ErrDPoPProofInvalid)
+	}
+	if !dpopURLMatches(claims.HTU, req.URL) {
+		return nil, fmt.Errorf("%w: htu does not match the request URL", ErrDPoPProofInvalid)
+	}
+
+	issuedAt := time.Unix(claims.IAT, 0)
+	if claims.IAT == 0 || issuedAt.Before(req.Now.Add(-req.MaxAge)) || issuedAt.After(req.Now.Add(req.ClockSkew)) {
+		return nil, fmt.Errorf("%w: iat is outside of the accepted window", ErrDPoPProofInvalid)
+	}
+
+	if req.AccessToken != "" {
+		if claims.ATH != DPoPAccessTokenHash(req.AccessToken) {
+			return nil, fmt.Errorf("%w: ath does not match the access token", ErrDPoPProofInvalid)
+		}
+	}
+
+	thumbprint, err := jwk.Thumbprint()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDPoPProofInvalid, err)
+	}
+
+	return &DPoPProof{
+		JKT:      thumbprint,
+		JTI:      claims.JTI,
+		IssuedAt: issuedAt,
+	}, nil
+}
+
+// VerifyDPoPBinding checks the proof sent with an access token bound to a DPoP key: it must match
+// the request and be signed by that key. The ath claim is only checked when req.AccessToken is
+// set, that is when the client presented the token itself. A token carried by the HttpOnly session
+// cookie cannot be read by the client, so its proofs cannot hash it.
+func VerifyDPoPBinding(claims *JWTClaims, proof string, req *DPoPProofRequest) (*DPoPProof, error) {
+	if claims.Confirmation == nil || claims.Confirmation.JKT == "" {
+		return nil, fmt.Errorf("%w: the access token is not bound to a key", ErrDPoPProofInvalid)
+	}
+
+	verified, err := ParseDPoPProof(proof, req)
+	if err != nil {
+		return nil, err
+	}
+	if verified.JKT != claims.Confirmation.JKT {
+		return nil, fmt.Errorf("%w: the proof key is not the one the access token is bound to", ErrDPoPProofInvalid)
+	}
+
+	return verified, nil
+}
+
+// DPoPAccessTokenHash is the ath claim binding a proof to an access token
+func DPoPAccessTokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// PublicKey converts the JWK into a key usable for signature verification
+func (k *JWK) PublicKey() (crypto.PublicKey, error) {
+	if k.D != "" {
+		return nil, errors.New("jwk must not contain a private key")
+	}
+
+	switch k.Kty {
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeJWKInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("jwk point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "RSA":
+		n, err := decodeJWKInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if n.BitLen() < 2048 {
+			return nil, errors.New("RSA key must be at least 2048 bits")
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// Thumbprint computes the RFC 7638 SHA-256 thumbprint used as cnf.jkt
+func (k *JWK) Thumbprint() (string, error) {
+	var canonical string
+	switch k.Kty {
+	case "EC":
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, k.Crv, k.X, k.Y)
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, k.E, k.N)
+	case "OKP":
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"OKP","x":%q}`, k.Crv, k.X)
+	default:
+		return "", fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func decodeJWKInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(raw) == 0 {
+		return nil, errors.New("invalid jwk parameter")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
+
+// ExternalRequestURL rebuilds the URL a client used to reach this service, preferring the
+// configured public URL over whatever a proxy forwarded
+func ExternalRequestURL(publicURL, baseURL, path string) string {
+	if publicURL != "" {
+		return strings.TrimSuffix(publicURL, "/") + path
+	}
+	return baseURL + path
+}
+
+// dpopURLMatches compares the htu claim with the request URL, ignoring query and fragment
+func dpopURLMatches(htu, requestURL string) bool {
+	left, err := url.Parse(htu)
+	if err != nil {
+		return false
+	}
+	right, err := url.Parse(requestURL)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(left.Scheme, right.Scheme) &&
+		strings.EqualFold(left.Host, right.Host) &&
+		strings.TrimSuffix(left.Path, "/") == strings.TrimSuffix(right.Path, "/")
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"fmt"
+	"go-backend-v2/global"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// invitationAudience keeps invitation tokens and access tokens from being used for one another
+const invitationAudience = "workspace_invitation"
+
+// InvitationClaims identify an invitation. The token ID is the nonce stored with the invitation,
+// so resending an invitation invalidates the links sent before.
+type InvitationClaims struct {
+	InvitationID string `json:"inv"`
+	jwt.RegisteredClaims
+}
+
+// GenerateInvitationToken signs the link token of a workspace invitation
+func GenerateInvitationToken(invitationID, nonce string, expiresAt time.Time) (string, error) {
+	claims := InvitationClaims{
+		InvitationID: invitationID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        nonce,
+			Audience:  jwt.ClaimStrings{invitationAudience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    "go-backend-v2",
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	tokenString, err := token.SignedString([]byte(global.Config.JWT.Secret))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign invitation token: %w", err)
+	}
+
+	return tokenString, nil
+}
+
+// ParseInvitationToken verifies the signature, expiry and audience of an invitation token
+func ParseInvitationToken(tokenString string) (*InvitationClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &InvitationClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(global.Config.JWT.Secret), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse invitation token: %w", err)
+	}
+
+	claims, ok := token.Claims.(*InvitationClaims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid invitation token claims")
+	}
+	if !claims.VerifyAudience(invitationAudience, true) || claims.InvitationID == "" || claims.ID == "" {
+		return nil, fmt.Errorf("not an invitation token")
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,529 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// JSONSchema is a compiled JSON Schema (draft 2020-12) that documents are validated against.
+// Only the keywords listed in jsonSchemaKeywords are supported and compiling a schema that uses
+// any other fails, so that a schema served to clients never promises more than is checked.
+type JSONSchema struct {
+	root *schemaNode
+	defs map[string]*schemaNode
+}
+
+// SchemaError tells where a document breaks its schema. Path is the dotted path of the value,
+// with [i] for array items, and is empty for the document itself.
+type SchemaError struct {
+	Path    string
+	Message string
+}
+
+func (e *SchemaError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + " " + e.Message
+}
+
+// jsonSchemaKeywords are the keywords a schema may use, annotations included
+var jsonSchemaKeywords = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "$defs": true, "$ref": true,
+	"title": true, "description": true, "default": true, "examples": true,
+	"type": true, "const": true, "enum": true,
+	"properties": true, "additionalProperties": true, "required": true,
+	"items": true, "minItems": true, "maxItems": true, "uniqueItems": true,
+	"minLength": true, "maxLength": true, "pattern": true, "format": true,
+	"minimum": true, "maximum": true,
+}
+
+var (
+	ErrInvalidSchema = errors.New("invalid JSON schema")
+
+	schemaUUIDRegex     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	schemaHostnameRegex = regexp.MustCompile(`^[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+)
+
+// jsonSchemaFormats checks the values of the supported formats, which are asserted rather than
+// treated as annotations
+var jsonSchemaFormats = map[string]func(string) bool{
+	"uuid": schemaUUIDRegex.MatchString,
+	"hostname": func(s string) bool {
+		return len(s) <= 253 && schemaHostnameRegex.MatchString(s)
+	},
+	"uri": func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.IsAbs()
+	},
+}
+
+type schemaNode struct {
+	types                []string
+	constValue           interface{}
+	hasConst             bool
+	enum                 []interface{}
+	properties           map[string]*schemaNode
+	additionalProperties *bool
+	required             []string
+	items                *schemaNode
+	minItems, maxItems   *int
+	uniqueItems          bool
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+	format               string
+	minimum, maximum     *float64
+	ref                  string
+}
+
+// CompileJSONSchema parses a schema, failing with ErrInvalidSchema on unsupported keywords and
+// on references that do not resolve
+func CompileJSONSchema(data []byte) (*JSONSchema, error) {
+	var raw map[string]interface{}
+	if err := decodeJSONNumbers(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+
+	schema := &JSONSchema{defs: make(map[string]*schemaNode)}
+	if defs, ok := raw["$defs"].(map[string]interface{}); ok {
+		for name, def := range defs {
+			node, err := compileSchemaNode(def, "#/$defs/"+name)
+			if err != nil {
+				return nil, err
+			}
+			schema.defs[name] = node
+		}
+	}
+
+	root, err := compileSchemaNode(raw, "#")
+	if err != nil {
+		return nil, err
+	}
+	schema.root = root
+
+	if err := schema.checkRefs(root); err != nil {
+		return nil, err
+	}
+	for _, def := range schema.defs {
+		if err := schema.checkRefs(def); err != nil {
+			return nil, err
+		}
+	}
+	return schema, nil
+}
+
+// MustCompileJSONSchema is CompileJSONSchema for schemas embedded in the binary, it panics when
+// the schema does not compile
+func MustCompileJSONSchema(data []byte) *JSONSchema {
+	schema, err := CompileJSONSchema(data)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+func compileSchemaNode(value interface{}, location string) (*schemaNode, error) {
+	raw, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not an object", ErrInvalidSchema, location)
+	}
+
+	node := &schemaNode{}
+	for keyword, v := range raw {
+		if !jsonSchemaKeywords[keyword] {
+			return nil, fmt.Errorf("%w: %s uses the unsupported keyword %s", ErrInvalidSchema, location, keyword)
+		}
+
+		var err error
+		switch keyword {
+		case "$ref":
+			ref, _ := v.(string)
+			name, found := strings.CutPrefix(ref, "#/$defs/")
+			if !found {
+				return nil, fmt.Errorf("%w: %s refers to %q, only #/$defs/ references are supported", ErrInvalidSchema, location, ref)
+			}
+			node.ref = name
+		case "type":
+			switch t := v.(type) {
+			case string:
+				node.types = []string{t}
+			case []interface{}:
+				for _, item := range t {
+					name, _ := item.(string)
+					node.types = append(node.types, name)
+				}
+			}
+		case "const":
+			node.constValue, node.hasConst = v, true
+		case "enum":
+			node.enum, _ = v.([]interface{})
+		case "properties":
+			properties, _ := v.(map[string]interface{})
+			node.properties = make(map[string]*schemaNode, len(properties))
+			for name, property := range properties {
+				if node.properties[name], err = compileSchemaNode(property, location+"/properties/"+name); err != nil {
+					return nil, err
+				}
+			}
+		case "additionalProperties":
+			allowed, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s only supports a boolean additionalProperties", ErrInvalidSchema, location)
+			}
+			node.additionalProperties = &allowed
+		case "required":
+			required, _ := v.([]interface{})
+			for _, item := range required {
+				name, _ := item.(string)
+				node.required = append(node.required, name)
+			}
+		case "items":
+			node.items, err = compileSchemaNode(v, location+"/items")
+		case "minItems":
+			node.minItems, err = schemaInt(v, location, keyword)
+		case "maxItems":
+			node.maxItems, err = schemaInt(v, location, keyword)
+		case "uniqueItems":
+			node.uniqueItems, _ = v.(bool)
+		case "minLength":
+			node.minLength, err = schemaInt(v, location, keyword)
+		case "maxLength":
+			node.maxLength, err = schemaInt(v, location, keyword)
+		case "pattern":
+			pattern, _ := v.(string)
+			if node.pattern, err = regexp.Compile(pattern); err != nil {
+				err = fmt.Errorf("%w: %s has an invalid pattern: %v", ErrInvalidSchema, location, err)
+			}
+		case "format":
+			node.format, _ = v.(string)
+			if jsonSchemaFormats[node.format] == nil {
+				return nil, fmt.Errorf("%w: %s uses the unsupported format %q", ErrInvalidSchema, location, node.format)
+			}
+		case "minimum":
+			node.minimum, err = schemaFloat(v, location, keyword)
+		case "maximum":
+			node.maximum, err = schemaFloat(v, location, keyword)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+func schemaInt(v interface{}, location, keyword string) (*int, error) {
+	n, ok := v.(json.Number)
+	if ok {
+		if i, err := strconv.Atoi(n.String()); err == nil && i >= 0 {
+			return &i, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s %s must be a non-negative integer", ErrInvalidSchema, location, keyword)
+}
+
+func schemaFloat(v interface{}, location, keyword string) (*float64, error) {
+	n, ok := v.(json.Number)
+	if ok {
+		if f, err := n.Float64(); err == nil {
+			return &f, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s %s must be a number", ErrInvalidSchema, location, keyword)
+}
+
+func (s *JSONSchema) checkRefs(node *schemaNode) error {
+	if node.ref != "" && s.defs[node.ref] == nil {
+		return fmt.Errorf("%w: #/$defs/%s is not defined", ErrInvalidSchema, node.ref)
+	}
+	for _, property := range node.properties {
+		if err := s.checkRefs(property); err != nil {
+			return err
+		}
+	}
+	if node.items != nil {
+		return s.checkRefs(node.items)
+	}
+	return nil
+}
+
+// Validate checks a document, any value encoding/json can encode, against the schema. It returns
+// a *SchemaError for the first rule broken, properties being checked in alphabetical order.
+func (s *JSONSchema) Validate(document interface{}) error {
+	value, err := normalizeJSON(document)
+	if err != nil {
+		return err
+	}
+	return s.validate(s.root, value, "")
+}
+
+// ValidateProperty checks a single property of an object document against the schema, without
+// the rules on the document as a whole such as required properties
+func (s *JSONSchema) ValidateProperty(name string, value interface{}) error {
+	normalized, err := normalizeJSON(value)
+	if err != nil {
+		return err
+	}
+	property, ok := s.root.properties[name]
+	if !ok {
+		if s.root.additionalProperties != nil && !*s.root.additionalProperties {
+			return &SchemaError{Path: name, Message: "is not allowed"}
+		}
+		return nil
+	}
+	return s.validate(property, normalized, name)
+}
+
+// PruneProperties returns a copy of an object document without the properties that break the
+// schema, along with the error of each property dropped. Documents stored before a schema existed
+// are brought into it this way, keeping what is still valid.
+func (s *JSONSchema) PruneProperties(document map[string]interface{}) (map[string]interface{}, map[string]error) {
+	pruned := make(map[string]interface{}, len(document))
+	var dropped map[string]error
+	for name, value := range document {
+		if err := s.ValidateProperty(name, value); err != nil {
+			if dropped == nil {
+				dropped = make(map[string]error)
+			}
+			dropped[name] = err
+			continue
+		}
+		pruned[name] = value
+	}
+	return pruned, dropped
+}
+
+func (s *JSONSchema) validate(node *schemaNode, value interface{}, path string) error {
+	if node.ref != "" {
+		if err := s.validate(s.defs[node.ref], value, path); err != nil {
+			return err
+		}
+	}
+
+	if len(node.types) > 0 && !hasJSONType(value, node.types) {
+		return &SchemaError{Path: path, Message: "must be of type " + strings.Join(node.types, " or ")}
+	}
+	if node.hasConst && !equalJSON(value, node.constValue) {
+		return &SchemaError{Path: path, Message: "must be " + formatJSON(node.constValue)}
+	}
+	if node.enum != nil && !containsJSON(node.enum, value) {
+		return &SchemaError{Path: path, Message: "must be one of " + formatJSON(node.enum)}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return s.validateObject(node, v, path)
+	case []interface{}:
+		return s.validateArray(node, v, path)
+	case string:
+		return validateString(node, v, path)
+	case json.Number:
+		return validateNumber(node, v, path)
+	}
+	return nil
+}
+
+func (s *JSONSchema) validateObject(node *schemaNode, object map[string]interface{}, path string) error {
+	for _, name := range node.required {
+		if _, ok := object[name]; !ok {
+			return &SchemaError{Path: joinSchemaPath(path, name), Message: "is required"}
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, ok := node.properties[name]
+		if !ok {
+			if node.additionalProperties != nil && !*node.additionalProperties {
+				return &SchemaError{Path: joinSchemaPath(path, name), Message: "is not allowed"}
+			}
+			continue
+		}
+		if err := s.validate(property, object[name], joinSchemaPath(path, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *JSONSchema) validateArray(node *schemaNode, array []interface{}, path string) error {
+	if node.minItems != nil && len(array) < *node.minItems {
+		return &SchemaError{Path: path, Message: fmt.Sprintf("must have at least %d items", *node.minItems)}
+	}
+	if node.maxItems != nil && len(array) > *node.maxItems {
+		return &SchemaError{Path: path, Message: fmt.Sprintf("must have at most %d items", *node.maxItems)}
+	}
+	if node.uniqueItems {
+		for i := range array {
+			if containsJSON(array[:i], array[i]) {
+				return &SchemaError{Path: fmt.Sprintf("%s[%d]", path, i), Message: "duplicates an earlier item"}
+			}
+		}
+	}
+	if node.items != nil {
+		for i, item := range array {
+			if err := s.validate(node.items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateString(node *schemaNode, s, path string) error {
+	length := utf8.RuneCountInString(s)
+	if node.minLength != nil && length < *node.minLength {
+		return &SchemaError{Path: path, Message: fmt.Sprintf("must be at least %d characters", *node.minLength)}
+	}
+	if node.maxLength != nil && length > *node.maxLength {
+		return &SchemaError{Path: path, Message: fmt.Sprintf("must be at most %d characters", *node.maxLength)}
+	}
+	if node.pattern != nil && !node.pattern.MatchString(s) {
+		return &SchemaError{Path: path, Message: "must match " + node.pattern.String()}
+	}
+	if node.format != "" && !jsonSchemaFormats[node.format](s) {
+		return &SchemaError{Path: path, Message: "must be a valid " + node.format}
+	}
+	return nil
+}
+
+func validateNumber(node *schemaNode, n json.Number, path string) error {
+	f, err := n.Float64()
+	if err != nil {
+		return &SchemaError{Path: path, Message: "must be a number"}
+	}
+	if node.minimum != nil && f < *node.minimum {
+		return &SchemaError{Path: path, Message: "must be at least " + strconv.FormatFloat(*node.minimum, 'f', -1, 64)}
+	}
+	if node.maximum != nil && f > *node.maximum {
+		return &SchemaError{Path: path, Message: "must be at most " + strconv.FormatFloat(*node.maximum, 'f', -1, 64)}
+	}
+	return nil
+}
+
+func hasJSONType(value interface{}, types []string) bool {
+	for _, t := range types {
+		switch v := value.(type) {
+		case nil:
+			if t == "null" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case []interface{}:
+			if t == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if t == "object" {
+				return true
+			}
+		case json.Number:
+			if t == "number" {
+				return true
+			}
+			if f, err := v.Float64(); t == "integer" && err == nil && f == math.Trunc(f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// equalJSON compares normalized JSON values, numbers by value so that 1 equals 1.0
+func equalJSON(a, b interface{}) bool {
+	switch av := a.(type) {
+	case json.Number:
+		bv, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		af, aErr := av.Float64()
+		bf, bErr := bv.Float64()
+		return aErr == nil && bErr == nil && af == bf
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !equalJSON(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for key, value := range av {
+			other, ok := bv[key]
+			if !ok || !equalJSON(value, other) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+func containsJSON(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if equalJSON(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatJSON(value interface{}) string {
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
+
+func joinSchemaPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// normalizeJSON turns a value into what decoding its JSON encoding gives, with numbers kept as
+// json.Number, so that documents built in Go and documents read from requests compare the same
+func normalizeJSON(value interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode document: %w", err)
+	}
+	var normalized interface{}
+	if err := decodeJSONNumbers(encoded, &normalized); err != nil {
+		return nil, fmt.Errorf("failed to decode document: %w", err)
+	}
+	return normalized, nil
+}
+
+func decodeJSONNumbers(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
@@ -9,15 +9,32 @@ import (
 )
 
 type JWTClaims struct {
-	UserID string `json:"user_id"`
+	UserID       string           `json:"user_id"`
+	Confirmation *JWTConfirmation `json:"cnf,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// JWTConfirmation binds a token to the key its holder must prove possession of (RFC 9449)
+type JWTConfirmation struct {
+	JKT string `json:"jkt"`
+}
+
 func GenerateToken(userID string) (string, error) {
+	return GenerateTokenWithExpiry(userID, time.Now().Add(global.Config.JWT.ExpirationTime))
+}
+
+// GenerateTokenWithExpiry signs a token that expires at the end of the session it belongs to
+func GenerateTokenWithExpiry(userID string, expiresAt time.Time) (string, error) {
+	return GenerateDPoPBoundToken(userID, expiresAt, "")
+}
+
+// GenerateDPoPBoundToken signs a token only usable with DPoP proofs made by the key with the
+// given thumbprint; an empty thumbprint gives a plain bearer token
+func GenerateDPoPBoundToken(userID string, expiresAt time.Time, jkt string) (string, error) {
 	claims := JWTClaims{
 		UserID: userID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(global.Config.JWT.ExpirationTime)),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "go-backend-v2",
@@ -25,6 +42,10 @@ func GenerateToken(userID string) (string, error) {
 		},
 	}
 
+	if jkt != "" {
+		claims.Confirmation = &JWTConfirmation{JKT: jkt}
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	tokenString, err := token.SignedString([]byte(global.Config.JWT.Secret))
@@ -36,6 +57,15 @@ func GenerateToken(userID string) (string, error) {
 }
 
 func ValidateToken(tokenString string) (string, error) {
+	claims, err := ParseToken(tokenString)
+	if err != nil {
+		return "", err
+	}
+	return claims.UserID, nil
+}
+
+// ParseToken verifies a token and returns all of its claims
+func ParseToken(tokenString string) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -44,14 +74,14 @@ func ValidateToken(tokenString string) (string, error) {
 	})
 
 	if err != nil {
-		return "", fmt.Errorf("failed to parse token: %w", err)
+		return nil, fmt.Errorf("failed to parse token: %w", err)
 	}
 
 	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-		return claims.UserID, nil
+		return claims, nil
 	}
 
-	return "", fmt.Errorf("invalid token claims")
+	return nil, fmt.Errorf("invalid token claims")
 }
 
 func ExtractUserIDFromToken(tokenString string) string {
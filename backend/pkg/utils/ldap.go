@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// LDAPUsernamePlaceholder is replaced by the escaped login name in the user search filter
+const LDAPUsernamePlaceholder = "{username}"
+
+var (
+	ErrLDAPInvalidCredentials = errors.New("invalid LDAP credentials")
+	ErrLDAPUserNotFound       = errors.New("LDAP user not found")
+	ErrLDAPAmbiguousUser      = errors.New("LDAP search returned more than one user")
+)
+
+// LDAPOptions describes how to reach the directory and where to look users up
+type LDAPOptions struct {
+	URL                string
+	StartTLS           bool
+	InsecureSkipVerify bool
+	BindDN             string // service account used for the user search, anonymous when empty
+	BindPassword       string
+	BaseDN             string
+	UserFilter         string // e.g. (&(objectClass=person)(uid={username}))
+	Attributes         LDAPAttributeMapping
+	Timeout            time.Duration
+}
+
+// LDAPAttributeMapping names the directory attributes holding each profile field
+type LDAPAttributeMapping struct {
+	Email       string
+	FirstName   string
+	LastName    string
+	DisplayName string
+	Groups      string
+}
+
+// LDAPIdentity is the directory entry of an authenticated user
+type LDAPIdentity struct {
+	DN          string
+	Email       string
+	FirstName   string
+	LastName    string
+	DisplayName string
+	Groups      []string
+}
+
+// AuthenticateLDAP looks the user up with the service account and verifies the password by
+// binding as the entry that was found
+func AuthenticateLDAP(opts *LDAPOptions, username, password string) (*LDAPIdentity, error) {
+	// An empty password turns the bind into an unauthenticated bind, which most servers accept
+	if strings.TrimSpace(username) == "" || password == "" {
+		return nil, ErrLDAPInvalidCredentials
+	}
+
+	conn, err := dialLDAP(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if opts.BindDN != "" {
+		err = conn.Bind(opts.BindDN, opts.BindPassword)
+	} else {
+		err = conn.UnauthenticatedBind("")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind LDAP service account: %w", err)
+	}
+
+	entry, err := searchLDAPUser(conn, opts, username)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrLDAPInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to bind LDAP user: %w", err)
+	}
+
+	return extractLDAPIdentity(entry, opts.Attributes), nil
+}
+
+func dialLDAP(opts *LDAPOptions) (*ldap.Conn, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: opts.InsecureSkipVerify}
+
+	dialOpts := []ldap.DialOpt{ldap.DialWithTLSConfig(tlsConfig)}
+	if opts.Timeout > 0 {
+		dialOpts = append(dialOpts, ldap.DialWithDialer(&net.Dialer{Timeout: opts.Timeout}))
+	}
+
+	conn, err := ldap.DialURL(opts.URL, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to LDAP server: %w", err)
+	}
+	if opts.Timeout > 0 {
+		conn.SetTimeout(opts.Timeout)
+	}
+
+	if opts.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	return conn, nil
+}
+
+func searchLDAPUser(conn *ldap.Conn, opts *LDAPOptions, username string) (*ldap.Entry, error) {
+	filter := strings.ReplaceAll(opts.UserFilter, LDAPUsernamePlaceholder, ldap.EscapeFilter(username))
+
+	var attributes []string
+	for _, attribute := range []string{
+		opts.Attributes.Email,
+		opts.Attributes.FirstName,
+		opts.Attributes.LastName,
+		opts.Attributes.DisplayName,
+		opts.Attributes.Groups,
+	} {
+		if attribute != "" {
+			attributes = append(attributes, attribute)
+		}
+	}
+
+	request := ldap.NewSearchRequest(
+		opts.BaseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		2, // one match is expected, a second one is enough to detect ambiguity
+		int(opts.Timeout.Seconds()),
+		false,
+		filter,
+		attributes,
+		nil,
+	)
+
+	result, err := conn.Search(request)
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return nil, ErrLDAPUserNotFound
+		}
+		return nil, fmt.Errorf("failed to search LDAP user: %w", err)
+	}
+	if result == nil || len(result.Entries) == 0 {
+		return nil, ErrLDAPUserNotFound
+	}
+	if len(result.Entries) > 1 {
+		return nil, ErrLDAPAmbiguousUser
+	}
+
+	return result.Entries[0], nil
+}
+
+func extractLDAPIdentity(entry *ldap.Entry, mapping LDAPAttributeMapping) *LDAPIdentity {
+	identity := &LDAPIdentity{DN: entry.DN}
+
+	if mapping.Email != "" {
+		identity.Email = strings.ToLower(strings.TrimSpace(entry.GetAttributeValue(mapping.Email)))
+	}
+	if mapping.FirstName != "" {
+		identity.FirstName = strings.TrimSpace(entry.GetAttributeValue(mapping.FirstName))
+	}
+	if mapping.LastName != "" {
+		identity.LastName = strings.TrimSpace(entry.GetAttributeValue(mapping.LastName))
+	}
+	if mapping.DisplayName != "" {
+		identity.DisplayName = strings.TrimSpace(entry.GetAttributeValue(mapping.DisplayName))
+	}
+	if mapping.Groups != "" {
+		identity.Groups = entry.GetAttributeValues(mapping.Groups)
+	}
+
+	return identity
+}
+
+// LDAPDNEqual compares two distinguished names the way directories do, ignoring case and spacing
+func LDAPDNEqual(a, b string) bool {
+	left, err := ldap.ParseDN(a)
+	if err != nil {
+		return strings.EqualFold(a, b)
+	}
+	right, err := ldap.ParseDN(b)
+	if err != nil {
+		return strings.EqualFold(a, b)
+	}
+	return left.EqualFold(right)
+}
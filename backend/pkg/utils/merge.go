@@ -0,0 +1,28 @@
+package utils
+
+// MergePatch applies a JSON merge patch (RFC 7386) to target and returns the result. Objects are
+// merged key by key at every depth, a null value removes the key and any other value, arrays
+// included, replaces the current one. Neither target nor patch is modified.
+func MergePatch(target, patch map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(target)+len(patch))
+	for key, value := range target {
+		merged[key] = value
+	}
+
+	for key, value := range patch {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+
+		if patchObject, ok := value.(map[string]interface{}); ok {
+			current, _ := merged[key].(map[string]interface{})
+			merged[key] = MergePatch(current, patchObject)
+			continue
+		}
+
+		merged[key] = value
+	}
+
+	return merged
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"fmt"
+	"go-backend-v2/global"
+)
+
+const (
+	NotifierDriverLog   = "log"
+	NotifierDriverEvent = "event"
+)
+
+// NotificationRequestedPayload is published by the event notifier so that a
+// delivery service (mailer, push gateway, ...) can render and send the message
+type NotificationRequestedPayload struct {
+	Recipient string                 `json:"recipient"`
+	Subject   string                 `json:"subject"`
+	Template  string                 `json:"template"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
+type logNotifier struct{}
+
+// NewLogNotifier returns a notifier that only prints notifications, meant for local development
+func NewLogNotifier() global.Notifier {
+	return &logNotifier{}
+}
+
+func (n *logNotifier) Send(recipient, subject, template string, data map[string]interface{}) error {
+	fmt.Printf("Notification [%s] to %s: %s %v\n", template, recipient, subject, data)
+	return nil
+}
+
+type eventNotifier struct {
+	publisher global.EventPublisher
+	topic     string
+}
+
+// NewEventNotifier returns a notifier that hands notifications to the delivery service over the event bus
+func NewEventNotifier(publisher global.EventPublisher, topic string) (global.Notifier, error) {
+	if publisher == nil {
+		return nil, fmt.Errorf("event notifier requires an event publisher")
+	}
+	if topic == "" {
+		return nil, fmt.Errorf("event notifier requires a topic")
+	}
+
+	return &eventNotifier{
+		publisher: publisher,
+		topic:     topic,
+	}, nil
+}
+
+func (n *eventNotifier) Send(recipient, subject, template string, data map[string]interface{}) error {
+	payload := &NotificationRequestedPayload{
+		Recipient: recipient,
+		Subject:   subject,
+		Template:  template,
+		Data:      data,
+	}
+
+	if err := n.publisher.Publish(n.topic, payload); err != nil {
+		return fmt.Errorf("failed to publish notification: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,305 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"go-backend-v2/internal/common"
+	"hash/maphash"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Permission grammar, as stored in RolePermissions.Permissions and in the RBAC data of sessions:
+//
+//	grant      = ["!"] permission
+//	permission = "all" | "*" | segment ":" segment
+//	segment    = name | "*"      (names as in IsResourceName)
+//
+// A "*" segment matches any resource or action, and all is accepted for * as in the original
+// grammar, so all, *:*, member:all and member:* keep their meaning. A grant starting with "!" is a
+// deny, which wins over every allow, whatever role or group it comes from.
+const (
+	PermissionWildcard   = "*"
+	PermissionDenyPrefix = "!"
+
+	// permissionCacheSize bounds the compiled permission sets kept in memory, the cache is
+	// dropped as a whole when it is full
+	permissionCacheSize = 4096
+)
+
+var (
+	ErrInvalidPermission = errors.New("invalid permission")
+	ErrRoleCycle         = errors.New("role inheritance cycle")
+
+	permissionCache = struct {
+		sync.RWMutex
+		seed maphash.Seed
+		sets map[uint64]cachedPermissionSet
+	}{seed: maphash.MakeSeed(), sets: make(map[uint64]cachedPermissionSet)}
+)
+
+type cachedPermissionSet struct {
+	grants []string
+	set    *PermissionSet
+}
+
+// Permission is a parsed grant
+type Permission struct {
+	Resource string
+	Action   string
+	Deny     bool
+}
+
+// ParsePermission parses a grant, returning it with all replaced by *
+func ParsePermission(s string) (Permission, error) {
+	var p Permission
+	s = strings.TrimSpace(s)
+	if rest, ok := strings.CutPrefix(s, PermissionDenyPrefix); ok {
+		p.Deny = true
+		s = rest
+	}
+
+	if s == common.PermissionAll || s == PermissionWildcard {
+		p.Resource, p.Action = PermissionWildcard, PermissionWildcard
+		return p, nil
+	}
+
+	resource, action, found := strings.Cut(s, ":")
+	if !found {
+		return Permission{}, fmt.Errorf("%w: %q is not resource:action", ErrInvalidPermission, s)
+	}
+	var err error
+	if p.Resource, err = parsePermissionSegment(resource); err != nil {
+		return Permission{}, err
+	}
+	if p.Action, err = parsePermissionSegment(action); err != nil {
+		return Permission{}, err
+	}
+	return p, nil
+}
+
+func parsePermissionSegment(segment string) (string, error) {
+	if segment == PermissionWildcard || segment == common.PermissionAll {
+		return PermissionWildcard, nil
+	}
+	if !IsResourceName(segment) {
+		return "", fmt.Errorf("%w: %q is not a resource or action name", ErrInvalidPermission, segment)
+	}
+	return segment, nil
+}
+
+func (p Permission) String() string {
+	s := p.Resource + ":" + p.Action
+	if p.Deny {
+		return PermissionDenyPrefix + s
+	}
+	return s
+}
+
+type permissionKey struct {
+	resource string
+	action   string
+}
+
+// PermissionSet is a compiled list of grants. Checks take a few map lookups, whatever the number
+// of grants, plus a scan of the denies when the checked permission has a wildcard.
+type PermissionSet struct {
+	allow map[permissionKey]struct{}
+	deny  map[permissionKey]struct{}
+}
+
+// CompilePermissions compiles grants, failing on the first one that does not parse
+func CompilePermissions(grants []string) (*PermissionSet, error) {
+	set := &PermissionSet{
+		allow: make(map[permissionKey]struct{}, len(grants)),
+		deny:  make(map[permissionKey]struct{}),
+	}
+	for _, grant := range grants {
+		p, err := ParsePermission(grant)
+		if err != nil {
+			return nil, err
+		}
+		key := permissionKey{p.Resource, p.Action}
+		if p.Deny {
+			set.deny[key] = struct{}{}
+		} else {
+			set.allow[key] = struct{}{}
+		}
+	}
+	return set, nil
+}
+
+// CachedPermissions returns the compiled set of grants, compiling it on the first use. Sets are
+// cached by a hash of the grants in order, so that a hit does not allocate.
+func CachedPermissions(grants []string) (*PermissionSet, error) {
+	var h maphash.Hash
+	h.SetSeed(permissionCache.seed)
+	for _, grant := range grants {
+		h.WriteString(grant)
+		h.WriteByte('\n')
+	}
+	key := h.Sum64()
+
+	permissionCache.RLock()
+	cached, ok := permissionCache.sets[key]
+	permissionCache.RUnlock()
+	if ok && slices.Equal(cached.grants, grants) {
+		return cached.set, nil
+	}
+
+	set, err := CompilePermissions(grants)
+	if err != nil {
+		return nil, err
+	}
+
+	permissionCache.Lock()
+	if len(permissionCache.sets) >= permissionCacheSize {
+		permissionCache.sets = make(map[uint64]cachedPermissionSet)
+	}
+	permissionCache.sets[key] = cachedPermissionSet{grants: slices.Clone(grants), set: set}
+	permissionCache.Unlock()
+
+	return set, nil
+}
+
+// Allows tells whether the set grants a permission. A permission with wildcards, such as member:*,
+// is granted only when the set grants all it covers: an allow at least as wide and no deny
+// overlapping it. Denies and malformed permissions are never granted.
+func (s *PermissionSet) Allows(permission string) bool {
+	p, ok := checkedPermission(permission)
+	if !ok {
+		return false
+	}
+
+	if !matches(s.allow, p) {
+		return false
+	}
+	if p.resource != PermissionWildcard && p.action != PermissionWildcard {
+		return !matches(s.deny, p)
+	}
+	for deny := range s.deny {
+		if overlaps(deny.resource, p.resource) && overlaps(deny.action, p.action) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkedPermission splits a permission to check. Unlike ParsePermission it does not validate the
+// names, which cannot match a grant anyway, to keep checks cheap.
+func checkedPermission(permission string) (permissionKey, bool) {
+	if strings.HasPrefix(permission, PermissionDenyPrefix) {
+		return permissionKey{}, false
+	}
+	if permission == common.PermissionAll || permission == PermissionWildcard {
+		return permissionKey{PermissionWildcard, PermissionWildcard}, true
+	}
+	resource, action, found := strings.Cut(permission, ":")
+	if !found || resource == "" || action == "" {
+		return permissionKey{}, false
+	}
+	if resource == common.PermissionAll {
+		resource = PermissionWildcard
+	}
+	if action == common.PermissionAll {
+		action = PermissionWildcard
+	}
+	return permissionKey{resource, action}, true
+}
+
+// matches looks for a grant covering p: p itself or p with the resource, the action or both
+// replaced by *
+func matches(grants map[permissionKey]struct{}, p permissionKey) bool {
+	for _, key := range [...]permissionKey{
+		p,
+		{p.resource, PermissionWildcard},
+		{PermissionWildcard, p.action},
+		{PermissionWildcard, PermissionWildcard},
+	} {
+		if _, ok := grants[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func overlaps(a, b string) bool {
+	return a == b || a == PermissionWildcard || b == PermissionWildcard
+}
+
+// HasPermission matches a permission against granted ones, compiled through the cache. Grants that
+// do not parse grant nothing at all, so that a broken deny cannot open access.
+func HasPermission(granted []string, permission string) bool {
+	set, err := CachedPermissions(granted)
+	if err != nil {
+		return false
+	}
+	return set.Allows(permission)
+}
+
+// UnionPermissions merges permission sets, such as those of a member's own role and of the roles
+// of their groups, keeping the first occurrence of each permission
+func UnionPermissions(sets ...[]string) []string {
+	seen := make(map[string]bool)
+	union := []string{}
+	for _, set := range sets {
+		for _, p := range set {
+			if !seen[p] {
+				seen[p] = true
+				union = append(union, p)
+			}
+		}
+	}
+	return union
+}
+
+// RoleDefinition is what a role contributes to the permissions of its holders: its own grants and
+// the roles it inherits from
+type RoleDefinition struct {
+	Permissions []string
+	Inherits    []string
+}
+
+// ResolveRolePermissions returns the union of the grants of the given roles and of every role they
+// inherit from, directly or not. Roles missing from the map, such as deleted ones, grant nothing.
+// A cycle in the inheritance fails with ErrRoleCycle.
+func ResolveRolePermissions(roles map[string]RoleDefinition, roleIDs ...string) ([]string, error) {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(roles))
+	var sets [][]string
+
+	var visit func(roleID string) error
+	visit = func(roleID string) error {
+		switch state[roleID] {
+		case visiting:
+			return fmt.Errorf("%w through role %s", ErrRoleCycle, roleID)
+		case done:
+			return nil
+		}
+		role, ok := roles[roleID]
+		if !ok {
+			return nil
+		}
+
+		state[roleID] = visiting
+		sets = append(sets, role.Permissions)
+		for _, parentID := range role.Inherits {
+			if err := visit(parentID); err != nil {
+				return err
+			}
+		}
+		state[roleID] = done
+		return nil
+	}
+
+	for _, roleID := range roleIDs {
+		if err := visit(roleID); err != nil {
+			return nil, err
+		}
+	}
+	return UnionPermissions(sets...), nil
+}
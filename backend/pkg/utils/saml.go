@@ -0,0 +1,358 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/crewjam/saml"
+	"github.com/crewjam/saml/samlsp"
+)
+
+// Well known attribute names used when a workspace has not configured its own mapping
+var (
+	SAMLDefaultEmailAttributes       = []string{"email", "mail", "emailaddress", "urn:oid:0.9.2342.19200300.100.1.3", "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress"}
+	SAMLDefaultFirstNameAttributes   = []string{"first_name", "firstname", "givenName", "urn:oid:2.5.4.42", "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/givenname"}
+	SAMLDefaultLastNameAttributes    = []string{"last_name", "lastname", "sn", "surname", "urn:oid:2.5.4.4", "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/surname"}
+	SAMLDefaultDisplayNameAttributes = []string{"display_name", "displayName", "cn", "urn:oid:2.5.4.3", "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/name"}
+	SAMLDefaultGroupsAttributes      = []string{"groups", "memberOf", "http://schemas.microsoft.com/ws/2008/06/identity/claims/groups"}
+)
+
+// SAMLServiceProviderOptions describes one workspace's service provider and the IdP it trusts.
+// Either IdPMetadataXML or the IdPEntityID/IdPSSOURL/IdPCertificatePEM triple must be set.
+type SAMLServiceProviderOptions struct {
+	MetadataURL       string
+	ACSURL            string
+	PrivateKeyPEM     string
+	CertificatePEM    string
+	IdPMetadataXML    string
+	IdPEntityID       string
+	IdPSSOURL         string
+	IdPCertificatePEM string
+	AllowIDPInitiated bool
+}
+
+// SAMLAttributeMapping names the assertion attributes holding each profile field
+type SAMLAttributeMapping struct {
+	Email       string
+	FirstName   string
+	LastName    string
+	DisplayName string
+	Groups      string
+}
+
+// SAMLIdentity is the user identity asserted by the IdP
+type SAMLIdentity struct {
+	NameID      string
+	Email       string
+	FirstName   string
+	LastName    string
+	DisplayName string
+	Groups      []string
+}
+
+// NewSAMLServiceProvider builds a service provider able to issue AuthnRequests and verify responses
+func NewSAMLServiceProvider(opts *SAMLServiceProviderOptions) (*saml.ServiceProvider, error) {
+	key, err := ParseRSAPrivateKeyPEM(opts.PrivateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SP private key: %w", err)
+	}
+
+	cert, err := ParseCertificatePEM(opts.CertificatePEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SP certificate: %w", err)
+	}
+
+	metadataURL, err := url.Parse(opts.MetadataURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SP metadata URL: %w", err)
+	}
+
+	acsURL, err := url.Parse(opts.ACSURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SP ACS URL: %w", err)
+	}
+
+	idpMetadata, err := BuildSAMLIdPMetadata(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &saml.ServiceProvider{
+		EntityID:          metadataURL.String(),
+		Key:               key,
+		Certificate:       cert,
+		MetadataURL:       *metadataURL,
+		AcsURL:            *acsURL,
+		IDPMetadata:       idpMetadata,
+		AllowIDPInitiated: opts.AllowIDPInitiated,
+		AuthnNameIDFormat: saml.UnspecifiedNameIDFormat,
+		SignatureMethod:   "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256",
+	}, nil
+}
+
+// BuildSAMLIdPMetadata returns the IdP metadata, parsed from XML or assembled from the individual fields
+func BuildSAMLIdPMetadata(opts *SAMLServiceProviderOptions) (*saml.EntityDescriptor, error) {
+	if strings.TrimSpace(opts.IdPMetadataXML) != "" {
+		metadata, err := samlsp.ParseMetadata([]byte(opts.IdPMetadataXML))
+		if err != nil {
+			return nil, fmt.Errorf("invalid IdP metadata: %w", err)
+		}
+		if len(metadata.IDPSSODescriptors) == 0 {
+			return nil, fmt.Errorf("IdP metadata has no IDPSSODescriptor")
+		}
+		return metadata, nil
+	}
+
+	if opts.IdPEntityID == "" || opts.IdPSSOURL == "" || opts.IdPCertificatePEM == "" {
+		return nil, fmt.Errorf("IdP metadata XML or entity ID, SSO URL and certificate are required")
+	}
+
+	cert, err := ParseCertificatePEM(opts.IdPCertificatePEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IdP certificate: %w", err)
+	}
+
+	return &saml.EntityDescriptor{
+		EntityID: opts.IdPEntityID,
+		IDPSSODescriptors: []saml.IDPSSODescriptor{{
+			SSODescriptor: saml.SSODescriptor{
+				RoleDescriptor: saml.RoleDescriptor{
+					ProtocolSupportEnumeration: "urn:oasis:names:tc:SAML:2.0:protocol",
+					KeyDescriptors: []saml.KeyDescriptor{{
+						Use: "signing",
+						KeyInfo: saml.KeyInfo{
+							X509Data: saml.X509Data{
+								X509Certificates: []saml.X509Certificate{{
+									Data: base64.StdEncoding.EncodeToString(cert.Raw),
+								}},
+							},
+						},
+					}},
+				},
+			},
+			SingleSignOnServices: []saml.Endpoint{
+				{Binding: saml.HTTPRedirectBinding, Location: opts.IdPSSOURL},
+				{Binding: saml.HTTPPostBinding, Location: opts.IdPSSOURL},
+			},
+		}},
+	}, nil
+}
+
+// MakeSAMLRedirectRequest creates an AuthnRequest for the HTTP-Redirect binding and returns the
+// IdP URL to send the browser to together with the request ID the response must answer
+func MakeSAMLRedirectRequest(sp *saml.ServiceProvider, relayState string) (string, string, error) {
+	ssoURL := sp.GetSSOBindingLocation(saml.HTTPRedirectBinding)
+	if ssoURL == "" {
+		return "", "", fmt.Errorf("IdP does not support the HTTP-Redirect binding")
+	}
+
+	req, err := sp.MakeAuthenticationRequest(ssoURL, saml.HTTPRedirectBinding, saml.HTTPPostBinding)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create authentication request: %w", err)
+	}
+
+	redirectURL, err := req.Redirect(relayState, sp)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode authentication request: %w", err)
+	}
+
+	return redirectURL.String(), req.ID, nil
+}
+
+// SAMLResponseRequestID returns the InResponseTo attribute of a base64 encoded SAML response
+// without verifying it, so the caller can look up which AuthnRequest it answers
+func SAMLResponseRequestID(samlResponse string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(samlResponse)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode SAML response: %w", err)
+	}
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(raw); err != nil {
+		return "", fmt.Errorf("failed to parse SAML response: %w", err)
+	}
+	if doc.Root() == nil {
+		return "", fmt.Errorf("empty SAML response")
+	}
+
+	return doc.Root().SelectAttrValue("InResponseTo", ""), nil
+}
+
+// ParseSAMLResponse verifies the signature, audience, destination and validity window of a base64
+// encoded SAML response and returns its assertion
+func ParseSAMLResponse(sp *saml.ServiceProvider, samlResponse string, possibleRequestIDs []string) (*saml.Assertion, error) {
+	raw, err := base64.StdEncoding.DecodeString(samlResponse)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode SAML response: %w", err)
+	}
+
+	assertion, err := sp.ParseXMLResponse(raw, possibleRequestIDs, sp.AcsURL)
+	if err != nil {
+		if invalid, ok := err.(*saml.InvalidResponseError); ok && invalid.PrivateErr != nil {
+			return nil, fmt.Errorf("invalid SAML response: %w", invalid.PrivateErr)
+		}
+		return nil, fmt.Errorf("invalid SAML response: %w", err)
+	}
+
+	return assertion, nil
+}
+
+// ExtractSAMLIdentity maps the assertion attributes onto a user identity.
+// Configured attribute names win, the well known names are used as fallback.
+func ExtractSAMLIdentity(assertion *saml.Assertion, mapping SAMLAttributeMapping) *SAMLIdentity {
+	identity := &SAMLIdentity{}
+
+	if assertion.Subject != nil && assertion.Subject.NameID != nil {
+		identity.NameID = strings.TrimSpace(assertion.Subject.NameID.Value)
+	}
+
+	values := make(map[string][]string)
+	for _, statement := range assertion.AttributeStatements {
+		for _, attr := range statement.Attributes {
+			var attrValues []string
+			for _, v := range attr.Values {
+				if value := strings.TrimSpace(v.Value); value != "" {
+					attrValues = append(attrValues, value)
+				}
+			}
+			for _, name := range []string{attr.Name, attr.FriendlyName} {
+				if name != "" {
+					values[strings.ToLower(name)] = append(values[strings.ToLower(name)], attrValues...)
+				}
+			}
+		}
+	}
+
+	lookup := func(configured string, defaults []string) []string {
+		names := defaults
+		if configured != "" {
+			names = append([]string{configured}, defaults...)
+		}
+		for _, name := range names {
+			if v := values[strings.ToLower(name)]; len(v) > 0 {
+				return v
+			}
+		}
+		return nil
+	}
+
+	first := func(v []string) string {
+		if len(v) == 0 {
+			return ""
+		}
+		return v[0]
+	}
+
+	identity.Email = strings.ToLower(first(lookup(mapping.Email, SAMLDefaultEmailAttributes)))
+	identity.FirstName = first(lookup(mapping.FirstName, SAMLDefaultFirstNameAttributes))
+	identity.LastName = first(lookup(mapping.LastName, SAMLDefaultLastNameAttributes))
+	identity.DisplayName = first(lookup(mapping.DisplayName, SAMLDefaultDisplayNameAttributes))
+	identity.Groups = lookup(mapping.Groups, SAMLDefaultGroupsAttributes)
+
+	if identity.Email == "" && strings.Contains(identity.NameID, "@") {
+		identity.Email = strings.ToLower(identity.NameID)
+	}
+
+	return identity
+}
+
+// SAMLMetadataXML renders the service provider metadata document
+func SAMLMetadataXML(sp *saml.ServiceProvider) ([]byte, error) {
+	body, err := xml.MarshalIndent(sp.Metadata(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render SP metadata: %w", err)
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
+
+// GenerateSelfSignedKeyPair creates an RSA key and a self signed certificate, PEM encoded
+func GenerateSelfSignedKeyPair(commonName string, validFor time.Duration) (keyPEM string, certPEM string, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate RSA key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate certificate serial: %w", err)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validFor),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	keyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	certPEM = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+
+	return keyPEM, certPEM, nil
+}
+
+// ParseRSAPrivateKeyPEM parses a PKCS#1 or PKCS#8 encoded RSA private key
+func ParseRSAPrivateKeyPEM(keyPEM string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(keyPEM))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not an RSA key")
+	}
+
+	return key, nil
+}
+
+// ParseCertificatePEM parses a PEM encoded X.509 certificate; bare base64 DER is accepted as well
+// because that is how most IdPs display their signing certificate
+func ParseCertificatePEM(certPEM string) (*x509.Certificate, error) {
+	var der []byte
+
+	if block, _ := pem.Decode([]byte(certPEM)); block != nil {
+		der = block.Bytes
+	} else {
+		decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(certPEM), ""))
+		if err != nil {
+			return nil, fmt.Errorf("no PEM block found")
+		}
+		der = decoded
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	return cert, nil
+}
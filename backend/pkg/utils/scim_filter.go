@@ -0,0 +1,455 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SCIM filter operators (RFC 7644 section 3.4.2.2)
+const (
+	SCIMFilterAnd = "and"
+	SCIMFilterOr  = "or"
+	SCIMFilterNot = "not"
+
+	SCIMFilterEq = "eq"
+	SCIMFilterNe = "ne"
+	SCIMFilterCo = "co"
+	SCIMFilterSw = "sw"
+	SCIMFilterEw = "ew"
+	SCIMFilterGt = "gt"
+	SCIMFilterGe = "ge"
+	SCIMFilterLt = "lt"
+	SCIMFilterLe = "le"
+	SCIMFilterPr = "pr"
+)
+
+// SCIMFilter is a node of a parsed SCIM filter expression. Logical nodes use Left/Right
+// (only Left for "not"), comparison nodes use Attribute and Value.
+type SCIMFilter struct {
+	Op        string
+	Attribute string
+	Value     interface{} // string, bool, float64 or nil
+	Left      *SCIMFilter
+	Right     *SCIMFilter
+}
+
+// SCIMAttributeKind tells how an attribute is compared when translated to SQL
+type SCIMAttributeKind int
+
+const (
+	SCIMAttributeString SCIMAttributeKind = iota
+	SCIMAttributeBoolean
+	SCIMAttributeDateTime
+)
+
+// SCIMFilterColumn maps a SCIM attribute onto SQL. For boolean attributes Column is a SQL
+// condition that is true when the attribute is true.
+type SCIMFilterColumn struct {
+	Column string
+	Kind   SCIMAttributeKind
+}
+
+var scimComparisonOperators = map[string]bool{
+	SCIMFilterEq: true, SCIMFilterNe: true, SCIMFilterCo: true, SCIMFilterSw: true, SCIMFilterEw: true,
+	SCIMFilterGt: true, SCIMFilterGe: true, SCIMFilterLt: true, SCIMFilterLe: true,
+}
+
+// ParseSCIMFilter parses a SCIM filter such as `userName eq "john" and not (active eq false)`.
+// Value filters on multi-valued attributes (`emails[type eq "work"]`) are flattened into
+// sub-attribute comparisons (`emails.type eq "work"`).
+func ParseSCIMFilter(filter string) (*SCIMFilter, error) {
+	tokens, err := tokenizeSCIMFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty filter")
+	}
+
+	p := &scimFilterParser{tokens: tokens}
+	node, err := p.parseOr("")
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.tokens[p.pos].text, p.tokens[p.pos].offset)
+	}
+
+	return node, nil
+}
+
+// SCIMFilterToSQL translates a parsed filter into a SQL condition with positional arguments.
+// Attributes are matched case-insensitively against columns; unknown attributes are rejected.
+func SCIMFilterToSQL(filter *SCIMFilter, columns map[string]SCIMFilterColumn) (string, []interface{}, error) {
+	switch filter.Op {
+	case SCIMFilterAnd, SCIMFilterOr:
+		left, leftArgs, err := SCIMFilterToSQL(filter.Left, columns)
+		if err != nil {
+			return "", nil, err
+		}
+		right, rightArgs, err := SCIMFilterToSQL(filter.Right, columns)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("(%s %s %s)", left, strings.ToUpper(filter.Op), right), append(leftArgs, rightArgs...), nil
+	case SCIMFilterNot:
+		inner, args, err := SCIMFilterToSQL(filter.Left, columns)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("(NOT %s)", inner), args, nil
+	}
+
+	column, ok := columns[strings.ToLower(filter.Attribute)]
+	if !ok {
+		return "", nil, fmt.Errorf("filtering on %q is not supported", filter.Attribute)
+	}
+
+	switch column.Kind {
+	case SCIMAttributeBoolean:
+		return scimBooleanSQL(filter, column)
+	case SCIMAttributeDateTime:
+		return scimDateTimeSQL(filter, column)
+	default:
+		return scimStringSQL(filter, column)
+	}
+}
+
+func scimStringSQL(filter *SCIMFilter, column SCIMFilterColumn) (string, []interface{}, error) {
+	if filter.Op == SCIMFilterPr {
+		return fmt.Sprintf("(%s IS NOT NULL AND %s <> '')", column.Column, column.Column), nil, nil
+	}
+
+	value, ok := filter.Value.(string)
+	if !ok {
+		return "", nil, fmt.Errorf("attribute %q expects a string value", filter.Attribute)
+	}
+
+	switch filter.Op {
+	case SCIMFilterEq:
+		return fmt.Sprintf("%s = ?", column.Column), []interface{}{value}, nil
+	case SCIMFilterNe:
+		return fmt.Sprintf("(%s <> ? OR %s IS NULL)", column.Column, column.Column), []interface{}{value}, nil
+	case SCIMFilterCo:
+		return fmt.Sprintf("%s LIKE ?", column.Column), []interface{}{"%" + EscapeLike(value) + "%"}, nil
+	case SCIMFilterSw:
+		return fmt.Sprintf("%s LIKE ?", column.Column), []interface{}{EscapeLike(value) + "%"}, nil
+	case SCIMFilterEw:
+		return fmt.Sprintf("%s LIKE ?", column.Column), []interface{}{"%" + EscapeLike(value)}, nil
+	default:
+		return fmt.Sprintf("%s %s ?", column.Column, scimSQLComparison(filter.Op)), []interface{}{value}, nil
+	}
+}
+
+func scimBooleanSQL(filter *SCIMFilter, column SCIMFilterColumn) (string, []interface{}, error) {
+	if filter.Op == SCIMFilterPr {
+		return "1 = 1", nil, nil
+	}
+
+	value, ok := filter.Value.(bool)
+	if !ok {
+		return "", nil, fmt.Errorf("attribute %q expects a boolean value", filter.Attribute)
+	}
+
+	switch filter.Op {
+	case SCIMFilterEq, SCIMFilterNe:
+		if value == (filter.Op == SCIMFilterEq) {
+			return fmt.Sprintf("(%s)", column.Column), nil, nil
+		}
+		return fmt.Sprintf("(NOT (%s))", column.Column), nil, nil
+	default:
+		return "", nil, fmt.Errorf("operator %q is not supported for boolean attribute %q", filter.Op, filter.Attribute)
+	}
+}
+
+func scimDateTimeSQL(filter *SCIMFilter, column SCIMFilterColumn) (string, []interface{}, error) {
+	if filter.Op == SCIMFilterPr {
+		return fmt.Sprintf("%s IS NOT NULL", column.Column), nil, nil
+	}
+
+	raw, ok := filter.Value.(string)
+	if !ok {
+		return "", nil, fmt.Errorf("attribute %q expects a dateTime value", filter.Attribute)
+	}
+	value, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return "", nil, fmt.Errorf("attribute %q expects a dateTime value", filter.Attribute)
+	}
+
+	switch filter.Op {
+	case SCIMFilterCo, SCIMFilterSw, SCIMFilterEw:
+		return "", nil, fmt.Errorf("operator %q is not supported for dateTime attribute %q", filter.Op, filter.Attribute)
+	case SCIMFilterNe:
+		return fmt.Sprintf("(%s <> ? OR %s IS NULL)", column.Column, column.Column), []interface{}{value}, nil
+	default:
+		return fmt.Sprintf("%s %s ?", column.Column, scimSQLComparison(filter.Op)), []interface{}{value}, nil
+	}
+}
+
+func scimSQLComparison(op string) string {
+	switch op {
+	case SCIMFilterGt:
+		return ">"
+	case SCIMFilterGe:
+		return ">="
+	case SCIMFilterLt:
+		return "<"
+	case SCIMFilterLe:
+		return "<="
+	default:
+		return "="
+	}
+}
+
+// EscapeLike escapes the LIKE wildcards of a value matched literally
+func EscapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+type scimTokenKind int
+
+const (
+	scimTokenWord scimTokenKind = iota
+	scimTokenString
+	scimTokenLParen
+	scimTokenRParen
+	scimTokenLBracket
+	scimTokenRBracket
+)
+
+type scimToken struct {
+	kind   scimTokenKind
+	text   string
+	offset int
+}
+
+func tokenizeSCIMFilter(filter string) ([]scimToken, error) {
+	var tokens []scimToken
+	runes := []rune(filter)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, scimToken{scimTokenLParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, scimToken{scimTokenRParen, ")", i})
+			i++
+		case r == '[':
+			tokens = append(tokens, scimToken{scimTokenLBracket, "[", i})
+			i++
+		case r == ']':
+			tokens = append(tokens, scimToken{scimTokenRBracket, "]", i})
+			i++
+		case r == '"':
+			start := i
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				if runes[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			i++
+
+			var value string
+			if err := json.Unmarshal([]byte(string(runes[start:i])), &value); err != nil {
+				return nil, fmt.Errorf("invalid string at position %d", start)
+			}
+			tokens = append(tokens, scimToken{scimTokenString, value, start})
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(`()[]"`, runes[i]) {
+				i++
+			}
+			tokens = append(tokens, scimToken{scimTokenWord, string(runes[start:i]), start})
+		}
+	}
+
+	return tokens, nil
+}
+
+type scimFilterParser struct {
+	tokens []scimToken
+	pos    int
+}
+
+func (p *scimFilterParser) peekKeyword(keyword string) bool {
+	if p.pos >= len(p.tokens) {
+		return false
+	}
+	t := p.tokens[p.pos]
+	return t.kind == scimTokenWord && strings.EqualFold(t.text, keyword)
+}
+
+func (p *scimFilterParser) parseOr(prefix string) (*SCIMFilter, error) {
+	left, err := p.parseAnd(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peekKeyword(SCIMFilterOr) {
+		p.pos++
+		right, err := p.parseAnd(prefix)
+		if err != nil {
+			return nil, err
+		}
+		left = &SCIMFilter{Op: SCIMFilterOr, Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *scimFilterParser) parseAnd(prefix string) (*SCIMFilter, error) {
+	left, err := p.parseUnary(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peekKeyword(SCIMFilterAnd) {
+		p.pos++
+		right, err := p.parseUnary(prefix)
+		if err != nil {
+			return nil, err
+		}
+		left = &SCIMFilter{Op: SCIMFilterAnd, Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *scimFilterParser) parseUnary(prefix string) (*SCIMFilter, error) {
+	if p.peekKeyword(SCIMFilterNot) {
+		p.pos++
+		if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != scimTokenLParen {
+			return nil, fmt.Errorf("expected \"(\" after not")
+		}
+		inner, err := p.parseUnary(prefix)
+		if err != nil {
+			return nil, err
+		}
+		return &SCIMFilter{Op: SCIMFilterNot, Left: inner}, nil
+	}
+
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == scimTokenLParen {
+		p.pos++
+		inner, err := p.parseOr(prefix)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(scimTokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+
+	return p.parseComparison(prefix)
+}
+
+func (p *scimFilterParser) parseComparison(prefix string) (*SCIMFilter, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of filter")
+	}
+
+	t := p.tokens[p.pos]
+	if t.kind != scimTokenWord {
+		return nil, fmt.Errorf("expected attribute at position %d", t.offset)
+	}
+	p.pos++
+
+	attribute := normalizeSCIMAttribute(t.text)
+	if prefix != "" {
+		attribute = prefix + "." + attribute
+	}
+
+	// Value filter on a multi-valued attribute, optionally followed by a sub-attribute
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == scimTokenLBracket {
+		p.pos++
+		inner, err := p.parseOr(attribute)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(scimTokenRBracket, "]"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != scimTokenWord {
+		return nil, fmt.Errorf("expected operator after %q", t.text)
+	}
+	op := strings.ToLower(p.tokens[p.pos].text)
+	p.pos++
+
+	if op == SCIMFilterPr {
+		return &SCIMFilter{Op: op, Attribute: attribute}, nil
+	}
+	if !scimComparisonOperators[op] {
+		return nil, fmt.Errorf("unknown operator %q", op)
+	}
+
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("expected value after %q", op)
+	}
+	valueToken := p.tokens[p.pos]
+	p.pos++
+
+	value, err := scimFilterValue(valueToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SCIMFilter{Op: op, Attribute: attribute, Value: value}, nil
+}
+
+func (p *scimFilterParser) expect(kind scimTokenKind, text string) error {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != kind {
+		return fmt.Errorf("expected %q", text)
+	}
+	p.pos++
+	return nil
+}
+
+func scimFilterValue(t scimToken) (interface{}, error) {
+	if t.kind == scimTokenString {
+		return t.text, nil
+	}
+	if t.kind != scimTokenWord {
+		return nil, fmt.Errorf("expected value at position %d", t.offset)
+	}
+
+	switch strings.ToLower(t.text) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+
+	number, err := strconv.ParseFloat(t.text, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q at position %d", t.text, t.offset)
+	}
+	return number, nil
+}
+
+// normalizeSCIMAttribute strips the schema URN from fully qualified attribute paths
+func normalizeSCIMAttribute(attribute string) string {
+	if strings.HasPrefix(strings.ToLower(attribute), "urn:") {
+		if i := strings.LastIndex(attribute, ":"); i >= 0 {
+			return attribute[i+1:]
+		}
+	}
+	return attribute
+}
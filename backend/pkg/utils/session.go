@@ -0,0 +1,17 @@
+package utils
+
+import "time"
+
+// SessionIdleDeadline returns when a session expires if there is no further activity after
+// lastActivity: one idle timeout later, but never past the absolute expiry
+func SessionIdleDeadline(lastActivity time.Time, idleTimeout time.Duration, expiresAt time.Time) time.Time {
+	if idleTimeout <= 0 {
+		return expiresAt
+	}
+
+	deadline := lastActivity.Add(idleTimeout)
+	if deadline.After(expiresAt) {
+		return expiresAt
+	}
+	return deadline
+}
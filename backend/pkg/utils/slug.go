@@ -1,55 +1,163 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"math/big"
 	"regexp"
 	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/unicode/norm"
 )
 
-func GenerateSlug(name string) string {
-	slug := strings.ToLower(name)
+// MaxSlugLength is the longest slug GenerateSlug returns and a user can choose. Suffixes added to
+// make a slug unique come on top of it.
+const MaxSlugLength = 60
+
+const (
+	sequentialSlugAttempts = 9 // base-1 to base-9 before random suffixes
+	randomSlugAttempts     = 5
+	randomSlugSuffixLength = 6
+	slugSuffixAlphabet     = "abcdefghijklmnopqrstuvwxyz0123456789"
+)
 
-	slug = strings.ReplaceAll(slug, " ", "-")
+// ErrSlugTaken is returned by the create callback of GenerateUniqueSlug when the unique index
+// rejects a slug
+var ErrSlugTaken = errors.New("slug is already taken")
 
-	reg := regexp.MustCompile(`[^a-z0-9\-]`)
-	slug = reg.ReplaceAllString(slug, "")
+var (
+	slugRegex         = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+	slugHyphensRegex  = regexp.MustCompile(`-+`)
+	slugSpecialLetter = map[rune]string{
+		'đ': "d", 'ð': "d", 'ħ': "h", 'ı': "i", 'ł': "l", 'ø': "o",
+		'œ': "oe", 'æ': "ae", 'ß': "ss", 'þ': "th",
+	}
+)
 
-	reg = regexp.MustCompile(`-+`)
-	slug = reg.ReplaceAllString(slug, "-")
+// reservedSlugs would clash with routes, well-known paths or would mislead users
+var reservedSlugs = map[string]bool{
+	"admin": true, "administrator": true, "api": true, "app": true, "assets": true, "auth": true,
+	"billing": true, "callback": true, "dashboard": true, "docs": true, "help": true, "invitations": true,
+	"joinable": true, "ldap": true, "login": true, "logout": true, "me": true, "new": true, "null": true, "oauth": true,
+	"root": true, "saml": true, "scim": true, "security": true, "settings": true, "signup": true,
+	"sso": true, "static": true, "status": true, "support": true, "system": true, "undefined": true,
+	"users": true, "workspaces": true, "www": true,
+}
 
+// GenerateSlug turns a name into a URL friendly slug. Letters are transliterated to ASCII
+// ("Công ty Đất Việt" gives "cong-ty-dat-viet"), whitespace becomes a hyphen and anything else is
+// dropped. Names written only in scripts without a transliteration, such as CJK, get a slug
+// derived from a hash of the name.
+func GenerateSlug(name string) string {
+	var b strings.Builder
+	for _, r := range transliterate(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '-' || unicode.IsSpace(r):
+			b.WriteByte('-')
+		}
+	}
+
+	slug := slugHyphensRegex.ReplaceAllString(b.String(), "-")
 	slug = strings.Trim(slug, "-")
+	if len(slug) > MaxSlugLength {
+		slug = strings.TrimRight(slug[:MaxSlugLength], "-")
+	}
 
 	if slug == "" {
+		if strings.IndexFunc(name, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			sum := sha256.Sum256([]byte(strings.TrimSpace(name)))
+			return "workspace-" + hex.EncodeToString(sum[:4])
+		}
 		slug = "workspace"
 	}
 
 	return slug
 }
 
-func GenerateUniqueSlug(name string, checkExists func(string) (bool, error)) (string, error) {
-	baseSlug := GenerateSlug(name)
-
-	exists, err := checkExists(baseSlug)
-	if err != nil {
-		return "", fmt.Errorf("failed to check slug existence: %w", err)
+// transliterate lowercases s and strips diacritics, spelling out the letters that do not decompose
+func transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if replacement, ok := slugSpecialLetter[r]; ok {
+			b.WriteString(replacement)
+			continue
+		}
+		b.WriteRune(r)
 	}
+	return b.String()
+}
+
+// IsValidSlug reports whether slug is lowercase letters and digits in hyphen separated groups,
+// at most MaxSlugLength long
+func IsValidSlug(slug string) bool {
+	return len(slug) <= MaxSlugLength && slugRegex.MatchString(slug)
+}
 
-	if !exists {
-		return baseSlug, nil
+// IsReservedSlug reports whether slug may not be given to a workspace. UUIDs are reserved as
+// well, since workspaces are looked up by ID or slug.
+func IsReservedSlug(slug string) bool {
+	if reservedSlugs[slug] {
+		return true
 	}
+	_, err := uuid.Parse(slug)
+	return err == nil
+}
 
-	for i := 1; i <= 1000; i++ {
-		candidateSlug := fmt.Sprintf("%s-%d", baseSlug, i)
+// GenerateUniqueSlug derives a slug from name and hands candidates to create until it accepts
+// one: the slug itself, then base-1 to base-9, then a few random suffixes. Reserved slugs are
+// skipped. create must return ErrSlugTaken when the unique index rejects the slug, any other
+// error is returned as is. Relying on the index instead of checking first keeps concurrent
+// requests for the same name from both passing.
+func GenerateUniqueSlug(name string, create func(slug string) error) (string, error) {
+	base := GenerateSlug(name)
 
-		exists, err := checkExists(candidateSlug)
+	for attempt := 0; attempt <= sequentialSlugAttempts+randomSlugAttempts; attempt++ {
+		candidate, err := slugCandidate(base, attempt)
 		if err != nil {
-			return "", fmt.Errorf("failed to check slug existence for %s: %w", candidateSlug, err)
+			return "", err
+		}
+		if IsReservedSlug(candidate) {
+			continue
 		}
 
-		if !exists {
-			return candidateSlug, nil
+		err = create(candidate)
+		if err == nil {
+			return candidate, nil
+		}
+		if !errors.Is(err, ErrSlugTaken) {
+			return "", err
 		}
 	}
 
-	return "", fmt.Errorf("unable to generate unique slug for name: %s", name)
+	return "", fmt.Errorf("unable to generate unique slug for name %s: %w", name, ErrSlugTaken)
+}
+
+func slugCandidate(base string, attempt int) (string, error) {
+	if attempt == 0 {
+		return base, nil
+	}
+	if attempt <= sequentialSlugAttempts {
+		return fmt.Sprintf("%s-%d", base, attempt), nil
+	}
+
+	suffix := make([]byte, randomSlugSuffixLength)
+	max := big.NewInt(int64(len(slugSuffixAlphabet)))
+	for i := range suffix {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate slug suffix: %w", err)
+		}
+		suffix[i] = slugSuffixAlphabet[n.Int64()]
+	}
+	return base + "-" + string(suffix), nil
 }
@@ -2,16 +2,24 @@ package utils
 
 import (
 	"regexp"
+	"strings"
+	"time"
+	_ "time/tzdata" // timezone validation must not depend on the zoneinfo of the host
 
 	"github.com/go-playground/validator/v10"
+	"golang.org/x/text/language"
 )
 
 var (
-	alphaSpaceRegex = regexp.MustCompile(`^[a-zA-Z\s\-']+$`)
+	alphaSpaceRegex   = regexp.MustCompile(`^[a-zA-Z\s\-']+$`)
+	resourceNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
 )
 
 func SetupCustomValidators(v *validator.Validate) {
 	v.RegisterValidation("alpha_space", validateAlphaSpace)
+	v.RegisterValidation("iana_timezone", validateIANATimezone)
+	v.RegisterValidation("resource_name", validateResourceName)
+	v.RegisterValidation("slug", validateSlug)
 }
 
 func validateAlphaSpace(fl validator.FieldLevel) bool {
@@ -21,3 +29,42 @@ func validateAlphaSpace(fl validator.FieldLevel) bool {
 	}
 	return alphaSpaceRegex.MatchString(field)
 }
+
+func validateIANATimezone(fl validator.FieldLevel) bool {
+	return IsIANATimezone(fl.Field().String())
+}
+
+func validateSlug(fl validator.FieldLevel) bool {
+	return IsValidSlug(fl.Field().String())
+}
+
+func validateResourceName(fl validator.FieldLevel) bool {
+	return IsResourceName(fl.Field().String())
+}
+
+// IsResourceName reports whether name can be the resource part of a resource:action permission:
+// lowercase letters, digits and underscores, starting with a letter
+func IsResourceName(name string) bool {
+	return resourceNameRegex.MatchString(name)
+}
+
+// IsIANATimezone reports whether name is a zone of the IANA time zone database such as
+// "Europe/Berlin" or "UTC". The empty name and "Local" are accepted by time.LoadLocation
+// but depend on the server, so they are rejected.
+func IsIANATimezone(name string) bool {
+	if name == "" || strings.EqualFold(name, "local") {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// IsLanguageTag reports whether tag is a BCP 47 language tag such as "en" or "pt-BR" that fits the
+// locale column, the rule the bcp47_language_tag and max=10 tags apply to the user API
+func IsLanguageTag(tag string) bool {
+	if tag == "" || len(tag) > 10 {
+		return false
+	}
+	_, err := language.Parse(tag)
+	return err == nil
+}
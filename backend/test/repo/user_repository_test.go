@@ -0,0 +1,264 @@
+package repo_test
+
+import (
+	"fmt"
+	"go-backend-v2/global"
+	"go-backend-v2/internal/common"
+	"go-backend-v2/internal/models"
+	"go-backend-v2/internal/repo"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type UserRepositoryTestSuite struct {
+	suite.Suite
+	db         *gorm.DB
+	userRepo   repo.UserRepositoryInterface
+	testDBName string
+}
+
+func (suite *UserRepositoryTestSuite) SetupSuite() {
+	suite.testDBName = fmt.Sprintf("test_user_repo_%d", time.Now().Unix())
+
+	host := getEnvOrDefault("TEST_MYSQL_HOST", "localhost")
+	port := getEnvOrDefault("TEST_MYSQL_PORT", "3307")
+	user := getEnvOrDefault("TEST_MYSQL_USER", "root")
+	password := getEnvOrDefault("TEST_MYSQL_PASSWORD", "root")
+
+	rootDSN := fmt.Sprintf("%s:%s@tcp(%s:%s)/", user, password, host, port)
+	rootDB, err := gorm.Open(mysql.Open(rootDSN), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	assert.NoError(suite.T(), err, "Failed to connect to MySQL root")
+
+	err = rootDB.Exec(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s` CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci", suite.testDBName)).Error
+	assert.NoError(suite.T(), err, "Failed to create test database")
+
+	sqlDB, _ := rootDB.DB()
+	sqlDB.Close()
+
+	testDSN := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		user, password, host, port, suite.testDBName)
+
+	testDB, err := gorm.Open(mysql.Open(testDSN), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	assert.NoError(suite.T(), err, "Failed to connect to test database")
+
+	suite.db = testDB
+
+	err = suite.db.AutoMigrate(
+		&models.User{},
+		&models.UserProfile{},
+		&models.UserAuthProvider{},
+		&models.UserDevice{},
+		&models.Workspace{},
+		&models.WorkspaceRole{},
+		&models.UserWorkspaceMembership{},
+		&models.WorkspaceGroup{},
+		&models.WorkspaceGroupMember{},
+	)
+	assert.NoError(suite.T(), err, "Failed to migrate test database schema")
+
+	// The deletion lifecycle queries run outside a transaction, on the global connection
+	global.DB = suite.db
+	suite.userRepo = repo.NewUserRepository()
+}
+
+func (suite *UserRepositoryTestSuite) SetupTest() {
+	tables := []string{
+		"workspace_group_members",
+		"user_workspace_memberships",
+		"user_devices",
+		"user_auth_providers",
+		"user_profiles",
+		"users",
+	}
+
+	suite.db.Exec("SET FOREIGN_KEY_CHECKS = 0")
+	for _, table := range tables {
+		suite.db.Exec(fmt.Sprintf("TRUNCATE TABLE %s", table))
+	}
+	suite.db.Exec("SET FOREIGN_KEY_CHECKS = 1")
+}
+
+func (suite *UserRepositoryTestSuite) TearDownSuite() {
+	if suite.db != nil {
+		suite.db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS `%s`", suite.testDBName))
+		sqlDB, _ := suite.db.DB()
+		sqlDB.Close()
+	}
+}
+
+func (suite *UserRepositoryTestSuite) TestChangeEmail_UpdatesLinkedProviders() {
+	oldEmail := "member@example.com"
+	newEmail := "member@example.org"
+
+	user := &models.User{
+		ID:     generateTestID("user"),
+		Email:  oldEmail,
+		Status: "active",
+	}
+	profile := &models.UserProfile{
+		UserID:    user.ID,
+		FirstName: "Linked",
+		LastName:  "Member",
+	}
+	local := &models.UserAuthProvider{
+		UserID:         user.ID,
+		Provider:       common.AuthProviderLocal,
+		ProviderUserID: oldEmail,
+		ProviderEmail:  stringPtr(oldEmail),
+		PasswordHash:   stringPtr("$2a$10$hashedpassword"),
+		IsPrimary:      true,
+		Status:         "active",
+	}
+
+	tx := suite.db.Begin()
+	err := suite.userRepo.CreateUserWithAuth(tx, user, profile, local)
+	assert.NoError(suite.T(), err)
+	tx.Commit()
+
+	linked := &models.UserAuthProvider{
+		UserID:         user.ID,
+		Provider:       common.AuthProviderGoogle,
+		ProviderUserID: "google-subject-1",
+		ProviderEmail:  stringPtr(oldEmail),
+		Status:         "active",
+	}
+	other := &models.UserAuthProvider{
+		UserID:         user.ID,
+		Provider:       common.AuthProviderGithub,
+		ProviderUserID: "github-subject-1",
+		ProviderEmail:  stringPtr("someone-else@example.com"),
+		Status:         "active",
+	}
+	assert.NoError(suite.T(), suite.db.Create(linked).Error)
+	assert.NoError(suite.T(), suite.db.Create(other).Error)
+
+	tx = suite.db.Begin()
+	err = suite.userRepo.ChangeEmail(tx, user.ID, oldEmail, newEmail)
+	assert.NoError(suite.T(), err)
+	tx.Commit()
+
+	var savedLocal, savedLinked, savedOther models.UserAuthProvider
+	assert.NoError(suite.T(), suite.db.First(&savedLocal, "id = ?", local.ID).Error)
+	assert.NoError(suite.T(), suite.db.First(&savedLinked, "id = ?", linked.ID).Error)
+	assert.NoError(suite.T(), suite.db.First(&savedOther, "id = ?", other.ID).Error)
+
+	assert.Equal(suite.T(), newEmail, savedLocal.ProviderUserID)
+	assert.Equal(suite.T(), newEmail, *savedLocal.ProviderEmail)
+
+	// Linked providers follow the new address but keep their own subject
+	assert.Equal(suite.T(), "google-subject-1", savedLinked.ProviderUserID)
+	assert.Equal(suite.T(), newEmail, *savedLinked.ProviderEmail)
+
+	// A provider linked under another address is left alone
+	assert.Equal(suite.T(), "someone-else@example.com", *savedOther.ProviderEmail)
+}
+
+func (suite *UserRepositoryTestSuite) TestChangeEmail_StaleOldEmail() {
+	user := &models.User{
+		ID:     generateTestID("user"),
+		Email:  "current@example.com",
+		Status: "active",
+	}
+	assert.NoError(suite.T(), suite.db.Create(user).Error)
+
+	tx := suite.db.Begin()
+	err := suite.userRepo.ChangeEmail(tx, user.ID, "previous@example.com", "next@example.com")
+	tx.Rollback()
+
+	assert.ErrorIs(suite.T(), err, gorm.ErrRecordNotFound)
+}
+
+func (suite *UserRepositoryTestSuite) TestDeletion_ScheduleRestorePurge() {
+	email := "leaving@example.com"
+
+	user := &models.User{
+		ID:     generateTestID("user"),
+		Email:  email,
+		Status: common.UserStatusActive,
+	}
+	profile := &models.UserProfile{
+		UserID:    user.ID,
+		FirstName: "Leaving",
+		LastName:  "Member",
+	}
+	local := &models.UserAuthProvider{
+		UserID:         user.ID,
+		Provider:       common.AuthProviderLocal,
+		ProviderUserID: email,
+		ProviderEmail:  stringPtr(email),
+		PasswordHash:   stringPtr("$2a$10$hashedpassword"),
+		IsPrimary:      true,
+		Status:         "active",
+	}
+
+	tx := suite.db.Begin()
+	assert.NoError(suite.T(), suite.userRepo.CreateUserWithAuth(tx, user, profile, local))
+	tx.Commit()
+
+	// Scheduled: out of reach of the purger until the grace period is over
+	now := time.Now()
+	assert.NoError(suite.T(), suite.userRepo.ScheduleUserDeletion(user.ID, now.Add(time.Hour)))
+	assert.ErrorIs(suite.T(), suite.userRepo.ScheduleUserDeletion(user.ID, now.Add(time.Hour)), gorm.ErrRecordNotFound, "only active accounts can be scheduled")
+
+	scheduled, err := suite.userRepo.GetUserByID(user.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), common.UserStatusPendingDeletion, scheduled.Status)
+	assert.NotNil(suite.T(), scheduled.DeletionScheduledAt)
+
+	due, err := suite.userRepo.GetUsersDueForPurge(now, 10)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), due)
+
+	// Restored: active again, with nothing left to purge
+	assert.NoError(suite.T(), suite.userRepo.RestoreUser(user.ID))
+	assert.ErrorIs(suite.T(), suite.userRepo.RestoreUser(user.ID), gorm.ErrRecordNotFound, "an active account cannot be restored")
+
+	restored, err := suite.userRepo.GetUserByID(user.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), common.UserStatusActive, restored.Status)
+	assert.Nil(suite.T(), restored.DeletionScheduledAt)
+
+	// Scheduled again and left past its grace period: due and purged
+	assert.NoError(suite.T(), suite.userRepo.ScheduleUserDeletion(user.ID, now.Add(-time.Minute)))
+
+	due, err = suite.userRepo.GetUsersDueForPurge(now, 10)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), due, 1) {
+		assert.Equal(suite.T(), user.ID, due[0].ID)
+	}
+
+	tx = suite.db.Begin()
+	assert.NoError(suite.T(), suite.userRepo.PurgeUser(tx, user.ID))
+	tx.Commit()
+
+	var purged models.User
+	assert.NoError(suite.T(), suite.db.First(&purged, "id = ?", user.ID).Error)
+	assert.Equal(suite.T(), common.UserStatusDeleted, purged.Status)
+	assert.NotEqual(suite.T(), email, purged.Email, "the email is freed")
+	assert.NotNil(suite.T(), purged.PurgedAt)
+	assert.Nil(suite.T(), purged.DeletionScheduledAt)
+
+	var profiles, providers int64
+	suite.db.Model(&models.UserProfile{}).Where("user_id = ?", user.ID).Count(&profiles)
+	suite.db.Model(&models.UserAuthProvider{}).Where("user_id = ?", user.ID).Count(&providers)
+	assert.Zero(suite.T(), profiles)
+	assert.Zero(suite.T(), providers)
+
+	due, err = suite.userRepo.GetUsersDueForPurge(now, 10)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), due, "a purged account is not purged again")
+}
+
+func TestUserRepositorySuite(t *testing.T) {
+	suite.Run(t, new(UserRepositoryTestSuite))
+}
@@ -0,0 +1,95 @@
+package utils_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"go-backend-v2/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func encodeTestJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)), nil))
+	return buf.Bytes()
+}
+
+func decodeSize(t *testing.T, data []byte) (int, int, string) {
+	t.Helper()
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+	return config.Width, config.Height, format
+}
+
+func TestProcessAvatar_CropsAndResizes(t *testing.T) {
+	avatar, err := utils.ProcessAvatar(encodeTestPNG(t, 300, 200), &utils.AvatarOptions{Size: 100, ThumbnailSize: 32})
+	require.NoError(t, err)
+
+	width, height, format := decodeSize(t, avatar.Image.Data)
+	assert.Equal(t, 100, width)
+	assert.Equal(t, 100, height)
+	assert.Equal(t, "png", format)
+	assert.Equal(t, "image/png", avatar.Image.ContentType)
+	assert.Equal(t, ".png", avatar.Image.Extension)
+
+	width, height, _ = decodeSize(t, avatar.Thumbnail.Data)
+	assert.Equal(t, 32, width)
+	assert.Equal(t, 32, height)
+}
+
+func TestProcessAvatar_KeepsJPEGAndNeverUpscales(t *testing.T) {
+	avatar, err := utils.ProcessAvatar(encodeTestJPEG(t, 40, 60), &utils.AvatarOptions{Size: 512, ThumbnailSize: 128})
+	require.NoError(t, err)
+
+	width, height, format := decodeSize(t, avatar.Image.Data)
+	assert.Equal(t, 40, width)
+	assert.Equal(t, 40, height)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, ".jpg", avatar.Image.Extension)
+
+	width, _, _ = decodeSize(t, avatar.Thumbnail.Data)
+	assert.Equal(t, 40, width)
+}
+
+func TestProcessAvatar_SniffsContent(t *testing.T) {
+	_, err := utils.ProcessAvatar([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"), nil)
+	assert.ErrorIs(t, err, utils.ErrAvatarUnsupportedType)
+
+	_, err = utils.ProcessAvatar([]byte("GIF89a"), nil)
+	assert.ErrorIs(t, err, utils.ErrAvatarInvalidImage)
+}
+
+func TestProcessAvatar_EnforcesLimits(t *testing.T) {
+	data := encodeTestPNG(t, 120, 80)
+
+	_, err := utils.ProcessAvatar(data, &utils.AvatarOptions{MaxBytes: int64(len(data) - 1)})
+	assert.ErrorIs(t, err, utils.ErrAvatarTooLarge)
+
+	_, err = utils.ProcessAvatar(data, &utils.AvatarOptions{MaxDimension: 100})
+	assert.ErrorIs(t, err, utils.ErrAvatarTooLarge)
+}
+
+func TestAvatarThumbnailKey(t *testing.T) {
+	assert.Equal(t, "avatars/users/u1/abc_thumb.jpg", utils.AvatarThumbnailKey("avatars/users/u1/abc.jpg"))
+	assert.Equal(t, "avatars/users/u1/abc_thumb.png", utils.AvatarThumbnailKey("avatars/users/u1/abc.png"))
+}
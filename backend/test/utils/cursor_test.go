@@ -0,0 +1,34 @@
+package utils_test
+
+import (
+	"go-backend-v2/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	createdAt := time.Date(2025, 3, 14, 15, 9, 26, 535000000, time.UTC)
+
+	encoded := utils.EncodeCursor(utils.Cursor{CreatedAt: createdAt, ID: "membership-1"})
+	assert.NotContains(t, encoded, "=", "cursors are safe in query strings")
+
+	cursor, err := utils.DecodeCursor(encoded)
+	require.NoError(t, err)
+	assert.True(t, createdAt.Equal(cursor.CreatedAt))
+	assert.Equal(t, "membership-1", cursor.ID)
+}
+
+func TestCursor_Invalid(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"not base64!",
+		"bm90IGpzb24",                  // "not json"
+		"eyJpZCI6Im1lbWJlcnNoaXAtMSJ9", // no creation time
+	} {
+		_, err := utils.DecodeCursor(value)
+		assert.ErrorIs(t, err, utils.ErrInvalidCursor, value)
+	}
+}
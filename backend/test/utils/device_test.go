@@ -0,0 +1,96 @@
+package utils_test
+
+import (
+	"go-backend-v2/pkg/utils"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	chromeWindowsUA = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"
+	edgeWindowsUA   = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 Edg/126.0.2592.87"
+	safariIPhoneUA  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1"
+	firefoxLinuxUA  = "Mozilla/5.0 (X11; Linux x86_64; rv:127.0) Gecko/20100101 Firefox/127.0"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		name            string
+		userAgent       string
+		expectedBrowser string
+		expectedOS      string
+	}{
+		{"Chrome on Windows", chromeWindowsUA, "Chrome", "Windows"},
+		{"Edge is not reported as Chrome", edgeWindowsUA, "Edge", "Windows"},
+		{"Safari on iPhone", safariIPhoneUA, "Safari", "iOS"},
+		{"Firefox on Linux", firefoxLinuxUA, "Firefox", "Linux"},
+		{"Command line client", "curl/8.5.0", "curl", utils.DeviceOSUnknown},
+		{"Empty user agent", "", utils.DeviceBrowserUnknown, utils.DeviceOSUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			browser, os := utils.ParseUserAgent(tt.userAgent)
+			assert.Equal(t, tt.expectedBrowser, browser)
+			assert.Equal(t, tt.expectedOS, os)
+		})
+	}
+}
+
+func TestIPSubnet(t *testing.T) {
+	tests := []struct {
+		name     string
+		ip       string
+		expected string
+	}{
+		{"IPv4 address", "203.0.113.42", "203.0.113.0/24"},
+		{"IPv4 mapped IPv6 address", "::ffff:203.0.113.42", "203.0.113.0/24"},
+		{"IPv6 address", "2001:db8:abcd:12:1:2:3:4", "2001:db8:abcd:12::/64"},
+		{"Invalid address", "not-an-ip", utils.DeviceSubnetUnknown},
+		{"Empty address", "", utils.DeviceSubnetUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, utils.IPSubnet(tt.ip))
+		})
+	}
+}
+
+func TestFingerprintDevice_SameNetworkSameBrowser(t *testing.T) {
+	first := utils.FingerprintDevice(chromeWindowsUA, "203.0.113.10")
+	// Browser upgrade and a new DHCP lease in the same network
+	second := utils.FingerprintDevice("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/127.0.0.0 Safari/537.36", "203.0.113.99")
+
+	assert.Equal(t, first.Fingerprint, second.Fingerprint)
+	assert.Len(t, first.Fingerprint, 64)
+}
+
+func TestFingerprintDevice_DifferentDevices(t *testing.T) {
+	base := utils.FingerprintDevice(chromeWindowsUA, "203.0.113.10")
+
+	otherNetwork := utils.FingerprintDevice(chromeWindowsUA, "198.51.100.10")
+	otherBrowser := utils.FingerprintDevice(firefoxLinuxUA, "203.0.113.10")
+
+	assert.NotEqual(t, base.Fingerprint, otherNetwork.Fingerprint)
+	assert.NotEqual(t, base.Fingerprint, otherBrowser.Fingerprint)
+}
+
+func TestTruncateUTF8(t *testing.T) {
+	assert.Equal(t, "Firefox", utils.TruncateUTF8("Firefox", 512))
+	assert.Equal(t, "Fire", utils.TruncateUTF8("Firefox", 4))
+
+	// "é" takes two bytes and "日" three, neither may be split
+	assert.Equal(t, "caf", utils.TruncateUTF8("café", 4))
+	assert.Equal(t, "café", utils.TruncateUTF8("café", 5))
+	assert.Equal(t, "", utils.TruncateUTF8("日本", 2))
+	assert.Equal(t, "日", utils.TruncateUTF8("日本", 4))
+
+	long := strings.Repeat("a", 511) + "日本語"
+	truncated := utils.TruncateUTF8(long, 512)
+	assert.True(t, utf8.ValidString(truncated))
+	assert.Equal(t, strings.Repeat("a", 511), truncated)
+}
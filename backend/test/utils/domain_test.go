@@ -0,0 +1,78 @@
+package utils_test
+
+import (
+	"context"
+	"errors"
+	"go-backend-v2/pkg/utils"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubResolver answers TXT lookups from a map instead of DNS
+type stubResolver struct {
+	records map[string][]string
+	err     error
+}
+
+func (r *stubResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	records, ok := r.records[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func TestNormalizeDomain(t *testing.T) {
+	assert.Equal(t, "acme.com", utils.NormalizeDomain(" ACME.com. "))
+	assert.Equal(t, "mail.acme.co.uk", utils.NormalizeDomain("mail.acme.co.uk"))
+	assert.Equal(t, "xn--bcher-kva.example", utils.NormalizeDomain("xn--bcher-kva.example"))
+
+	for _, domain := range []string{"", "localhost", "acme", "-acme.com", "acme-.com", "ac_me.com", "acme..com", "acme.c0m", "user@acme.com"} {
+		assert.Empty(t, utils.NormalizeDomain(domain), domain)
+	}
+}
+
+func TestEmailDomain(t *testing.T) {
+	assert.Equal(t, "acme.com", utils.EmailDomain("Jane.Doe@Acme.COM"))
+	assert.Equal(t, "acme.com", utils.EmailDomain(`"odd@name"@acme.com`))
+	assert.Empty(t, utils.EmailDomain("no-at-sign"))
+	assert.Empty(t, utils.EmailDomain("jane@localhost"))
+}
+
+func TestVerifyDomainTXT(t *testing.T) {
+	resolver := &stubResolver{records: map[string][]string{
+		"_workspace-verification.acme.com":  {"v=spf1 -all", " workspace-verification=token-1 "},
+		"_workspace-verification.other.com": {"workspace-verification=someone-else"},
+	}}
+
+	ok, err := utils.VerifyDomainTXT(context.Background(), resolver, "acme.com", "token-1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = utils.VerifyDomainTXT(context.Background(), resolver, "other.com", "token-1")
+	require.NoError(t, err)
+	assert.False(t, ok, "the record holds another token")
+
+	ok, err = utils.VerifyDomainTXT(context.Background(), resolver, "missing.com", "token-1")
+	require.NoError(t, err)
+	assert.False(t, ok, "a missing record is not an error")
+}
+
+func TestVerifyDomainTXT_LookupError(t *testing.T) {
+	resolver := &stubResolver{err: errors.New("server misbehaving")}
+
+	ok, err := utils.VerifyDomainTXT(context.Background(), resolver, "acme.com", "token-1")
+	assert.Error(t, err)
+	assert.False(t, ok)
+}
+
+func TestDomainVerificationRecord(t *testing.T) {
+	assert.Equal(t, "_workspace-verification.acme.com", utils.DomainVerificationRecord("acme.com"))
+	assert.Equal(t, "workspace-verification=abc", utils.DomainVerificationValue("abc"))
+}
@@ -0,0 +1,262 @@
+package utils_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"go-backend-v2/global"
+	"go-backend-v2/pkg/setting"
+	"go-backend-v2/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const dpopTestURL = "https://api.example.com/api/v1/auth/login"
+
+type DPoPTestSuite struct {
+	suite.Suite
+	key *ecdsa.PrivateKey
+	jwk *utils.JWK
+	now time.Time
+}
+
+func (s *DPoPTestSuite) SetupTest() {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+
+	s.key = key
+	s.jwk = &utils.JWK{
+		Kty: "EC",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+	s.now = time.Now()
+}
+
+func (s *DPoPTestSuite) proof(claims *utils.DPoPProofClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["typ"] = utils.DPoPProofType
+	token.Header["jwk"] = s.jwk
+
+	signed, err := token.SignedString(s.key)
+	s.Require().NoError(err)
+	return signed
+}
+
+func (s *DPoPTestSuite) claims() *utils.DPoPProofClaims {
+	return &utils.DPoPProofClaims{
+		JTI: "proof-1",
+		HTM: "POST",
+		HTU: dpopTestURL,
+		IAT: s.now.Unix(),
+	}
+}
+
+func (s *DPoPTestSuite) request() *utils.DPoPProofRequest {
+	return &utils.DPoPProofRequest{
+		Method:    "POST",
+		URL:       dpopTestURL,
+		Now:       s.now,
+		MaxAge:    5 * time.Minute,
+		ClockSkew: 30 * time.Second,
+	}
+}
+
+func (s *DPoPTestSuite) TestParse_ValidProof() {
+	proof, err := utils.ParseDPoPProof(s.proof(s.claims()), s.request())
+
+	s.Require().NoError(err)
+	thumbprint, err := s.jwk.Thumbprint()
+	s.Require().NoError(err)
+	s.Equal(thumbprint, proof.JKT)
+	s.Equal("proof-1", proof.JTI)
+}
+
+func (s *DPoPTestSuite) TestParse_IgnoresQuery() {
+	claims := s.claims()
+	claims.HTU = dpopTestURL + "?redirect=1"
+
+	_, err := utils.ParseDPoPProof(s.proof(claims), s.request())
+
+	s.NoError(err)
+}
+
+func (s *DPoPTestSuite) TestParse_WrongMethod() {
+	claims := s.claims()
+	claims.HTM = "GET"
+
+	_, err := utils.ParseDPoPProof(s.proof(claims), s.request())
+
+	s.ErrorIs(err, utils.ErrDPoPProofInvalid)
+}
+
+func (s *DPoPTestSuite) TestParse_WrongURL() {
+	claims := s.claims()
+	claims.HTU = "https://api.example.com/api/v1/users/me"
+
+	_, err := utils.ParseDPoPProof(s.proof(claims), s.request())
+
+	s.ErrorIs(err, utils.ErrDPoPProofInvalid)
+}
+
+func (s *DPoPTestSuite) TestParse_StaleProof() {
+	claims := s.claims()
+	claims.IAT = s.now.Add(-10 * time.Minute).Unix()
+
+	_, err := utils.ParseDPoPProof(s.proof(claims), s.request())
+
+	s.ErrorIs(err, utils.ErrDPoPProofInvalid)
+}
+
+func (s *DPoPTestSuite) TestParse_ProofFromTheFuture() {
+	claims := s.claims()
+	claims.IAT = s.now.Add(5 * time.Minute).Unix()
+
+	_, err := utils.ParseDPoPProof(s.proof(claims), s.request())
+
+	s.ErrorIs(err, utils.ErrDPoPProofInvalid)
+}
+
+func (s *DPoPTestSuite) TestParse_AccessTokenHash() {
+	req := s.request()
+	req.AccessToken = "access-token"
+
+	claims := s.claims()
+	claims.ATH = utils.DPoPAccessTokenHash("other-token")
+	_, err := utils.ParseDPoPProof(s.proof(claims), req)
+	s.ErrorIs(err, utils.ErrDPoPProofInvalid)
+
+	claims.ATH = utils.DPoPAccessTokenHash("access-token")
+	_, err = utils.ParseDPoPProof(s.proof(claims), req)
+	s.NoError(err)
+}
+
+func (s *DPoPTestSuite) TestParse_RejectsPrivateKeyInHeader() {
+	s.jwk.D = base64.RawURLEncoding.EncodeToString(s.key.D.Bytes())
+
+	_, err := utils.ParseDPoPProof(s.proof(s.claims()), s.request())
+
+	s.ErrorIs(err, utils.ErrDPoPProofInvalid)
+}
+
+func (s *DPoPTestSuite) TestParse_RejectsWrongType() {
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, s.claims())
+	token.Header["typ"] = "JWT"
+	token.Header["jwk"] = s.jwk
+	signed, err := token.SignedString(s.key)
+	s.Require().NoError(err)
+
+	_, err = utils.ParseDPoPProof(signed, s.request())
+
+	s.ErrorIs(err, utils.ErrDPoPProofInvalid)
+}
+
+func (s *DPoPTestSuite) TestParse_RejectsSymmetricAlgorithm() {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, s.claims())
+	token.Header["typ"] = utils.DPoPProofType
+	token.Header["jwk"] = s.jwk
+	signed, err := token.SignedString([]byte("shared-secret"))
+	s.Require().NoError(err)
+
+	_, err = utils.ParseDPoPProof(signed, s.request())
+
+	s.ErrorIs(err, utils.ErrDPoPProofInvalid)
+}
+
+func (s *DPoPTestSuite) TestParse_RejectsForeignSignature() {
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, s.claims())
+	token.Header["typ"] = utils.DPoPProofType
+	token.Header["jwk"] = s.jwk
+	signed, err := token.SignedString(other)
+	s.Require().NoError(err)
+
+	_, err = utils.ParseDPoPProof(signed, s.request())
+
+	s.ErrorIs(err, utils.ErrDPoPProofInvalid)
+}
+
+// TestBinding_LoginThenProtectedRoute follows a client through the flow: the login proof binds
+// the access token to its key, then the proof sent to a protected route, which cannot hash the
+// token held in the HttpOnly cookie, is accepted only when signed by that key
+func (s *DPoPTestSuite) TestBinding_LoginThenProtectedRoute() {
+	originalConfig := global.Config
+	global.Config = &setting.Config{JWT: setting.JWT{Secret: "test-jwt-secret-key-for-testing"}}
+	s.T().Cleanup(func() { global.Config = originalConfig })
+
+	login, err := utils.ParseDPoPProof(s.proof(s.claims()), s.request())
+	s.Require().NoError(err)
+
+	accessToken, err := utils.GenerateDPoPBoundToken("user-1", s.now.Add(time.Hour), login.JKT)
+	s.Require().NoError(err)
+	claims, err := utils.ParseToken(accessToken)
+	s.Require().NoError(err)
+
+	const meURL = "https://api.example.com/api/v1/users/me"
+	routeClaims := &utils.DPoPProofClaims{JTI: "proof-2", HTM: "GET", HTU: meURL, IAT: s.now.Unix()}
+	routeRequest := &utils.DPoPProofRequest{
+		Method:    "GET",
+		URL:       meURL,
+		Now:       s.now,
+		MaxAge:    5 * time.Minute,
+		ClockSkew: 30 * time.Second,
+	}
+
+	verified, err := utils.VerifyDPoPBinding(claims, s.proof(routeClaims), routeRequest)
+	s.Require().NoError(err)
+	s.Equal(login.JKT, verified.JKT)
+
+	// A proof made for the login request does not carry over to the route
+	_, err = utils.VerifyDPoPBinding(claims, s.proof(s.claims()), routeRequest)
+	s.ErrorIs(err, utils.ErrDPoPProofInvalid)
+
+	// Nor does a proof signed by another key
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+	s.key = other
+	s.jwk = &utils.JWK{
+		Kty: "EC",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(other.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(other.Y.FillBytes(make([]byte, 32))),
+	}
+	_, err = utils.VerifyDPoPBinding(claims, s.proof(routeClaims), routeRequest)
+	s.ErrorIs(err, utils.ErrDPoPProofInvalid)
+}
+
+func (s *DPoPTestSuite) TestBinding_UnboundToken() {
+	_, err := utils.VerifyDPoPBinding(&utils.JWTClaims{UserID: "user-1"}, s.proof(s.claims()), s.request())
+
+	s.ErrorIs(err, utils.ErrDPoPProofInvalid)
+}
+
+func TestDPoPTestSuite(t *testing.T) {
+	suite.Run(t, new(DPoPTestSuite))
+}
+
+func TestJWKThumbprint(t *testing.T) {
+	// Example key from RFC 7638 section 3.1
+	jwk := &utils.JWK{
+		Kty: "RSA",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+	}
+
+	thumbprint, err := jwk.Thumbprint()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint)
+}
+
+func TestExternalRequestURL(t *testing.T) {
+	assert.Equal(t, "https://api.example.com/api/v1/users/me", utils.ExternalRequestURL("https://api.example.com/", "http://10.0.0.1:8080", "/api/v1/users/me"))
+	assert.Equal(t, "http://localhost:8080/api/v1/users/me", utils.ExternalRequestURL("", "http://localhost:8080", "/api/v1/users/me"))
+}
@@ -0,0 +1,51 @@
+package utils_test
+
+import (
+	"go-backend-v2/global"
+	"go-backend-v2/pkg/setting"
+	"go-backend-v2/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withJWTSecret(t *testing.T, secret string) {
+	t.Helper()
+	original := global.Config
+	global.Config = &setting.Config{JWT: setting.JWT{Secret: secret, ExpirationTime: time.Hour}}
+	t.Cleanup(func() { global.Config = original })
+}
+
+func TestInvitationToken_RoundTrip(t *testing.T) {
+	withJWTSecret(t, "test-jwt-secret-key-for-testing")
+
+	token, err := utils.GenerateInvitationToken("inv-1", "nonce-1", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	claims, err := utils.ParseInvitationToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "inv-1", claims.InvitationID)
+	assert.Equal(t, "nonce-1", claims.ID)
+}
+
+func TestInvitationToken_Rejected(t *testing.T) {
+	withJWTSecret(t, "test-jwt-secret-key-for-testing")
+
+	expired, err := utils.GenerateInvitationToken("inv-1", "nonce-1", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	_, err = utils.ParseInvitationToken(expired)
+	assert.Error(t, err, "expired invitations are rejected")
+
+	accessToken, err := utils.GenerateToken("user-1")
+	require.NoError(t, err)
+	_, err = utils.ParseInvitationToken(accessToken)
+	assert.Error(t, err, "access tokens are not invitations")
+
+	valid, err := utils.GenerateInvitationToken("inv-1", "nonce-1", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	withJWTSecret(t, "another-secret")
+	_, err = utils.ParseInvitationToken(valid)
+	assert.Error(t, err, "tokens signed with another secret are rejected")
+}
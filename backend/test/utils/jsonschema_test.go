@@ -0,0 +1,127 @@
+package utils_test
+
+import (
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func workspaceSettingsSchema(t *testing.T) *utils.JSONSchema {
+	schema, err := utils.CompileJSONSchema(dto.WorkspaceSettingsSchema)
+	require.NoError(t, err)
+	return schema
+}
+
+func TestJSONSchema_WorkspaceSettingsValid(t *testing.T) {
+	schema := workspaceSettingsSchema(t)
+
+	err := schema.Validate(map[string]interface{}{
+		"version":                2,
+		"default_member_role_id": "0b5e8c1e-6a2f-4c39-9d7e-3f1a2b4c5d6e",
+		"branding": map[string]interface{}{
+			"primary_color": "#112233",
+			"accent_color":  "#abc",
+			"logo_url":      "https://cdn.example.com/logo.png",
+		},
+	})
+
+	assert.NoError(t, err)
+}
+
+func TestJSONSchema_WorkspaceSettingsInvalid(t *testing.T) {
+	schema := workspaceSettingsSchema(t)
+
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		path     string
+	}{
+		{"missing version", map[string]interface{}{}, "version"},
+		{"wrong version", map[string]interface{}{"version": 1}, "version"},
+		{"unknown key", map[string]interface{}{"version": 2, "theme": "dark"}, "theme"},
+		{"unenforced key", map[string]interface{}{"version": 2, "session_policy": map[string]interface{}{"require_sso": true}}, "session_policy"},
+		{"role not a uuid", map[string]interface{}{"version": 2, "default_member_role_id": "member"}, "default_member_role_id"},
+		{"logo over http", map[string]interface{}{"version": 2, "branding": map[string]interface{}{"logo_url": "http://example.com/logo.png"}}, "branding.logo_url"},
+		{"bad color", map[string]interface{}{"version": 2, "branding": map[string]interface{}{"primary_color": "red"}}, "branding.primary_color"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate(tt.settings)
+
+			var schemaErr *utils.SchemaError
+			require.ErrorAs(t, err, &schemaErr)
+			assert.Equal(t, tt.path, schemaErr.Path)
+		})
+	}
+}
+
+func TestJSONSchema_NumbersCompareByValue(t *testing.T) {
+	schema := workspaceSettingsSchema(t)
+
+	assert.NoError(t, schema.Validate(map[string]interface{}{"version": 2.0}))
+
+	limits, err := utils.CompileJSONSchema([]byte(`{"type": "object", "properties": {"hours": {"type": "integer", "minimum": 1}}}`))
+	require.NoError(t, err)
+	assert.NoError(t, limits.Validate(map[string]interface{}{"hours": 24.0}))
+}
+
+func TestJSONSchema_HostnameItems(t *testing.T) {
+	schema, err := utils.CompileJSONSchema([]byte(`{"type": "array", "uniqueItems": true, "items": {"type": "string", "format": "hostname"}}`))
+	require.NoError(t, err)
+
+	assert.NoError(t, schema.Validate([]interface{}{"example.com", "mail.example.org", "localhost"}))
+
+	var schemaErr *utils.SchemaError
+	require.ErrorAs(t, schema.Validate([]interface{}{"example.com", "not a domain"}), &schemaErr)
+	assert.Equal(t, "[1]", schemaErr.Path)
+	require.ErrorAs(t, schema.Validate([]interface{}{"example.com", "example.com"}), &schemaErr)
+	assert.Equal(t, "[1]", schemaErr.Path)
+}
+
+func TestJSONSchema_PruneLegacyProperties(t *testing.T) {
+	schema := workspaceSettingsSchema(t)
+
+	legacy := map[string]interface{}{
+		"version":                2,
+		"default_member_role_id": "member",
+		"branding":               map[string]interface{}{"primary_color": "#112233"},
+		"theme":                  "dark",
+	}
+
+	pruned, dropped := schema.PruneProperties(legacy)
+
+	assert.Equal(t, map[string]interface{}{
+		"version":  2,
+		"branding": map[string]interface{}{"primary_color": "#112233"},
+	}, pruned)
+	assert.Len(t, dropped, 2)
+	assert.Contains(t, dropped, "default_member_role_id")
+	assert.Contains(t, dropped, "theme")
+	assert.NoError(t, schema.Validate(pruned), "what is left passes the schema")
+	assert.Contains(t, legacy, "default_member_role_id", "the document is left untouched")
+}
+
+func TestCompileJSONSchema_RejectsUnsupportedSchemas(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"unknown keyword", `{"type": "object", "patternProperties": {}}`},
+		{"unknown format", `{"type": "string", "format": "email"}`},
+		{"remote reference", `{"$ref": "https://example.com/schema.json"}`},
+		{"missing definition", `{"properties": {"a": {"$ref": "#/$defs/missing"}}}`},
+		{"invalid pattern", `{"type": "string", "pattern": "("}`},
+		{"not json", `{`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := utils.CompileJSONSchema([]byte(tt.schema))
+			assert.ErrorIs(t, err, utils.ErrInvalidSchema)
+		})
+	}
+}
@@ -174,6 +174,40 @@ func (suite *JWTTestSuite) TestIsTokenExpired_ExpiredToken() {
 	assert.True(suite.T(), isExpired)
 }
 
+func (suite *JWTTestSuite) TestGenerateTokenWithExpiry() {
+	expiresAt := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second)
+
+	token, err := utils.GenerateTokenWithExpiry("test-user-123", expiresAt)
+	assert.NoError(suite.T(), err)
+
+	parsedToken, err := jwt.ParseWithClaims(token, &utils.JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return []byte(global.Config.JWT.Secret), nil
+	})
+	assert.NoError(suite.T(), err)
+
+	claims := parsedToken.Claims.(*utils.JWTClaims)
+	assert.True(suite.T(), claims.ExpiresAt.Time.Equal(expiresAt))
+}
+
+func (suite *JWTTestSuite) TestGenerateDPoPBoundToken() {
+	expiresAt := time.Now().Add(time.Hour)
+
+	bound, err := utils.GenerateDPoPBoundToken("test-user-123", expiresAt, "test-thumbprint")
+	assert.NoError(suite.T(), err)
+
+	claims, err := utils.ParseToken(bound)
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), claims.Confirmation)
+	assert.Equal(suite.T(), "test-thumbprint", claims.Confirmation.JKT)
+
+	bearer, err := utils.GenerateDPoPBoundToken("test-user-123", expiresAt, "")
+	assert.NoError(suite.T(), err)
+
+	claims, err = utils.ParseToken(bearer)
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), claims.Confirmation)
+}
+
 func (suite *JWTTestSuite) TestIsTokenExpired_InvalidToken() {
 	invalidToken := "invalid.token.string"
 
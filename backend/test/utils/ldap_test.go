@@ -0,0 +1,129 @@
+package utils_test
+
+import (
+	"fmt"
+	"go-backend-v2/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/jimlambrt/gldap"
+	"github.com/jimlambrt/gldap/testdirectory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	ldapTestServiceDN = "cn=service,ou=people,dc=example,dc=org"
+	ldapTestAliceDN   = "cn=alice,ou=people,dc=example,dc=org"
+	ldapTestGroupDN   = "cn=engineering,ou=groups,dc=example,dc=org"
+)
+
+type LDAPTestSuite struct {
+	suite.Suite
+	directory *testdirectory.Directory
+	options   *utils.LDAPOptions
+}
+
+func (s *LDAPTestSuite) SetupTest() {
+	t := s.T()
+
+	s.directory = testdirectory.Start(t,
+		testdirectory.WithNoTLS(t),
+		testdirectory.WithDefaults(t, &testdirectory.Defaults{
+			Users: []*gldap.Entry{
+				gldap.NewEntry(ldapTestServiceDN, map[string][]string{
+					"password": {"service-secret"},
+				}),
+				gldap.NewEntry(ldapTestAliceDN, map[string][]string{
+					"password":    {"alice-secret"},
+					"mail":        {"Alice@Example.com"},
+					"givenName":   {"Alice"},
+					"sn":          {"Liddell"},
+					"displayName": {"Alice Liddell"},
+					"memberOf":    {ldapTestGroupDN},
+				}),
+			},
+		}),
+	)
+
+	s.options = &utils.LDAPOptions{
+		URL:          fmt.Sprintf("ldap://%s:%d", s.directory.Host(), s.directory.Port()),
+		BindDN:       ldapTestServiceDN,
+		BindPassword: "service-secret",
+		BaseDN:       "ou=people,dc=example,dc=org",
+		UserFilter:   "(&(objectClass=person)(cn={username}))",
+		Timeout:      5 * time.Second,
+		Attributes: utils.LDAPAttributeMapping{
+			Email:       "mail",
+			FirstName:   "givenName",
+			LastName:    "sn",
+			DisplayName: "displayName",
+			Groups:      "memberOf",
+		},
+	}
+}
+
+func (s *LDAPTestSuite) TestAuthenticate_MapsAttributes() {
+	identity, err := utils.AuthenticateLDAP(s.options, "alice", "alice-secret")
+
+	s.Require().NoError(err)
+	s.Equal(ldapTestAliceDN, identity.DN)
+	s.Equal("alice@example.com", identity.Email)
+	s.Equal("Alice", identity.FirstName)
+	s.Equal("Liddell", identity.LastName)
+	s.Equal("Alice Liddell", identity.DisplayName)
+	s.Equal([]string{ldapTestGroupDN}, identity.Groups)
+}
+
+func (s *LDAPTestSuite) TestAuthenticate_WrongPassword() {
+	_, err := utils.AuthenticateLDAP(s.options, "alice", "wrong")
+
+	s.ErrorIs(err, utils.ErrLDAPInvalidCredentials)
+}
+
+func (s *LDAPTestSuite) TestAuthenticate_EmptyPasswordIsRejected() {
+	_, err := utils.AuthenticateLDAP(s.options, "alice", "")
+
+	s.ErrorIs(err, utils.ErrLDAPInvalidCredentials)
+}
+
+func (s *LDAPTestSuite) TestAuthenticate_UnknownUser() {
+	_, err := utils.AuthenticateLDAP(s.options, "bob", "alice-secret")
+
+	s.ErrorIs(err, utils.ErrLDAPUserNotFound)
+}
+
+func (s *LDAPTestSuite) TestAuthenticate_EscapesUsername() {
+	_, err := utils.AuthenticateLDAP(s.options, "*)(cn=*", "alice-secret")
+
+	s.ErrorIs(err, utils.ErrLDAPUserNotFound)
+}
+
+func (s *LDAPTestSuite) TestAuthenticate_ServiceAccountFailure() {
+	s.options.BindPassword = "wrong"
+
+	_, err := utils.AuthenticateLDAP(s.options, "alice", "alice-secret")
+
+	s.Error(err)
+	s.NotErrorIs(err, utils.ErrLDAPInvalidCredentials, "a broken service account is not the user's fault")
+}
+
+func (s *LDAPTestSuite) TestAuthenticate_ServerUnavailable() {
+	s.options.URL = fmt.Sprintf("ldap://127.0.0.1:%d", testdirectory.FreePort(s.T()))
+
+	_, err := utils.AuthenticateLDAP(s.options, "alice", "alice-secret")
+
+	s.Error(err)
+	s.NotErrorIs(err, utils.ErrLDAPInvalidCredentials)
+}
+
+func TestLDAPTestSuite(t *testing.T) {
+	suite.Run(t, new(LDAPTestSuite))
+}
+
+func TestLDAPDNEqual(t *testing.T) {
+	assert.True(t, utils.LDAPDNEqual("CN=Engineering, OU=Groups,DC=example,DC=org", ldapTestGroupDN))
+	assert.False(t, utils.LDAPDNEqual("cn=sales,ou=groups,dc=example,dc=org", ldapTestGroupDN))
+	require.True(t, utils.LDAPDNEqual("not a dn", "NOT A DN"))
+}
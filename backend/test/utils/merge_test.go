@@ -0,0 +1,55 @@
+package utils_test
+
+import (
+	"go-backend-v2/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergePatch_DeepMerge(t *testing.T) {
+	target := map[string]interface{}{
+		"version": 1,
+		"branding": map[string]interface{}{
+			"primary_color": "#112233",
+			"logo_url":      "https://example.com/logo.png",
+		},
+		"allowed_email_domains": []interface{}{"example.com"},
+	}
+	patch := map[string]interface{}{
+		"branding": map[string]interface{}{
+			"primary_color": "#445566",
+			"logo_url":      nil,
+		},
+		"allowed_email_domains": []interface{}{"example.org"},
+		"session_policy":        map[string]interface{}{"require_sso": true},
+	}
+
+	merged := utils.MergePatch(target, patch)
+
+	assert.Equal(t, map[string]interface{}{
+		"version":               1,
+		"branding":              map[string]interface{}{"primary_color": "#445566"},
+		"allowed_email_domains": []interface{}{"example.org"},
+		"session_policy":        map[string]interface{}{"require_sso": true},
+	}, merged)
+	assert.Equal(t, "#112233", target["branding"].(map[string]interface{})["primary_color"], "target is left untouched")
+}
+
+func TestMergePatch_NullRemovesKey(t *testing.T) {
+	merged := utils.MergePatch(map[string]interface{}{"a": 1, "b": 2}, map[string]interface{}{"a": nil, "c": nil})
+	assert.Equal(t, map[string]interface{}{"b": 2}, merged)
+}
+
+func TestMergePatch_ObjectReplacesScalar(t *testing.T) {
+	merged := utils.MergePatch(
+		map[string]interface{}{"branding": "legacy"},
+		map[string]interface{}{"branding": map[string]interface{}{"primary_color": "#000000", "logo_url": nil}},
+	)
+	assert.Equal(t, map[string]interface{}{"branding": map[string]interface{}{"primary_color": "#000000"}}, merged)
+}
+
+func TestMergePatch_NilTarget(t *testing.T) {
+	merged := utils.MergePatch(nil, map[string]interface{}{"version": 1})
+	assert.Equal(t, map[string]interface{}{"version": 1}, merged)
+}
@@ -0,0 +1,227 @@
+package utils_test
+
+import (
+	"fmt"
+	"go-backend-v2/pkg/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasPermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		granted    []string
+		permission string
+		expected   bool
+	}{
+		{"exact match", []string{"member:read"}, "member:read", true},
+		{"all grants everything", []string{"all"}, "role:delete", true},
+		{"resource wildcard", []string{"member:all"}, "member:remove", true},
+		{"other action", []string{"member:read"}, "member:update", false},
+		{"wildcard of another resource", []string{"role:all"}, "member:read", false},
+		{"resource prefix is not a match", []string{"member:all"}, "membership:read", false},
+		{"nothing granted", nil, "member:read", false},
+		{"star grants everything", []string{"*"}, "role:delete", true},
+		{"resource star", []string{"member:*"}, "member:remove", true},
+		{"action star", []string{"*:read"}, "role:read", true},
+		{"action star of another action", []string{"*:read"}, "role:delete", false},
+		{"deny wins over all", []string{"all", "!member:remove"}, "member:remove", false},
+		{"deny leaves other actions", []string{"all", "!member:remove"}, "member:read", true},
+		{"deny wins whatever the order", []string{"!member:*", "member:read"}, "member:read", false},
+		{"action deny on every resource", []string{"*:*", "!*:delete"}, "role:delete", false},
+		{"deny alone grants nothing", []string{"!member:remove"}, "member:read", false},
+		{"a deny is never granted", []string{"all"}, "!member:read", false},
+		{"invalid grant fails closed", []string{"all", "!Member:Remove"}, "member:read", false},
+		{"malformed permission", []string{"all"}, "member", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, utils.HasPermission(tt.granted, tt.permission))
+		})
+	}
+}
+
+func TestUnionPermissions(t *testing.T) {
+	direct := []string{"workspace:read", "member:read"}
+	group := []string{"member:read", "member:invite"}
+	other := []string{"role:all"}
+
+	union := utils.UnionPermissions(direct, group, other)
+	assert.Equal(t, []string{"workspace:read", "member:read", "member:invite", "role:all"}, union)
+	assert.True(t, utils.HasPermission(union, "member:invite"), "a group grant adds to the direct role")
+	assert.True(t, utils.HasPermission(union, "role:delete"))
+
+	assert.Empty(t, utils.UnionPermissions())
+	assert.NotNil(t, utils.UnionPermissions(nil, nil))
+}
+
+func TestParsePermission(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected utils.Permission
+	}{
+		{"member:read", utils.Permission{Resource: "member", Action: "read"}},
+		{" member:read ", utils.Permission{Resource: "member", Action: "read"}},
+		{"all", utils.Permission{Resource: "*", Action: "*"}},
+		{"*", utils.Permission{Resource: "*", Action: "*"}},
+		{"member:all", utils.Permission{Resource: "member", Action: "*"}},
+		{"*:read", utils.Permission{Resource: "*", Action: "read"}},
+		{"!role:delete", utils.Permission{Resource: "role", Action: "delete", Deny: true}},
+		{"!all", utils.Permission{Resource: "*", Action: "*", Deny: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			p, err := utils.ParsePermission(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, p)
+		})
+	}
+
+	for _, input := range []string{"", "member", "member:", ":read", "Member:read", "member:read:all", "!!member:read", "member :read", "mem*:read"} {
+		t.Run("invalid "+input, func(t *testing.T) {
+			_, err := utils.ParsePermission(input)
+			assert.ErrorIs(t, err, utils.ErrInvalidPermission)
+		})
+	}
+
+	p, _ := utils.ParsePermission("!member:all")
+	assert.Equal(t, "!member:*", p.String())
+}
+
+func TestPermissionSetAllowsWildcards(t *testing.T) {
+	set, err := utils.CompilePermissions([]string{"member:*", "*:read", "!role:read"})
+	require.NoError(t, err)
+
+	assert.True(t, set.Allows("member:*"), "granted as such")
+	assert.True(t, set.Allows("member:all"))
+	assert.False(t, set.Allows("*:read"), "role:read is denied")
+	assert.False(t, set.Allows("role:*"))
+	assert.False(t, set.Allows("*:*"))
+	assert.True(t, set.Allows("workspace:read"))
+	assert.False(t, set.Allows("role:read"))
+
+	_, err = utils.CompilePermissions([]string{"member:read", "bogus"})
+	assert.ErrorIs(t, err, utils.ErrInvalidPermission)
+}
+
+func TestResolveRolePermissions(t *testing.T) {
+	roles := map[string]utils.RoleDefinition{
+		"viewer":  {Permissions: []string{"*:read"}},
+		"editor":  {Permissions: []string{"member:invite"}, Inherits: []string{"viewer"}},
+		"auditor": {Permissions: []string{"!member:read"}, Inherits: []string{"viewer"}},
+		"lead":    {Permissions: []string{"role:update"}, Inherits: []string{"editor", "auditor", "deleted"}},
+	}
+
+	permissions, err := utils.ResolveRolePermissions(roles, "lead")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"role:update", "member:invite", "*:read", "!member:read"}, permissions,
+		"a role shared by two parents is counted once, missing roles grant nothing")
+	assert.True(t, utils.HasPermission(permissions, "role:read"))
+	assert.False(t, utils.HasPermission(permissions, "member:read"), "an inherited deny applies")
+
+	permissions, err = utils.ResolveRolePermissions(roles, "editor", "viewer")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"member:invite", "*:read"}, permissions)
+
+	permissions, err = utils.ResolveRolePermissions(roles, "deleted")
+	require.NoError(t, err)
+	assert.Empty(t, permissions)
+	assert.NotNil(t, permissions)
+}
+
+func TestResolveRolePermissionsCycle(t *testing.T) {
+	roles := map[string]utils.RoleDefinition{
+		"a": {Permissions: []string{"member:read"}, Inherits: []string{"b"}},
+		"b": {Permissions: []string{"role:read"}, Inherits: []string{"c"}},
+		"c": {Permissions: []string{"group:read"}, Inherits: []string{"a"}},
+		"d": {Inherits: []string{"d"}},
+		"e": {Inherits: []string{"b"}},
+	}
+
+	for _, roleID := range []string{"a", "d", "e"} {
+		_, err := utils.ResolveRolePermissions(roles, roleID)
+		assert.ErrorIs(t, err, utils.ErrRoleCycle, roleID)
+	}
+}
+
+func TestCachedPermissions(t *testing.T) {
+	grants := []string{"member:*", "!member:remove"}
+
+	first, err := utils.CachedPermissions(grants)
+	require.NoError(t, err)
+	second, err := utils.CachedPermissions([]string{"member:*", "!member:remove"})
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	other, err := utils.CachedPermissions([]string{"!member:remove", "member:*"})
+	require.NoError(t, err)
+	assert.NotSame(t, first, other, "sets are cached by their grants in order")
+	assert.Equal(t, first.Allows("member:remove"), other.Allows("member:remove"))
+}
+
+func TestHasPermissionDoesNotAllocate(t *testing.T) {
+	granted := benchmarkGrants(30)
+	utils.HasPermission(granted, "resource_0:read")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		utils.HasPermission(granted, "resource_29:update")
+	})
+	assert.Zero(t, allocs, "a cached check does not allocate")
+}
+
+// A request checks a handful of permissions through HasPermission, so a warm check on a role of
+// 30 grants must stay within 2µs, with room for slow machines; it takes about 400ns. The target is
+// checked by running the benchmark, timings taken during tests are too noisy to assert on.
+func BenchmarkHasPermission(b *testing.B) {
+	granted := benchmarkGrants(30)
+	utils.HasPermission(granted, "resource_0:read")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		utils.HasPermission(granted, "resource_29:update")
+	}
+}
+
+func BenchmarkCompilePermissions(b *testing.B) {
+	granted := benchmarkGrants(30)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := utils.CompilePermissions(granted); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkResolveRolePermissions(b *testing.B) {
+	// a chain of 10 roles, each inheriting from the previous one
+	roles := make(map[string]utils.RoleDefinition)
+	for i := 0; i < 10; i++ {
+		role := utils.RoleDefinition{Permissions: benchmarkGrants(3)}
+		if i > 0 {
+			role.Inherits = []string{fmt.Sprintf("role_%d", i-1)}
+		}
+		roles[fmt.Sprintf("role_%d", i)] = role
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := utils.ResolveRolePermissions(roles, "role_9"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkGrants(n int) []string {
+	grants := make([]string, 0, n+2)
+	for i := 0; i < n; i++ {
+		grants = append(grants, fmt.Sprintf("resource_%d:%s", i, []string{"read", "update", "*"}[i%3]))
+	}
+	return append(grants, "*:read", "!resource_1:delete")
+}
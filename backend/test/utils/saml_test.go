@@ -0,0 +1,240 @@
+package utils_test
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"go-backend-v2/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/crewjam/saml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	samlTestMetadataURL = "http://localhost:8080/api/v1/sso/saml/ws-1/metadata"
+	samlTestACSURL      = "http://localhost:8080/api/v1/sso/saml/ws-1/acs"
+	samlTestIdPMetadata = "https://idp.example.com/metadata"
+	samlTestIdPSSOURL   = "https://idp.example.com/sso"
+)
+
+// SAMLTestSuite runs the service provider against an in-process identity provider
+type SAMLTestSuite struct {
+	suite.Suite
+	idp     *saml.IdentityProvider
+	idpCert string
+	sp      *saml.ServiceProvider
+}
+
+func (suite *SAMLTestSuite) SetupTest() {
+	idpKeyPEM, idpCertPEM, err := utils.GenerateSelfSignedKeyPair("idp.example.com", time.Hour)
+	require.NoError(suite.T(), err)
+
+	idpKey, err := utils.ParseRSAPrivateKeyPEM(idpKeyPEM)
+	require.NoError(suite.T(), err)
+
+	idpCert, err := utils.ParseCertificatePEM(idpCertPEM)
+	require.NoError(suite.T(), err)
+
+	metadataURL, _ := url.Parse(samlTestIdPMetadata)
+	ssoURL, _ := url.Parse(samlTestIdPSSOURL)
+
+	suite.idp = &saml.IdentityProvider{
+		Key:         idpKey,
+		Certificate: idpCert,
+		MetadataURL: *metadataURL,
+		SSOURL:      *ssoURL,
+	}
+	suite.idpCert = idpCertPEM
+
+	idpMetadata, err := xml.Marshal(suite.idp.Metadata())
+	require.NoError(suite.T(), err)
+
+	suite.sp = suite.newServiceProvider(string(idpMetadata), false)
+}
+
+func (suite *SAMLTestSuite) newServiceProvider(idpMetadataXML string, allowIDPInitiated bool) *saml.ServiceProvider {
+	spKeyPEM, spCertPEM, err := utils.GenerateSelfSignedKeyPair(samlTestMetadataURL, time.Hour)
+	require.NoError(suite.T(), err)
+
+	sp, err := utils.NewSAMLServiceProvider(&utils.SAMLServiceProviderOptions{
+		MetadataURL:       samlTestMetadataURL,
+		ACSURL:            samlTestACSURL,
+		PrivateKeyPEM:     spKeyPEM,
+		CertificatePEM:    spCertPEM,
+		IdPMetadataXML:    idpMetadataXML,
+		IdPEntityID:       samlTestIdPMetadata,
+		IdPSSOURL:         samlTestIdPSSOURL,
+		IdPCertificatePEM: suite.idpCert,
+		AllowIDPInitiated: allowIDPInitiated,
+	})
+	require.NoError(suite.T(), err)
+
+	return sp
+}
+
+// respond lets the IdP answer requestID (empty for IdP-initiated) and returns the base64 response
+func (suite *SAMLTestSuite) respond(sp *saml.ServiceProvider, requestID string, session *saml.Session) string {
+	spMetadata := sp.Metadata()
+	descriptor := &spMetadata.SPSSODescriptors[0]
+
+	req := &saml.IdpAuthnRequest{
+		IDP:                     suite.idp,
+		HTTPRequest:             httptest.NewRequest(http.MethodPost, samlTestIdPSSOURL, nil),
+		Now:                     saml.TimeNow(),
+		ServiceProviderMetadata: spMetadata,
+		SPSSODescriptor:         descriptor,
+		ACSEndpoint:             &descriptor.AssertionConsumerServices[0],
+		Request: saml.AuthnRequest{
+			ID:     requestID,
+			Issuer: &saml.Issuer{Value: sp.EntityID},
+		},
+	}
+
+	require.NoError(suite.T(), saml.DefaultAssertionMaker{}.MakeAssertion(req, session))
+	require.NoError(suite.T(), req.MakeResponse())
+
+	doc := etree.NewDocument()
+	doc.SetRoot(req.ResponseEl)
+	raw, err := doc.WriteToBytes()
+	require.NoError(suite.T(), err)
+
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+func (suite *SAMLTestSuite) session() *saml.Session {
+	return &saml.Session{
+		ID:             "session-1",
+		NameID:         "jdoe",
+		UserEmail:      "John.Doe@Example.com",
+		UserGivenName:  "John",
+		UserSurname:    "Doe",
+		UserCommonName: "John Doe",
+		Groups:         []string{"engineering", "admins"},
+	}
+}
+
+func (suite *SAMLTestSuite) TestMakeRedirectRequest() {
+	redirectURL, requestID, err := utils.MakeSAMLRedirectRequest(suite.sp, "/dashboard")
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), requestID)
+
+	parsed, err := url.Parse(redirectURL)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "idp.example.com", parsed.Host)
+	assert.NotEmpty(suite.T(), parsed.Query().Get("SAMLRequest"))
+	assert.Equal(suite.T(), "/dashboard", parsed.Query().Get("RelayState"))
+}
+
+func (suite *SAMLTestSuite) TestParseResponse_SPInitiated() {
+	_, requestID, err := utils.MakeSAMLRedirectRequest(suite.sp, "")
+	require.NoError(suite.T(), err)
+
+	response := suite.respond(suite.sp, requestID, suite.session())
+
+	inResponseTo, err := utils.SAMLResponseRequestID(response)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), requestID, inResponseTo)
+
+	assertion, err := utils.ParseSAMLResponse(suite.sp, response, []string{requestID})
+	require.NoError(suite.T(), err)
+
+	identity := utils.ExtractSAMLIdentity(assertion, utils.SAMLAttributeMapping{Groups: "eduPersonAffiliation"})
+	assert.Equal(suite.T(), "jdoe", identity.NameID)
+	assert.Equal(suite.T(), "john.doe@example.com", identity.Email)
+	assert.Equal(suite.T(), "John", identity.FirstName)
+	assert.Equal(suite.T(), "Doe", identity.LastName)
+	assert.Equal(suite.T(), "John Doe", identity.DisplayName)
+	assert.Equal(suite.T(), []string{"engineering", "admins"}, identity.Groups)
+}
+
+func (suite *SAMLTestSuite) TestParseResponse_ManualIdPSettings() {
+	sp := suite.newServiceProvider("", false)
+
+	_, requestID, err := utils.MakeSAMLRedirectRequest(sp, "")
+	require.NoError(suite.T(), err)
+
+	assertion, err := utils.ParseSAMLResponse(sp, suite.respond(sp, requestID, suite.session()), []string{requestID})
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), assertion)
+}
+
+func (suite *SAMLTestSuite) TestParseResponse_UnknownRequestID() {
+	response := suite.respond(suite.sp, "id-unknown", suite.session())
+
+	_, err := utils.ParseSAMLResponse(suite.sp, response, []string{"id-expected"})
+	assert.Error(suite.T(), err)
+}
+
+func (suite *SAMLTestSuite) TestParseResponse_IdPInitiated() {
+	response := suite.respond(suite.sp, "", suite.session())
+
+	_, err := utils.ParseSAMLResponse(suite.sp, response, nil)
+	assert.Error(suite.T(), err, "IdP-initiated responses are rejected unless allowed")
+
+	allowed := suite.newServiceProvider("", true)
+	_, err = utils.ParseSAMLResponse(allowed, suite.respond(allowed, "", suite.session()), nil)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *SAMLTestSuite) TestParseResponse_UntrustedIdP() {
+	otherKeyPEM, otherCertPEM, err := utils.GenerateSelfSignedKeyPair("evil.example.com", time.Hour)
+	require.NoError(suite.T(), err)
+
+	suite.idp.Key, err = utils.ParseRSAPrivateKeyPEM(otherKeyPEM)
+	require.NoError(suite.T(), err)
+	suite.idp.Certificate, err = utils.ParseCertificatePEM(otherCertPEM)
+	require.NoError(suite.T(), err)
+
+	_, requestID, err := utils.MakeSAMLRedirectRequest(suite.sp, "")
+	require.NoError(suite.T(), err)
+
+	_, err = utils.ParseSAMLResponse(suite.sp, suite.respond(suite.sp, requestID, suite.session()), []string{requestID})
+	assert.Error(suite.T(), err)
+}
+
+func (suite *SAMLTestSuite) TestMetadataXML() {
+	metadata, err := utils.SAMLMetadataXML(suite.sp)
+
+	assert.NoError(suite.T(), err)
+	assert.Contains(suite.T(), string(metadata), samlTestACSURL)
+	assert.Contains(suite.T(), string(metadata), samlTestMetadataURL)
+}
+
+func TestSAMLTestSuite(t *testing.T) {
+	suite.Run(t, new(SAMLTestSuite))
+}
+
+func TestExtractSAMLIdentity_EmailFromNameID(t *testing.T) {
+	assertion := &saml.Assertion{
+		Subject: &saml.Subject{NameID: &saml.NameID{Value: "Jane@Example.com"}},
+	}
+
+	identity := utils.ExtractSAMLIdentity(assertion, utils.SAMLAttributeMapping{})
+
+	assert.Equal(t, "Jane@Example.com", identity.NameID)
+	assert.Equal(t, "jane@example.com", identity.Email)
+}
+
+func TestExtractSAMLIdentity_ConfiguredMappingWins(t *testing.T) {
+	assertion := &saml.Assertion{
+		Subject: &saml.Subject{NameID: &saml.NameID{Value: "u-1"}},
+		AttributeStatements: []saml.AttributeStatement{{
+			Attributes: []saml.Attribute{
+				{Name: "mail", Values: []saml.AttributeValue{{Value: "fallback@example.com"}}},
+				{Name: "workEmail", Values: []saml.AttributeValue{{Value: "primary@example.com"}}},
+			},
+		}},
+	}
+
+	identity := utils.ExtractSAMLIdentity(assertion, utils.SAMLAttributeMapping{Email: "workEmail"})
+
+	assert.Equal(t, "primary@example.com", identity.Email)
+}
@@ -0,0 +1,184 @@
+package utils_test
+
+import (
+	"go-backend-v2/pkg/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var scimTestColumns = map[string]utils.SCIMFilterColumn{
+	"username":        {Column: "users.email"},
+	"emails.value":    {Column: "users.email"},
+	"emails.type":     {Column: "emails.type"},
+	"name.givenname":  {Column: "profiles.first_name"},
+	"active":          {Column: "memberships.status = 'active'", Kind: utils.SCIMAttributeBoolean},
+	"meta.created":    {Column: "memberships.created_at", Kind: utils.SCIMAttributeDateTime},
+	"name.familyname": {Column: "profiles.last_name"},
+}
+
+func TestParseSCIMFilter_Comparison(t *testing.T) {
+	filter, err := utils.ParseSCIMFilter(`userName eq "john@example.com"`)
+
+	require.NoError(t, err)
+	assert.Equal(t, utils.SCIMFilterEq, filter.Op)
+	assert.Equal(t, "userName", filter.Attribute)
+	assert.Equal(t, "john@example.com", filter.Value)
+}
+
+func TestParseSCIMFilter_Precedence(t *testing.T) {
+	filter, err := utils.ParseSCIMFilter(`userName sw "j" or name.givenName eq "Ann" and active eq true`)
+
+	require.NoError(t, err)
+	assert.Equal(t, utils.SCIMFilterOr, filter.Op, "and binds tighter than or")
+	assert.Equal(t, utils.SCIMFilterSw, filter.Left.Op)
+	assert.Equal(t, utils.SCIMFilterAnd, filter.Right.Op)
+	assert.Equal(t, true, filter.Right.Right.Value)
+}
+
+func TestParseSCIMFilter_Values(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   string
+		expected interface{}
+	}{
+		{"String with escaped quote", `displayName eq "say \"hi\""`, `say "hi"`},
+		{"Boolean is case insensitive", `active eq False`, false},
+		{"Null", `externalId eq null`, nil},
+		{"Number", `meta.version gt 2.5`, 2.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := utils.ParseSCIMFilter(tt.filter)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, filter.Value)
+		})
+	}
+}
+
+func TestParseSCIMFilter_SchemaURNAndValuePath(t *testing.T) {
+	filter, err := utils.ParseSCIMFilter(`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "a" and emails[type eq "work" and value co "@example.com"]`)
+
+	require.NoError(t, err)
+	assert.Equal(t, "userName", filter.Left.Attribute)
+	assert.Equal(t, "emails.type", filter.Right.Left.Attribute)
+	assert.Equal(t, "emails.value", filter.Right.Right.Attribute)
+}
+
+func TestParseSCIMFilter_Errors(t *testing.T) {
+	tests := []string{
+		``,
+		`userName`,
+		`userName eq`,
+		`userName like "a"`,
+		`userName eq "unterminated`,
+		`(userName eq "a"`,
+		`userName eq "a" extra`,
+		`not userName eq "a"`,
+		`emails[type eq "work"`,
+	}
+
+	for _, filter := range tests {
+		t.Run(filter, func(t *testing.T) {
+			_, err := utils.ParseSCIMFilter(filter)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSCIMFilterToSQL(t *testing.T) {
+	tests := []struct {
+		name         string
+		filter       string
+		expectedSQL  string
+		expectedArgs []interface{}
+	}{
+		{"Equality", `userName eq "a@example.com"`, "users.email = ?", []interface{}{"a@example.com"}},
+		{"Attribute names are case insensitive", `USERNAME eq "a"`, "users.email = ?", []interface{}{"a"}},
+		{"Not equal includes nulls", `name.givenName ne "Ann"`, "(profiles.first_name <> ? OR profiles.first_name IS NULL)", []interface{}{"Ann"}},
+		{"Contains escapes LIKE wildcards", `userName co "50%_off"`, "users.email LIKE ?", []interface{}{`%50\%\_off%`}},
+		{"Starts with", `userName sw "jo"`, "users.email LIKE ?", []interface{}{"jo%"}},
+		{"Ends with", `userName ew "@example.com"`, "users.email LIKE ?", []interface{}{"%@example.com"}},
+		{"Present", `name.familyName pr`, "(profiles.last_name IS NOT NULL AND profiles.last_name <> '')", nil},
+		{"Boolean true", `active eq true`, "(memberships.status = 'active')", nil},
+		{"Boolean false", `active eq false`, "(NOT (memberships.status = 'active'))", nil},
+		{"Boolean ne true", `active ne true`, "(NOT (memberships.status = 'active'))", nil},
+		{
+			"Logical operators",
+			`userName eq "a" or not (active eq true and name.givenName sw "B")`,
+			"(users.email = ? OR (NOT ((memberships.status = 'active') AND profiles.first_name LIKE ?)))",
+			[]interface{}{"a", "B%"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := utils.ParseSCIMFilter(tt.filter)
+			require.NoError(t, err)
+
+			sql, args, err := utils.SCIMFilterToSQL(filter, scimTestColumns)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedSQL, sql)
+			assert.Equal(t, tt.expectedArgs, args)
+		})
+	}
+}
+
+func TestSCIMFilterToSQL_DateTime(t *testing.T) {
+	filter, err := utils.ParseSCIMFilter(`meta.created gt "2024-05-01T10:00:00Z"`)
+	require.NoError(t, err)
+
+	sql, args, err := utils.SCIMFilterToSQL(filter, scimTestColumns)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "memberships.created_at > ?", sql)
+	assert.Equal(t, []interface{}{time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}, args)
+}
+
+func TestSCIMFilterToSQL_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+	}{
+		{"Unknown attribute", `password eq "secret"`},
+		{"String attribute with boolean value", `userName eq true`},
+		{"Boolean attribute with ordering operator", `active gt true`},
+		{"Invalid dateTime", `meta.created gt "yesterday"`},
+		{"Substring on dateTime", `meta.created co "2024"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := utils.ParseSCIMFilter(tt.filter)
+			require.NoError(t, err)
+
+			_, _, err = utils.SCIMFilterToSQL(filter, scimTestColumns)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestGenerateSecureToken(t *testing.T) {
+	first, err := utils.GenerateSecureToken("scim_", 32)
+	require.NoError(t, err)
+	second, err := utils.GenerateSecureToken("scim_", 32)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(first, "scim_"))
+	assert.Len(t, first, len("scim_")+43)
+	assert.NotEqual(t, first, second)
+}
+
+func TestHashToken(t *testing.T) {
+	hash := utils.HashToken("scim_token")
+
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, utils.HashToken("scim_token"))
+	assert.NotEqual(t, hash, utils.HashToken("scim_other"))
+}
@@ -0,0 +1,31 @@
+package utils_test
+
+import (
+	"go-backend-v2/pkg/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionIdleDeadline(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(72 * time.Hour)
+
+	tests := []struct {
+		name        string
+		idleTimeout time.Duration
+		expiresAt   time.Time
+		expected    time.Time
+	}{
+		{"Slides by the idle timeout", 8 * time.Hour, expiresAt, now.Add(8 * time.Hour)},
+		{"Capped by the absolute expiry", 8 * time.Hour, now.Add(time.Hour), now.Add(time.Hour)},
+		{"No idle timeout", 0, expiresAt, expiresAt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, utils.SessionIdleDeadline(now, tt.idleTimeout, tt.expiresAt))
+		})
+	}
+}
@@ -3,6 +3,7 @@ package utils_test
 import (
 	"fmt"
 	"go-backend-v2/pkg/utils"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -82,7 +83,7 @@ func TestGenerateSlug(t *testing.T) {
 		{
 			name:     "Name with accented characters",
 			input:    "Café Workspace",
-			expected: "caf-workspace",
+			expected: "cafe-workspace",
 		},
 		{
 			name:     "Name with only hyphens",
@@ -92,7 +93,27 @@ func TestGenerateSlug(t *testing.T) {
 		{
 			name:     "Vietnamese workspace name",
 			input:    "Không gian làm việc",
-			expected: "khng-gian-lm-vic",
+			expected: "khong-gian-lam-viec",
+		},
+		{
+			name:     "Vietnamese name with d stroke",
+			input:    "Công ty Đất Việt",
+			expected: "cong-ty-dat-viet",
+		},
+		{
+			name:     "Letters without decomposition",
+			input:    "Straße Ærø Łódź",
+			expected: "strasse-aero-lodz",
+		},
+		{
+			name:     "Latin name mixed with CJK",
+			input:    "東京 Office",
+			expected: "office",
+		},
+		{
+			name:     "Tabs and newlines",
+			input:    "My\tAwesome\nWorkspace",
+			expected: "my-awesome-workspace",
 		},
 	}
 
@@ -104,11 +125,52 @@ func TestGenerateSlug(t *testing.T) {
 	}
 }
 
-func TestGenerateUniqueSlug_NoConflict(t *testing.T) {
-	checkExists := func(slug string) (bool, error) {
-		return false, nil
+func TestGenerateSlug_CJKFallback(t *testing.T) {
+	slug := utils.GenerateSlug("東京チーム")
+	assert.Regexp(t, `^workspace-[0-9a-f]{8}$`, slug)
+	assert.Equal(t, slug, utils.GenerateSlug("東京チーム"), "the fallback is stable for a name")
+	assert.NotEqual(t, slug, utils.GenerateSlug("大阪チーム"))
+}
+
+func TestGenerateSlug_Truncated(t *testing.T) {
+	slug := utils.GenerateSlug(strings.Repeat("word ", 30))
+	assert.LessOrEqual(t, len(slug), utils.MaxSlugLength)
+	assert.True(t, utils.IsValidSlug(slug), slug)
+}
+
+func TestIsValidSlug(t *testing.T) {
+	for _, slug := range []string{"acme", "acme-2", "a1-b2-c3", strings.Repeat("a", utils.MaxSlugLength)} {
+		assert.True(t, utils.IsValidSlug(slug), slug)
+	}
+	for _, slug := range []string{"", "Acme", "-acme", "acme-", "ac--me", "ac_me", "công-ty", strings.Repeat("a", utils.MaxSlugLength+1)} {
+		assert.False(t, utils.IsValidSlug(slug), slug)
 	}
+}
+
+func TestIsReservedSlug(t *testing.T) {
+	assert.True(t, utils.IsReservedSlug("admin"))
+	assert.True(t, utils.IsReservedSlug("settings"))
+	assert.True(t, utils.IsReservedSlug("joinable"), "GET /workspaces/joinable would shadow it")
+	assert.True(t, utils.IsReservedSlug("0b8e6f3c-4a52-4f8e-9d3a-2c1b7e5f9a60"), "UUIDs are looked up as IDs")
+	assert.False(t, utils.IsReservedSlug("acme"))
+	assert.False(t, utils.IsReservedSlug("admin-1"))
+}
+
+// createWithConflicts mimics an insert rejected by the unique index for the given slugs
+func createWithConflicts(conflictingSlugs ...string) func(string) error {
+	conflicts := make(map[string]bool)
+	for _, slug := range conflictingSlugs {
+		conflicts[slug] = true
+	}
+	return func(slug string) error {
+		if conflicts[slug] {
+			return utils.ErrSlugTaken
+		}
+		return nil
+	}
+}
 
+func TestGenerateUniqueSlug_NoConflict(t *testing.T) {
 	tests := []struct {
 		name     string
 		input    string
@@ -128,7 +190,7 @@ func TestGenerateUniqueSlug_NoConflict(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := utils.GenerateUniqueSlug(tt.input, checkExists)
+			result, err := utils.GenerateUniqueSlug(tt.input, createWithConflicts())
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expected, result)
 		})
@@ -161,52 +223,50 @@ func TestGenerateUniqueSlug_WithConflicts(t *testing.T) {
 			expected:         "my-workspace-2",
 		},
 		{
-			name:             "Many conflicts",
-			input:            "Popular Name",
-			conflictingSlugs: generateSlugSequence("popular-name", 10),
-			expected:         "popular-name-10",
+			name:             "Reserved slug",
+			input:            "Admin",
+			conflictingSlugs: nil,
+			expected:         "admin-1",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Create a map for quick lookup
-			conflicts := make(map[string]bool)
-			for _, slug := range tt.conflictingSlugs {
-				conflicts[slug] = true
-			}
-
-			checkExists := func(slug string) (bool, error) {
-				return conflicts[slug], nil
-			}
-
-			result, err := utils.GenerateUniqueSlug(tt.input, checkExists)
+			result, err := utils.GenerateUniqueSlug(tt.input, createWithConflicts(tt.conflictingSlugs...))
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
 
-func TestGenerateUniqueSlug_CheckExistsError(t *testing.T) {
-	// Mock function that returns an error
-	checkExists := func(slug string) (bool, error) {
-		return false, fmt.Errorf("database connection failed")
+func TestGenerateUniqueSlug_RandomSuffixAfterSequence(t *testing.T) {
+	result, err := utils.GenerateUniqueSlug("Popular Name", createWithConflicts(generateSlugSequence("popular-name", 10)...))
+	assert.NoError(t, err)
+	assert.Regexp(t, `^popular-name-[a-z0-9]{6}$`, result)
+}
+
+func TestGenerateUniqueSlug_CreateError(t *testing.T) {
+	dbErr := fmt.Errorf("database connection failed")
+	attempts := 0
+	create := func(slug string) error {
+		attempts++
+		return dbErr
 	}
 
-	result, err := utils.GenerateUniqueSlug("My Workspace", checkExists)
-	assert.Error(t, err)
+	result, err := utils.GenerateUniqueSlug("My Workspace", create)
+	assert.ErrorIs(t, err, dbErr)
 	assert.Empty(t, result)
-	assert.Contains(t, err.Error(), "failed to check slug existence")
+	assert.Equal(t, 1, attempts, "only a taken slug is retried")
 }
 
 func TestGenerateUniqueSlug_TooManyConflicts(t *testing.T) {
-	// Mock function that always returns true (everything conflicts)
-	checkExists := func(slug string) (bool, error) {
-		return true, nil
+	// Every candidate is rejected by the unique index
+	create := func(slug string) error {
+		return utils.ErrSlugTaken
 	}
 
-	result, err := utils.GenerateUniqueSlug("My Workspace", checkExists)
-	assert.Error(t, err)
+	result, err := utils.GenerateUniqueSlug("My Workspace", create)
+	assert.ErrorIs(t, err, utils.ErrSlugTaken)
 	assert.Empty(t, result)
 	assert.Contains(t, err.Error(), "unable to generate unique slug")
 }
@@ -229,14 +289,9 @@ func TestGenerateUniqueSlug_EdgeCases(t *testing.T) {
 		},
 	}
 
-	// Mock function that never conflicts
-	checkExists := func(slug string) (bool, error) {
-		return false, nil
-	}
-
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := utils.GenerateUniqueSlug(tt.input, checkExists)
+			result, err := utils.GenerateUniqueSlug(tt.input, createWithConflicts())
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expected, result)
 		})
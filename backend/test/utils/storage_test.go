@@ -0,0 +1,179 @@
+package utils_test
+
+import (
+	"bufio"
+	"context"
+	"go-backend-v2/pkg/utils"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStorage_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	storage, err := utils.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	content := `{"hello":"world"}`
+	err = storage.Put(ctx, "exports/user-1/export-1.json", strings.NewReader(content), int64(len(content)), "application/json")
+	require.NoError(t, err)
+
+	body, err := storage.Open(ctx, "exports/user-1/export-1.json")
+	require.NoError(t, err)
+	data, err := io.ReadAll(body)
+	body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+
+	signed, err := storage.SignedURL(ctx, "exports/user-1/export-1.json", time.Minute)
+	assert.NoError(t, err)
+	assert.Empty(t, signed, "local files are served by the application")
+
+	require.NoError(t, storage.Delete(ctx, "exports/user-1/export-1.json"))
+	_, err = storage.Open(ctx, "exports/user-1/export-1.json")
+	assert.ErrorIs(t, err, utils.ErrStorageObjectNotFound)
+
+	assert.NoError(t, storage.Delete(ctx, "exports/user-1/export-1.json"), "deleting twice is not an error")
+}
+
+func TestLocalStorage_RejectsEscapingKeys(t *testing.T) {
+	ctx := context.Background()
+	storage, err := utils.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	err = storage.Put(ctx, "../outside.json", strings.NewReader("x"), 1, "application/json")
+	assert.Error(t, err)
+
+	_, err = storage.Open(ctx, "exports/../../etc/passwd")
+	assert.Error(t, err)
+}
+
+// fakeS3 is a minimal in-memory stand-in for an S3 compatible server (path-style PUT, GET,
+// HEAD and DELETE on one bucket), enough to exercise the S3 storage without a MinIO instance
+type fakeS3 struct {
+	mu      sync.Mutex
+	bucket  string
+	objects map[string]fakeS3Object
+}
+
+type fakeS3Object struct {
+	data        []byte
+	contentType string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != f.bucket || key == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		data, err := readS3Payload(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = fakeS3Object{data: data, contentType: r.Header.Get("Content-Type")}
+		w.Header().Set("ETag", `"fake-etag"`)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet, http.MethodHead:
+		object, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		w.Header().Set("ETag", `"fake-etag"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			w.Write(object.data)
+		}
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// readS3Payload returns the object body, decoding the aws-chunked encoding clients use to
+// stream signed uploads over plain HTTP
+func readS3Payload(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var data []byte
+	reader := bufio.NewReader(r.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return data, nil
+		}
+		chunk := make([]byte, size+2) // chunk data followed by CRLF
+		if _, err := io.ReadFull(reader, chunk); err != nil {
+			return nil, err
+		}
+		data = append(data, chunk[:size]...)
+	}
+}
+
+func TestS3Storage_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeS3{bucket: "iam", objects: map[string]fakeS3Object{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	storage, err := utils.NewS3Storage(&utils.S3StorageOptions{
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		Region:    "us-east-1",
+		Bucket:    "iam",
+		AccessKey: "access",
+		SecretKey: "secret-secret",
+	})
+	require.NoError(t, err)
+
+	content := "avatar-bytes"
+	err = storage.Put(ctx, "avatars/users/u1/a.png", strings.NewReader(content), int64(len(content)), "image/png")
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", fake.objects["avatars/users/u1/a.png"].contentType)
+
+	body, err := storage.Open(ctx, "avatars/users/u1/a.png")
+	require.NoError(t, err)
+	data, err := io.ReadAll(body)
+	body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+
+	signed, err := storage.SignedURL(ctx, "avatars/users/u1/a.png", time.Minute)
+	require.NoError(t, err)
+	assert.Contains(t, signed, "/iam/avatars/users/u1/a.png")
+	assert.Contains(t, signed, "X-Amz-Signature=")
+
+	require.NoError(t, storage.Delete(ctx, "avatars/users/u1/a.png"))
+	_, err = storage.Open(ctx, "avatars/users/u1/a.png")
+	assert.ErrorIs(t, err, utils.ErrStorageObjectNotFound)
+}
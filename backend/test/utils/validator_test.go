@@ -0,0 +1,86 @@
+package utils_test
+
+import (
+	"go-backend-v2/internal/dto"
+	"go-backend-v2/pkg/utils"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestValidator() *validator.Validate {
+	v := validator.New()
+	utils.SetupCustomValidators(v)
+	return v
+}
+
+func TestIsIANATimezone(t *testing.T) {
+	assert.True(t, utils.IsIANATimezone("UTC"))
+	assert.True(t, utils.IsIANATimezone("Europe/Berlin"))
+	assert.True(t, utils.IsIANATimezone("America/Argentina/Buenos_Aires"))
+
+	assert.False(t, utils.IsIANATimezone(""))
+	assert.False(t, utils.IsIANATimezone("Local"))
+	assert.False(t, utils.IsIANATimezone("Mars/Olympus_Mons"))
+}
+
+func TestIsLanguageTag(t *testing.T) {
+	assert.True(t, utils.IsLanguageTag("en"))
+	assert.True(t, utils.IsLanguageTag("pt-BR"))
+
+	assert.False(t, utils.IsLanguageTag(""))
+	assert.False(t, utils.IsLanguageTag("not a locale"))
+	assert.False(t, utils.IsLanguageTag("zh-Hant-TW-x-private"), "longer than the locale column")
+}
+
+func TestUpdateProfileRequest_Validation(t *testing.T) {
+	v := newTestValidator()
+	ptr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name  string
+		req   dto.UpdateProfileRequest
+		valid bool
+	}{
+		{"empty update", dto.UpdateProfileRequest{}, true},
+		{"full update", dto.UpdateProfileRequest{
+			FirstName:   ptr("Mary-Jane"),
+			LastName:    ptr("O'Neil"),
+			DateOfBirth: ptr("1990-04-01"),
+			Country:     ptr("DE"),
+			Timezone:    ptr("Europe/Berlin"),
+			Locale:      ptr("de-DE"),
+		}, true},
+		{"clear optional fields", dto.UpdateProfileRequest{DisplayName: ptr(""), Country: ptr(""), DateOfBirth: ptr("")}, true},
+		{"blank first name", dto.UpdateProfileRequest{FirstName: ptr("")}, false},
+		{"digits in last name", dto.UpdateProfileRequest{LastName: ptr("R2D2")}, false},
+		{"unknown timezone", dto.UpdateProfileRequest{Timezone: ptr("Europe/Atlantis")}, false},
+		{"timezone offset", dto.UpdateProfileRequest{Timezone: ptr("+02:00")}, false},
+		{"invalid locale", dto.UpdateProfileRequest{Locale: ptr("english")}, false},
+		{"unknown country", dto.UpdateProfileRequest{Country: ptr("XX")}, false},
+		{"malformed date of birth", dto.UpdateProfileRequest{DateOfBirth: ptr("01/04/1990")}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Struct(&tt.req)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestIsResourceName(t *testing.T) {
+	assert.True(t, utils.IsResourceName("member"))
+	assert.True(t, utils.IsResourceName("billing_invoice2"))
+
+	assert.False(t, utils.IsResourceName(""))
+	assert.False(t, utils.IsResourceName("Member"))
+	assert.False(t, utils.IsResourceName("2fa"))
+	assert.False(t, utils.IsResourceName("member:read"))
+	assert.False(t, utils.IsResourceName("billing-invoice"))
+}